@@ -112,11 +112,59 @@ type PgclusterSpec struct {
 	BackrestS3Region   string                   `json:"backrestS3Region"`
 	BackrestS3Endpoint string                   `json:"backrestS3Endpoint"`
 	BackrestRepoPath   string                   `json:"backrestRepoPath"`
+	BackrestRetention  PgBackRestRetentionSpec  `json:"backrestRetention"`
 	TablespaceMounts   map[string]PgStorageSpec `json:"tablespaceMounts"`
 	TLS                TLSSpec                  `json:"tls"`
 	TLSOnly            bool                     `json:"tlsOnly"`
 	Standby            bool                     `json:"standby"`
 	Shutdown           bool                     `json:"shutdown"`
+	// BackrestRepos, if specified, are the pgBackRest repositories of the
+	// cluster. The first is the local repository of the pgBackRest repository
+	// Deployment, and the others are remote repositories that each backup and
+	// WAL archive can be sent to
+	BackrestRepos []PgBackRestRepoSpec `json:"backrestRepos,omitempty"`
+	// BackrestGCSBucket, BackrestGCSEndpoint and BackrestGCSKeyType are the
+	// location of a "gcs" pgBackRest repository and the type of its key, i.e.
+	// "service" or "token"
+	BackrestGCSBucket   string `json:"backrestGCSBucket,omitempty"`
+	BackrestGCSEndpoint string `json:"backrestGCSEndpoint,omitempty"`
+	BackrestGCSKeyType  string `json:"backrestGCSKeyType,omitempty"`
+	// BackrestAzureContainer and BackrestAzureEndpoint are the location of an
+	// "azure" pgBackRest repository, BackrestAzureKeyType the type of its key,
+	// i.e. "shared" or "sas", and BackrestAzureURIStyle the style of its URIs,
+	// i.e. "host" or "path"
+	BackrestAzureContainer string `json:"backrestAzureContainer,omitempty"`
+	BackrestAzureEndpoint  string `json:"backrestAzureEndpoint,omitempty"`
+	BackrestAzureKeyType   string `json:"backrestAzureKeyType,omitempty"`
+	BackrestAzureURIStyle  string `json:"backrestAzureURIStyle,omitempty"`
+	// BackrestStorageNoVerifyTLS turns off the verification of the TLS
+	// certificate of the "s3", "gcs" or "azure" storage of the pgBackRest
+	// repository, e.g. of a local emulator of that storage
+	BackrestStorageNoVerifyTLS bool `json:"backrestStorageNoVerifyTLS,omitempty"`
+	// SyncReplicationSettings, if specified, tune the synchronous replication
+	// that is turned on by SyncReplication, and imply it when SyncReplication is
+	// not set
+	SyncReplicationSettings *SyncReplicationSpec `json:"syncReplicationSettings,omitempty"`
+	// Parameters are the PostgreSQL parameters of the cluster, i.e. the settings
+	// of "postgresql.conf", which are stored in the DCS configuration. Some only
+	// take effect once the instances are restarted
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// SyncReplicationSpec is the synchronous replication settings of a cluster,
+// which are stored in the Patroni DCS configuration of the cluster and in the
+// local configuration of its excluded replicas
+// swagger:ignore
+type SyncReplicationSpec struct {
+	// NodeCount is the number of synchronous standbys, i.e. Patroni's
+	// "synchronous_node_count". Patroni defaults it to 1 when it is 0
+	NodeCount int `json:"nodeCount,omitempty"`
+	// Strict, i.e. Patroni's "synchronous_mode_strict", stops writes on the
+	// primary when no synchronous standby is available
+	Strict bool `json:"strict,omitempty"`
+	// ExcludedReplicas are the names of the replicas that are never chosen as
+	// synchronous standbys, e.g. a replica used for disaster recovery
+	ExcludedReplicas []string `json:"excludedReplicas,omitempty"`
 }
 
 // PgclusterList is the CRD that defines a Crunchy PG Cluster List
@@ -134,12 +182,104 @@ type PgclusterList struct {
 type PgclusterStatus struct {
 	State   PgclusterState `json:"state,omitempty"`
 	Message string         `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the pgcluster spec that was most
+	// recently acted upon by the Operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the health of the cluster, see the
+	// PgclusterCondition* constants for the conditions that are reported
+	Conditions []PgclusterCondition `json:"conditions,omitempty"`
+	// Instances contains the status of each PostgreSQL instance of the cluster
+	Instances []PgclusterInstanceStatus `json:"instances,omitempty"`
+	// Primary is the name of the instance that is currently the primary
+	Primary string `json:"primary,omitempty"`
+	// LastBackupTime is the time the most recent successful pgBackRest backup
+	// completed
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
 }
 
 // PgclusterState is the crd that defines PG Cluster Stage
 // swagger:ignore
 type PgclusterState string
 
+// PgclusterConditionType is the type of a condition reported in the status of
+// a pgcluster
+type PgclusterConditionType string
+
+// PgclusterCondition is an observation about the health of a pgcluster, which
+// follows the conventions of the conditions of the built-in Kubernetes types
+// swagger:ignore
+type PgclusterCondition struct {
+	Type   PgclusterConditionType `json:"type"`
+	Status v1.ConditionStatus     `json:"status"`
+	// LastTransitionTime is the last time the status of the condition changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase, machine readable reason for the status
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PgclusterInstanceStatus is the status of a single PostgreSQL instance, i.e.
+// the primary or a replica, of a pgcluster
+// swagger:ignore
+type PgclusterInstanceStatus struct {
+	// Name is the name of the instance, which is also the name of its Deployment
+	Name string `json:"name"`
+	Pod  string `json:"pod,omitempty"`
+	// Role is either "primary" or "replica"
+	Role     string `json:"role,omitempty"`
+	Ready    bool   `json:"ready"`
+	Timeline int    `json:"timeline,omitempty"`
+	// ReplicationLagMB is how far behind the primary the instance is, in
+	// megabytes, as reported by Patroni
+	ReplicationLagMB int `json:"replicationLagMB,omitempty"`
+	// PendingRestart is whether PostgreSQL parameters were changed that only
+	// take effect once the instance is restarted
+	PendingRestart bool `json:"pendingRestart,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, or nil if it is not
+// set
+func (s *PgclusterStatus) GetCondition(conditionType PgclusterConditionType) *PgclusterCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates a condition. The transition time is only moved
+// forward when the status of the condition changes
+func (s *PgclusterStatus) SetCondition(condition PgclusterCondition) {
+	existing := s.GetCondition(condition.Type)
+
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		s.Conditions = append(s.Conditions, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		}
+	}
+
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
+
+// IsConditionTrue returns true if the condition of the given type is set and
+// its status is "True"
+func (s *PgclusterStatus) IsConditionTrue(conditionType PgclusterConditionType) bool {
+	condition := s.GetCondition(conditionType)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
 // PodAntiAffinityDeployment distinguishes between the different types of
 // Deployments that can leverage PodAntiAffinity
 type PodAntiAffinityDeployment int
@@ -166,11 +306,91 @@ type PodAntiAffinitySpec struct {
 	PgBouncer  PodAntiAffinityType `json:"pgBouncer"`
 }
 
+// PgBackRestRetentionSpec is the retention policy of the pgBackRest repository
+// of a cluster, which is applied each time pgBackRest expires backups and WAL
+// archives. A value of 0 leaves the setting to pgBackRest
+type PgBackRestRetentionSpec struct {
+	// Full is the number of full backups to retain
+	Full int `json:"full"`
+	// Diff is the number of differential backups to retain
+	Diff int `json:"diff"`
+	// Archive is the number of backups of type ArchiveType to retain the WAL
+	// archives of
+	Archive int `json:"archive"`
+	// ArchiveType is the type of backup, i.e. "full", "diff" or "incr", that
+	// Archive refers to
+	ArchiveType string `json:"archiveType"`
+}
+
+// IsSet returns true if any of the settings of the retention policy are set
+func (s PgBackRestRetentionSpec) IsSet() bool {
+	return s != PgBackRestRetentionSpec{}
+}
+
+// PgBackRestRepoSpec is a pgBackRest repository of a cluster. Repositories are
+// numbered by their position in the list of repositories of the cluster, i.e.
+// the first is "repo1", which pgBackRest commands select with "--repo"
+type PgBackRestRepoSpec struct {
+	// Type is the type of storage of the repository. The first repository is
+	// always "local", and the others are "s3" or "azure"
+	Type string `json:"type"`
+	// Path is the path of the repository within its storage, which defaults to
+	// the path of the local repository. It cannot be set on the first repository
+	Path string `json:"path,omitempty"`
+	// Secret is the name of the Secret that holds the credentials of a remote
+	// repository, i.e. the "aws-s3-key" and "aws-s3-key-secret" of an "s3"
+	// repository, or the "azure-account" and "azure-key" of an "azure" one
+	Secret string `json:"secret,omitempty"`
+	// S3Bucket, S3Endpoint and S3Region are the location of an "s3" repository
+	S3Bucket   string `json:"s3Bucket,omitempty"`
+	S3Endpoint string `json:"s3Endpoint,omitempty"`
+	S3Region   string `json:"s3Region,omitempty"`
+	// AzureContainer, AzureEndpoint, AzureKeyType and AzureURIStyle are the
+	// location of an "azure" repository, the type of its key and the style of
+	// its URIs
+	AzureContainer string `json:"azureContainer,omitempty"`
+	AzureEndpoint  string `json:"azureEndpoint,omitempty"`
+	AzureKeyType   string `json:"azureKeyType,omitempty"`
+	AzureURIStyle  string `json:"azureURIStyle,omitempty"`
+	// StorageNoVerifyTLS turns off the verification of the TLS certificate of
+	// the storage of a remote repository
+	StorageNoVerifyTLS bool `json:"storageNoVerifyTLS,omitempty"`
+	// Retention is the retention policy of a remote repository. The retention
+	// policy of the first repository is BackrestRetention
+	Retention PgBackRestRetentionSpec `json:"retention,omitempty"`
+	// Schedule, if set, is the cron schedule of the full backups that are taken
+	// into the repository
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// BackrestRemoteRepoTypes are the types of storage that the repositories of a
+// cluster that follow the local one can use. "gcs" is not among them, as the
+// service account key of a GCS repository has to be mounted as a file, which
+// is only done for the Secret of the cluster
+var BackrestRemoteRepoTypes = []string{"s3", "azure"}
+
+// BackrestGCSKeyTypes are the types of key that pgBackRest can use to access a
+// GCS repository, the default being "service"
+var BackrestGCSKeyTypes = []string{"", "service", "token"}
+
+// BackrestAzureKeyTypes are the types of key that pgBackRest can use to access
+// an Azure repository, the default being "shared"
+var BackrestAzureKeyTypes = []string{"", "shared", "sas"}
+
+// BackrestAzureURIStyles are the styles of the URIs of an Azure repository, the
+// default being "host". "path" is used by emulators such as Azurite
+var BackrestAzureURIStyles = []string{"", "host", "path"}
+
+// MaxBackrestRepos is the number of repositories that pgBackRest supports
+const MaxBackrestRepos = 4
+
 // PgBouncerSpec is a struct that is used within the Cluster specification that
 // provides the attributes for managing a PgBouncer implementation, including:
 // - is it enabled?
 // - what resources it should consume
 // - the total number of replicas
+// - the settings of the connection pooler
+// - whether there is a read-only pool that is routed to the replicas
 type PgBouncerSpec struct {
 	// Replicas represents the total number of Pods to deploy with pgBouncer,
 	// which effectively enables/disables the pgBouncer.
@@ -182,6 +402,22 @@ type PgBouncerSpec struct {
 	// Resources, if specified, contains the container request resources
 	// for any pgBouncer Deployments that are part of a PostgreSQL cluster
 	Resources v1.ResourceList `json:"resources"`
+	// Config, if specified, contains the settings of the "[pgbouncer]" section
+	// of the pgbouncer.ini file, e.g. "pool_mode" or "default_pool_size", which
+	// take precedence over the defaults of the Operator
+	Config map[string]string `json:"config"`
+	// Databases, if specified, contains the settings of the connections to
+	// specific databases, e.g. "pool_size" or "pool_mode", which take
+	// precedence over those of Config for that database
+	Databases map[string]map[string]string `json:"databases"`
+	// ReadOnlyReplicas represents the total number of Pods to deploy with the
+	// read-only pgBouncer, which pools connections to the replicas rather than
+	// the primary. It is only deployed alongside the pgBouncer of the primary.
+	//
+	// if it is set to 0 or less, it is disabled.
+	//
+	// if it is set to 1 or more, it is enabled
+	ReadOnlyReplicas int32 `json:"readOnlyReplicas"`
 }
 
 // Enabled returns true if the pgBouncer is enabled for the cluster, i.e. there
@@ -190,6 +426,13 @@ func (s *PgBouncerSpec) Enabled() bool {
 	return s.Replicas > 0
 }
 
+// ReadOnlyEnabled returns true if the read-only pgBouncer is enabled for the
+// cluster, i.e. pgBouncer is enabled and there is at least one read-only
+// replica set
+func (s *PgBouncerSpec) ReadOnlyEnabled() bool {
+	return s.Enabled() && s.ReadOnlyReplicas > 0
+}
+
 // TLSSpec contains the information to set up a TLS-enabled PostgreSQL cluster
 type TLSSpec struct {
 	// CASecret contains the name of the secret to use as the trusted CA for the
@@ -223,6 +466,22 @@ const (
 	// deployment has been scaled to 0
 	PgclusterStateShutdown PgclusterState = "pgcluster Shutdown"
 
+	// PgclusterConditionReady indicates that the primary and every replica are
+	// available, as well as pgBouncer if it is enabled
+	PgclusterConditionReady PgclusterConditionType = "Ready"
+	// PgclusterConditionPrimaryAvailable indicates that the primary is running
+	// and ready to accept connections
+	PgclusterConditionPrimaryAvailable PgclusterConditionType = "PrimaryAvailable"
+	// PgclusterConditionReplicasStreaming indicates that every replica is
+	// running and streaming from the primary
+	PgclusterConditionReplicasStreaming PgclusterConditionType = "ReplicasStreaming"
+	// PgclusterConditionBackupsHealthy indicates that the most recent
+	// pgBackRest backup succeeded
+	PgclusterConditionBackupsHealthy PgclusterConditionType = "BackupsHealthy"
+	// PgclusterConditionPgBouncerReady indicates that every pgBouncer Pod is
+	// ready. It is "False" with reason "NotEnabled" if pgBouncer is not enabled
+	PgclusterConditionPgBouncerReady PgclusterConditionType = "PgBouncerReady"
+
 	// PodAntiAffinityRequired results in requiredDuringSchedulingIgnoredDuringExecution for any
 	// default pod anti-affinity rules applied to pg custers
 	PodAntiAffinityRequired PodAntiAffinityType = "required"
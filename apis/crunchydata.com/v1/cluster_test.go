@@ -0,0 +1,105 @@
+package v1
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPgclusterStatusSetCondition(t *testing.T) {
+	status := PgclusterStatus{}
+
+	if status.GetCondition(PgclusterConditionReady) != nil {
+		t.Fatal("expected no condition")
+	}
+
+	status.SetCondition(PgclusterCondition{
+		Type:   PgclusterConditionReady,
+		Status: v1.ConditionFalse,
+		Reason: "PrimaryNotAvailable",
+	})
+
+	condition := status.GetCondition(PgclusterConditionReady)
+	if condition == nil || condition.LastTransitionTime.IsZero() {
+		t.Fatalf("expected the condition to be set with a transition time, got %v", condition)
+	}
+
+	// pretend the condition was set a while ago
+	then := metav1.NewTime(time.Now().Add(-time.Hour))
+	condition.LastTransitionTime = then
+
+	{
+		status.SetCondition(PgclusterCondition{
+			Type:    PgclusterConditionReady,
+			Status:  v1.ConditionFalse,
+			Reason:  "InstancesNotReady",
+			Message: "instances not ready: hippo-abcd",
+		})
+
+		condition := status.GetCondition(PgclusterConditionReady)
+		if !condition.LastTransitionTime.Equal(&then) {
+			t.Errorf("expected the transition time to stay, got %v", condition.LastTransitionTime)
+		}
+		if condition.Reason != "InstancesNotReady" || condition.Message != "instances not ready: hippo-abcd" {
+			t.Errorf("expected the reason and message to be updated, got %v", condition)
+		}
+		if status.IsConditionTrue(PgclusterConditionReady) {
+			t.Error("expected the condition not to be true")
+		}
+	}
+
+	{
+		status.SetCondition(PgclusterCondition{
+			Type:   PgclusterConditionReady,
+			Status: v1.ConditionTrue,
+			Reason: "ClusterReady",
+		})
+
+		condition := status.GetCondition(PgclusterConditionReady)
+		if !then.Before(&condition.LastTransitionTime) {
+			t.Errorf("expected the transition time to move, got %v", condition.LastTransitionTime)
+		}
+		if !status.IsConditionTrue(PgclusterConditionReady) {
+			t.Error("expected the condition to be true")
+		}
+	}
+
+	if len(status.Conditions) != 1 {
+		t.Errorf("expected one condition, got %v", status.Conditions)
+	}
+}
+
+func TestPgBouncerSpecReadOnlyEnabled(t *testing.T) {
+	tests := []struct {
+		spec    PgBouncerSpec
+		enabled bool
+	}{
+		{spec: PgBouncerSpec{}, enabled: false},
+		{spec: PgBouncerSpec{Replicas: 1}, enabled: false},
+		{spec: PgBouncerSpec{ReadOnlyReplicas: 1}, enabled: false},
+		{spec: PgBouncerSpec{Replicas: 1, ReadOnlyReplicas: 2}, enabled: true},
+	}
+
+	for _, test := range tests {
+		if enabled := test.spec.ReadOnlyEnabled(); enabled != test.enabled {
+			t.Errorf("expected %t for %+v, got %t", test.enabled, test.spec, enabled)
+		}
+	}
+}
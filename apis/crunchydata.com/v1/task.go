@@ -25,6 +25,9 @@ const PgtaskResourcePlural = "pgtasks"
 const PgtaskDeleteBackups = "delete-backups"
 const PgtaskDeleteData = "delete-data"
 const PgtaskFailover = "failover"
+const PgtaskSwitchover = "switchover"
+const PgtaskRestart = "restart"
+const PgtaskRestartPending = "restart-pending"
 const PgtaskAutoFailover = "autofailover"
 const PgtaskAddPolicies = "addpolicies"
 const PgtaskMinorUpgrade = "minorupgradecluster"
@@ -52,6 +55,22 @@ const PgtaskBackrestBackup = "backup"
 const PgtaskBackrestInfo = "info"
 const PgtaskBackrestRestore = "restore"
 const PgtaskBackrestStanzaCreate = "stanza-create"
+const PgtaskBackrestVerify = "backrest-verify"
+
+// the outcomes of a pgBackRest backup verification
+const PgtaskBackrestVerifyPassed = "passed"
+const PgtaskBackrestVerifyFailed = "failed"
+
+// the states of a switchover
+const PgtaskSwitchoverInProgress = "in progress"
+const PgtaskSwitchoverScheduled = "scheduled"
+const PgtaskSwitchoverCompleted = "completed"
+const PgtaskSwitchoverFailed = "failed"
+
+// the states of a restart
+const PgtaskRestartInProgress = "in progress"
+const PgtaskRestartCompleted = "completed"
+const PgtaskRestartFailed = "failed"
 
 const PgtaskpgDump = "pgdump"
 const PgtaskpgDumpBackup = "pgdumpbackup"
@@ -76,7 +95,7 @@ const (
 
 // BackrestStorageTypes defines the valid types of storage that can be utilized
 // with pgBackRest
-var BackrestStorageTypes = []string{"local", "s3"}
+var BackrestStorageTypes = []string{"local", "s3", "gcs", "azure"}
 
 // PgtaskSpec ...
 // swagger:ignore
@@ -24,6 +24,39 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PgBackRestRepoSpec) DeepCopyInto(out *PgBackRestRepoSpec) {
+	*out = *in
+	out.Retention = in.Retention
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgBackRestRepoSpec.
+func (in *PgBackRestRepoSpec) DeepCopy() *PgBackRestRepoSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PgBackRestRepoSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PgBackRestRetentionSpec) DeepCopyInto(out *PgBackRestRetentionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgBackRestRetentionSpec.
+func (in *PgBackRestRetentionSpec) DeepCopy() *PgBackRestRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PgBackRestRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PgBouncerSpec) DeepCopyInto(out *PgBouncerSpec) {
 	*out = *in
@@ -34,6 +67,30 @@ func (in *PgBouncerSpec) DeepCopyInto(out *PgBouncerSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make(map[string]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -69,7 +126,7 @@ func (in *Pgcluster) DeepCopyInto(out *Pgcluster) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -91,6 +148,39 @@ func (in *Pgcluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PgclusterCondition) DeepCopyInto(out *PgclusterCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgclusterCondition.
+func (in *PgclusterCondition) DeepCopy() *PgclusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PgclusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PgclusterInstanceStatus) DeepCopyInto(out *PgclusterInstanceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgclusterInstanceStatus.
+func (in *PgclusterInstanceStatus) DeepCopy() *PgclusterInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PgclusterInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PgclusterList) DeepCopyInto(out *PgclusterList) {
 	*out = *in
@@ -159,6 +249,12 @@ func (in *PgclusterSpec) DeepCopyInto(out *PgclusterSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	out.BackrestRetention = in.BackrestRetention
+	if in.BackrestRepos != nil {
+		in, out := &in.BackrestRepos, &out.BackrestRepos
+		*out = make([]PgBackRestRepoSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.TablespaceMounts != nil {
 		in, out := &in.TablespaceMounts, &out.TablespaceMounts
 		*out = make(map[string]PgStorageSpec, len(*in))
@@ -167,6 +263,18 @@ func (in *PgclusterSpec) DeepCopyInto(out *PgclusterSpec) {
 		}
 	}
 	out.TLS = in.TLS
+	if in.SyncReplicationSettings != nil {
+		in, out := &in.SyncReplicationSettings, &out.SyncReplicationSettings
+		*out = new(SyncReplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -183,6 +291,22 @@ func (in *PgclusterSpec) DeepCopy() *PgclusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PgclusterStatus) DeepCopyInto(out *PgclusterStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PgclusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]PgclusterInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -507,6 +631,27 @@ func (in *PodAntiAffinitySpec) DeepCopy() *PodAntiAffinitySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncReplicationSpec) DeepCopyInto(out *SyncReplicationSpec) {
+	*out = *in
+	if in.ExcludedReplicas != nil {
+		in, out := &in.ExcludedReplicas, &out.ExcludedReplicas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncReplicationSpec.
+func (in *SyncReplicationSpec) DeepCopy() *SyncReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
 	*out = *in
@@ -111,6 +111,7 @@ func main() {
 
 	r := mux.NewRouter()
 	routing.RegisterAllRoutes(r)
+	r.Use(apiserver.Audit)
 
 	var srv *http.Server
 	if !tlsDisabled {
@@ -0,0 +1,224 @@
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiv2 provides the resource oriented version 2 of the apiserver REST
+// API, which is served under /api/v2.
+//
+// The handlers of this package translate between the v2 routes and the
+// existing *service implementations, so both API versions behave the same. In
+// contrast to the legacy routes, the API version is part of the path rather than
+// of the request body, the namespace and the names of the resources are part of
+// the path, the outcome is reflected in the HTTP status code, and every error is
+// returned as an apiservermsgs.Status. The OpenAPI document describing the API
+// is generated from the route table and is served at /api/v2/openapi.json.
+package apiv2
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathPrefix is the path under which the v2 API is served
+const PathPrefix = "/api/v2"
+
+// The following are the names of the route variables used by the v2 API
+const (
+	varNamespace = "ns"
+	varName      = "name"
+	varUsername  = "username"
+)
+
+// handlerFunc handles a request that has been authenticated and, for the
+// routes within a namespace, checked for access to the namespace
+type handlerFunc func(w http.ResponseWriter, r *http.Request, username, ns string)
+
+// parameter describes a query parameter of a route
+type parameter struct {
+	name        string
+	kind        string
+	description string
+}
+
+// route describes a route of the v2 API, which is used both to register the
+// route and to generate the OpenAPI document
+type route struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	// perm is the permission the caller needs. Routes without a permission do
+	// not require authentication
+	perm string
+	// query are the query parameters of the route
+	query []parameter
+	// request is the type of the request body, if there is one
+	request interface{}
+	// response is the type of the response body
+	response interface{}
+	// status is the HTTP status code of a successful response
+	status  int
+	handler handlerFunc
+}
+
+// RegisterRoutes adds the routes of the v2 API to the router
+func RegisterRoutes(r *mux.Router) {
+	s := r.PathPrefix(PathPrefix).Subrouter()
+
+	for _, rt := range routes() {
+		s.HandleFunc(rt.path, rt.serve).Methods(rt.method)
+	}
+}
+
+// serve authenticates the caller and checks their access to the namespace in
+// the path before calling the handler of the route
+func (rt route) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("apiv2 %s %s called", rt.method, rt.path)
+
+	var username string
+
+	if rt.perm != "" {
+		var ok bool
+		if username, ok = authenticate(rt.perm, w, r); !ok {
+			return
+		}
+	}
+
+	var ns string
+
+	if requestedNS, ok := mux.Vars(r)[varNamespace]; ok {
+		var err error
+		if ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, requestedNS); err != nil {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+
+	rt.handler(w, r, username, ns)
+}
+
+// authenticate runs Authn, returning its outcome as a Status rather than as
+// the plain text error that Authn writes for the legacy routes
+func authenticate(perm string, w http.ResponseWriter, r *http.Request) (string, bool) {
+	capture := &responseCapture{header: w.Header(), status: http.StatusUnauthorized}
+
+	username, err := apiserver.Authn(perm, capture, r)
+	if err != nil {
+		writeError(w, capture.status, strings.TrimSpace(capture.body.String()))
+		return "", false
+	}
+
+	return username, true
+}
+
+// authorize checks that an authenticated caller is also granted an additional
+// permission, writing an error if they are not
+func authorize(perm, username string, w http.ResponseWriter, r *http.Request) bool {
+	if !apiserver.AuthzCheck(r.Context(), username, perm) {
+		log.Errorf("Authorization Failed %s username=[%s]", perm, username)
+		writeError(w, http.StatusForbidden, "Not authorized for this apiserver action")
+		return false
+	}
+
+	return true
+}
+
+// responseCapture records what Authn writes for a failed authentication, so
+// that it can be returned as a Status. Headers are passed through
+type responseCapture struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) Header() http.Header { return c.header }
+
+func (c *responseCapture) Write(b []byte) (int, error) { return c.body.Write(b) }
+
+func (c *responseCapture) WriteHeader(status int) { c.status = status }
+
+// decode reads the JSON request body into the request, writing an error if the
+// body is not valid. An empty body leaves the request unchanged
+func decode(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// queryBool returns the value of a boolean query parameter, which is false if
+// it is not set or is not a boolean
+func queryBool(r *http.Request, name string) bool {
+	b, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return b
+}
+
+// queryInt returns the value of an integer query parameter, which is 0 if it
+// is not set or is not an integer
+func queryInt(r *http.Request, name string) int {
+	i, _ := strconv.Atoi(r.URL.Query().Get(name))
+	return i
+}
+
+// writeResponse writes the response of one of the service implementations. If
+// the implementation reports an error, only its Status is returned, with an
+// error status code
+func writeResponse(w http.ResponseWriter, status int, result msgs.Status, response interface{}) {
+	if result.Code != msgs.Ok {
+		writeError(w, errorStatusCode(result.Msg), result.Msg)
+		return
+	}
+
+	writeJSON(w, status, response)
+}
+
+// writeError writes an error as a Status
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, msgs.Status{Code: msgs.Error, Msg: message})
+}
+
+// writeJSON writes the body as JSON with the status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Errorf("could not encode apiv2 response: %s", err.Error())
+	}
+}
+
+// errorStatusCode chooses the HTTP status code for an error reported by one of
+// the service implementations. They only report a message, so a missing
+// resource is recognized by the wording that they use for it
+func errorStatusCode(message string) int {
+	message = strings.ToLower(message)
+
+	if strings.Contains(message, "not found") || strings.Contains(message, "does not exist") ||
+		(strings.HasPrefix(message, "no ") && strings.HasSuffix(message, " found")) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,69 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"net/http"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	"github.com/crunchydata/postgres-operator/apiserver/backrestservice"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/gorilla/mux"
+)
+
+// listBackups returns the pgBackRest backups of a cluster
+func listBackups(w http.ResponseWriter, r *http.Request, username, ns string) {
+	name := mux.Vars(r)[varName]
+
+	// the selector keeps a cluster named "all" from being treated as every
+	// cluster in the namespace
+	resp := backrestservice.ShowBackrest(name, "name="+name, ns)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// createBackup starts a pgBackRest backup of a cluster
+func createBackup(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.CreateBackrestBackupRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.Args = []string{mux.Vars(r)[varName]}
+	request.Selector = ""
+	request.Namespace = ns
+
+	resp := backrestservice.CreateBackup(&request, ns, username)
+	writeResponse(w, http.StatusAccepted, resp.Status, resp)
+}
+
+// createRestore starts a pgBackRest restore of a cluster
+func createRestore(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.RestoreRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.FromCluster = mux.Vars(r)[varName]
+	request.Namespace = ns
+
+	// a restore into a new cluster is carried out as a clone
+	if request.TargetCluster != "" && !authorize(apiserver.CLONE_PERM, username, w, r) {
+		return
+	}
+
+	resp := backrestservice.Restore(&request, ns, username)
+	writeResponse(w, http.StatusAccepted, resp.Status, resp)
+}
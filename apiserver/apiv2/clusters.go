@@ -0,0 +1,94 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	"github.com/crunchydata/postgres-operator/apiserver/clusterservice"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/gorilla/mux"
+)
+
+// listClusters returns the clusters in the namespace, optionally filtered by a
+// label selector
+func listClusters(w http.ResponseWriter, r *http.Request, username, ns string) {
+	selector := r.URL.Query().Get("selector")
+
+	resp := clusterservice.ShowCluster("", selector, "", ns, selector == "")
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// getCluster returns a single cluster
+func getCluster(w http.ResponseWriter, r *http.Request, username, ns string) {
+	name := mux.Vars(r)[varName]
+
+	resp := clusterservice.ShowCluster(name, "", "", ns, false)
+	if resp.Status.Code == msgs.Ok && len(resp.Results) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster %s not found", name))
+		return
+	}
+
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// createCluster creates a cluster in the namespace
+func createCluster(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.CreateClusterRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	if request.ShowSystemAccounts && !authorize(apiserver.SHOW_SYSTEM_ACCOUNTS_PERM, username, w, r) {
+		return
+	}
+
+	request.Namespace = ns
+	request.ClientVersion = msgs.PGO_VERSION
+
+	resp := clusterservice.CreateCluster(&request, ns, username)
+	writeResponse(w, http.StatusCreated, resp.Status, resp)
+}
+
+// updateCluster updates a cluster
+func updateCluster(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.UpdateClusterRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.Clustername = []string{mux.Vars(r)[varName]}
+	request.Selector = ""
+	request.AllFlag = false
+	request.Namespace = ns
+	request.ClientVersion = msgs.PGO_VERSION
+
+	resp := clusterservice.UpdateCluster(&request, username)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// deleteCluster deletes a cluster, and optionally its data and backups
+func deleteCluster(w http.ResponseWriter, r *http.Request, username, ns string) {
+	name := mux.Vars(r)[varName]
+
+	// the selector keeps a cluster named "all" from being treated as every
+	// cluster in the namespace
+	resp := clusterservice.DeleteCluster(name, "name="+name, queryBool(r, "delete-data"),
+		queryBool(r, "delete-backups"), ns, username)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
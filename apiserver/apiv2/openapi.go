@@ -0,0 +1,301 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document
+// conforms to
+const openAPIVersion = "3.0.3"
+
+// openAPISchemaRef is the prefix of a reference to a schema of the document
+const openAPISchemaRef = "#/components/schemas/"
+
+// pathVariables matches the route variables of a path, e.g. "{name}"
+var pathVariables = regexp.MustCompile(`{([^}]+)}`)
+
+// pathVariableDescriptions describe the route variables used by the v2 API
+var pathVariableDescriptions = map[string]string{
+	varNamespace: "the namespace of the resource",
+	varName:      "the name of the PostgreSQL cluster",
+	varUsername:  "the name of the PostgreSQL user",
+}
+
+// The following types have their own JSON encoding, which is detected so that
+// e.g. times and resource quantities are described as strings
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openAPI holds the OpenAPI document once it has been generated
+var openAPI struct {
+	once     sync.Once
+	document map[string]interface{}
+}
+
+// openAPIDocument returns the OpenAPI document describing the v2 API
+func openAPIDocument() map[string]interface{} {
+	openAPI.once.Do(func() {
+		openAPI.document = generateOpenAPIDocument()
+	})
+
+	return openAPI.document
+}
+
+// generateOpenAPIDocument generates the OpenAPI document from the route table
+// of the v2 API, deriving the schemas of the request and response bodies from
+// their Go types
+func generateOpenAPIDocument() map[string]interface{} {
+	g := newSchemaGenerator()
+	paths := map[string]interface{}{}
+
+	errorResponse := map[string]interface{}{
+		"description": "The error that occurred",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": g.schema(reflect.TypeOf(msgs.Status{})),
+			},
+		},
+	}
+
+	for _, rt := range routes() {
+		operation := map[string]interface{}{
+			"operationId": handlerName(rt.handler),
+			"summary":     rt.summary,
+			"tags":        []string{rt.tag},
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.status): map[string]interface{}{
+					"description": http.StatusText(rt.status),
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": g.schema(reflect.TypeOf(rt.response)),
+						},
+					},
+				},
+				"default": errorResponse,
+			},
+		}
+
+		if rt.perm == "" {
+			operation["security"] = []interface{}{}
+		} else {
+			operation["description"] = "Requires the " + rt.perm + " permission."
+		}
+
+		parameters := []interface{}{}
+		for _, match := range pathVariables.FindAllStringSubmatch(rt.path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        match[1],
+				"in":          "path",
+				"required":    true,
+				"description": pathVariableDescriptions[match[1]],
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, p := range rt.query {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        p.name,
+				"in":          "query",
+				"description": p.description,
+				"schema":      map[string]interface{}{"type": p.kind},
+			})
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		if rt.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"description": "The namespace and names in the path take precedence over any in the body",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": g.schema(reflect.TypeOf(rt.request)),
+					},
+				},
+			}
+		}
+
+		item, ok := paths[rt.path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "PostgreSQL Operator API",
+			"version": msgs.PGO_VERSION,
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": PathPrefix},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"basicAuth": []string{}},
+			map[string]interface{}{"bearerAuth": []string{}},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				"basicAuth":  map[string]interface{}{"type": "http", "scheme": "basic"},
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// handlerName returns the name of the function handling a route, which is used
+// as the ID of its operation
+func handlerName(handler handlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// schemaGenerator derives OpenAPI schemas from Go types, following the rules of
+// encoding/json. Named struct types become shared schemas that are referenced
+type schemaGenerator struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: map[string]interface{}{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schema returns the schema of the type, or a reference to it
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+
+	// interfaces can hold any value
+	return map[string]interface{}{}
+}
+
+// ref adds the schema of a named struct type to the shared schemas, if it is
+// not there yet, and returns a reference to it
+func (g *schemaGenerator) ref(t reflect.Type) map[string]interface{} {
+	name, ok := g.names[t]
+
+	if !ok {
+		name = t.Name()
+		if _, taken := g.schemas[name]; taken {
+			name = path.Base(t.PkgPath()) + t.Name()
+		}
+
+		// the name is assigned before the schema is built, so that recursive
+		// types refer to themselves
+		g.names[t] = name
+		g.schemas[name] = nil
+		g.schemas[name] = g.object(t)
+	}
+
+	return map[string]interface{}{"$ref": openAPISchemaRef + name}
+}
+
+// object returns the schema of a struct type
+func (g *schemaGenerator) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	g.properties(t, properties)
+
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// properties adds the fields of a struct type to the properties of a schema,
+// including the fields of embedded structs
+func (g *schemaGenerator) properties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.properties(embedded, properties)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schema(field.Type)
+	}
+}
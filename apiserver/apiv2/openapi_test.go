@@ -0,0 +1,125 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	b, err := json.Marshal(generateOpenAPIDocument())
+	if err != nil {
+		t.Fatalf("could not encode the OpenAPI document: %s", err.Error())
+	}
+
+	document := struct {
+		Paths      map[string]map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]interface{}
+		}
+	}{}
+	if err := json.Unmarshal(b, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	operationIDs := map[string]bool{}
+
+	for _, rt := range routes() {
+		operation, ok := document.Paths[rt.path][strings.ToLower(rt.method)]
+		if !ok {
+			t.Errorf("%s %s is not described", rt.method, rt.path)
+			continue
+		}
+
+		id, _ := operation["operationId"].(string)
+		if id == "" || operationIDs[id] {
+			t.Errorf("%s %s does not have a unique operationId: %q", rt.method, rt.path, id)
+		}
+		operationIDs[id] = true
+	}
+
+	// every reference has to resolve to a schema of the document
+	var check func(v interface{})
+	check = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, openAPISchemaRef)
+				if _, ok := document.Components.Schemas[name]; !ok {
+					t.Errorf("reference %s does not resolve", ref)
+				}
+			}
+			for _, item := range value {
+				check(item)
+			}
+		case []interface{}:
+			for _, item := range value {
+				check(item)
+			}
+		}
+	}
+
+	for _, item := range document.Paths {
+		check(item)
+	}
+	check(document.Components.Schemas)
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	type Inner struct {
+		Value string `json:"value"`
+	}
+	type Status struct {
+		Code string
+	}
+	type Outer struct {
+		Status
+		Name     string
+		Count    int32  `json:"count,omitempty"`
+		Ignored  string `json:"-"`
+		Inner    *Inner
+		Items    []Inner
+		Labels   map[string]string
+		Data     []byte
+		internal bool
+	}
+
+	g := newSchemaGenerator()
+	ref := g.schema(reflect.TypeOf(Outer{}))
+
+	if ref["$ref"] != openAPISchemaRef+"Outer" {
+		t.Fatalf("expected a reference to Outer, got %v", ref)
+	}
+
+	properties := g.schemas["Outer"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	expected := map[string]interface{}{
+		"Code":   map[string]interface{}{"type": "string"},
+		"Name":   map[string]interface{}{"type": "string"},
+		"count":  map[string]interface{}{"type": "integer", "format": "int32"},
+		"Inner":  map[string]interface{}{"$ref": openAPISchemaRef + "Inner"},
+		"Items":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": openAPISchemaRef + "Inner"}},
+		"Labels": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+		"Data":   map[string]interface{}{"type": "string", "format": "byte"},
+	}
+
+	if !reflect.DeepEqual(properties, expected) {
+		t.Errorf("expected properties %v, got %v", expected, properties)
+	}
+}
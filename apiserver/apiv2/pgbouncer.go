@@ -0,0 +1,82 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"net/http"
+
+	"github.com/crunchydata/postgres-operator/apiserver/pgbouncerservice"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/gorilla/mux"
+)
+
+// getPgBouncer returns the pgBouncer deployment of a cluster
+func getPgBouncer(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.ShowPgBouncerRequest{
+		ClientVersion: msgs.PGO_VERSION,
+		ClusterNames:  []string{mux.Vars(r)[varName]},
+		Namespace:     ns,
+		Stats:         queryBool(r, "stats"),
+	}
+
+	resp := pgbouncerservice.ShowPgBouncer(&request, ns)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// createPgBouncer adds a pgBouncer deployment to a cluster
+func createPgBouncer(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.CreatePgbouncerRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.Args = []string{mux.Vars(r)[varName]}
+	request.ClientVersion = msgs.PGO_VERSION
+	request.Namespace = ns
+	request.Selector = ""
+
+	resp := pgbouncerservice.CreatePgbouncer(&request, ns, username)
+	writeResponse(w, http.StatusCreated, resp.Status, resp)
+}
+
+// updatePgBouncer updates the pgBouncer deployment of a cluster
+func updatePgBouncer(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.UpdatePgBouncerRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.ClientVersion = msgs.PGO_VERSION
+	request.ClusterNames = []string{mux.Vars(r)[varName]}
+	request.Namespace = ns
+	request.Selector = ""
+
+	resp := pgbouncerservice.UpdatePgBouncer(&request, ns, username)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// deletePgBouncer removes the pgBouncer deployment from a cluster
+func deletePgBouncer(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.DeletePgbouncerRequest{
+		Args:          []string{mux.Vars(r)[varName]},
+		ClientVersion: msgs.PGO_VERSION,
+		Namespace:     ns,
+		Uninstall:     queryBool(r, "uninstall"),
+	}
+
+	resp := pgbouncerservice.DeletePgbouncer(&request, ns)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
@@ -0,0 +1,257 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"net/http"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	"github.com/crunchydata/postgres-operator/apiserver/versionservice"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+)
+
+// The following are the paths of the resources of the v2 API, relative to
+// PathPrefix
+const (
+	pathClusters  = "/namespaces/{ns}/clusters"
+	pathCluster   = pathClusters + "/{name}"
+	pathBackups   = pathCluster + "/backups"
+	pathRestores  = pathCluster + "/restores"
+	pathUsers     = pathCluster + "/users"
+	pathUser      = pathUsers + "/{username}"
+	pathPgBouncer = pathCluster + "/pgbouncer"
+)
+
+// routes returns the route table of the v2 API
+func routes() []route {
+	return []route{
+		{
+			method:   http.MethodGet,
+			path:     "/openapi.json",
+			tag:      "api",
+			summary:  "Get the OpenAPI document describing this API",
+			response: map[string]interface{}{},
+			status:   http.StatusOK,
+			handler:  getOpenAPI,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/version",
+			tag:      "api",
+			summary:  "Get the version of the Operator",
+			perm:     apiserver.VERSION_PERM,
+			response: msgs.VersionResponse{},
+			status:   http.StatusOK,
+			handler:  getVersion,
+		},
+		{
+			method:  http.MethodGet,
+			path:    pathClusters,
+			tag:     "clusters",
+			summary: "List the PostgreSQL clusters in a namespace",
+			perm:    apiserver.SHOW_CLUSTER_PERM,
+			query: []parameter{
+				{name: "selector", kind: "string", description: "only list the clusters matching this label selector"},
+			},
+			response: msgs.ShowClusterResponse{},
+			status:   http.StatusOK,
+			handler:  listClusters,
+		},
+		{
+			method:   http.MethodPost,
+			path:     pathClusters,
+			tag:      "clusters",
+			summary:  "Create a PostgreSQL cluster",
+			perm:     apiserver.CREATE_CLUSTER_PERM,
+			request:  msgs.CreateClusterRequest{},
+			response: msgs.CreateClusterResponse{},
+			status:   http.StatusCreated,
+			handler:  createCluster,
+		},
+		{
+			method:   http.MethodGet,
+			path:     pathCluster,
+			tag:      "clusters",
+			summary:  "Get a PostgreSQL cluster",
+			perm:     apiserver.SHOW_CLUSTER_PERM,
+			response: msgs.ShowClusterResponse{},
+			status:   http.StatusOK,
+			handler:  getCluster,
+		},
+		{
+			method:   http.MethodPatch,
+			path:     pathCluster,
+			tag:      "clusters",
+			summary:  "Update a PostgreSQL cluster",
+			perm:     apiserver.UPDATE_CLUSTER_PERM,
+			request:  msgs.UpdateClusterRequest{},
+			response: msgs.UpdateClusterResponse{},
+			status:   http.StatusOK,
+			handler:  updateCluster,
+		},
+		{
+			method:  http.MethodDelete,
+			path:    pathCluster,
+			tag:     "clusters",
+			summary: "Delete a PostgreSQL cluster",
+			perm:    apiserver.DELETE_CLUSTER_PERM,
+			query: []parameter{
+				{name: "delete-data", kind: "boolean", description: "also delete the data of the cluster"},
+				{name: "delete-backups", kind: "boolean", description: "also delete the backups of the cluster"},
+			},
+			response: msgs.DeleteClusterResponse{},
+			status:   http.StatusOK,
+			handler:  deleteCluster,
+		},
+		{
+			method:   http.MethodGet,
+			path:     pathBackups,
+			tag:      "backups",
+			summary:  "List the pgBackRest backups of a PostgreSQL cluster",
+			perm:     apiserver.SHOW_BACKUP_PERM,
+			response: msgs.ShowBackrestResponse{},
+			status:   http.StatusOK,
+			handler:  listBackups,
+		},
+		{
+			method:   http.MethodPost,
+			path:     pathBackups,
+			tag:      "backups",
+			summary:  "Start a pgBackRest backup of a PostgreSQL cluster",
+			perm:     apiserver.CREATE_BACKUP_PERM,
+			request:  msgs.CreateBackrestBackupRequest{},
+			response: msgs.CreateBackrestBackupResponse{},
+			status:   http.StatusAccepted,
+			handler:  createBackup,
+		},
+		{
+			method:   http.MethodPost,
+			path:     pathRestores,
+			tag:      "backups",
+			summary:  "Start a pgBackRest restore of a PostgreSQL cluster",
+			perm:     apiserver.RESTORE_PERM,
+			request:  msgs.RestoreRequest{},
+			response: msgs.RestoreResponse{},
+			status:   http.StatusAccepted,
+			handler:  createRestore,
+		},
+		{
+			method:  http.MethodGet,
+			path:    pathUsers,
+			tag:     "users",
+			summary: "List the PostgreSQL users of a PostgreSQL cluster",
+			perm:    apiserver.SHOW_SECRETS_PERM,
+			query: []parameter{
+				{name: "expired", kind: "integer", description: "only list the users whose passwords expire within this many days"},
+				{name: "show-system-accounts", kind: "boolean", description: "also list the system accounts, which requires the ShowSystemAccounts permission"},
+			},
+			response: msgs.ShowUserResponse{},
+			status:   http.StatusOK,
+			handler:  listUsers,
+		},
+		{
+			method:   http.MethodPost,
+			path:     pathUsers,
+			tag:      "users",
+			summary:  "Create a PostgreSQL user in a PostgreSQL cluster",
+			perm:     apiserver.CREATE_USER_PERM,
+			request:  msgs.CreateUserRequest{},
+			response: msgs.CreateUserResponse{},
+			status:   http.StatusCreated,
+			handler:  createUser,
+		},
+		{
+			method:   http.MethodPatch,
+			path:     pathUser,
+			tag:      "users",
+			summary:  "Update a PostgreSQL user of a PostgreSQL cluster",
+			perm:     apiserver.UPDATE_USER_PERM,
+			request:  msgs.UpdateUserRequest{},
+			response: msgs.UpdateUserResponse{},
+			status:   http.StatusOK,
+			handler:  updateUser,
+		},
+		{
+			method:   http.MethodDelete,
+			path:     pathUser,
+			tag:      "users",
+			summary:  "Delete a PostgreSQL user from a PostgreSQL cluster",
+			perm:     apiserver.DELETE_USER_PERM,
+			response: msgs.DeleteUserResponse{},
+			status:   http.StatusOK,
+			handler:  deleteUser,
+		},
+		{
+			method:  http.MethodGet,
+			path:    pathPgBouncer,
+			tag:     "pgbouncer",
+			summary: "Get the pgBouncer deployment of a PostgreSQL cluster",
+			perm:    apiserver.SHOW_PGBOUNCER_PERM,
+			query: []parameter{
+				{name: "stats", kind: "boolean", description: "also gather the statistics of the pgBouncer pods"},
+			},
+			response: msgs.ShowPgBouncerResponse{},
+			status:   http.StatusOK,
+			handler:  getPgBouncer,
+		},
+		{
+			method:   http.MethodPost,
+			path:     pathPgBouncer,
+			tag:      "pgbouncer",
+			summary:  "Add a pgBouncer deployment to a PostgreSQL cluster",
+			perm:     apiserver.CREATE_PGBOUNCER_PERM,
+			request:  msgs.CreatePgbouncerRequest{},
+			response: msgs.CreatePgbouncerResponse{},
+			status:   http.StatusCreated,
+			handler:  createPgBouncer,
+		},
+		{
+			method:   http.MethodPatch,
+			path:     pathPgBouncer,
+			tag:      "pgbouncer",
+			summary:  "Update the pgBouncer deployment of a PostgreSQL cluster",
+			perm:     apiserver.UPDATE_PGBOUNCER_PERM,
+			request:  msgs.UpdatePgBouncerRequest{},
+			response: msgs.UpdatePgBouncerResponse{},
+			status:   http.StatusOK,
+			handler:  updatePgBouncer,
+		},
+		{
+			method:  http.MethodDelete,
+			path:    pathPgBouncer,
+			tag:     "pgbouncer",
+			summary: "Remove the pgBouncer deployment from a PostgreSQL cluster",
+			perm:    apiserver.DELETE_PGBOUNCER_PERM,
+			query: []parameter{
+				{name: "uninstall", kind: "boolean", description: "also remove the pgBouncer objects from the database"},
+			},
+			response: msgs.DeletePgbouncerResponse{},
+			status:   http.StatusOK,
+			handler:  deletePgBouncer,
+		},
+	}
+}
+
+// getVersion returns the version of the Operator
+func getVersion(w http.ResponseWriter, r *http.Request, username, ns string) {
+	resp := versionservice.Version()
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// getOpenAPI returns the OpenAPI document describing the v2 API
+func getOpenAPI(w http.ResponseWriter, r *http.Request, username, ns string) {
+	writeJSON(w, http.StatusOK, openAPIDocument())
+}
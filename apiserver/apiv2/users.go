@@ -0,0 +1,95 @@
+package apiv2
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"net/http"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	"github.com/crunchydata/postgres-operator/apiserver/userservice"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/gorilla/mux"
+)
+
+// listUsers returns the PostgreSQL users of a cluster
+func listUsers(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.ShowUserRequest{
+		Clusters:           []string{mux.Vars(r)[varName]},
+		ClientVersion:      msgs.PGO_VERSION,
+		Expired:            queryInt(r, "expired"),
+		Namespace:          ns,
+		ShowSystemAccounts: queryBool(r, "show-system-accounts"),
+	}
+
+	if request.ShowSystemAccounts && !authorize(apiserver.SHOW_SYSTEM_ACCOUNTS_PERM, username, w, r) {
+		return
+	}
+
+	resp := userservice.ShowUser(&request)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// createUser creates a PostgreSQL user in a cluster
+func createUser(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.CreateUserRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	request.AllFlag = false
+	request.Clusters = []string{mux.Vars(r)[varName]}
+	request.ClientVersion = msgs.PGO_VERSION
+	request.Namespace = ns
+	request.Selector = ""
+
+	resp := userservice.CreateUser(&request, username)
+	writeResponse(w, http.StatusCreated, resp.Status, resp)
+}
+
+// updateUser updates a PostgreSQL user of a cluster
+func updateUser(w http.ResponseWriter, r *http.Request, username, ns string) {
+	request := msgs.UpdateUserRequest{}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	request.AllFlag = false
+	request.Clusters = []string{vars[varName]}
+	request.ClientVersion = msgs.PGO_VERSION
+	request.Namespace = ns
+	request.Selector = ""
+	request.Username = vars[varUsername]
+
+	resp := userservice.UpdateUser(&request, username)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
+
+// deleteUser deletes a PostgreSQL user from a cluster
+func deleteUser(w http.ResponseWriter, r *http.Request, username, ns string) {
+	vars := mux.Vars(r)
+
+	request := msgs.DeleteUserRequest{
+		Clusters:      []string{vars[varName]},
+		ClientVersion: msgs.PGO_VERSION,
+		Namespace:     ns,
+		Username:      vars[varUsername],
+	}
+
+	resp := userservice.DeleteUser(&request, username)
+	writeResponse(w, http.StatusOK, resp.Status, resp)
+}
@@ -0,0 +1,634 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AuditRequestIDHeader is the header that carries the ID of a request. If
+	// a client sets it, its value is used as the request ID of the audit
+	// record, otherwise one is generated. Either way it is returned in the
+	// response
+	AuditRequestIDHeader = "X-Request-Id"
+	// auditRedacted replaces the value of any sensitive request parameter
+	auditRedacted = "[REDACTED]"
+	// auditMaxBodySize is the largest request body that is included in the
+	// parameters of an audit record
+	auditMaxBodySize = 1 << 20
+	// auditWebhookQueueSize is the number of audit records that can be waiting
+	// to be sent to the webhook before new records are dropped
+	auditWebhookQueueSize = 1024
+)
+
+// auditResourceParams are the request parameters whose values name the
+// resources that a request acts on
+var auditResourceParams = []string{
+	"Args",
+	"ClusterName",
+	"ClusterNames",
+	"Clustername",
+	"Clusters",
+	"FromCluster",
+	"Name",
+	"PVCName",
+	"PgoroleName",
+	"PgouserName",
+	"PolicyName",
+	"Policyname",
+	"ScheduleName",
+	"Username",
+}
+
+// auditSensitiveParams are the fragments of a request parameter name, in lower
+// case, that mark its value as sensitive
+var auditSensitiveParams = []string{"password", "secret", "token", "key"}
+
+// auditSkipPaths are the routes that are not audited, as they are called
+// frequently by probes and cannot change anything
+var auditSkipPaths = map[string]struct{}{
+	"/health":  {},
+	"/healthz": {},
+}
+
+// AuditRecord is a single entry of the audit log. Each record contains the
+// hash of the record before it, so that a record that has been removed or
+// altered breaks the chain
+type AuditRecord struct {
+	// Sequence is incremented for every record, so that gaps can be detected
+	Sequence   uint64    `json:"seq"`
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"requestID"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	RemoteAddr string    `json:"remoteAddr"`
+	// Permission and Username are set once the handler authenticates the
+	// caller
+	Permission string `json:"permission,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Resources are the names of the clusters, users, policies, etc. that the
+	// request acts on
+	Resources []string `json:"resources,omitempty"`
+	// Params are the request parameters with sensitive values redacted
+	Params    json.RawMessage `json:"params,omitempty"`
+	Status    int             `json:"status"`
+	LatencyMS float64         `json:"latencyMS"`
+	PrevHash  string          `json:"prevHash"`
+	Hash      string          `json:"hash"`
+}
+
+// computeHash returns the hash of the record, which covers every field except
+// the hash itself
+func (a AuditRecord) computeHash() (string, error) {
+	a.Hash = ""
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// auditContextKey is the key of the audit record in the context of a request
+type auditContextKey struct{}
+
+// auditSink is a destination of the audit log
+type auditSink interface {
+	write(line []byte) error
+}
+
+// auditLogger writes the records of the audit log to its sinks, chaining each
+// record to the one before it
+type auditLogger struct {
+	mutex    sync.Mutex
+	sequence uint64
+	lastHash string
+	sinks    []auditSink
+}
+
+// auditLog is the audit logger of the apiserver, which is set up when auditing
+// is enabled
+var auditLog *auditLogger
+
+// initAudit sets up the sinks of the audit log if auditing is enabled. If no
+// sink is configured, the records are written to the apiserver log
+func initAudit() error {
+	if !AuditFlag {
+		return nil
+	}
+
+	logger := &auditLogger{}
+
+	if Pgo.AuditLog.File != "" {
+		sink, err := newAuditFileSink(Pgo.AuditLog.File, Pgo.AuditLog.MaxSize, Pgo.AuditLog.MaxBackups)
+		if err != nil {
+			return err
+		}
+
+		// continue the chain from where the previous apiserver left off
+		if last, ok := sink.lastRecord(); ok {
+			logger.sequence, logger.lastHash = last.Sequence, last.Hash
+		}
+
+		logger.sinks = append(logger.sinks, sink)
+	}
+
+	if Pgo.AuditLog.WebhookURL != "" {
+		timeout := time.Duration(Pgo.AuditLog.WebhookTimeout) * time.Second
+		logger.sinks = append(logger.sinks, newAuditWebhookSink(Pgo.AuditLog.WebhookURL, timeout))
+	}
+
+	if len(logger.sinks) == 0 {
+		logger.sinks = append(logger.sinks, auditLogSink{})
+	}
+
+	auditLog = logger
+
+	return nil
+}
+
+// record assigns the record its place in the chain and writes it to every sink
+func (l *auditLogger) record(record *AuditRecord) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.sequence++
+	record.Sequence = l.sequence
+	record.PrevHash = l.lastHash
+
+	hash, err := record.computeHash()
+	if err != nil {
+		log.Errorf("could not hash audit record %s: %s", record.RequestID, err.Error())
+		return
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		log.Errorf("could not encode audit record %s: %s", record.RequestID, err.Error())
+		return
+	}
+
+	l.lastHash = hash
+
+	for _, sink := range l.sinks {
+		if err := sink.write(line); err != nil {
+			log.Errorf("could not write audit record %d: %s", record.Sequence, err.Error())
+		}
+	}
+}
+
+// Audit is an HTTP middleware that writes a record to the audit log for every
+// request handled by the apiserver. The record is created before the handler
+// runs, so that Authn can fill in the caller, and is written once the handler
+// returns
+func Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auditLog == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if _, ok := auditSkipPaths[r.URL.Path]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+
+		record := &AuditRecord{
+			Time:       start.UTC(),
+			RequestID:  auditRequestID(r),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			RemoteAddr: r.RemoteAddr,
+		}
+
+		params := auditParams(r)
+		record.Namespace = auditNamespace(params)
+		record.Resources = auditResources(params)
+
+		sanitizeAuditParams(params)
+		if len(params) > 0 {
+			if b, err := json.Marshal(params); err == nil {
+				record.Params = b
+			}
+		}
+
+		w.Header().Set(AuditRequestIDHeader, record.RequestID)
+		recorder := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, record)))
+
+		record.Status = recorder.status
+		record.LatencyMS = float64(time.Since(start)) / float64(time.Millisecond)
+
+		auditLog.record(record)
+	})
+}
+
+// auditAuthn adds the permission being checked and the user making the request
+// to the audit record of the request
+func auditAuthn(r *http.Request, perm, username string) {
+	if record, ok := r.Context().Value(auditContextKey{}).(*AuditRecord); ok {
+		record.Permission = perm
+		record.Username = username
+	}
+}
+
+// auditRequestID returns the request ID sent by the client, or generates one
+func auditRequestID(r *http.Request) string {
+	if id := r.Header.Get(AuditRequestIDHeader); id != "" && len(id) <= 128 {
+		return id
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// auditParams collects the parameters of a request from the route variables,
+// the query string and a JSON body. The body is restored so that the handler
+// can read it
+func auditParams(r *http.Request) map[string]interface{} {
+	params := map[string]interface{}{}
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if err == nil && len(body) > 0 && len(body) <= auditMaxBodySize {
+			if err := json.Unmarshal(body, &params); err != nil {
+				params = map[string]interface{}{}
+			}
+		}
+	}
+
+	for k, v := range r.URL.Query() {
+		if len(v) == 1 {
+			params[k] = v[0]
+		} else {
+			params[k] = v
+		}
+	}
+
+	for k, v := range mux.Vars(r) {
+		params[k] = v
+	}
+
+	return params
+}
+
+// auditNamespace returns the namespace a request is made against
+func auditNamespace(params map[string]interface{}) string {
+	for _, k := range []string{"Namespace", "namespace", "ns"} {
+		if ns, ok := params[k].(string); ok {
+			return ns
+		}
+	}
+
+	return ""
+}
+
+// auditResources returns the names of the resources a request acts on
+func auditResources(params map[string]interface{}) []string {
+	resources := []string{}
+
+	add := func(v interface{}) {
+		switch value := v.(type) {
+		case string:
+			if value != "" {
+				resources = append(resources, value)
+			}
+		case []interface{}:
+			for _, item := range value {
+				if s, ok := item.(string); ok && s != "" {
+					resources = append(resources, s)
+				}
+			}
+		}
+	}
+
+	for _, k := range auditResourceParams {
+		add(params[k])
+	}
+
+	// the route variables, e.g. the {name} in /backrest/{name}
+	add(params["name"])
+	add(params["id"])
+	add(params["username"])
+
+	return resources
+}
+
+// sanitizeAuditParams redacts the value of every parameter whose name marks it
+// as sensitive, at any depth
+func sanitizeAuditParams(params map[string]interface{}) {
+	for k, v := range params {
+		if isSensitiveAuditParam(k) {
+			if v != nil && v != "" {
+				params[k] = auditRedacted
+			}
+			continue
+		}
+
+		switch value := v.(type) {
+		case map[string]interface{}:
+			sanitizeAuditParams(value)
+		case []interface{}:
+			for _, item := range value {
+				if m, ok := item.(map[string]interface{}); ok {
+					sanitizeAuditParams(m)
+				}
+			}
+		}
+	}
+}
+
+// isSensitiveAuditParam returns true if the name of the parameter indicates
+// that its value should not be recorded
+func isSensitiveAuditParam(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, s := range auditSensitiveParams {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditResponseWriter records the status code sent by a handler
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// auditLogSink writes audit records to the apiserver log
+type auditLogSink struct{}
+
+func (auditLogSink) write(line []byte) error {
+	log.Infof("[audit] %s", line)
+	return nil
+}
+
+// auditFileSink writes audit records to a file, one JSON document per line,
+// and rotates the file once it reaches its maximum size. Rotated files are
+// suffixed with ".1", ".2", etc., ".1" being the most recent
+type auditFileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newAuditFileSink opens the audit log file for appending. The maximum size is
+// in megabytes
+func newAuditFileSink(path string, maxSize, maxBackups int) (*auditFileSink, error) {
+	sink := &auditFileSink{
+		path:       path,
+		maxSize:    int64(maxSize) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+// open opens the current audit log file, creating it if needed
+func (s *auditFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open audit log %s: %s", s.path, err.Error())
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return nil
+}
+
+// lastRecord returns the last record written to the audit log, looking in the
+// most recently rotated file if the current one is empty
+func (s *auditFileSink) lastRecord() (AuditRecord, bool) {
+	for _, path := range []string{s.path, s.path + ".1"} {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		var last []byte
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 2*auditMaxBodySize)
+		for scanner.Scan() {
+			if line := scanner.Bytes(); len(line) > 0 {
+				last = append(last[:0], line...)
+			}
+		}
+		file.Close()
+
+		if last == nil {
+			continue
+		}
+
+		record := AuditRecord{}
+		if err := json.Unmarshal(last, &record); err != nil {
+			log.Errorf("could not read the last record of audit log %s: %s", path, err.Error())
+			return record, false
+		}
+
+		return record, true
+	}
+
+	return AuditRecord{}, false
+}
+
+func (s *auditFileSink) write(line []byte) error {
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line))+1 > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(append(line, '\n'))
+	s.size += int64(n)
+
+	return err
+}
+
+// rotate moves the current file to the first backup, shifting the existing
+// backups and removing the oldest one, and then opens a new file
+func (s *auditFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+
+	for i := s.maxBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// auditWebhookSink sends each audit record in the body of a POST request to a
+// webhook. Records are sent in the background so that a slow webhook does not
+// slow down the apiserver; if the webhook falls too far behind, records are
+// dropped, which shows up as a gap in the sequence numbers
+type auditWebhookSink struct {
+	url    string
+	client *http.Client
+	queue  chan []byte
+}
+
+// newAuditWebhookSink starts sending audit records to the webhook
+func newAuditWebhookSink(url string, timeout time.Duration) *auditWebhookSink {
+	sink := &auditWebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan []byte, auditWebhookQueueSize),
+	}
+
+	go sink.run()
+
+	return sink
+}
+
+func (s *auditWebhookSink) write(line []byte) error {
+	select {
+	case s.queue <- line:
+		return nil
+	default:
+		return fmt.Errorf("audit webhook queue is full, dropping record")
+	}
+}
+
+// run sends the queued audit records to the webhook in order
+func (s *auditWebhookSink) run() {
+	for line := range s.queue {
+		resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(line))
+		if err != nil {
+			log.Errorf("could not send audit record to %s: %s", s.url, err.Error())
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			log.Errorf("audit webhook %s responded with %s", s.url, resp.Status)
+		}
+	}
+}
+
+// VerifyAuditLog checks that the audit records read from r form an unbroken
+// chain, i.e. that no record was altered and no record is missing between the
+// first and the last. It returns the number of records that were checked
+func VerifyAuditLog(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 2*auditMaxBodySize)
+
+	count := 0
+	var previous *AuditRecord
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return count, fmt.Errorf("record after seq %d cannot be decoded: %s", count, err.Error())
+		}
+
+		hash, err := record.computeHash()
+		if err != nil {
+			return count, err
+		}
+		if hash != record.Hash {
+			return count, fmt.Errorf("record seq %d has been altered", record.Sequence)
+		}
+
+		if previous != nil {
+			if record.Sequence != previous.Sequence+1 {
+				return count, fmt.Errorf("records missing between seq %d and seq %d",
+					previous.Sequence, record.Sequence)
+			}
+			if record.PrevHash != previous.Hash {
+				return count, fmt.Errorf("record seq %d does not follow seq %d",
+					record.Sequence, previous.Sequence)
+			}
+		}
+
+		previous = &record
+		count++
+	}
+
+	return count, scanner.Err()
+}
@@ -0,0 +1,155 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// auditBufferSink collects audit records in memory
+type auditBufferSink struct {
+	lines [][]byte
+}
+
+func (s *auditBufferSink) write(line []byte) error {
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+func (s *auditBufferSink) join(lines [][]byte) *bytes.Reader {
+	return bytes.NewReader(append(bytes.Join(lines, []byte("\n")), '\n'))
+}
+
+func TestAuditChain(t *testing.T) {
+	sink := &auditBufferSink{}
+	logger := &auditLogger{sinks: []auditSink{sink}}
+
+	for _, path := range []string{"/clusters", "/clustersdelete", "/pgousercreate"} {
+		logger.record(&AuditRecord{Path: path, Method: "POST", Status: 200})
+	}
+
+	if count, err := VerifyAuditLog(sink.join(sink.lines)); err != nil || count != 3 {
+		t.Fatalf("expected 3 valid records, got %d: %v", count, err)
+	}
+
+	t.Run("altered", func(t *testing.T) {
+		lines := [][]byte{sink.lines[0], bytes.Replace(sink.lines[1], []byte(`"status":200`), []byte(`"status":201`), 1), sink.lines[2]}
+		if _, err := VerifyAuditLog(sink.join(lines)); err == nil {
+			t.Fatal("expected an altered record to be detected")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		lines := [][]byte{sink.lines[0], sink.lines[2]}
+		if _, err := VerifyAuditLog(sink.join(lines)); err == nil {
+			t.Fatal("expected a missing record to be detected")
+		}
+	})
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	sink := &auditBufferSink{}
+	auditLog = &auditLogger{sinks: []auditSink{sink}}
+	defer func() { auditLog = nil }()
+
+	var handlerBody []byte
+	handler := Audit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerBody, _ = ioutil.ReadAll(r.Body)
+		auditAuthn(r, "CreatePgouser", "admin")
+		http.Error(w, "Not authorized for this apiserver action", 403)
+	}))
+
+	body := `{"PgouserName":"alice","PgouserPassword":"hunter2","Namespace":"pgo","Nested":{"BackrestS3Key":"abc"}}`
+	r := httptest.NewRequest("POST", "/pgousercreate", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if string(handlerBody) != body {
+		t.Fatalf("handler did not receive the request body, got %q", handlerBody)
+	}
+	if w.Header().Get(AuditRequestIDHeader) == "" {
+		t.Fatal("expected a request ID in the response")
+	}
+	if len(sink.lines) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.lines))
+	}
+
+	line := string(sink.lines[0])
+	for _, expected := range []string{
+		`"permission":"CreatePgouser"`, `"username":"admin"`, `"namespace":"pgo"`,
+		`"resources":["alice"]`, `"status":403`, `"PgouserPassword":"[REDACTED]"`,
+		`"BackrestS3Key":"[REDACTED]"`,
+	} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("expected %s in %s", expected, line)
+		}
+	}
+	if strings.Contains(line, "hunter2") || strings.Contains(line, "abc") {
+		t.Errorf("sensitive value recorded in %s", line)
+	}
+}
+
+func TestAuditFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+
+	sink, err := newAuditFileSink(path, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rotate after every record
+	sink.maxSize = 1
+
+	logger := &auditLogger{sinks: []auditSink{sink}}
+	for i := 0; i < 4; i++ {
+		logger.record(&AuditRecord{Path: "/clusters"})
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+
+	last, ok := sink.lastRecord()
+	if !ok || last.Sequence != 4 || last.Hash != logger.lastHash {
+		t.Fatalf("expected to resume from record 4, got %d (%t)", last.Sequence, ok)
+	}
+
+	// the rotated files and the current file continue the same chain
+	var all []byte
+	for _, p := range []string{path + ".2", path + ".1", path} {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, b...)
+	}
+
+	if count, err := VerifyAuditLog(bytes.NewReader(all)); err != nil || count != 3 {
+		t.Fatalf("expected 3 valid records, got %d: %v", count, err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/crunchydata/postgres-operator/apiserver/backupoptions"
+	"github.com/crunchydata/postgres-operator/apiserver/cloneservice"
 	"github.com/crunchydata/postgres-operator/util"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
@@ -42,9 +43,9 @@ const containername = "database"
 // pgBackRest info
 var pgBackRestInfoCommand = []string{"pgbackrest", "info", "--output", "json"}
 
-// repoTypeFlagS3 is used for getting the pgBackRest info for a repository that
-// is stored in S3
-var repoTypeFlagS3 = []string{"--repo-type", "s3"}
+// repoTypeFlag is used for getting the pgBackRest info for a repository that
+// is stored in S3, GCS or Azure, and is followed by the storage type
+const repoTypeFlag = "--repo-type"
 
 //  CreateBackup ...
 // pgo backup mycluster
@@ -64,6 +65,13 @@ func CreateBackup(request *msgs.CreateBackrestBackupRequest, ns, pgouser string)
 		}
 	}
 
+	// backups are verified by restoring them from the default repository
+	if request.Repo != 0 && (request.Verify || request.VerifyOnly) {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "backups can only be verified in the default pgBackRest repository"
+		return resp
+	}
+
 	clusterList := crv1.PgclusterList{}
 	var err error
 	if request.Selector != "" {
@@ -139,6 +147,34 @@ func CreateBackup(request *msgs.CreateBackrestBackupRequest, ns, pgouser string)
 			return resp
 		}
 
+		// the backup is taken into the repository that is selected, if any
+		repoOption, err := apiserver.BackrestRepoOption(&cluster, request.Repo, request.BackrestStorageType)
+		if err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
+
+		// verify an existing backup rather than taking a new one
+		if request.VerifyOnly {
+			taskName, err := createVerifyTask(&cluster, request, ns, pgouser)
+			if err != nil {
+				resp.Status.Code = msgs.Error
+				resp.Status.Msg = err.Error()
+				return resp
+			}
+			resp.Results = append(resp.Results, "created Pgtask "+taskName)
+			continue
+		}
+
+		// the retention policy of the cluster cannot be overridden by the options
+		// of a backup
+		if err := apiserver.ValidateRetentionOptions(&cluster, request.BackupOpts); err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
+
 		result := crv1.Pgtask{}
 
 		// error if it already exists
@@ -197,10 +233,17 @@ func CreateBackup(request *msgs.CreateBackrestBackupRequest, ns, pgouser string)
 		jobName := "backrest-" + crv1.PgtaskBackrestBackup + "-" + clusterName
 		log.Debugf("setting jobName to %s", jobName)
 
-		err = kubeapi.Createpgtask(apiserver.RESTClient,
-			getBackupParams(cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER], clusterName, taskName, crv1.PgtaskBackrestBackup, podname, "database",
-				util.GetValueOrDefault(cluster.Spec.PGOImagePrefix, apiserver.Pgo.Pgo.PGOImagePrefix), request.BackupOpts, request.BackrestStorageType, jobName, ns, pgouser),
-			ns)
+		task := getBackupParams(cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER], clusterName, taskName, crv1.PgtaskBackrestBackup, podname, "database",
+			util.GetValueOrDefault(cluster.Spec.PGOImagePrefix, apiserver.Pgo.Pgo.PGOImagePrefix),
+			strings.TrimSpace(request.BackupOpts+" "+repoOption), request.BackrestStorageType, jobName, ns, pgouser)
+
+		// the backup is verified by the Operator once it completes
+		if request.Verify {
+			task.Spec.Parameters[config.LABEL_BACKREST_VERIFY] = "true"
+			task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_QUERY] = request.VerifyQuery
+		}
+
+		err = kubeapi.Createpgtask(apiserver.RESTClient, task, ns)
 		if err != nil {
 			resp.Status.Code = msgs.Error
 			resp.Status.Msg = err.Error()
@@ -376,8 +419,8 @@ func ShowBackrest(name, selector, ns string) msgs.ShowBackrestResponse {
 			//
 			// 1. If storage type is "local" and the string either contains "local" or
 			// is empty, we can add the pgBackRest info
-			// 2. if the storage type is "s3" and the string contains "s3", we can
-			// add the pgBackRest info
+			// 2. if the storage type is "s3", "gcs" or "azure" and the string
+			// contains it, we can add the pgBackRest info
 			// 3. Otherwise, continue
 			if (storageTypes == "" && storageType != "local") || (storageTypes != "" && !strings.Contains(storageTypes, storageType)) {
 				continue
@@ -409,6 +452,16 @@ func ShowBackrest(name, selector, ns string) msgs.ShowBackrestResponse {
 				return response
 			}
 
+			// report the backups that are expiring next under the retention policy
+			detail.Retention = c.Spec.BackrestRetention
+			detail.ExpiringBackups = []string{}
+			for _, stanza := range detail.Info {
+				detail.ExpiringBackups = append(detail.ExpiringBackups,
+					expiringBackups(stanza.Backups, detail.Retention)...)
+			}
+
+			detail.Verification = getVerification(c.Name, storageType, ns)
+
 			// append the details to the list of items
 			response.Items = append(response.Items, detail)
 		}
@@ -423,8 +476,8 @@ func getInfo(clusterName, storageType, podname, ns string) (string, error) {
 
 	cmd := pgBackRestInfoCommand
 
-	if storageType == "s3" {
-		cmd = append(cmd, repoTypeFlagS3...)
+	if storageType != "" && storageType != "local" {
+		cmd = append(cmd, repoTypeFlag, storageType)
 	}
 
 	output, stderr, err := kubeapi.ExecToPodThroughAPI(apiserver.RESTConfig, apiserver.Clientset, cmd, containername, podname, ns, nil)
@@ -499,6 +552,30 @@ func Restore(request *msgs.RestoreRequest, ns, pgouser string) msgs.RestoreRespo
 		return resp
 	}
 
+	// the backup is restored from the repository that is selected, if any
+	repoOption, err := apiserver.BackrestRepoOption(&cluster, request.Repo, request.BackrestStorageType)
+	if err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	// a restore into a new cluster leaves this cluster untouched
+	if request.TargetCluster != "" {
+		if repoOption != "" {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = "a pgBackRest repository cannot be selected when restoring into a new cluster"
+			return resp
+		}
+		return restoreToNewCluster(request, ns, pgouser)
+	}
+
+	if request.BackupLabel != "" {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "a backup label can only be given when restoring into a new cluster"
+		return resp
+	}
+
 	var id string
 	id, err = createRestoreWorkflowTask(cluster.Name, ns)
 	if err != nil {
@@ -506,6 +583,8 @@ func Restore(request *msgs.RestoreRequest, ns, pgouser string) msgs.RestoreRespo
 		return resp
 	}
 
+	request.RestoreOpts = strings.TrimSpace(request.RestoreOpts + " " + repoOption)
+
 	pgtask, err := getRestoreParams(request, ns, cluster)
 	if err != nil {
 		resp.Status.Code = msgs.Error
@@ -532,6 +611,44 @@ func Restore(request *msgs.RestoreRequest, ns, pgouser string) msgs.RestoreRespo
 	return resp
 }
 
+// restoreToNewCluster restores a backup of a cluster, up to a point-in-time
+// recovery target if one is given, into a new cluster. The restore is carried
+// out by the clone workflow, which copies the pgBackRest repository of the
+// cluster so that the cluster keeps running while the new one is created
+func restoreToNewCluster(request *msgs.RestoreRequest, ns, pgouser string) msgs.RestoreResponse {
+	resp := msgs.RestoreResponse{}
+	resp.Status.Code = msgs.Ok
+	resp.Results = make([]string, 0)
+
+	// the options of the restore and the placement of the primary are those of
+	// the clone workflow
+	if request.RestoreOpts != "" || request.NodeLabel != "" {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "restore options and node labels are not supported when restoring into a new cluster"
+		return resp
+	}
+
+	cloneResp := cloneservice.Clone(&msgs.CloneRequest{
+		BackrestStorageSource: request.BackrestStorageType,
+		BackupLabel:           request.BackupLabel,
+		Namespace:             ns,
+		PITRTarget:            request.PITRTarget,
+		SourceClusterName:     request.FromCluster,
+		TargetClusterName:     request.TargetCluster,
+	}, ns, pgouser)
+
+	if cloneResp.Status.Code != msgs.Ok {
+		resp.Status = cloneResp.Status
+		return resp
+	}
+
+	resp.Results = append(resp.Results, fmt.Sprintf("restore of %s into new cluster %s started backup=%s pitr-target=%s",
+		request.FromCluster, request.TargetCluster, util.GetValueOrDefault(request.BackupLabel, "latest"), request.PITRTarget))
+	resp.Results = append(resp.Results, "workflow id "+cloneResp.WorkflowID)
+
+	return resp
+}
+
 func getRestoreParams(request *msgs.RestoreRequest, ns string, cluster crv1.Pgcluster) (*crv1.Pgtask, error) {
 	var newInstance *crv1.Pgtask
 
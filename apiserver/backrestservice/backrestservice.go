@@ -65,7 +65,7 @@ func CreateBackupHandler(w http.ResponseWriter, r *http.Request) {
 	resp := msgs.CreateBackrestBackupResponse{}
 	resp.Status = msgs.Status{Code: msgs.Ok, Msg: ""}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -141,7 +141,7 @@ func ShowBackrestHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -185,6 +185,14 @@ func RestoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// a special authz check here: a restore into a new cluster is carried out
+	// as a clone, so ensure the user is also authorized to clone
+	if request.TargetCluster != "" && !apiserver.AuthzCheck(r.Context(), username, apiserver.CLONE_PERM) {
+		log.Errorf("Authorization Failed %s username=[%s]", apiserver.CLONE_PERM, username)
+		http.Error(w, "Not authorized for this apiserver action", 403)
+		return
+	}
+
 	w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -192,7 +200,7 @@ func RestoreHandler(w http.ResponseWriter, r *http.Request) {
 	resp := msgs.RestoreResponse{}
 	resp.Status = msgs.Status{Code: msgs.Ok, Msg: ""}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
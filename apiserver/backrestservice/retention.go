@@ -0,0 +1,78 @@
+package backrestservice
+
+/*
+Copyright 2018 - 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+)
+
+// expiringBackups returns the labels of the backups that pgBackRest expires
+// under a retention policy the next time a full or a differential backup
+// completes, in the order the backups were taken. The backups pgBackRest lists
+// are in the order they were taken.
+//
+// Once a full backup completes, the full backups beyond the newest ones that
+// are retained expire, and likewise for the differential backups. Any backup
+// that depends on a backup that expires also expires
+func expiringBackups(backups []msgs.PgBackRestInfoBackup, retention crv1.PgBackRestRetentionSpec) []string {
+	expiring := map[string]bool{}
+
+	// oldest returns the labels of the oldest backups of a type that exceed the
+	// number that is retained, accounting for the backup that is taken next
+	oldest := func(backupType string, retained int) []string {
+		labels := []string{}
+		for _, backup := range backups {
+			if backup.Type == backupType && !expiring[backup.Label] {
+				labels = append(labels, backup.Label)
+			}
+		}
+
+		if count := len(labels) - (retained - 1); retained > 0 && count > 0 {
+			return labels[:count]
+		}
+		return []string{}
+	}
+
+	for _, label := range oldest("full", retention.Full) {
+		expiring[label] = true
+	}
+
+	// differential backups of the full backups that expire also expire, and are
+	// therefore not counted
+	for _, backup := range backups {
+		if expiring[backup.Prior] {
+			expiring[backup.Label] = true
+		}
+	}
+
+	for _, label := range oldest("diff", retention.Diff) {
+		expiring[label] = true
+	}
+
+	labels := []string{}
+	for _, backup := range backups {
+		if expiring[backup.Prior] {
+			expiring[backup.Label] = true
+		}
+
+		if expiring[backup.Label] {
+			labels = append(labels, backup.Label)
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,62 @@
+package backrestservice
+
+/*
+Copyright 2018 - 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+)
+
+func TestExpiringBackups(t *testing.T) {
+	backups := []msgs.PgBackRestInfoBackup{
+		{Label: "F1", Type: "full"},
+		{Label: "F1_D1", Type: "diff", Prior: "F1"},
+		{Label: "F1_I1", Type: "incr", Prior: "F1_D1"},
+		{Label: "F2", Type: "full"},
+		{Label: "F2_D1", Type: "diff", Prior: "F2"},
+		{Label: "F2_D2", Type: "diff", Prior: "F2"},
+		{Label: "F2_I1", Type: "incr", Prior: "F2_D2"},
+		{Label: "F3", Type: "full"},
+	}
+
+	tests := []struct {
+		name      string
+		retention crv1.PgBackRestRetentionSpec
+		expected  []string
+	}{
+		{name: "no policy", retention: crv1.PgBackRestRetentionSpec{}, expected: []string{}},
+		{name: "full retained", retention: crv1.PgBackRestRetentionSpec{Full: 4}, expected: []string{}},
+		{name: "full", retention: crv1.PgBackRestRetentionSpec{Full: 3},
+			expected: []string{"F1", "F1_D1", "F1_I1"}},
+		{name: "one full", retention: crv1.PgBackRestRetentionSpec{Full: 1},
+			expected: []string{"F1", "F1_D1", "F1_I1", "F2", "F2_D1", "F2_D2", "F2_I1", "F3"}},
+		{name: "diff", retention: crv1.PgBackRestRetentionSpec{Diff: 3},
+			expected: []string{"F1_D1", "F1_I1"}},
+		{name: "full and diff", retention: crv1.PgBackRestRetentionSpec{Full: 3, Diff: 2},
+			expected: []string{"F1", "F1_D1", "F1_I1", "F2_D1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := expiringBackups(backups, test.retention); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package backrestservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/apiserver"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// createVerifyTask creates the Pgtask of a verification of an existing backup
+// of a cluster, replacing the task of a previous verification. The Job of a
+// verification is removed by the Operator once it finishes
+func createVerifyTask(cluster *crv1.Pgcluster, request *msgs.CreateBackrestBackupRequest, ns, pgouser string) (string, error) {
+	taskName := "backrest-verify-" + cluster.Name
+
+	if err := kubeapi.Deletepgtask(apiserver.RESTClient, taskName, ns); err != nil && !kerrors.IsNotFound(err) {
+		return "", err
+	}
+
+	spec := crv1.PgtaskSpec{}
+	spec.Name = taskName
+	spec.Namespace = ns
+	spec.TaskType = crv1.PgtaskBackrestVerify
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_JOB_NAME] = taskName
+	spec.Parameters[config.LABEL_PG_CLUSTER] = cluster.Name
+	spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE] = request.BackrestStorageType
+	spec.Parameters[config.LABEL_BACKREST_VERIFY_SET] = request.VerifySet
+	spec.Parameters[config.LABEL_BACKREST_VERIFY_QUERY] = request.VerifyQuery
+
+	newInstance := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: taskName,
+		},
+		Spec: spec,
+	}
+	newInstance.ObjectMeta.Labels = make(map[string]string)
+	newInstance.ObjectMeta.Labels[config.LABEL_PG_CLUSTER] = cluster.Name
+	newInstance.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER] = cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER]
+	newInstance.ObjectMeta.Labels[config.LABEL_BACKREST_VERIFY] = "true"
+	newInstance.ObjectMeta.Labels[config.LABEL_PGOUSER] = pgouser
+
+	return taskName, kubeapi.Createpgtask(apiserver.RESTClient, newInstance, ns)
+}
+
+// getVerification returns the outcome of the latest verification of a backup
+// in a repository of a cluster, if any
+func getVerification(clusterName, storageType, ns string) *msgs.PgBackRestVerification {
+	selector := config.LABEL_PG_CLUSTER + "=" + clusterName + "," + config.LABEL_BACKREST_VERIFY + "=true"
+
+	tasks := crv1.PgtaskList{}
+	if err := kubeapi.GetpgtasksBySelector(apiserver.RESTClient, &tasks, selector, ns); err != nil {
+		log.Error(err)
+		return nil
+	}
+
+	return latestVerification(tasks.Items, storageType)
+}
+
+// latestVerification returns the outcome of the latest of the verification
+// tasks that restored a backup from a repository of the storage type. Only an
+// "s3", "gcs" or "azure" storage type uses the repository of that storage, as
+// it is for the verification Job
+func latestVerification(tasks []crv1.Pgtask, storageType string) *msgs.PgBackRestVerification {
+	var latest *crv1.Pgtask
+
+	for i, task := range tasks {
+		taskStorageType := "local"
+		switch task.Spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE] {
+		case "s3", "gcs", "azure":
+			taskStorageType = task.Spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE]
+		}
+
+		if taskStorageType != storageType {
+			continue
+		}
+
+		if latest == nil || latest.CreationTimestamp.Before(&task.CreationTimestamp) {
+			latest = &tasks[i]
+		}
+	}
+
+	if latest == nil {
+		return nil
+	}
+
+	return &msgs.PgBackRestVerification{
+		BackupSet: latest.Spec.Parameters[config.LABEL_BACKREST_VERIFY_SET],
+		Status:    latest.Spec.Parameters[config.LABEL_BACKREST_VERIFY_STATUS],
+		Completed: latest.Spec.Parameters[config.LABEL_BACKREST_VERIFY_COMPLETED],
+		Message:   latest.Status.Message,
+	}
+}
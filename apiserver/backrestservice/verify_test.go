@@ -0,0 +1,65 @@
+package backrestservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLatestVerification(t *testing.T) {
+	start := time.Date(2020, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	task := func(hours int, storageType, status string) crv1.Pgtask {
+		return crv1.Pgtask{
+			ObjectMeta: meta_v1.ObjectMeta{
+				CreationTimestamp: meta_v1.NewTime(start.Add(time.Duration(hours) * time.Hour)),
+			},
+			Spec: crv1.PgtaskSpec{Parameters: map[string]string{
+				config.LABEL_BACKREST_STORAGE_TYPE:  storageType,
+				config.LABEL_BACKREST_VERIFY_STATUS: status,
+			}},
+		}
+	}
+
+	tasks := []crv1.Pgtask{
+		task(2, "", crv1.PgtaskBackrestVerifyFailed),
+		task(1, "local", crv1.PgtaskBackrestVerifyPassed),
+		task(3, "s3", crv1.PgtaskBackrestVerifyPassed),
+		task(0, "local,s3", ""),
+		task(4, "gcs", crv1.PgtaskBackrestVerifyFailed),
+	}
+
+	if v := latestVerification(tasks, "local"); v == nil || v.Status != crv1.PgtaskBackrestVerifyFailed {
+		t.Errorf("expected the latest local verification to have failed, got %+v", v)
+	}
+
+	if v := latestVerification(tasks, "s3"); v == nil || v.Status != crv1.PgtaskBackrestVerifyPassed {
+		t.Errorf("expected the latest s3 verification to have passed, got %+v", v)
+	}
+
+	if v := latestVerification(tasks[:2], "s3"); v != nil {
+		t.Errorf("expected no s3 verification, got %+v", v)
+	}
+
+	if v := latestVerification(tasks, "gcs"); v == nil || v.Status != crv1.PgtaskBackrestVerifyFailed {
+		t.Errorf("expected the latest gcs verification to have failed, got %+v", v)
+	}
+}
@@ -152,8 +152,11 @@ func createBackupOptionsStruct(backupOpts string, request interface{}) (backupOp
 	case *msgs.PgRestoreRequest:
 		return &pgRestoreOptions{}, "pg_restore", nil
 	case *msgs.CreateScheduleRequest:
-		if request.(*msgs.CreateScheduleRequest).ScheduleType == "pgbackrest" {
+		switch request.(*msgs.CreateScheduleRequest).ScheduleType {
+		case "pgbackrest":
 			return &pgBackRestBackupOptions{}, "pgBackRest", nil
+		case "pgdump":
+			return &pgDumpOptions{}, "pg_dump", nil
 		}
 	}
 	return nil, "", errors.New("Request type not recognized. Unable to create struct for backup opts")
@@ -60,7 +60,7 @@ func CatHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp := msgs.CatResponse{}
 		resp.Status.Code = msgs.Error
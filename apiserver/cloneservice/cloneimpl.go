@@ -114,8 +114,10 @@ func Clone(request *msgs.CloneRequest, namespace, pgouser string) msgs.CloneResp
 	cloneTask := util.CloneTask{
 		BackrestPVCSize:       request.BackrestPVCSize,
 		BackrestStorageSource: request.BackrestStorageSource,
+		BackupLabel:           request.BackupLabel,
 		EnableMetrics:         request.EnableMetrics,
 		PGOUser:               pgouser,
+		PITRTarget:            request.PITRTarget,
 		PVCSize:               request.PVCSize,
 		SourceClusterName:     request.SourceClusterName,
 		TargetClusterName:     request.TargetClusterName,
@@ -213,11 +215,22 @@ func validateCloneRequest(request *msgs.CloneRequest, cluster crv1.Pgcluster) er
 		return fmt.Errorf(apiserver.ErrMessagePVCSize, request.BackrestPVCSize, err.Error())
 	}
 
+	if err := apiserver.ValidateBackupLabel(request.BackupLabel); err != nil {
+		return err
+	}
+
 	// clone is a form of restore, so validate using ValidateBackrestStorageTypeOnBackupRestore
 	if err := util.ValidateBackrestStorageTypeOnBackupRestore(request.BackrestStorageSource,
 		cluster.Spec.UserLabels[config.LABEL_BACKREST_STORAGE_TYPE], true); err != nil {
 		return err
 	}
 
+	// the pgBackRest repository of the clone can only be synced from the local
+	// or S3 storage of the source cluster
+	if request.BackrestStorageSource == "gcs" || request.BackrestStorageSource == "azure" {
+		return fmt.Errorf("a cluster cannot be cloned from pgBackRest storage type '%s'",
+			request.BackrestStorageSource)
+	}
+
 	return nil
 }
@@ -58,7 +58,7 @@ func CloneHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 
 	if err != nil {
 		resp := msgs.CloneResponse{
@@ -19,15 +19,19 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/apiserver"
+	"github.com/crunchydata/postgres-operator/apiserver/scheduleservice"
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	clusteroperator "github.com/crunchydata/postgres-operator/operator/cluster"
+	"github.com/crunchydata/postgres-operator/pgo-scheduler/scheduler"
 	"github.com/crunchydata/postgres-operator/util"
 
 	log "github.com/sirupsen/logrus"
@@ -223,6 +227,7 @@ func GetPods(cluster *crv1.Pgcluster, ns string) ([]msgs.ShowClusterPod, error)
 		d.Phase = string(p.Status.Phase)
 		d.NodeName = p.Spec.NodeName
 		d.ReadyStatus, d.Ready = getReadyStatus(&p)
+		d.PendingRestart = util.IsPendingRestart(&p)
 		d.PVCName = apiserver.GetPVCName(&p)
 
 		d.Primary = false
@@ -589,6 +594,37 @@ func CreateCluster(request *msgs.CreateClusterRequest, ns, pgouser string) msgs.
 		return resp
 	}
 
+	if err := util.ValidateBackrestRetention(request.BackrestRetention); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	if err := validateBackrestRepos(request); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	// synchronous replication settings turn on synchronous replication, and so
+	// cannot be combined with turning it off
+	if request.SyncReplicationSettings != nil {
+		if request.SyncReplication != nil && !*request.SyncReplication {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = "sync replication settings cannot be set when sync replication is disabled"
+			return resp
+		}
+
+		if err := util.ValidateSyncReplicationSettings(request.SyncReplicationSettings); err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
+
+		syncReplication := true
+		request.SyncReplication = &syncReplication
+	}
+
 	// similarly, if any of the pgBouncer CPU / Memory values have been set,
 	// evaluate those as well
 	if err := apiserver.ValidateQuantity(request.PgBouncerCPURequest); err != nil {
@@ -895,12 +931,15 @@ func CreateCluster(request *msgs.CreateClusterRequest, ns, pgouser string) msgs.
 
 		err := util.CreateBackrestRepoSecrets(apiserver.Clientset,
 			util.BackrestRepoConfig{
-				BackrestS3CA:        backrestS3CACert,
-				BackrestS3Key:       request.BackrestS3Key,
-				BackrestS3KeySecret: request.BackrestS3KeySecret,
-				ClusterName:         clusterName,
-				ClusterNamespace:    request.Namespace,
-				OperatorNamespace:   apiserver.PgoNamespace,
+				BackrestS3CA:         backrestS3CACert,
+				BackrestS3Key:        request.BackrestS3Key,
+				BackrestS3KeySecret:  request.BackrestS3KeySecret,
+				ClusterName:          clusterName,
+				ClusterNamespace:     request.Namespace,
+				OperatorNamespace:    apiserver.PgoNamespace,
+				BackrestGCSKey:       request.BackrestGCSKey,
+				BackrestAzureAccount: request.BackrestAzureAccount,
+				BackrestAzureKey:     request.BackrestAzureKey,
 			})
 
 		if err != nil {
@@ -938,13 +977,52 @@ func CreateCluster(request *msgs.CreateClusterRequest, ns, pgouser string) msgs.
 		return resp
 	}
 
+	// the status is a subresource of the pgcluster, so it is set once the
+	// pgcluster has been created, unless it has already been processed
+	created := crv1.Pgcluster{}
+	if found, _ := kubeapi.Getpgcluster(apiserver.RESTClient, &created, newInstance.Name, ns); found &&
+		created.Status.State == "" {
+		if err := kubeapi.PatchpgclusterStatus(apiserver.RESTClient, crv1.PgclusterStateCreated,
+			"Created, not processed yet", &created, ns); err != nil {
+			log.Error(err)
+		}
+	}
+
 	// assign the cluster information to the result
 	resp.Result.Name = newInstance.Spec.Name
 
+	// schedule the backups of the pgBackRest repositories that have a schedule
+	if scheduleResp := scheduleservice.CreateBackrestRepoSchedules(newInstance, ns); scheduleResp.Status.Code != msgs.Ok {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = fmt.Sprintf("cluster %s was created, but the schedules of its pgBackRest "+
+			"repositories could not be: %s", newInstance.Name, scheduleResp.Status.Msg)
+		return resp
+	}
+
 	// and return!
 	return resp
 }
 
+// validateBackrestRepos validates the pgBackRest repositories of a cluster that
+// is being created, including the schedules of their backups
+func validateBackrestRepos(request *msgs.CreateClusterRequest) error {
+	if err := util.ValidateBackrestRepos(request.BackrestRepos, request.BackrestStorageType); err != nil {
+		return err
+	}
+
+	for i, repo := range request.BackrestRepos {
+		if repo.Schedule == "" {
+			continue
+		}
+
+		if err := scheduler.ValidateSchedule(repo.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule of pgBackRest repository %d: %s", i+1, err.Error())
+		}
+	}
+
+	return nil
+}
+
 func validateConfigPolicies(clusterName, PoliciesFlag, ns string) error {
 	var err error
 	var configPolicies string
@@ -1258,6 +1336,9 @@ func getClusterParams(request *msgs.CreateClusterRequest, name string, userLabel
 
 	spec.CustomConfig = request.CustomConfig
 	spec.SyncReplication = request.SyncReplication
+	spec.SyncReplicationSettings = request.SyncReplicationSettings
+	spec.BackrestRetention = request.BackrestRetention
+	spec.BackrestRepos = request.BackrestRepos
 
 	// set pgBackRest S3 settings in the spec if included in the request
 	if request.BackrestS3Bucket != "" {
@@ -1270,6 +1351,16 @@ func getClusterParams(request *msgs.CreateClusterRequest, name string, userLabel
 		spec.BackrestS3Region = request.BackrestS3Region
 	}
 
+	// likewise for the GCS and Azure settings
+	spec.BackrestGCSBucket = request.BackrestGCSBucket
+	spec.BackrestGCSEndpoint = request.BackrestGCSEndpoint
+	spec.BackrestGCSKeyType = request.BackrestGCSKeyType
+	spec.BackrestAzureContainer = request.BackrestAzureContainer
+	spec.BackrestAzureEndpoint = request.BackrestAzureEndpoint
+	spec.BackrestAzureKeyType = request.BackrestAzureKeyType
+	spec.BackrestAzureURIStyle = request.BackrestAzureURIStyle
+	spec.BackrestStorageNoVerifyTLS = request.BackrestStorageNoVerifyTLS
+
 	labels := make(map[string]string)
 	labels[config.LABEL_NAME] = name
 	if !request.AutofailFlag || apiserver.Pgo.Cluster.DisableAutofail {
@@ -1299,10 +1390,6 @@ func getClusterParams(request *msgs.CreateClusterRequest, name string, userLabel
 			Labels: labels,
 		},
 		Spec: spec,
-		Status: crv1.PgclusterStatus{
-			State:   crv1.PgclusterStateCreated,
-			Message: "Created, not processed yet",
-		},
 	}
 	return newInstance
 }
@@ -1543,7 +1630,7 @@ func createUserSecret(request *msgs.CreateClusterRequest, cluster *crv1.Pgcluste
 }
 
 // UpdateCluster ...
-func UpdateCluster(request *msgs.UpdateClusterRequest) msgs.UpdateClusterResponse {
+func UpdateCluster(request *msgs.UpdateClusterRequest, pgouser string) msgs.UpdateClusterResponse {
 	var err error
 
 	response := msgs.UpdateClusterResponse{}
@@ -1716,6 +1803,82 @@ func UpdateCluster(request *msgs.UpdateClusterRequest) msgs.UpdateClusterRespons
 			cluster.Spec.BackrestResources[v1.ResourceMemory] = quantity
 		}
 
+		// update the settings of the pgBackRest retention policy that are provided
+		if request.BackrestRetentionFull != nil {
+			cluster.Spec.BackrestRetention.Full = *request.BackrestRetentionFull
+		}
+		if request.BackrestRetentionDiff != nil {
+			cluster.Spec.BackrestRetention.Diff = *request.BackrestRetentionDiff
+		}
+		if request.BackrestRetentionArchive != nil {
+			cluster.Spec.BackrestRetention.Archive = *request.BackrestRetentionArchive
+		}
+		if request.BackrestRetentionArchiveType != nil {
+			cluster.Spec.BackrestRetention.ArchiveType = *request.BackrestRetentionArchiveType
+		}
+
+		if err := util.ValidateBackrestRetention(cluster.Spec.BackrestRetention); err != nil {
+			response.Status.Code = msgs.Error
+			response.Status.Msg = err.Error()
+			return response
+		}
+
+		// update the settings of synchronous replication that are provided, which
+		// only apply to clusters that have it enabled
+		if request.SyncReplicationNodeCount != nil || request.SyncReplicationStrict != nil ||
+			request.SyncReplicationExcludedReplicas != nil {
+			syncReplication := apiserver.Pgo.Cluster.SyncReplication
+			if cluster.Spec.SyncReplication != nil {
+				syncReplication = *cluster.Spec.SyncReplication
+			}
+
+			if !syncReplication {
+				response.Status.Code = msgs.Error
+				response.Status.Msg = fmt.Sprintf("sync replication is not enabled on cluster %s", cluster.Name)
+				return response
+			}
+
+			if cluster.Spec.SyncReplicationSettings == nil {
+				cluster.Spec.SyncReplicationSettings = &crv1.SyncReplicationSpec{}
+			}
+			if request.SyncReplicationNodeCount != nil {
+				cluster.Spec.SyncReplicationSettings.NodeCount = *request.SyncReplicationNodeCount
+			}
+			if request.SyncReplicationStrict != nil {
+				cluster.Spec.SyncReplicationSettings.Strict = *request.SyncReplicationStrict
+			}
+			if request.SyncReplicationExcludedReplicas != nil {
+				cluster.Spec.SyncReplicationSettings.ExcludedReplicas = *request.SyncReplicationExcludedReplicas
+			}
+
+			if err := util.ValidateSyncReplicationSettings(cluster.Spec.SyncReplicationSettings); err != nil {
+				response.Status.Code = msgs.Error
+				response.Status.Msg = err.Error()
+				return response
+			}
+		}
+
+		// set the PostgreSQL parameters that are provided, removing those that
+		// have an empty value
+		if len(request.Parameters) > 0 {
+			if cluster.Spec.Parameters == nil {
+				cluster.Spec.Parameters = map[string]string{}
+			}
+			for name, value := range request.Parameters {
+				if value == "" {
+					delete(cluster.Spec.Parameters, name)
+					continue
+				}
+				cluster.Spec.Parameters[name] = value
+			}
+
+			if err := util.ValidatePostgresParameters(cluster.Spec.Parameters); err != nil {
+				response.Status.Code = msgs.Error
+				response.Status.Msg = err.Error()
+				return response
+			}
+		}
+
 		// extract the parameters for the TablespaceMounts and put them in the
 		// format that is required by the pgcluster CRD
 		for _, tablespace := range request.Tablespaces {
@@ -1737,11 +1900,74 @@ func UpdateCluster(request *msgs.UpdateClusterRequest) msgs.UpdateClusterRespons
 		}
 
 		response.Results = append(response.Results, "updated pgcluster "+cluster.Spec.Name)
+
+		// point out the parameters that only take effect once the instances are
+		// restarted
+		restartParameters := []string{}
+		for name, value := range request.Parameters {
+			if value != "" && util.PostgresParameterRequiresRestart(name) {
+				restartParameters = append(restartParameters, name)
+			}
+		}
+		sort.Strings(restartParameters)
+
+		if len(restartParameters) > 0 {
+			response.Results = append(response.Results, fmt.Sprintf("%s only take effect once the instances "+
+				"of pgcluster %s are restarted, which can be done with --restart-pending once they are "+
+				"shown as pending a restart", strings.Join(restartParameters, ", "), cluster.Spec.Name))
+		}
+
+		if request.RestartPending {
+			if err := createRestartPendingTask(&cluster, request.Namespace, pgouser); err != nil {
+				response.Status.Code = msgs.Error
+				response.Status.Msg = err.Error()
+				return response
+			}
+
+			response.Results = append(response.Results, "created Pgtask (restart-pending) for cluster "+
+				cluster.Spec.Name)
+		}
 	}
 
 	return response
 }
 
+// createRestartPendingTask creates the pgtask that restarts the instances of a
+// cluster that are pending a restart, removing the one of a previous restart
+func createRestartPendingTask(cluster *crv1.Pgcluster, ns, pgouser string) error {
+	// only one restart of a cluster is run at a time, and the pgtask of one
+	// that is in progress must not be replaced
+	if name, err := clusteroperator.ActiveRestartTask(apiserver.RESTClient, cluster.Name, ns); err != nil {
+		return err
+	} else if name != "" {
+		return fmt.Errorf("a restart of cluster %s is already in progress (pgtask %s)", cluster.Name, name)
+	}
+
+	spec := crv1.PgtaskSpec{}
+	spec.Namespace = ns
+	spec.Name = cluster.Name + "-" + crv1.PgtaskRestartPending
+	spec.TaskType = crv1.PgtaskRestartPending
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_PG_CLUSTER] = cluster.Name
+
+	// previous restarts will leave a pgtask so remove it first
+	kubeapi.Deletepgtask(apiserver.RESTClient, spec.Name, ns)
+
+	labels := make(map[string]string)
+	labels[config.LABEL_PG_CLUSTER] = cluster.Name
+	labels[config.LABEL_PGOUSER] = pgouser
+
+	task := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   spec.Name,
+			Labels: labels,
+		},
+		Spec: spec,
+	}
+
+	return kubeapi.Createpgtask(apiserver.RESTClient, task, ns)
+}
+
 func GetPrimaryAndReplicaPods(cluster *crv1.Pgcluster, ns string) ([]msgs.ShowClusterPod, error) {
 
 	output := make([]msgs.ShowClusterPod, 0)
@@ -1759,6 +1985,7 @@ func GetPrimaryAndReplicaPods(cluster *crv1.Pgcluster, ns string) ([]msgs.ShowCl
 		d.Phase = string(p.Status.Phase)
 		d.NodeName = p.Spec.NodeName
 		d.ReadyStatus, d.Ready = getReadyStatus(&p)
+		d.PendingRestart = util.IsPendingRestart(&p)
 		d.PVCName = apiserver.GetPVCName(&p)
 
 		d.Primary = false
@@ -1782,6 +2009,7 @@ func GetPrimaryAndReplicaPods(cluster *crv1.Pgcluster, ns string) ([]msgs.ShowCl
 		d.Phase = string(p.Status.Phase)
 		d.NodeName = p.Spec.NodeName
 		d.ReadyStatus, d.Ready = getReadyStatus(&p)
+		d.PendingRestart = util.IsPendingRestart(&p)
 		d.PVCName = apiserver.GetPVCName(&p)
 
 		d.Primary = false
@@ -1799,53 +2027,28 @@ func GetPrimaryAndReplicaPods(cluster *crv1.Pgcluster, ns string) ([]msgs.ShowCl
 
 // validateBackrestStorageTypeOnCreate validates the pgbackrest storage type specified when
 // a new cluster.  This includes ensuring the type provided is valid, and that the required
-// configuration settings (s3 bucket, region, etc.) are also present
+// configuration settings (s3 bucket, region, gcs bucket, azure container etc.) are also present
 func validateBackrestStorageTypeOnCreate(request *msgs.CreateClusterRequest) error {
-
-	requestBackRestStorageType := request.BackrestStorageType
-
-	if requestBackRestStorageType != "" && !util.IsValidBackrestStorageType(requestBackRestStorageType) {
-		return fmt.Errorf("Invalid value provided for pgBackRest storage type. The following values are allowed: %s",
-			"\""+strings.Join(apiserver.GetBackrestStorageTypes(), "\", \"")+"\"")
-	} else if strings.Contains(requestBackRestStorageType, "s3") && isMissingS3Config(request) {
-		return errors.New("A configuration setting for AWS S3 storage is missing. Values must be " +
-			"provided for the S3 bucket, S3 endpoint and S3 region in order to use the 's3' " +
-			"storage type with pgBackRest.")
+	if err := util.ValidateBackrestStorageType(request.BackrestStorageType,
+		util.GetValueOrDefault(request.BackrestS3Bucket, apiserver.Pgo.Cluster.BackrestS3Bucket),
+		util.GetValueOrDefault(request.BackrestS3Endpoint, apiserver.Pgo.Cluster.BackrestS3Endpoint),
+		util.GetValueOrDefault(request.BackrestS3Region, apiserver.Pgo.Cluster.BackrestS3Region)); err != nil {
+		return err
 	}
 
-	return nil
+	return util.ValidateBackrestGCSAzureStorage(request.BackrestStorageType,
+		util.GetValueOrDefault(request.BackrestGCSBucket, apiserver.Pgo.Cluster.BackrestGCSBucket),
+		util.GetValueOrDefault(request.BackrestGCSKeyType, apiserver.Pgo.Cluster.BackrestGCSKeyType),
+		util.GetValueOrDefault(request.BackrestAzureContainer, apiserver.Pgo.Cluster.BackrestAzureContainer),
+		util.GetValueOrDefault(request.BackrestAzureKeyType, apiserver.Pgo.Cluster.BackrestAzureKeyType),
+		util.GetValueOrDefault(request.BackrestAzureURIStyle, apiserver.Pgo.Cluster.BackrestAzureURIStyle))
 }
 
 // validateClusterTLS validates the parameters that allow a user to enable TLS
 // connections to a PostgreSQL cluster
 func validateClusterTLS(request *msgs.CreateClusterRequest) error {
-	// if TLSOnly is not set and  neither TLSSecret no CASecret are set, just return
-	if !request.TLSOnly && request.TLSSecret == "" && request.CASecret == "" {
-		return nil
-	}
-
-	// if TLS only is set, but there is no TLSSecret nor CASecret, return
-	if request.TLSOnly && !(request.TLSSecret != "" && request.CASecret != "") {
-		return fmt.Errorf("TLS only clusters requires both a TLS secret and CA secret")
-	}
-	// if TLSSecret or CASecret is set, but not both are set, return
-	if (request.TLSSecret != "" && request.CASecret == "") || (request.TLSSecret == "" && request.CASecret != "") {
-		fmt.Errorf("Both TLS secret and CA secret must be set in order to enable TLS for PostgreSQL")
-	}
-
-	// now check for the existence of the two secrets
-	// First the TLS secret
-	if _, err := kubeapi.GetSecret(apiserver.Clientset, request.TLSSecret, request.Namespace); err != nil {
-		return err
-	}
-
-	// then, the CA secret
-	if _, err := kubeapi.GetSecret(apiserver.Clientset, request.CASecret, request.Namespace); err != nil {
-		return err
-	}
-
-	// after this, we are validated!
-	return nil
+	return util.ValidateClusterTLS(apiserver.Clientset, request.Namespace, request.TLSOnly,
+		request.TLSSecret, request.CASecret)
 }
 
 // validateTablespaces validates the tablespace parameters. if there is an error
@@ -1867,29 +2070,6 @@ func validateTablespaces(tablespaces []msgs.ClusterTablespaceDetail) error {
 	return nil
 }
 
-// determines if any of the required S3 configuration settings (bucket, endpoint
-// and region) are missing from both the incoming request or the pgo.yaml config file
-func isMissingS3Config(request *msgs.CreateClusterRequest) bool {
-	if request.BackrestS3Bucket == "" && apiserver.Pgo.Cluster.BackrestS3Bucket == "" {
-		return true
-	}
-	if request.BackrestS3Endpoint == "" && apiserver.Pgo.Cluster.BackrestS3Endpoint == "" {
-		return true
-	}
-	if request.BackrestS3Region == "" && apiserver.Pgo.Cluster.BackrestS3Region == "" {
-		return true
-	}
-	return false
-}
-
 func validateStandbyCluster(request *msgs.CreateClusterRequest) error {
-	switch {
-	case !strings.Contains(request.BackrestStorageType, "s3"):
-		return errors.New("Backrest storage type 's3' must be selected in order to create a " +
-			"standby cluster")
-	case request.BackrestRepoPath == "":
-		return errors.New("A pgBackRest repository path must be specified when creating a " +
-			"standby cluster")
-	}
-	return nil
+	return util.ValidateStandbyCluster(request.BackrestStorageType, request.BackrestRepoPath)
 }
@@ -61,7 +61,7 @@ func CreateClusterHandler(w http.ResponseWriter, r *http.Request) {
 	// a special authz check here: if the ShowSystemAccounts flag is set, ensure
 	// the user is authorized to show system accounts
 	if request.ShowSystemAccounts &&
-		!apiserver.BasicAuthzCheck(username, apiserver.SHOW_SYSTEM_ACCOUNTS_PERM) {
+		!apiserver.AuthzCheck(r.Context(), username, apiserver.SHOW_SYSTEM_ACCOUNTS_PERM) {
 		log.Errorf("Authorization Failed %s username=[%s]", apiserver.SHOW_SYSTEM_ACCOUNTS_PERM, username)
 		http.Error(w, "Not authorized for this apiserver action", 403)
 		return
@@ -80,7 +80,7 @@ func CreateClusterHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(resp)
 		return
 	}
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status.Code = msgs.Error
 		resp.Status.Msg = err.Error()
@@ -154,7 +154,7 @@ func ShowClusterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		resp.Results = make([]msgs.ShowClusterDetail, 0)
@@ -228,7 +228,7 @@ func DeleteClusterHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(resp)
 		return
 	}
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		resp.Results = make([]string, 0)
@@ -294,7 +294,7 @@ func TestClusterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -356,7 +356,7 @@ func UpdateClusterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		resp.Results = make([]string, 0)
@@ -364,7 +364,7 @@ func UpdateClusterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp = UpdateCluster(&request)
+	resp = UpdateCluster(&request, username)
 	json.NewEncoder(w).Encode(resp)
 
 }
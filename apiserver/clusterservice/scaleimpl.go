@@ -25,6 +25,7 @@ import (
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	clusteroperator "github.com/crunchydata/postgres-operator/operator/cluster"
 	"github.com/crunchydata/postgres-operator/util"
 
 	log "github.com/sirupsen/logrus"
@@ -162,6 +163,12 @@ func ScaleCluster(name, replicaCount, storageConfig, nodeLabel,
 		response.Results = append(response.Results, "created Pgreplica "+labels[config.LABEL_NAME])
 	}
 
+	if err := updateReplicaCount(name, ns); err != nil {
+		log.Error(err)
+		response.Status.Code = msgs.Error
+		response.Status.Msg = err.Error()
+	}
+
 	return response
 }
 
@@ -325,6 +332,42 @@ func ScaleDown(deleteData bool, clusterName, replicaName, ns string) msgs.ScaleD
 		return response
 	}
 
+	if err := updateReplicaCount(clusterName, ns); err != nil {
+		log.Error(err)
+		response.Status.Code = msgs.Error
+		response.Status.Msg = err.Error()
+		return response
+	}
+
 	response.Results = append(response.Results, "deleted replica "+replicaName)
 	return response
 }
+
+// updateReplicaCount sets spec.replicas of a cluster to the number of replicas
+// it has once replicas were added or removed directly, so that spec.replicas
+// keeps reflecting the replicas of the cluster. As the replicas that are being
+// added or removed are already accounted for, the Operator does not add or
+// remove any replica as a result
+func updateReplicaCount(clusterName, ns string) error {
+	cluster := crv1.Pgcluster{}
+	if _, err := kubeapi.Getpgcluster(apiserver.RESTClient, &cluster, clusterName, ns); err != nil {
+		return err
+	}
+
+	count, err := clusteroperator.ReplicaCount(apiserver.Clientset, apiserver.RESTClient, &cluster)
+	if err != nil {
+		return fmt.Errorf("could not update the number of replicas of cluster %s: %s", clusterName, err.Error())
+	}
+
+	if cluster.Spec.Replicas == strconv.Itoa(count) {
+		return nil
+	}
+
+	cluster.Spec.Replicas = strconv.Itoa(count)
+
+	if err := kubeapi.Updatepgcluster(apiserver.RESTClient, &cluster, clusterName, ns); err != nil {
+		return fmt.Errorf("could not update the number of replicas of cluster %s: %s", clusterName, err.Error())
+	}
+
+	return nil
+}
@@ -17,14 +17,17 @@ limitations under the License.
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,7 +47,11 @@ const (
 )
 
 var (
-	backrestStorageTypes = []string{"local", "s3"}
+	backrestStorageTypes = []string{"local", "s3", "gcs", "azure"}
+	// backupLabelRegex matches the label of a pgBackRest backup, i.e. that of a
+	// full backup, or that of a differential or incremental backup that is
+	// prefixed with the label of the full backup it depends on
+	backupLabelRegex = regexp.MustCompile(`^\d{8}-\d{6}F(_\d{8}-\d{6}[DI])?$`)
 	// ErrDBContainerNotFound is an error that indicates that a "database" container
 	// could not be found in a specific pod
 	ErrDBContainerNotFound = errors.New("\"database\" container not found in pod")
@@ -129,16 +136,9 @@ func IsValidPVC(pvcName, ns string) bool {
 // and determine whether or not it is a valid quantity object. Returns an error
 // if it is invalid, along with the error message.
 //
-// If it is empty, it returns no error
-//
-// See: https://github.com/kubernetes/apimachinery/blob/master/pkg/api/resource/quantity.go
+// If it is empty, it returns no error. See util.ValidateQuantity
 func ValidateQuantity(quantity string) error {
-	if quantity == "" {
-		return nil
-	}
-
-	_, err := resource.ParseQuantity(quantity)
-	return err
+	return util.ValidateQuantity(quantity)
 }
 
 // FindStandbyClusters takes a list of pgcluster structs and returns a slice containing the names
@@ -161,3 +161,53 @@ func PGClusterListHasStandby(clusterList crv1.PgclusterList) (bool, []string) {
 	standbyClusters := FindStandbyClusters(clusterList)
 	return len(FindStandbyClusters(clusterList)) > 0, standbyClusters
 }
+
+// ValidateRetentionOptions returns an error if the pgBackRest options provided
+// for a backup of a cluster would override the retention policy of the cluster
+func ValidateRetentionOptions(cluster *crv1.Pgcluster, options string) error {
+	if !cluster.Spec.BackrestRetention.IsSet() {
+		return nil
+	}
+
+	if found, _ := util.SplitPgBackRestRetentionOptions(options); len(found) > 0 {
+		return fmt.Errorf("cluster %s has a pgBackRest retention policy, which cannot be "+
+			"overridden by the %s option(s). Update the retention policy of the cluster instead",
+			cluster.Name, strings.Join(found, ", "))
+	}
+
+	return nil
+}
+
+// BackrestRepoOption returns the pgBackRest option that selects repository
+// "repo" of a cluster, e.g. "--repo=2", after validating that the cluster has
+// that repository. The default repository, which is selected by a repo of 0,
+// needs no option. As the repositories of a cluster replace the storage types
+// of its backups, a repository cannot be combined with a storage type
+func BackrestRepoOption(cluster *crv1.Pgcluster, repo int, storageType string) (string, error) {
+	if repo == 0 {
+		return "", nil
+	}
+
+	if repo < 0 || repo > len(cluster.Spec.BackrestRepos) {
+		return "", fmt.Errorf("cluster %s does not have pgBackRest repository %d", cluster.Name, repo)
+	}
+
+	if storageType != "" {
+		return "", fmt.Errorf("a pgBackRest repository cannot be combined with the pgBackRest "+
+			"storage type %q", storageType)
+	}
+
+	return fmt.Sprintf("--repo=%d", repo), nil
+}
+
+// ValidateBackupLabel returns an error if a label is not that of a pgBackRest
+// backup, e.g. "20200301-010000F" or "20200301-010000F_20200302-010000I". An
+// empty label, which refers to the latest backup, is valid
+func ValidateBackupLabel(label string) error {
+	if label != "" && !backupLabelRegex.MatchString(label) {
+		return fmt.Errorf("%q is not the label of a pgBackRest backup (hint: try a value like "+
+			"\"20200301-010000F\")", label)
+	}
+
+	return nil
+}
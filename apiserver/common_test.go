@@ -0,0 +1,75 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+)
+
+func TestBackrestRepoOption(t *testing.T) {
+	cluster := &crv1.Pgcluster{}
+	cluster.Name = "hippo"
+	cluster.Spec.BackrestRepos = []crv1.PgBackRestRepoSpec{{Type: "local"}, {Type: "s3"}}
+
+	tests := []struct {
+		repo        int
+		storageType string
+		option      string
+		valid       bool
+	}{
+		{0, "", "", true},
+		{0, "s3", "", true},
+		{1, "", "--repo=1", true},
+		{2, "", "--repo=2", true},
+		{3, "", "", false},
+		{-1, "", "", false},
+		{2, "s3", "", false},
+	}
+
+	for _, test := range tests {
+		option, err := BackrestRepoOption(cluster, test.repo, test.storageType)
+		if (err == nil) != test.valid {
+			t.Errorf("expected repo %d with storage type %q to be valid: %t, got %v",
+				test.repo, test.storageType, test.valid, err)
+		} else if option != test.option {
+			t.Errorf("expected option %q for repo %d, got %q", test.option, test.repo, option)
+		}
+	}
+}
+
+func TestValidateBackupLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		valid bool
+	}{
+		{"", true},
+		{"20200301-010000F", true},
+		{"20200301-010000F_20200302-010000D", true},
+		{"20200301-010000F_20200302-010000I", true},
+		{"20200301-010000I", false},
+		{"20200301-010000F_20200302-010000F", false},
+		{"latest", false},
+		{"20200301-010000F --type=time", false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateBackupLabel(test.label); (err == nil) != test.valid {
+			t.Errorf("expected %q to be valid: %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
@@ -72,7 +72,7 @@ func ShowConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -89,7 +89,7 @@ func DfHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ensure that the user has access to this namespace. if not, error out
-	if _, err := apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace); err != nil {
+	if _, err := apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace); err != nil {
 		response := CreateErrorResponse(err.Error())
 		json.NewEncoder(w).Encode(response)
 		return
@@ -0,0 +1,233 @@
+package failoverservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/apiserver"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// failoverTargetAuto is the target of a failover that selects the recommended
+// failover target
+const failoverTargetAuto = "auto"
+
+const (
+	// failoverScoreMax is the score of a failover candidate that is not behind
+	// the primary, has not restarted and is on another node than the primary
+	failoverScoreMax = 100
+	// failoverScoreMaxLagPenalty caps how much the replication lag, which costs
+	// a point per whole MB, lowers the score. A candidate whose lag is unknown
+	// gets the full penalty
+	failoverScoreMaxLagPenalty = 50
+	// failoverScoreRestartPenalty is the cost of each restart of the database
+	// container, up to failoverScoreMaxRestartPenalty
+	failoverScoreRestartPenalty    = 5
+	failoverScoreMaxRestartPenalty = 25
+	// failoverScoreSameNodePenalty is the cost of running on the same node as
+	// the primary, as a failure of the node would take both down
+	failoverScoreSameNodePenalty = 20
+)
+
+// failoverCandidate is the information about a replica that it is ranked on as
+// a failover target
+type failoverCandidate struct {
+	util.InstanceReplicationInfo
+	Ready    bool
+	Restarts int
+}
+
+// getFailoverTargets returns the failover targets of a cluster ranked from best
+// to worst, along with the name of the recommended target, which is empty if
+// no target passes the health bar
+func getFailoverTargets(clusterName, ns string) ([]msgs.FailoverTargetSpec, string, error) {
+	replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+		RESTConfig:     apiserver.RESTConfig,
+		Clientset:      apiserver.Clientset,
+		Namespace:      ns,
+		ClusterName:    clusterName,
+		IncludePrimary: true,
+		LagBytes:       true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the readiness and the restarts of each replica are found on its pod
+	selector := fmt.Sprintf("%s=%s,%s=%s", config.LABEL_PG_CLUSTER, clusterName,
+		config.LABEL_PGHA_ROLE, config.LABEL_PGHA_ROLE_REPLICA)
+	pods, err := kubeapi.GetPods(apiserver.Clientset, selector, ns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	primary := util.InstanceReplicationInfo{}
+	candidates := []failoverCandidate{}
+
+	for _, instance := range replicationStatus.Instances {
+		if instance.Role == config.LABEL_PGHA_ROLE_PRIMARY {
+			primary = instance
+			continue
+		}
+
+		candidate := failoverCandidate{InstanceReplicationInfo: instance}
+
+		for _, pod := range pods.Items {
+			if pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] == instance.Name {
+				candidate.Ready, candidate.Restarts = getDatabaseContainerStatus(pod)
+			}
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	maxLagBytes := config.DefaultFailoverMaxLagBytes
+	if apiserver.Pgo.Cluster.FailoverMaxLagBytes != nil {
+		maxLagBytes = *apiserver.Pgo.Cluster.FailoverMaxLagBytes
+	}
+
+	maxRestarts := config.DefaultFailoverMaxRestarts
+	if apiserver.Pgo.Cluster.FailoverMaxRestarts != nil {
+		maxRestarts = *apiserver.Pgo.Cluster.FailoverMaxRestarts
+	}
+
+	targets := rankFailoverCandidates(primary, candidates, maxLagBytes, maxRestarts)
+
+	recommended := ""
+	if len(targets) > 0 && targets[0].Eligible {
+		recommended = targets[0].Name
+	}
+
+	log.Debugf("failover targets of cluster %s: %v, recommended [%s]", clusterName, targets, recommended)
+
+	return targets, recommended, nil
+}
+
+// rankFailoverCandidates scores the failover candidates and returns them from
+// best to worst, with those that pass the health bar first. To pass the health
+// bar, a candidate must be running and ready, be on the same timeline as the
+// primary, and be within the maximum replication lag and number of restarts.
+//
+// The score of a candidate is lowered by its replication lag, its restarts and
+// by running on the same node as the primary
+func rankFailoverCandidates(primary util.InstanceReplicationInfo, candidates []failoverCandidate,
+	maxLagBytes int64, maxRestarts int) []msgs.FailoverTargetSpec {
+	targets := make([]msgs.FailoverTargetSpec, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		target := msgs.FailoverTargetSpec{
+			Name:           candidate.Name,
+			Node:           candidate.Node,
+			Status:         candidate.Status,
+			ReplicationLag: candidate.ReplicationLag,
+			Timeline:       candidate.Timeline,
+			Ready:          candidate.Ready,
+			Restarts:       candidate.Restarts,
+			Score:          failoverScoreMax,
+			Eligible:       true,
+			Reasons:        []string{},
+		}
+
+		// the health bar
+		if candidate.Status != "running" {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, "not running")
+		}
+		if !candidate.Ready {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, "not ready")
+		}
+		if primary.Timeline != 0 && candidate.Timeline != primary.Timeline {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, fmt.Sprintf("on timeline %d instead of %d",
+				candidate.Timeline, primary.Timeline))
+		}
+		if candidate.ReplicationLagBytes == util.ReplicationLagUnknown {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, "replication lag unknown")
+		} else if candidate.ReplicationLagBytes > maxLagBytes {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, fmt.Sprintf("%d bytes behind, above the maximum of %d bytes",
+				candidate.ReplicationLagBytes, maxLagBytes))
+		}
+		if candidate.Restarts > maxRestarts {
+			target.Eligible = false
+			target.Reasons = append(target.Reasons, fmt.Sprintf("restarted %d times, above the maximum of %d",
+				candidate.Restarts, maxRestarts))
+		}
+
+		// the score
+		if candidate.ReplicationLagBytes == util.ReplicationLagUnknown {
+			target.Score -= failoverScoreMaxLagPenalty
+		} else if candidate.ReplicationLagBytes > 0 {
+			target.Score -= min(int(candidate.ReplicationLagBytes/1048576), failoverScoreMaxLagPenalty)
+			target.Reasons = append(target.Reasons, fmt.Sprintf("%d bytes behind the primary",
+				candidate.ReplicationLagBytes))
+		} else {
+			target.Reasons = append(target.Reasons, "caught up with the primary")
+		}
+		if candidate.Restarts > 0 {
+			target.Score -= min(candidate.Restarts*failoverScoreRestartPenalty, failoverScoreMaxRestartPenalty)
+			target.Reasons = append(target.Reasons, fmt.Sprintf("restarted %d times", candidate.Restarts))
+		}
+		if candidate.Node != "" && candidate.Node == primary.Node {
+			target.Score -= failoverScoreSameNodePenalty
+			target.Reasons = append(target.Reasons, "on the same node as the primary")
+		} else if candidate.Node != "" && primary.Node != "" {
+			target.Reasons = append(target.Reasons, "on another node than the primary")
+		}
+
+		targets = append(targets, target)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Eligible != targets[j].Eligible {
+			return targets[i].Eligible
+		}
+		if targets[i].Score != targets[j].Score {
+			return targets[i].Score > targets[j].Score
+		}
+		return strings.Compare(targets[i].Name, targets[j].Name) < 0
+	})
+
+	return targets
+}
+
+// getDatabaseContainerStatus returns whether the database container of a pod is
+// ready, and how many times it restarted
+func getDatabaseContainerStatus(pod v1.Pod) (bool, int) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == "database" {
+			return status.Ready, int(status.RestartCount)
+		}
+	}
+	return false, 0
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
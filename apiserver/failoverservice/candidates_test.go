@@ -0,0 +1,115 @@
+package failoverservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/crunchydata/postgres-operator/util"
+)
+
+func TestRankFailoverCandidates(t *testing.T) {
+	primary := util.InstanceReplicationInfo{Name: "hippo", Node: "node1", Role: "master", Timeline: 2}
+
+	replica := func(name, node, status string, lagBytes int64, timeline int, ready bool,
+		restarts int) failoverCandidate {
+		return failoverCandidate{
+			InstanceReplicationInfo: util.InstanceReplicationInfo{
+				Name: name, Node: node, Status: status, ReplicationLag: int(lagBytes / 1048576),
+				ReplicationLagBytes: lagBytes, Timeline: timeline,
+			},
+			Ready:    ready,
+			Restarts: restarts,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []failoverCandidate
+		expected   []string
+		eligible   []bool
+		scores     []int
+	}{
+		{
+			name: "lag and node placement",
+			candidates: []failoverCandidate{
+				replica("hippo-abcd", "node1", "running", 0, 2, true, 0),
+				replica("hippo-efgh", "node2", "running", 1048576, 2, true, 0),
+				replica("hippo-ijkl", "node3", "running", 0, 2, true, 0),
+			},
+			expected: []string{"hippo-ijkl", "hippo-efgh", "hippo-abcd"},
+			eligible: []bool{true, true, true},
+			scores:   []int{100, 99, 80},
+		},
+		{
+			name: "restarts",
+			candidates: []failoverCandidate{
+				replica("hippo-abcd", "node2", "running", 0, 2, true, 2),
+				replica("hippo-efgh", "node3", "running", 0, 2, true, 9),
+			},
+			expected: []string{"hippo-abcd", "hippo-efgh"},
+			eligible: []bool{true, false},
+			scores:   []int{90, 75},
+		},
+		{
+			name: "health bar",
+			candidates: []failoverCandidate{
+				replica("hippo-abcd", "node2", "stopped", 0, 2, false, 0),
+				replica("hippo-efgh", "node2", "running", 0, 1, true, 0),
+				replica("hippo-ijkl", "node2", "running", 2097152, 2, true, 0),
+				replica("hippo-mnop", "node1", "running", 1048576, 2, true, 1),
+			},
+			expected: []string{"hippo-mnop", "hippo-abcd", "hippo-efgh", "hippo-ijkl"},
+			eligible: []bool{true, false, false, false},
+			scores:   []int{74, 100, 100, 98},
+		},
+		{
+			name: "lag to the byte",
+			candidates: []failoverCandidate{
+				replica("hippo-abcd", "node2", "running", 1048577, 2, true, 0),
+				replica("hippo-efgh", "node2", "running", util.ReplicationLagUnknown, 2, true, 0),
+				replica("hippo-ijkl", "node2", "running", 1048575, 2, true, 0),
+			},
+			expected: []string{"hippo-ijkl", "hippo-abcd", "hippo-efgh"},
+			eligible: []bool{true, false, false},
+			scores:   []int{100, 99, 50},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			targets := rankFailoverCandidates(primary, test.candidates, 1048576, 3)
+
+			names, eligible, scores := []string{}, []bool{}, []int{}
+			for _, target := range targets {
+				names = append(names, target.Name)
+				eligible = append(eligible, target.Eligible)
+				scores = append(scores, target.Score)
+			}
+
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+			if !reflect.DeepEqual(eligible, test.eligible) {
+				t.Errorf("expected eligibility %v, got %v", test.eligible, eligible)
+			}
+			if !reflect.DeepEqual(scores, test.scores) {
+				t.Errorf("expected scores %v, got %v", test.scores, scores)
+			}
+		})
+	}
+}
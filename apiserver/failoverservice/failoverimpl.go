@@ -17,12 +17,17 @@ limitations under the License.
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/apiserver"
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	clusteroperator "github.com/crunchydata/postgres-operator/operator/cluster"
 	"github.com/crunchydata/postgres-operator/util"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/apps/v1"
@@ -33,6 +38,7 @@ import (
 // pgo failover mycluster
 // pgo failover all
 // pgo failover --selector=name=mycluster
+// pgo failover mycluster --target=auto
 func CreateFailover(request *msgs.CreateFailoverRequest, ns, pgouser string) msgs.CreateFailoverResponse {
 	var err error
 	resp := msgs.CreateFailoverResponse{}
@@ -47,6 +53,35 @@ func CreateFailover(request *msgs.CreateFailoverRequest, ns, pgouser string) msg
 		return resp
 	}
 
+	// with an "auto" target, the recommended failover target is used, provided
+	// one passes the health bar
+	if request.Target == failoverTargetAuto {
+		targets, recommended, err := getFailoverTargets(request.ClusterName, ns)
+		if err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
+
+		if recommended == "" {
+			reasons := []string{}
+			for _, target := range targets {
+				reasons = append(reasons, target.Name+": "+strings.Join(target.Reasons, ", "))
+			}
+
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = "no failover target passes the health bar"
+			if len(reasons) > 0 {
+				resp.Status.Msg += " (" + strings.Join(reasons, "; ") + ")"
+			}
+			return resp
+		}
+
+		request.Target = recommended
+		resp.Results = append(resp.Results, fmt.Sprintf("selected failover target %s (%s)",
+			recommended, strings.Join(targets[0].Reasons, ", ")))
+	}
+
 	if request.Target != "" {
 		_, err = isValidFailoverTarget(request.Target, request.ClusterName, ns)
 		if err != nil {
@@ -96,6 +131,124 @@ func CreateFailover(request *msgs.CreateFailoverRequest, ns, pgouser string) msg
 	return resp
 }
 
+// CreateSwitchover creates a pgtask for a switchover of a cluster, provided the
+// candidate, be it the target or the replica that is the least behind, is
+// within the maximum replication lag. The lag is checked again by the operator
+// right before it requests the switchover from Patroni
+// pgo switchover mycluster
+// pgo switchover mycluster --target=mycluster-abcd --max-lag-bytes=0
+// pgo switchover mycluster --scheduled-at=2020-06-01T01:00:00Z
+func CreateSwitchover(request *msgs.CreateSwitchoverRequest, ns, pgouser string) msgs.CreateSwitchoverResponse {
+	resp := msgs.CreateSwitchoverResponse{
+		Results: make([]string, 0),
+		Status:  msgs.Status{Code: msgs.Ok, Msg: ""},
+	}
+
+	cluster, err := validateClusterName(request.ClusterName, ns)
+	if err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	if cluster.Spec.Standby {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "a switchover cannot be performed on standby cluster " + request.ClusterName
+		return resp
+	}
+
+	if request.Target != "" {
+		if _, err := isValidFailoverTarget(request.Target, request.ClusterName, ns); err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
+	}
+
+	if request.MaxLagBytes < 0 {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "the maximum replication lag cannot be negative"
+		return resp
+	}
+
+	if request.ScheduledAt != "" {
+		scheduledAt, err := time.Parse(time.RFC3339, request.ScheduledAt)
+		if err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = fmt.Sprintf("invalid scheduled time %q, expected an RFC 3339 timestamp "+
+				"such as 2020-06-01T01:00:00Z", request.ScheduledAt)
+			return resp
+		} else if !scheduledAt.After(time.Now()) {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = "the scheduled time of a switchover must be in the future"
+			return resp
+		}
+	}
+
+	log.Debugf("create switchover called for %s", request.ClusterName)
+
+	// refuse the switchover now if there is no suitable candidate, rather than
+	// only once the operator processes the task
+	replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+		RESTConfig:  apiserver.RESTConfig,
+		Clientset:   apiserver.Clientset,
+		Namespace:   ns,
+		ClusterName: request.ClusterName,
+		LagBytes:    true,
+	})
+	if err != nil {
+		log.Error(err)
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	candidate, err := clusteroperator.SelectSwitchoverCandidate(replicationStatus.Instances,
+		request.Target, request.MaxLagBytes)
+	if err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "switchover refused: " + err.Error()
+		return resp
+	}
+
+	spec := crv1.PgtaskSpec{}
+	spec.Namespace = ns
+	spec.Name = request.ClusterName + "-" + config.LABEL_SWITCHOVER
+	spec.TaskType = crv1.PgtaskSwitchover
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_PG_CLUSTER] = request.ClusterName
+	spec.Parameters[config.LABEL_SWITCHOVER_MAX_LAG_BYTES] = strconv.FormatInt(request.MaxLagBytes, 10)
+	spec.Parameters[config.LABEL_SWITCHOVER_SCHEDULED_AT] = request.ScheduledAt
+
+	// previous switchovers will leave a pgtask so remove it first
+	kubeapi.Deletepgtask(apiserver.RESTClient, spec.Name, ns)
+
+	labels := make(map[string]string)
+	labels[config.LABEL_TARGET] = request.Target
+	labels[config.LABEL_PG_CLUSTER] = request.ClusterName
+	labels[config.LABEL_PGOUSER] = pgouser
+
+	newInstance := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   spec.Name,
+			Labels: labels,
+		},
+		Spec: spec,
+	}
+
+	if err := kubeapi.Createpgtask(apiserver.RESTClient, newInstance, ns); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	resp.Results = append(resp.Results, "created Pgtask (switchover) for cluster "+request.ClusterName)
+	resp.Results = append(resp.Results, fmt.Sprintf("candidate %s is currently %d bytes behind the primary",
+		candidate.Name, candidate.ReplicationLagBytes))
+
+	return resp
+}
+
 // QueryFailover provides the user with a list of replicas that can be failed
 // over to
 // pgo failover mycluster --query
@@ -118,18 +271,12 @@ func QueryFailover(name, ns string) msgs.QueryFailoverResponse {
 	// indicate in the response whether or not a standby cluster
 	response.Standby = cluster.Spec.Standby
 
-	// Get information about the current status of all of the replicas. This is
-	// handled by a helper function, that will return the information in a struct
-	// with the key elements to help the user understand the current state of the
-	// replicas in a cluster
-	replicationStatusRequest := util.ReplicationStatusRequest{
-		RESTConfig:  apiserver.RESTConfig,
-		Clientset:   apiserver.Clientset,
-		Namespace:   ns,
-		ClusterName: name,
-	}
-
-	replicationStatusResponse, err := util.ReplicationStatus(replicationStatusRequest)
+	// Get information about the current status of all of the replicas, ranked
+	// as failover targets. This is handled by a helper function, that will
+	// return the information along with the reasons behind the rank of each
+	// replica, to help the user understand the current state of the replicas in
+	// a cluster
+	targets, recommended, err := getFailoverTargets(name, ns)
 
 	// if an error is return, log the message, and return the response
 	if err != nil {
@@ -139,25 +286,8 @@ func QueryFailover(name, ns string) msgs.QueryFailoverResponse {
 		return response
 	}
 
-	// if there are no results, return the response as is
-	if len(replicationStatusResponse.Instances) == 0 {
-		return response
-	}
-
-	// iterate through response results to create the API response
-	for _, instance := range replicationStatusResponse.Instances {
-		// create an result for the response
-		result := msgs.FailoverTargetSpec{
-			Name:           instance.Name,
-			Node:           instance.Node,
-			Status:         instance.Status,
-			ReplicationLag: instance.ReplicationLag,
-			Timeline:       instance.Timeline,
-		}
-
-		// append the result to the response list
-		response.Results = append(response.Results, result)
-	}
+	response.Results = targets
+	response.Recommended = recommended
 
 	return response
 }
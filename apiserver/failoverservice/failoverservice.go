@@ -69,7 +69,7 @@ func CreateFailoverHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -141,7 +141,7 @@ func QueryFailoverHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -151,3 +151,60 @@ func QueryFailoverHandler(w http.ResponseWriter, r *http.Request) {
 	resp = QueryFailover(name, ns)
 	json.NewEncoder(w).Encode(resp)
 }
+
+// CreateSwitchoverHandler ...
+// pgo switchover mycluster
+func CreateSwitchoverHandler(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /switchover failoverservice switchover
+	/*```
+	Performs a switchover, i.e. a graceful failover.
+	*/
+	// ---
+	//  produces:
+	//  - application/json
+	//  parameters:
+	//  - name: "Create Switchover Request"
+	//    in: "body"
+	//    schema:
+	//      "$ref": "#/definitions/CreateSwitchoverRequest"
+	//  responses:
+	//    '200':
+	//      description: Output
+	//      schema:
+	//        "$ref": "#/definitions/CreateSwitchoverResponse"
+	var ns string
+
+	log.Debug("failoverservice.CreateSwitchoverHandler called")
+
+	var request msgs.CreateSwitchoverRequest
+	_ = json.NewDecoder(r.Body).Decode(&request)
+
+	username, err := apiserver.Authn(apiserver.CREATE_SWITCHOVER_PERM, w, r)
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	resp := msgs.CreateSwitchoverResponse{}
+	resp.Status = msgs.Status{Code: msgs.Ok, Msg: ""}
+
+	if request.ClientVersion != msgs.PGO_VERSION {
+		resp.Status = msgs.Status{Code: msgs.Error, Msg: apiserver.VERSION_MISMATCH_ERROR}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
+	if err != nil {
+		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	resp = CreateSwitchover(&request, ns, username)
+
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,186 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	authorizationapi "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// kubernetesUsernamePrefix is prepended to the name of a Kubernetes user so
+	// that it cannot collide with a pgouser
+	kubernetesUsernamePrefix = "kube."
+	// kubernetesNamespaceResource is the virtual resource checked to determine
+	// whether a caller can access a namespace
+	kubernetesNamespaceResource = "namespaces"
+	// kubernetesNamespaceVerb is the verb checked to determine whether a caller
+	// can access a namespace
+	kubernetesNamespaceVerb = "use"
+)
+
+// KubernetesUserInfo is the Kubernetes user that a caller authenticated as
+// with a TokenReview
+type KubernetesUserInfo struct {
+	Username string
+	UID      string
+	Groups   []string
+	Extra    map[string]authorizationapi.ExtraValue
+}
+
+// KubernetesAuth is true when callers can authenticate with a Kubernetes token
+// verified by a TokenReview and are authorized with SubjectAccessReviews
+var KubernetesAuth bool
+
+// invalidLabelValueChars matches the characters that cannot be part of a label
+// value
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// initKubernetesAuth enables Kubernetes token authentication and RBAC
+// authorization if it is configured
+func initKubernetesAuth() {
+	if !Pgo.KubernetesAuth.Enabled {
+		return
+	}
+
+	KubernetesAuth = true
+
+	log.Infof("Kubernetes authentication is enabled using API group %s",
+		Pgo.KubernetesAuth.APIGroup)
+}
+
+// kubernetesUsername converts the name of a Kubernetes user, e.g.
+// "system:serviceaccount:pgo:ci", into a username that can be stored in a label
+// and that does not collide with a pgouser
+func kubernetesUsername(name string) string {
+	return labelSafeUsername(kubernetesUsernamePrefix + name)
+}
+
+// labelSafeUsername converts the name of a caller that authenticated with a
+// bearer token, e.g. "oidc.alice@example.com", into a username that can be
+// stored in a label, e.g. as the creator of a cluster. If the name has to be
+// altered, a hash of the original is appended so that two names cannot end up
+// the same
+func labelSafeUsername(name string) string {
+	if len(validation.IsValidLabelValue(name)) == 0 {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:4])
+
+	// a label value has to start with an alphanumeric character
+	username := strings.TrimLeft(invalidLabelValueChars.ReplaceAllString(name, "_"), "._-")
+
+	if max := validation.LabelValueMaxLength - len(suffix) - 1; len(username) > max {
+		username = username[:max]
+	}
+
+	if username == "" {
+		return suffix
+	}
+
+	return username + "." + suffix
+}
+
+// kubernetesTokenIdentity authenticates a bearer token with a TokenReview and
+// returns the identity of the Kubernetes user it belongs to
+func kubernetesTokenIdentity(token string) (TokenIdentity, error) {
+	identity := TokenIdentity{}
+
+	review, err := kubeapi.CreateTokenReview(Clientset, token, Pgo.KubernetesAuth.Audiences)
+	if err != nil {
+		return identity, err
+	}
+
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return identity, errors.New(review.Status.Error)
+		}
+		return identity, ErrBearerTokenInvalid
+	}
+
+	user := review.Status.User
+
+	identity.Username = kubernetesUsername(user.Username)
+	identity.Kubernetes = &KubernetesUserInfo{
+		Username: user.Username,
+		UID:      user.UID,
+		Groups:   user.Groups,
+		Extra:    map[string]authorizationapi.ExtraValue{},
+	}
+
+	for k, v := range user.Extra {
+		identity.Kubernetes.Extra[k] = authorizationapi.ExtraValue(v)
+	}
+
+	return identity, nil
+}
+
+// kubernetesAccessReview asks Kubernetes whether the user may perform the verb
+// on the virtual resource of the pgo API group. If the namespace is empty, the
+// user needs to be allowed in every namespace
+func kubernetesAccessReview(user *KubernetesUserInfo, verb, resource, namespace, name string) (bool, error) {
+	review, err := kubeapi.CreateSubjectAccessReview(Clientset, user.Username, user.UID,
+		user.Groups, user.Extra, &authorizationapi.ResourceAttributes{
+			Group:     Pgo.KubernetesAuth.APIGroup,
+			Resource:  resource,
+			Verb:      verb,
+			Namespace: namespace,
+			Name:      name,
+		})
+	if err != nil {
+		return false, err
+	}
+
+	if !review.Status.Allowed {
+		log.Debugf("%s %s/%s denied for %s: %s", verb, Pgo.KubernetesAuth.APIGroup, resource,
+			user.Username, review.Status.Reason)
+	}
+
+	return review.Status.Allowed, nil
+}
+
+// kubernetesAuthzCheck checks whether the Kubernetes user is granted the
+// apiserver permission. Permissions are checked across the cluster, i.e. they
+// need to be granted with a ClusterRoleBinding, while access to individual
+// namespaces is checked separately
+func kubernetesAuthzCheck(user *KubernetesUserInfo, perm string) (bool, error) {
+	attrs, ok := PermResourceAttributes[perm]
+	if !ok {
+		return false, fmt.Errorf("no resource attributes defined for permission %s", perm)
+	}
+
+	return kubernetesAccessReview(user, attrs.Verb, attrs.Resource, "", "")
+}
+
+// kubernetesNamespaceCheck checks whether the Kubernetes user may use the
+// namespace, which is granted by the "use" verb on the "namespaces" virtual
+// resource, either by a RoleBinding in that namespace or by a
+// ClusterRoleBinding, optionally restricted to the name of the namespace
+func kubernetesNamespaceCheck(user *KubernetesUserInfo, namespace string) (bool, error) {
+	return kubernetesAccessReview(user, kubernetesNamespaceVerb, kubernetesNamespaceResource,
+		namespace, namespace)
+}
@@ -0,0 +1,47 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestLabelSafeUsername(t *testing.T) {
+	if username := labelSafeUsername("oidc.alice"); username != "oidc.alice" {
+		t.Errorf("expected a valid username to be kept, got %q", username)
+	}
+
+	for _, name := range []string{
+		"oidc.alice@example.com",
+		"kube.system:serviceaccount:pgo:ci",
+		"_alice",
+		"@",
+		"kube." + strings.Repeat("a", validation.LabelValueMaxLength),
+	} {
+		username := labelSafeUsername(name)
+		if errs := validation.IsValidLabelValue(username); len(errs) > 0 {
+			t.Errorf("expected %q to become a valid label value, got %q: %v", name, username, errs)
+		}
+	}
+
+	// names that only differ in the characters that are replaced stay distinct
+	if labelSafeUsername("oidc.alice@example.com") == labelSafeUsername("oidc.alice_example.com") {
+		t.Error("expected distinct usernames")
+	}
+}
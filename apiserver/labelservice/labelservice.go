@@ -68,7 +68,7 @@ func LabelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Msg: err.Error(), Code: msgs.Error}
 		json.NewEncoder(w).Encode(resp)
@@ -124,7 +124,7 @@ func DeleteLabelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Msg: err.Error(), Code: msgs.Error}
 		json.NewEncoder(w).Encode(resp)
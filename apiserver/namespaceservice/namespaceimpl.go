@@ -16,6 +16,7 @@ limitations under the License.
 */
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/crunchydata/postgres-operator/apiserver"
@@ -26,7 +27,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func ShowNamespace(clientset *kubernetes.Clientset, username string, request *msgs.ShowNamespaceRequest) msgs.ShowNamespaceResponse {
+func ShowNamespace(ctx context.Context, clientset *kubernetes.Clientset, username string, request *msgs.ShowNamespaceRequest) msgs.ShowNamespaceResponse {
 	log.Debug("ShowNamespace called")
 	resp := msgs.ShowNamespaceResponse{}
 	resp.Status = msgs.Status{Code: msgs.Ok, Msg: ""}
@@ -68,7 +69,7 @@ func ShowNamespace(clientset *kubernetes.Clientset, username string, request *ms
 	}
 
 	for i := 0; i < len(nsList); i++ {
-		iaccess, uaccess, err := apiserver.UserIsPermittedInNamespace(username, nsList[i])
+		iaccess, uaccess, err := apiserver.UserIsPermittedInNamespace(ctx, username, nsList[i])
 		if err != nil {
 			resp.Status.Code = msgs.Error
 			resp.Status.Msg = fmt.Sprintf("Error when determining whether user [%s] is allowed "+
@@ -81,7 +81,7 @@ func ShowNamespaceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp = ShowNamespace(apiserver.Clientset, username, &request)
+	resp = ShowNamespace(r.Context(), apiserver.Clientset, username, &request)
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -0,0 +1,544 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for RS256, ES256 and PS256
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for the remaining algorithms
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crunchydata/postgres-operator/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// oidcDiscoveryPath is appended to the issuer URL to find the OpenID Connect
+	// provider metadata when a JWKS location is not configured
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	// oidcClockSkew is the leeway given when checking the time based claims of
+	// a token
+	oidcClockSkew = time.Minute
+	// oidcKeyRefreshInterval is the minimum amount of time between refreshes of
+	// a remote JWKS, which happen when a token is signed with an unknown key
+	oidcKeyRefreshInterval = time.Minute
+)
+
+var (
+	// ErrBearerTokenInvalid is returned when a bearer token cannot be parsed or
+	// verified
+	ErrBearerTokenInvalid = errors.New("bearer token is invalid")
+	// ErrBearerTokenDisabled is returned when a bearer token is presented but
+	// neither OpenID Connect nor Kubernetes authentication is configured
+	ErrBearerTokenDisabled = errors.New("bearer token authentication is not enabled")
+)
+
+// OIDCAuth is true when the apiserver accepts bearer tokens issued by the
+// OpenID Connect provider configured in pgo.yaml
+var OIDCAuth bool
+
+// oidcTokenVerifier verifies the bearer tokens presented to the apiserver
+var oidcTokenVerifier *oidcVerifier
+
+// TokenIdentity is the identity of a caller that authenticated with a bearer
+// token
+type TokenIdentity struct {
+	// Username is the prefixed value of the username claim
+	Username string
+	// Roles are the pgoroles derived from the groups claim
+	Roles []string
+	// Namespaces are the namespaces derived from the namespaces claim. If nil,
+	// the caller has access to every namespace of the installation
+	Namespaces []string
+	// Expiry is when an OIDC token expires
+	Expiry time.Time
+	// Kubernetes is set if the caller authenticated with a Kubernetes token,
+	// in which case the caller is authorized with SubjectAccessReviews rather
+	// than with pgoroles
+	Kubernetes *KubernetesUserInfo
+}
+
+// permittedInNamespace returns true if the identity has access to the namespace
+func (t TokenIdentity) permittedInNamespace(namespace string) bool {
+	if t.Namespaces == nil {
+		return true
+	}
+
+	for _, ns := range t.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tokenIdentityContextKey is the key under which the identity of a caller that
+// authenticated with a bearer token is stored in the context of their request
+type tokenIdentityContextKey struct{}
+
+// contextWithTokenIdentity returns a copy of the context that carries the
+// identity of a caller that authenticated with a bearer token
+func contextWithTokenIdentity(ctx context.Context, identity TokenIdentity) context.Context {
+	return context.WithValue(ctx, tokenIdentityContextKey{}, identity)
+}
+
+// tokenIdentityFromContext returns the identity of a caller that authenticated
+// with a bearer token from the context of their request
+func tokenIdentityFromContext(ctx context.Context) (TokenIdentity, bool) {
+	if ctx == nil {
+		return TokenIdentity{}, false
+	}
+
+	identity, ok := ctx.Value(tokenIdentityContextKey{}).(TokenIdentity)
+	return identity, ok
+}
+
+// initOIDC enables bearer token authentication if an OpenID Connect issuer is
+// configured
+func initOIDC() error {
+	if Pgo.OIDC.IssuerURL == "" {
+		return nil
+	}
+
+	verifier, err := newOIDCVerifier(Pgo.OIDC)
+	if err != nil {
+		return err
+	}
+
+	oidcTokenVerifier = verifier
+	OIDCAuth = true
+
+	log.Infof("OIDC authentication is enabled for issuer %s", Pgo.OIDC.IssuerURL)
+
+	return nil
+}
+
+// verifyBearerToken authenticates a bearer token, first as an OIDC token and
+// then, if that fails and Kubernetes authentication is enabled, with a
+// TokenReview
+func verifyBearerToken(token string) (TokenIdentity, error) {
+	if !OIDCAuth && !KubernetesAuth {
+		return TokenIdentity{}, ErrBearerTokenDisabled
+	}
+
+	if OIDCAuth {
+		identity, err := oidcTokenVerifier.Verify(token, time.Now())
+		if err == nil || !KubernetesAuth {
+			return identity, err
+		}
+		log.Debugf("token is not a valid OIDC token, trying a TokenReview: %s", err.Error())
+	}
+
+	return kubernetesTokenIdentity(token)
+}
+
+// bearerToken returns the bearer token from the Authorization header of the
+// request, if there is one
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[7:]), true
+}
+
+// jsonWebKeySet is a JWKS document as defined in RFC 7517
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is a single RSA or EC public key from a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes the public key that the JWK represents
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// oidcVerifier verifies JWTs issued by an OpenID Connect provider and maps
+// their claims onto a TokenIdentity
+type oidcVerifier struct {
+	config     config.OIDCStruct
+	httpClient *http.Client
+
+	mutex       sync.Mutex
+	jwksURL     string
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+// newOIDCVerifier creates a verifier for the configured provider and loads its
+// signing keys
+func newOIDCVerifier(c config.OIDCStruct) (*oidcVerifier, error) {
+	v := &oidcVerifier{
+		config:     c,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		jwksURL:    c.JWKSURL,
+	}
+
+	if err := v.refreshKeys(); err != nil {
+		return nil, fmt.Errorf("could not load OIDC signing keys: %w", err)
+	}
+
+	return v, nil
+}
+
+// refreshKeys loads the signing keys from the JWKS file or URL, discovering
+// the URL from the issuer if needed
+func (v *oidcVerifier) refreshKeys() error {
+	var data []byte
+	var err error
+
+	switch {
+	case v.config.JWKSFile != "":
+		data, err = ioutil.ReadFile(v.config.JWKSFile)
+	default:
+		if v.jwksURL == "" {
+			if v.jwksURL, err = v.discoverJWKSURL(); err != nil {
+				return err
+			}
+		}
+		data, err = v.get(v.jwksURL)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Warnf("skipping OIDC signing key %q: %s", k.Kid, err.Error())
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return errors.New("no usable signing keys found")
+	}
+
+	v.keys = keys
+	v.lastRefresh = time.Now()
+
+	return nil
+}
+
+// discoverJWKSURL finds the JWKS URL in the provider metadata of the issuer
+func (v *oidcVerifier) discoverJWKSURL() (string, error) {
+	data, err := v.get(strings.TrimSuffix(v.config.IssuerURL, "/") + oidcDiscoveryPath)
+	if err != nil {
+		return "", err
+	}
+
+	metadata := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return "", err
+	}
+
+	if metadata.Issuer != v.config.IssuerURL {
+		return "", fmt.Errorf("issuer %q in provider metadata does not match %q",
+			metadata.Issuer, v.config.IssuerURL)
+	}
+
+	if metadata.JWKSURI == "" {
+		return "", errors.New("provider metadata does not contain a jwks_uri")
+	}
+
+	return metadata.JWKSURI, nil
+}
+
+// get returns the body of a successful HTTP GET request
+func (v *oidcVerifier) get(url string) ([]byte, error) {
+	resp, err := v.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// key returns the signing key with the given key ID. If the key is not known
+// and the keys come from a URL, they are refreshed in case the provider has
+// rotated them
+func (v *oidcVerifier) key(kid string) (crypto.PublicKey, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	if v.config.JWKSFile == "" && time.Since(v.lastRefresh) > oidcKeyRefreshInterval {
+		if err := v.refreshKeys(); err != nil {
+			log.Errorf("could not refresh OIDC signing keys: %s", err.Error())
+		} else if key, ok := v.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// Verify checks the signature and the registered claims of a JWT and returns
+// the identity it carries
+func (v *oidcVerifier) Verify(token string, now time.Time) (TokenIdentity, error) {
+	identity := TokenIdentity{}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return identity, ErrBearerTokenInvalid
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return identity, ErrBearerTokenInvalid
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return identity, ErrBearerTokenInvalid
+	}
+
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return identity, err
+	}
+
+	if err := verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return identity, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return identity, ErrBearerTokenInvalid
+	}
+
+	if iss, _ := claims["iss"].(string); iss != v.config.IssuerURL {
+		return identity, fmt.Errorf("token issuer %q is not trusted", iss)
+	}
+
+	if !audienceContains(claims["aud"], v.config.ClientID) {
+		return identity, fmt.Errorf("token audience does not contain %q", v.config.ClientID)
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return identity, errors.New("token does not expire")
+	}
+	identity.Expiry = time.Unix(int64(exp), 0)
+	if now.After(identity.Expiry.Add(oidcClockSkew)) {
+		return identity, errors.New("token is expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(oidcClockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return identity, errors.New("token is not valid yet")
+	}
+
+	username, _ := claims[v.config.UsernameClaim].(string)
+	if username == "" {
+		return identity, fmt.Errorf("token does not contain the %q claim", v.config.UsernameClaim)
+	}
+	identity.Username = labelSafeUsername(v.config.UsernamePrefix + username)
+
+	for _, group := range claimStrings(claims[v.config.GroupsClaim]) {
+		if strings.HasPrefix(group, v.config.GroupsPrefix) {
+			identity.Roles = append(identity.Roles, strings.TrimPrefix(group, v.config.GroupsPrefix))
+		}
+	}
+
+	if v.config.NamespacesClaim != "" {
+		identity.Namespaces = append([]string{}, claimStrings(claims[v.config.NamespacesClaim])...)
+	}
+
+	return identity, nil
+}
+
+// decodeJWTSegment decodes a base64url encoded JSON segment of a JWT
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// verifyJWTSignature verifies the signature of a JWT using one of the RSA or
+// ECDSA algorithms from RFC 7518
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+
+	switch strings.TrimLeft(alg, "RSPE") {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token signing algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, signature, nil)
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			break
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrBearerTokenInvalid
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return ErrBearerTokenInvalid
+		}
+		return nil
+	}
+
+	return fmt.Errorf("token signing algorithm %q does not match the signing key", alg)
+}
+
+// audienceContains returns true if the "aud" claim, which is either a string
+// or an array of strings, contains the client ID
+func audienceContains(aud interface{}, clientID string) bool {
+	for _, a := range claimStrings(aud) {
+		if a == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// claimStrings returns the value of a claim that is either a string or an
+// array of strings as a slice
+func claimStrings(claim interface{}) []string {
+	switch c := claim.(type) {
+	case string:
+		return []string{c}
+	case []interface{}:
+		values := []string{}
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
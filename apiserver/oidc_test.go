@@ -0,0 +1,175 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crunchydata/postgres-operator/config"
+)
+
+func TestOIDCVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	jwksFile := filepath.Join(dir, "jwks.json")
+	if err := ioutil.WriteFile(jwksFile, jwks, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := newOIDCVerifier(config.OIDCStruct{
+		IssuerURL:       "https://issuer.example.com",
+		ClientID:        "pgo",
+		JWKSFile:        jwksFile,
+		UsernameClaim:   "preferred_username",
+		UsernamePrefix:  "oidc.",
+		GroupsClaim:     "groups",
+		GroupsPrefix:    "pgo-",
+		NamespacesClaim: "namespaces",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1585742400, 0)
+	sign := func(kid string, claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." +
+			base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	claims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":                "https://issuer.example.com",
+			"aud":                []string{"other", "pgo"},
+			"exp":                now.Add(time.Hour).Unix(),
+			"preferred_username": "alice",
+			"groups":             []string{"pgo-pgoadmin", "engineering"},
+			"namespaces":         []string{"pgouser1"},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		identity, err := verifier.Verify(sign("test", claims()), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.Username != "oidc.alice" {
+			t.Errorf("expected oidc.alice, got %q", identity.Username)
+		}
+		if expected := []string{"pgoadmin"}; !reflect.DeepEqual(expected, identity.Roles) {
+			t.Errorf("expected %v, got %v", expected, identity.Roles)
+		}
+		if !identity.permittedInNamespace("pgouser1") || identity.permittedInNamespace("pgouser2") {
+			t.Errorf("unexpected namespace access for %v", identity.Namespaces)
+		}
+	})
+
+	t.Run("email username", func(t *testing.T) {
+		c := claims()
+		c["preferred_username"] = "alice@example.com"
+
+		identity, err := verifier.Verify(sign("test", c), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(identity.Username, "oidc.alice_example.com.") {
+			t.Errorf("expected a sanitized username, got %q", identity.Username)
+		}
+	})
+
+	for name, mutate := range map[string]func(map[string]interface{}){
+		"expired":     func(c map[string]interface{}) { c["exp"] = now.Add(-time.Hour).Unix() },
+		"issuer":      func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" },
+		"audience":    func(c map[string]interface{}) { c["aud"] = "other" },
+		"no username": func(c map[string]interface{}) { delete(c, "preferred_username") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := claims()
+			mutate(c)
+			if _, err := verifier.Verify(sign("test", c), now); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	t.Run("unknown key", func(t *testing.T) {
+		if _, err := verifier.Verify(sign("other", claims()), now); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		token := sign("test", claims())
+		c := claims()
+		c["groups"] = []string{"pgo-superuser"}
+		forged := sign("test", c)
+		parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+		if _, err := verifier.Verify(parts[0]+"."+forgedParts[1]+"."+parts[2], now); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestTokenIdentityContext(t *testing.T) {
+	if _, ok := tokenIdentityFromContext(context.Background()); ok {
+		t.Error("expected no identity in an empty context")
+	}
+
+	identity := TokenIdentity{Username: "oidc:hippo", Roles: []string{"pgoadmin"}}
+
+	found, ok := tokenIdentityFromContext(contextWithTokenIdentity(context.Background(), identity))
+	if !ok || !reflect.DeepEqual(found, identity) {
+		t.Errorf("expected %+v, got %+v", identity, found)
+	}
+}
@@ -31,25 +31,27 @@ const (
 	LABEL_PERM        = "Label"
 	LOAD_PERM         = "Load"
 	RELOAD_PERM       = "Reload"
+	RESTART_PERM      = "Restart"
 	RESTORE_PERM      = "Restore"
 	STATUS_PERM       = "Status"
 	TEST_CLUSTER_PERM = "TestCluster"
 	VERSION_PERM      = "Version"
 
 	// CREATE
-	CREATE_BACKUP_PERM    = "CreateBackup"
-	CREATE_CLUSTER_PERM   = "CreateCluster"
-	CREATE_DUMP_PERM      = "CreateDump"
-	CREATE_FAILOVER_PERM  = "CreateFailover"
-	CREATE_INGEST_PERM    = "CreateIngest"
-	CREATE_NAMESPACE_PERM = "CreateNamespace"
-	CREATE_PGBOUNCER_PERM = "CreatePgbouncer"
-	CREATE_PGOUSER_PERM   = "CreatePgouser"
-	CREATE_PGOROLE_PERM   = "CreatePgorole"
-	CREATE_POLICY_PERM    = "CreatePolicy"
-	CREATE_SCHEDULE_PERM  = "CreateSchedule"
-	CREATE_UPGRADE_PERM   = "CreateUpgrade"
-	CREATE_USER_PERM      = "CreateUser"
+	CREATE_BACKUP_PERM     = "CreateBackup"
+	CREATE_CLUSTER_PERM    = "CreateCluster"
+	CREATE_DUMP_PERM       = "CreateDump"
+	CREATE_FAILOVER_PERM   = "CreateFailover"
+	CREATE_INGEST_PERM     = "CreateIngest"
+	CREATE_NAMESPACE_PERM  = "CreateNamespace"
+	CREATE_PGBOUNCER_PERM  = "CreatePgbouncer"
+	CREATE_PGOUSER_PERM    = "CreatePgouser"
+	CREATE_PGOROLE_PERM    = "CreatePgorole"
+	CREATE_POLICY_PERM     = "CreatePolicy"
+	CREATE_SCHEDULE_PERM   = "CreateSchedule"
+	CREATE_SWITCHOVER_PERM = "CreateSwitchover"
+	CREATE_UPGRADE_PERM    = "CreateUpgrade"
+	CREATE_USER_PERM       = "CreateUser"
 
 	// RESTORE
 	RESTORE_DUMP_PERM = "RestoreDump"
@@ -98,6 +100,93 @@ const (
 var RoleMap map[string]map[string]string
 var PermMap map[string]string
 
+// PermResourceAttribute is the verb and virtual resource that an apiserver
+// permission maps onto when callers are authorized with Kubernetes
+// SubjectAccessReviews
+type PermResourceAttribute struct {
+	Verb     string
+	Resource string
+}
+
+// PermResourceAttributes maps every apiserver permission onto a verb and a
+// virtual resource within the configured API group, e.g. "CreateCluster" onto
+// the "create" verb of "clusters", so that they can be granted with Kubernetes
+// RBAC
+var PermResourceAttributes = map[string]PermResourceAttribute{
+	// MISC
+	APPLY_POLICY_PERM: {Verb: "apply", Resource: "policies"},
+	CAT_PERM:          {Verb: "cat", Resource: "clusters"},
+	CLONE_PERM:        {Verb: "clone", Resource: "clusters"},
+	DF_CLUSTER_PERM:   {Verb: "df", Resource: "clusters"},
+	LABEL_PERM:        {Verb: "label", Resource: "clusters"},
+	LOAD_PERM:         {Verb: "load", Resource: "clusters"},
+	RELOAD_PERM:       {Verb: "reload", Resource: "clusters"},
+	RESTART_PERM:      {Verb: "restart", Resource: "clusters"},
+	RESTORE_PERM:      {Verb: "restore", Resource: "clusters"},
+	STATUS_PERM:       {Verb: "get", Resource: "status"},
+	TEST_CLUSTER_PERM: {Verb: "test", Resource: "clusters"},
+	VERSION_PERM:      {Verb: "get", Resource: "version"},
+
+	// CREATE
+	CREATE_BACKUP_PERM:     {Verb: "create", Resource: "backups"},
+	CREATE_CLUSTER_PERM:    {Verb: "create", Resource: "clusters"},
+	CREATE_DUMP_PERM:       {Verb: "create", Resource: "pgdumps"},
+	CREATE_FAILOVER_PERM:   {Verb: "create", Resource: "failovers"},
+	CREATE_INGEST_PERM:     {Verb: "create", Resource: "ingests"},
+	CREATE_NAMESPACE_PERM:  {Verb: "create", Resource: "namespaces"},
+	CREATE_PGBOUNCER_PERM:  {Verb: "create", Resource: "pgbouncers"},
+	CREATE_PGOUSER_PERM:    {Verb: "create", Resource: "pgousers"},
+	CREATE_PGOROLE_PERM:    {Verb: "create", Resource: "pgoroles"},
+	CREATE_POLICY_PERM:     {Verb: "create", Resource: "policies"},
+	CREATE_SCHEDULE_PERM:   {Verb: "create", Resource: "schedules"},
+	CREATE_SWITCHOVER_PERM: {Verb: "create", Resource: "switchovers"},
+	CREATE_UPGRADE_PERM:    {Verb: "create", Resource: "upgrades"},
+	CREATE_USER_PERM:       {Verb: "create", Resource: "users"},
+
+	// RESTORE
+	RESTORE_DUMP_PERM: {Verb: "restore", Resource: "pgdumps"},
+
+	// DELETE
+	DELETE_BACKUP_PERM:    {Verb: "delete", Resource: "backups"},
+	DELETE_CLUSTER_PERM:   {Verb: "delete", Resource: "clusters"},
+	DELETE_INGEST_PERM:    {Verb: "delete", Resource: "ingests"},
+	DELETE_NAMESPACE_PERM: {Verb: "delete", Resource: "namespaces"},
+	DELETE_PGBOUNCER_PERM: {Verb: "delete", Resource: "pgbouncers"},
+	DELETE_PGOROLE_PERM:   {Verb: "delete", Resource: "pgoroles"},
+	DELETE_PGOUSER_PERM:   {Verb: "delete", Resource: "pgousers"},
+	DELETE_POLICY_PERM:    {Verb: "delete", Resource: "policies"},
+	DELETE_SCHEDULE_PERM:  {Verb: "delete", Resource: "schedules"},
+	DELETE_USER_PERM:      {Verb: "delete", Resource: "users"},
+
+	// SHOW
+	SHOW_BACKUP_PERM:          {Verb: "get", Resource: "backups"},
+	SHOW_CLUSTER_PERM:         {Verb: "get", Resource: "clusters"},
+	SHOW_CONFIG_PERM:          {Verb: "get", Resource: "config"},
+	SHOW_INGEST_PERM:          {Verb: "get", Resource: "ingests"},
+	SHOW_NAMESPACE_PERM:       {Verb: "get", Resource: "namespaces"},
+	SHOW_PGBOUNCER_PERM:       {Verb: "get", Resource: "pgbouncers"},
+	SHOW_PGOROLE_PERM:         {Verb: "get", Resource: "pgoroles"},
+	SHOW_PGOUSER_PERM:         {Verb: "get", Resource: "pgousers"},
+	SHOW_POLICY_PERM:          {Verb: "get", Resource: "policies"},
+	SHOW_PVC_PERM:             {Verb: "get", Resource: "pvcs"},
+	SHOW_SCHEDULE_PERM:        {Verb: "get", Resource: "schedules"},
+	SHOW_SECRETS_PERM:         {Verb: "get", Resource: "secrets"},
+	SHOW_SYSTEM_ACCOUNTS_PERM: {Verb: "get", Resource: "systemaccounts"},
+	SHOW_USER_PERM:            {Verb: "get", Resource: "users"},
+	SHOW_WORKFLOW_PERM:        {Verb: "get", Resource: "workflows"},
+
+	// SCALE
+	SCALE_CLUSTER_PERM: {Verb: "scale", Resource: "clusters"},
+
+	// UPDATE
+	UPDATE_CLUSTER_PERM:   {Verb: "update", Resource: "clusters"},
+	UPDATE_NAMESPACE_PERM: {Verb: "update", Resource: "namespaces"},
+	UPDATE_PGBOUNCER_PERM: {Verb: "update", Resource: "pgbouncers"},
+	UPDATE_PGOROLE_PERM:   {Verb: "update", Resource: "pgoroles"},
+	UPDATE_PGOUSER_PERM:   {Verb: "update", Resource: "pgousers"},
+	UPDATE_USER_PERM:      {Verb: "update", Resource: "users"},
+}
+
 const pgorolePath = "/default-pgo-config/pgorole"
 const pgoroleFile = "pgorole"
 
@@ -115,25 +204,27 @@ func InitializePerms() {
 		LABEL_PERM:        "yes",
 		LOAD_PERM:         "yes",
 		RELOAD_PERM:       "yes",
+		RESTART_PERM:      "yes",
 		RESTORE_PERM:      "yes",
 		STATUS_PERM:       "yes",
 		TEST_CLUSTER_PERM: "yes",
 		VERSION_PERM:      "yes",
 
 		// CREATE
-		CREATE_BACKUP_PERM:    "yes",
-		CREATE_DUMP_PERM:      "yes",
-		CREATE_CLUSTER_PERM:   "yes",
-		CREATE_FAILOVER_PERM:  "yes",
-		CREATE_INGEST_PERM:    "yes",
-		CREATE_NAMESPACE_PERM: "yes",
-		CREATE_PGBOUNCER_PERM: "yes",
-		CREATE_PGOROLE_PERM:   "yes",
-		CREATE_PGOUSER_PERM:   "yes",
-		CREATE_POLICY_PERM:    "yes",
-		CREATE_SCHEDULE_PERM:  "yes",
-		CREATE_UPGRADE_PERM:   "yes",
-		CREATE_USER_PERM:      "yes",
+		CREATE_BACKUP_PERM:     "yes",
+		CREATE_DUMP_PERM:       "yes",
+		CREATE_CLUSTER_PERM:    "yes",
+		CREATE_FAILOVER_PERM:   "yes",
+		CREATE_INGEST_PERM:     "yes",
+		CREATE_NAMESPACE_PERM:  "yes",
+		CREATE_PGBOUNCER_PERM:  "yes",
+		CREATE_PGOROLE_PERM:    "yes",
+		CREATE_PGOUSER_PERM:    "yes",
+		CREATE_POLICY_PERM:     "yes",
+		CREATE_SCHEDULE_PERM:   "yes",
+		CREATE_SWITCHOVER_PERM: "yes",
+		CREATE_UPGRADE_PERM:    "yes",
+		CREATE_USER_PERM:       "yes",
 
 		// RESTORE
 		RESTORE_DUMP_PERM: "yes",
@@ -0,0 +1,41 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+)
+
+func TestPermResourceAttributes(t *testing.T) {
+	InitializePerms()
+
+	for perm := range PermMap {
+		attrs, ok := PermResourceAttributes[perm]
+		if !ok {
+			t.Errorf("permission %q has no resource attributes", perm)
+			continue
+		}
+		if attrs.Verb == "" || attrs.Resource == "" {
+			t.Errorf("permission %q has incomplete resource attributes %+v", perm, attrs)
+		}
+	}
+
+	for perm := range PermResourceAttributes {
+		if _, ok := PermMap[perm]; !ok {
+			t.Errorf("resource attributes defined for unknown permission %q", perm)
+		}
+	}
+}
@@ -34,6 +34,10 @@ import (
 
 const pgBouncerServiceSuffix = "-pgbouncer"
 
+// pgBouncerReadOnlyServiceSuffix is the suffix of the Service of the read-only
+// pgBouncer, which is routed to the replicas
+const pgBouncerReadOnlyServiceSuffix = "-pgbouncer-ro"
+
 // CreatePgbouncer ...
 // pgo create pgbouncer mycluster
 // pgo create pgbouncer --selector=name=mycluster
@@ -66,6 +70,19 @@ func CreatePgbouncer(request *msgs.CreatePgbouncerRequest, ns, pgouser string) m
 		return resp
 	}
 
+	if request.ReadOnlyReplicas < 0 {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "read-only pgBouncer " + fmt.Sprintf(apiserver.ErrMessageReplicas, 0)
+		return resp
+	}
+
+	// validate the pgBouncer settings, if any are passed in
+	if err := util.ValidatePgBouncerConfig(request.Config, request.Databases); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
 	log.Debugf("createPgbouncer selector is [%s]", request.Selector)
 
 	// try to get the list of clusters. if there is an error, put it into the
@@ -92,6 +109,9 @@ func CreatePgbouncer(request *msgs.CreatePgbouncerRequest, ns, pgouser string) m
 			cluster.Spec.PgBouncer.Replicas = request.Replicas
 		}
 
+		// the read-only pgBouncer, which is routed to the replicas, is optional
+		cluster.Spec.PgBouncer.ReadOnlyReplicas = request.ReadOnlyReplicas
+
 		// if the request has overriding CPURequest and/or MemoryRequest parameters,
 		// these will take precedence over the defaults
 		if request.CPURequest != "" {
@@ -109,6 +129,8 @@ func CreatePgbouncer(request *msgs.CreatePgbouncerRequest, ns, pgouser string) m
 		}
 
 		cluster.Spec.PgBouncer.Resources = resources
+		cluster.Spec.PgBouncer.Config = request.Config
+		cluster.Spec.PgBouncer.Databases = request.Databases
 
 		// update the cluster CRD with these udpates. If there is an error
 		if err := kubeapi.Updatepgcluster(apiserver.RESTClient, &cluster, cluster.Name, request.Namespace); err != nil {
@@ -173,8 +195,10 @@ func DeletePgbouncer(request *msgs.DeletePgbouncerRequest, ns string) msgs.Delet
 			}
 		}
 
-		// Disable the pgBouncer Deploymnet, whcih means setting Replicas to 0
+		// Disable the pgBouncer Deploymnet, whcih means setting Replicas to 0, and
+		// the read-only pgBouncer along with it
 		cluster.Spec.PgBouncer.Replicas = 0
+		cluster.Spec.PgBouncer.ReadOnlyReplicas = 0
 
 		// update the cluster CRD with these udpates. If there is an error
 		if err := kubeapi.Updatepgcluster(apiserver.RESTClient, &cluster, cluster.Name, request.Namespace); err != nil {
@@ -198,6 +222,7 @@ func DeletePgbouncer(request *msgs.DeletePgbouncerRequest, ns string) msgs.Delet
 //
 // pgo show pgbouncer
 // pgo show pgbouncer --selector
+// pgo show pgbouncer --stats
 func ShowPgBouncer(request *msgs.ShowPgBouncerRequest, namespace string) msgs.ShowPgBouncerResponse {
 	// set up a dummy response
 	response := msgs.ShowPgBouncerResponse{
@@ -244,6 +269,11 @@ func ShowPgBouncer(request *msgs.ShowPgBouncerRequest, namespace string) msgs.Sh
 		// get the user information about the pgBouncer deployment
 		setPgBouncerPasswordDetail(cluster, &result)
 
+		// if requested, get the statistics of the pgBouncer Pods
+		if request.Stats {
+			setPgBouncerStatsDetail(cluster, &result)
+		}
+
 		// append the result to the list
 		response.Results = append(response.Results, result)
 	}
@@ -288,6 +318,12 @@ func UpdatePgBouncer(request *msgs.UpdatePgBouncerRequest, namespace, pgouser st
 		return response
 	}
 
+	if request.ReadOnlyReplicas != nil && *request.ReadOnlyReplicas < 0 {
+		response.Status.Code = msgs.Error
+		response.Status.Msg = "read-only pgBouncer " + fmt.Sprintf(apiserver.ErrMessageReplicas, 0)
+		return response
+	}
+
 	log.Debugf("update pgbouncer called, cluster [%v], selector [%s]", request.ClusterNames, request.Selector)
 
 	// try to get the list of clusters. if there is an error, put it into the
@@ -370,6 +406,24 @@ func UpdatePgBouncer(request *msgs.UpdatePgBouncerRequest, namespace, pgouser st
 			cluster.Spec.PgBouncer.Replicas = request.Replicas
 		}
 
+		// the read-only pgBouncer is added, scaled or removed only if asked to, as
+		// 0 removes it
+		if request.ReadOnlyReplicas != nil {
+			cluster.Spec.PgBouncer.ReadOnlyReplicas = *request.ReadOnlyReplicas
+		}
+
+		// merge the pgBouncer settings into those of the cluster, and ensure the
+		// result is valid
+		updatePgBouncerConfig(&cluster.Spec.PgBouncer, request.Config, request.Databases)
+
+		if err := util.ValidatePgBouncerConfig(cluster.Spec.PgBouncer.Config,
+			cluster.Spec.PgBouncer.Databases); err != nil {
+			result.Error = true
+			result.ErrorMessage = err.Error()
+			response.Results = append(response.Results, result)
+			continue
+		}
+
 		if err := kubeapi.Updatepgcluster(apiserver.RESTClient, &cluster, cluster.Name, cluster.Namespace); err != nil {
 			log.Error(err)
 			result.Error = true
@@ -437,6 +491,35 @@ func getClusterList(namespace string, clusterNames []string, selector string) (c
 	return clusterList, nil
 }
 
+// getServiceExternalIP returns the external IP address of a Service, if one is
+// assigned, based on the formula used in show cluster
+func getServiceExternalIP(service v1.Service) string {
+	externalIP := ""
+
+	if len(service.Spec.ExternalIPs) > 0 {
+		externalIP = service.Spec.ExternalIPs[0]
+	}
+
+	if len(service.Status.LoadBalancer.Ingress) > 0 {
+		externalIP = service.Status.LoadBalancer.Ingress[0].IP
+	}
+
+	return externalIP
+}
+
+// mergeSettings sets the settings provided, and removes those with an empty
+// value
+func mergeSettings(settings, update map[string]string) {
+	for name, value := range update {
+		if value == "" {
+			delete(settings, name)
+			continue
+		}
+
+		settings[name] = value
+	}
+}
+
 // setPgBouncerPasswordDetail applies the password that is used by the pgbouncer
 // service account
 func setPgBouncerPasswordDetail(cluster crv1.Pgcluster, result *msgs.ShowPgBouncerDetail) {
@@ -474,6 +557,14 @@ func setPgBouncerServiceDetail(cluster crv1.Pgcluster, result *msgs.ShowPgBounce
 	// adding the service information was borrowed from the ShowCluster
 	// resource
 	for _, service := range services.Items {
+		// the read-only pgBouncer has a service of its own
+		if strings.HasSuffix(service.Name, pgBouncerReadOnlyServiceSuffix) {
+			result.ReadOnlyServiceClusterIP = service.Spec.ClusterIP
+			result.ReadOnlyServiceExternalIP = getServiceExternalIP(service)
+			result.ReadOnlyServiceName = service.Name
+			continue
+		}
+
 		// if this service is not for pgBouncer, then skip
 		if !strings.HasSuffix(service.Name, pgBouncerServiceSuffix) {
 			continue
@@ -481,15 +572,35 @@ func setPgBouncerServiceDetail(cluster crv1.Pgcluster, result *msgs.ShowPgBounce
 
 		// this is the pgBouncer service!
 		result.ServiceClusterIP = service.Spec.ClusterIP
+		result.ServiceExternalIP = getServiceExternalIP(service)
 		result.ServiceName = service.Name
+	}
+}
 
-		// try to get the exterinal IP based on the formula used in show cluster
-		if len(service.Spec.ExternalIPs) > 0 {
-			result.ServiceExternalIP = service.Spec.ExternalIPs[0]
+// updatePgBouncerConfig merges pgBouncer settings into those of a cluster. A
+// setting with an empty value is removed, as is a database that no longer has
+// any settings
+func updatePgBouncerConfig(spec *crv1.PgBouncerSpec, settings map[string]string,
+	databases map[string]map[string]string) {
+	if len(settings) > 0 && spec.Config == nil {
+		spec.Config = map[string]string{}
+	}
+
+	mergeSettings(spec.Config, settings)
+
+	if len(databases) > 0 && spec.Databases == nil {
+		spec.Databases = map[string]map[string]string{}
+	}
+
+	for database, databaseSettings := range databases {
+		if spec.Databases[database] == nil {
+			spec.Databases[database] = map[string]string{}
 		}
 
-		if len(service.Status.LoadBalancer.Ingress) > 0 {
-			result.ServiceExternalIP = service.Status.LoadBalancer.Ingress[0].IP
+		mergeSettings(spec.Databases[database], databaseSettings)
+
+		if len(spec.Databases[database]) == 0 {
+			delete(spec.Databases, database)
 		}
 	}
 }
@@ -67,7 +67,7 @@ func CreatePgbouncerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status.Code = msgs.Error
 		resp.Status.Msg = err.Error()
@@ -145,7 +145,7 @@ func DeletePgbouncerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status.Code = msgs.Error
 		resp.Status.Msg = err.Error()
@@ -207,7 +207,7 @@ func ShowPgBouncerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ensure the namespace being used exists
-	namespace, err := apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	namespace, err := apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 
 	if err != nil {
 		response := msgs.ShowPgBouncerResponse{
@@ -275,7 +275,7 @@ func UpdatePgBouncerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ensure the namespace being used exists
-	namespace, err := apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	namespace, err := apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 
 	if err != nil {
 		response := msgs.UpdatePgBouncerResponse{
@@ -0,0 +1,280 @@
+package pgbouncerservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/apiserver"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// pgBouncerAdminDatabase is the name of the administrative console of
+// pgBouncer, whose own connections are left out of the statistics
+const pgBouncerAdminDatabase = "pgbouncer"
+
+// pgBouncerPort is the port pgBouncer listens on, as set in pgbouncer.ini
+const pgBouncerPort = "5432"
+
+// pgBouncerShowCommands are the SHOW commands of the administrative console
+// that the statistics are gathered from
+var pgBouncerShowCommands = []string{"POOLS", "STATS", "CLIENTS", "SERVERS", "DATABASES"}
+
+// pgBouncerShowResults contains the rows of each SHOW command of a pgBouncer
+// Pod, keyed by the command. Each row is keyed by the names of the columns, as
+// the columns vary between versions of pgBouncer
+type pgBouncerShowResults map[string][]map[string]string
+
+// aggregatePgBouncerStats aggregates the results of the SHOW commands of each
+// Pod of a pgBouncer deployment. The pools and databases of the Pods are summed,
+// and the average times are weighted by the number of queries and transactions
+// of each Pod
+func aggregatePgBouncerStats(serviceName string, pods []pgBouncerShowResults) msgs.PgBouncerStats {
+	stats := msgs.PgBouncerStats{
+		Databases:   []msgs.PgBouncerDatabaseStats{},
+		Errors:      []string{},
+		Pods:        len(pods),
+		Pools:       []msgs.PgBouncerPoolStats{},
+		ServiceName: serviceName,
+	}
+
+	pools := map[string]*msgs.PgBouncerPoolStats{}
+	databases := map[string]*msgs.PgBouncerDatabaseStats{}
+	// the weights of the average times, i.e. the number of queries and
+	// transactions per second of each database
+	queryWeights, transactionWeights := map[string]int64{}, map[string]int64{}
+
+	for _, pod := range pods {
+		// the pool size is a setting of the database
+		poolSizes := map[string]int64{}
+		for _, row := range pod["DATABASES"] {
+			poolSizes[row["name"]] = parseInt(row["pool_size"])
+		}
+
+		for _, row := range pod["POOLS"] {
+			if row["database"] == pgBouncerAdminDatabase {
+				continue
+			}
+
+			key := row["database"] + "/" + row["user"]
+			pool, ok := pools[key]
+			if !ok {
+				pool = &msgs.PgBouncerPoolStats{Database: row["database"], User: row["user"]}
+				pools[key] = pool
+			}
+
+			pool.ClientsActive += parseInt(row["cl_active"])
+			pool.ClientsWaiting += parseInt(row["cl_waiting"])
+			pool.ServersActive += parseInt(row["sv_active"])
+			pool.ServersIdle += parseInt(row["sv_idle"])
+			pool.PoolSize += poolSizes[row["database"]]
+
+			if maxWait := parseInt(row["maxwait"]); maxWait > pool.MaxWait {
+				pool.MaxWait = maxWait
+			}
+		}
+
+		for _, row := range pod["STATS"] {
+			if row["database"] == pgBouncerAdminDatabase {
+				continue
+			}
+
+			database, ok := databases[row["database"]]
+			if !ok {
+				database = &msgs.PgBouncerDatabaseStats{Database: row["database"]}
+				databases[row["database"]] = database
+			}
+
+			queries, transactions := parseInt(row["avg_query_count"]), parseInt(row["avg_xact_count"])
+
+			database.AverageQueryTime += parseInt(row["avg_query_time"]) * queries
+			database.AverageTransactionTime += parseInt(row["avg_xact_time"]) * transactions
+			database.TotalQueryCount += parseInt(row["total_query_count"])
+			database.TotalTransactionCount += parseInt(row["total_xact_count"])
+
+			queryWeights[row["database"]] += queries
+			transactionWeights[row["database"]] += transactions
+		}
+
+		for _, row := range pod["CLIENTS"] {
+			if row["database"] != pgBouncerAdminDatabase {
+				stats.ClientConnections++
+			}
+		}
+
+		for _, row := range pod["SERVERS"] {
+			if row["database"] != pgBouncerAdminDatabase {
+				stats.ServerConnections++
+			}
+		}
+	}
+
+	for _, pool := range pools {
+		if pool.PoolSize > 0 {
+			pool.Saturation = float64(pool.ServersActive) / float64(pool.PoolSize)
+		}
+		stats.Pools = append(stats.Pools, *pool)
+	}
+
+	for name, database := range databases {
+		if queryWeights[name] > 0 {
+			database.AverageQueryTime /= queryWeights[name]
+		}
+		if transactionWeights[name] > 0 {
+			database.AverageTransactionTime /= transactionWeights[name]
+		}
+		stats.Databases = append(stats.Databases, *database)
+	}
+
+	sort.Slice(stats.Pools, func(i, j int) bool {
+		if stats.Pools[i].Database != stats.Pools[j].Database {
+			return stats.Pools[i].Database < stats.Pools[j].Database
+		}
+		return stats.Pools[i].User < stats.Pools[j].User
+	})
+	sort.Slice(stats.Databases, func(i, j int) bool {
+		return stats.Databases[i].Database < stats.Databases[j].Database
+	})
+
+	return stats
+}
+
+// getPgBouncerShowResults runs each SHOW command on the administrative console
+// of a pgBouncer Pod, as the "pgbouncer" administrative user
+func getPgBouncerShowResults(pod v1.Pod) (pgBouncerShowResults, error) {
+	results := pgBouncerShowResults{}
+
+	for _, command := range pgBouncerShowCommands {
+		// the output is unaligned and keeps its header, so that the columns can
+		// be told apart by their names
+		cmd := []string{"sh", "-c",
+			fmt.Sprintf(`PGPASSWORD="${PG_PASSWORD}" psql -h localhost -p %s -U %s -d %s -A -P footer=off -c "SHOW %s"`,
+				pgBouncerPort, crv1.PGUserPgBouncer, pgBouncerAdminDatabase, command)}
+
+		stdout, stderr, err := kubeapi.ExecToPodThroughAPI(apiserver.RESTConfig, apiserver.Clientset,
+			cmd, "pgbouncer", pod.Name, pod.Namespace, nil)
+
+		if err != nil {
+			return nil, fmt.Errorf("SHOW %s: %s %s", command, err.Error(), strings.TrimSpace(stderr))
+		}
+
+		results[command] = parsePgBouncerShow(stdout)
+	}
+
+	return results, nil
+}
+
+// parseInt parses a number of the output of a SHOW command, which is 0 if the
+// column is not present
+func parseInt(value string) int64 {
+	i, _ := strconv.ParseInt(value, 10, 64)
+	return i
+}
+
+// parsePgBouncerShow parses the unaligned output of psql for a SHOW command into
+// rows that are keyed by the names of the columns
+func parsePgBouncerShow(output string) []map[string]string {
+	rows := []map[string]string{}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	if len(lines) == 0 || lines[0] == "" {
+		return rows
+	}
+
+	columns := strings.Split(lines[0], "|")
+
+	for _, line := range lines[1:] {
+		values := strings.Split(line, "|")
+
+		// a row that does not match the header is not one psql would output
+		if len(values) != len(columns) {
+			continue
+		}
+
+		row := map[string]string{}
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// setPgBouncerStatsDetail gathers the statistics of each pgBouncer Pod of a
+// cluster and adds them to the result for the pgBouncer show, aggregated by
+// pgBouncer deployment, i.e. the pgBouncer of the primary and the read-only
+// pgBouncer are reported separately
+func setPgBouncerStatsDetail(cluster crv1.Pgcluster, result *msgs.ShowPgBouncerDetail) {
+	selector := fmt.Sprintf("%s=%s,%s=true", config.LABEL_PG_CLUSTER, cluster.Spec.Name,
+		config.LABEL_PGBOUNCER)
+
+	pods, err := kubeapi.GetPods(apiserver.Clientset, selector, cluster.Spec.Namespace)
+
+	// if there is an error, return without making any adjustments
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	services := []string{}
+	results := map[string][]pgBouncerShowResults{}
+	errs := map[string][]string{}
+
+	for _, pod := range pods.Items {
+		service := pod.Labels[config.LABEL_SERVICE_NAME]
+
+		if _, ok := results[service]; !ok {
+			services = append(services, service)
+			results[service] = []pgBouncerShowResults{}
+		}
+
+		// Pods that are not running have no statistics to report
+		if pod.Status.Phase != v1.PodRunning {
+			errs[service] = append(errs[service], fmt.Sprintf("%s: pod is %s", pod.Name, pod.Status.Phase))
+			continue
+		}
+
+		showResults, err := getPgBouncerShowResults(pod)
+
+		if err != nil {
+			log.Error(err)
+			errs[service] = append(errs[service], fmt.Sprintf("%s: %s", pod.Name, err.Error()))
+			continue
+		}
+
+		results[service] = append(results[service], showResults)
+	}
+
+	sort.Strings(services)
+
+	result.Stats = []msgs.PgBouncerStats{}
+
+	for _, service := range services {
+		stats := aggregatePgBouncerStats(service, results[service])
+		stats.Errors = append(stats.Errors, errs[service]...)
+		result.Stats = append(result.Stats, stats)
+	}
+}
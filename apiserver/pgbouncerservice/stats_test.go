@@ -0,0 +1,94 @@
+package pgbouncerservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+)
+
+func TestParsePgBouncerShow(t *testing.T) {
+	output := "database|user|cl_active|cl_waiting\n" +
+		"hippo|hippo|3|1\n" +
+		"pgbouncer|pgbouncer|1|0\n"
+
+	rows := parsePgBouncerShow(output)
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %v", rows)
+	}
+	if rows[0]["database"] != "hippo" || rows[0]["cl_waiting"] != "1" {
+		t.Errorf("expected the columns to be keyed by name, got %v", rows[0])
+	}
+
+	if rows := parsePgBouncerShow(""); len(rows) != 0 {
+		t.Errorf("expected no rows, got %v", rows)
+	}
+}
+
+func TestAggregatePgBouncerStats(t *testing.T) {
+	pod := func(active, waiting, queries, queryTime string) pgBouncerShowResults {
+		return pgBouncerShowResults{
+			"DATABASES": parsePgBouncerShow("name|pool_size\nhippo|20\npgbouncer|2"),
+			"POOLS": parsePgBouncerShow("database|user|cl_active|cl_waiting|sv_active|sv_idle|maxwait\n" +
+				"hippo|hippo|" + active + "|" + waiting + "|" + active + "|0|" + waiting + "\n" +
+				"pgbouncer|pgbouncer|1|0|0|0|0"),
+			"STATS": parsePgBouncerShow("database|total_query_count|total_xact_count|avg_query_count|avg_xact_count|avg_query_time|avg_xact_time\n" +
+				"hippo|100|50|" + queries + "|" + queries + "|" + queryTime + "|" + queryTime),
+			"CLIENTS": parsePgBouncerShow("type|user|database\nC|hippo|hippo\nC|pgbouncer|pgbouncer"),
+			"SERVERS": parsePgBouncerShow("type|user|database\nS|hippo|hippo"),
+		}
+	}
+
+	stats := aggregatePgBouncerStats("hippo-pgbouncer", []pgBouncerShowResults{
+		pod("10", "2", "1", "100"),
+		pod("20", "5", "3", "500"),
+	})
+
+	if stats.ServiceName != "hippo-pgbouncer" || stats.Pods != 2 {
+		t.Errorf("expected the service and the number of pods, got %+v", stats)
+	}
+
+	// the connections of the administrative console are left out
+	if stats.ClientConnections != 2 || stats.ServerConnections != 2 {
+		t.Errorf("expected 2 client and 2 server connections, got %d and %d",
+			stats.ClientConnections, stats.ServerConnections)
+	}
+
+	if len(stats.Pools) != 1 {
+		t.Fatalf("expected a single pool, got %+v", stats.Pools)
+	}
+
+	pool := stats.Pools[0]
+	if pool.ClientsActive != 30 || pool.ClientsWaiting != 7 || pool.PoolSize != 40 || pool.MaxWait != 5 {
+		t.Errorf("expected the pools of the pods to be summed, got %+v", pool)
+	}
+	if pool.Saturation != 0.75 {
+		t.Errorf("expected a saturation of 0.75, got %v", pool.Saturation)
+	}
+
+	if len(stats.Databases) != 1 {
+		t.Fatalf("expected a single database, got %+v", stats.Databases)
+	}
+
+	// (1*100 + 3*500) / 4
+	database := stats.Databases[0]
+	if database.AverageQueryTime != 400 || database.AverageTransactionTime != 400 {
+		t.Errorf("expected the average times to be weighted, got %+v", database)
+	}
+	if database.TotalQueryCount != 200 || database.TotalTransactionCount != 100 {
+		t.Errorf("expected the totals to be summed, got %+v", database)
+	}
+}
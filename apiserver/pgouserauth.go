@@ -0,0 +1,211 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"time"
+
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// The following are the keys within a pgouser Secret that are used for
+// authenticating a pgouser
+const (
+	// PgouserSecretKeyPassword is the legacy key that stores the password of a
+	// pgouser in plaintext. It is only read so that existing Secrets can be
+	// migrated to a hashed password
+	PgouserSecretKeyPassword = "password"
+	// PgouserSecretKeyPasswordHash stores the bcrypt hash of the password of a
+	// pgouser
+	PgouserSecretKeyPasswordHash = "passwordhash"
+	// PgouserSecretKeyFailedLogins stores the number of consecutive failed
+	// login attempts for a pgouser
+	PgouserSecretKeyFailedLogins = "failedlogins"
+	// PgouserSecretKeyLockedUntil stores the time, in RFC3339 format, until
+	// which a pgouser is locked out
+	PgouserSecretKeyLockedUntil = "lockeduntil"
+)
+
+// pgouserPasswordHashCost is the bcrypt cost used when hashing pgouser
+// passwords
+const pgouserPasswordHashCost = bcrypt.DefaultCost
+
+// PgouserLockoutThreshold is the number of consecutive failed login attempts
+// after which a pgouser is locked out. If it is 0, lockout is disabled
+var PgouserLockoutThreshold int
+
+// PgouserLockoutDuration is how long a pgouser remains locked out after
+// reaching PgouserLockoutThreshold
+var PgouserLockoutDuration time.Duration
+
+// HashPgouserPassword returns the salted bcrypt hash of a pgouser password
+func HashPgouserPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), pgouserPasswordHashCost)
+}
+
+// SetPgouserPassword stores the hash of the password in the pgouser Secret,
+// removes any legacy plaintext password and clears any lockout that is in
+// place. It does not save the Secret
+func SetPgouserPassword(secret *v1.Secret, password string) error {
+	hash, err := HashPgouserPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	secret.Data[PgouserSecretKeyPasswordHash] = hash
+	delete(secret.Data, PgouserSecretKeyPassword)
+	ResetPgouserLockout(secret)
+
+	return nil
+}
+
+// ResetPgouserLockout clears the failed login count and any lockout from the
+// pgouser Secret. It does not save the Secret
+func ResetPgouserLockout(secret *v1.Secret) {
+	delete(secret.Data, PgouserSecretKeyFailedLogins)
+	delete(secret.Data, PgouserSecretKeyLockedUntil)
+}
+
+// GetPgouserLockout returns the number of consecutive failed logins and the
+// time until which the pgouser is locked out, which is the zero time if the
+// pgouser is not locked out
+func GetPgouserLockout(secret *v1.Secret) (int, time.Time) {
+	failedLogins, _ := strconv.Atoi(string(secret.Data[PgouserSecretKeyFailedLogins]))
+	lockedUntil, _ := time.Parse(time.RFC3339, string(secret.Data[PgouserSecretKeyLockedUntil]))
+
+	return failedLogins, lockedUntil
+}
+
+// verifyPgouserPassword checks the password against what is stored in the
+// pgouser Secret. The second return value is true if the Secret still stores
+// the password in plaintext and needs to be migrated
+func verifyPgouserPassword(secret *v1.Secret, password string) (bool, bool) {
+	if hash, ok := secret.Data[PgouserSecretKeyPasswordHash]; ok {
+		return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil, false
+	}
+
+	plaintext, ok := secret.Data[PgouserSecretKeyPassword]
+	if !ok {
+		return false, false
+	}
+
+	return subtle.ConstantTimeCompare(plaintext, []byte(password)) == 1, true
+}
+
+// recordPgouserLoginFailure increments the failed login count of the pgouser,
+// and if the lockout threshold is reached, locks the pgouser out. It does not
+// save the Secret
+func recordPgouserLoginFailure(secret *v1.Secret, now time.Time) {
+	failedLogins, lockedUntil := GetPgouserLockout(secret)
+
+	// once a lockout has passed, the failed logins are counted afresh
+	if !lockedUntil.IsZero() && !now.Before(lockedUntil) {
+		ResetPgouserLockout(secret)
+		failedLogins = 0
+	}
+
+	failedLogins++
+
+	secret.Data[PgouserSecretKeyFailedLogins] = []byte(strconv.Itoa(failedLogins))
+
+	if PgouserLockoutThreshold > 0 && failedLogins >= PgouserLockoutThreshold {
+		lockedUntil := now.Add(PgouserLockoutDuration).UTC().Format(time.RFC3339)
+		secret.Data[PgouserSecretKeyLockedUntil] = []byte(lockedUntil)
+	}
+}
+
+// checkPgouserPassword authenticates a pgouser against the contents of their
+// Secret, performing any lockout bookkeeping and migrating a plaintext password
+// to a hashed password on the first successful login. If the bookkeeping cannot
+// be saved, the authentication attempt fails
+func checkPgouserPassword(secret *v1.Secret, username, password string) bool {
+	now := time.Now()
+
+	if PgouserLockoutThreshold > 0 {
+		if _, lockedUntil := GetPgouserLockout(secret); now.Before(lockedUntil) {
+			log.Errorf("pgouser %s is locked out until %s", username, lockedUntil.Format(time.RFC3339))
+			return false
+		}
+	}
+
+	ok, migrate := verifyPgouserPassword(secret, password)
+
+	if !ok {
+		if PgouserLockoutThreshold > 0 {
+			updatePgouserSecret(secret.Name, username, func(secret *v1.Secret) error {
+				recordPgouserLoginFailure(secret, now)
+				return nil
+			})
+		}
+		return false
+	}
+
+	failedLogins, lockedUntil := GetPgouserLockout(secret)
+
+	if !migrate && failedLogins == 0 && lockedUntil.IsZero() {
+		return true
+	}
+
+	if migrate {
+		log.Infof("migrating password of pgouser %s to a hashed password", username)
+	}
+
+	return updatePgouserSecret(secret.Name, username, func(secret *v1.Secret) error {
+		// the password is only migrated if it has not been changed since it
+		// was verified
+		if ok, migrate := verifyPgouserPassword(secret, password); ok && migrate {
+			return SetPgouserPassword(secret, password)
+		}
+
+		ResetPgouserLockout(secret)
+		return nil
+	})
+}
+
+// updatePgouserSecret applies the authentication bookkeeping of a pgouser to
+// the latest version of their Secret and saves it, retrying if the Secret was
+// modified concurrently. It returns false if the Secret could not be saved
+func updatePgouserSecret(name, username string, update func(*v1.Secret) error) bool {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := kubeapi.GetSecret(Clientset, name, PgoNamespace)
+		if err != nil {
+			return err
+		}
+
+		if err := update(secret); err != nil {
+			return err
+		}
+
+		return kubeapi.UpdateSecret(Clientset, secret, PgoNamespace)
+	})
+
+	if err != nil {
+		log.Errorf("could not update pgouser secret %s: %s", username, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package apiserver
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestVerifyPgouserPassword(t *testing.T) {
+	t.Run("plaintext", func(t *testing.T) {
+		secret := &v1.Secret{Data: map[string][]byte{PgouserSecretKeyPassword: []byte("hippo")}}
+
+		if ok, migrate := verifyPgouserPassword(secret, "hippo"); !ok || !migrate {
+			t.Errorf("expected ok and migrate, got %t %t", ok, migrate)
+		}
+		if ok, _ := verifyPgouserPassword(secret, "rhino"); ok {
+			t.Error("expected wrong password to fail")
+		}
+	})
+
+	t.Run("hashed", func(t *testing.T) {
+		secret := &v1.Secret{Data: map[string][]byte{PgouserSecretKeyPassword: []byte("hippo")}}
+
+		if err := SetPgouserPassword(secret, "hippo"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := secret.Data[PgouserSecretKeyPassword]; ok {
+			t.Error("expected plaintext password to be removed")
+		}
+		if string(secret.Data[PgouserSecretKeyPasswordHash]) == "hippo" {
+			t.Error("expected password to be hashed")
+		}
+		if ok, migrate := verifyPgouserPassword(secret, "hippo"); !ok || migrate {
+			t.Errorf("expected ok without migrate, got %t %t", ok, migrate)
+		}
+		if ok, _ := verifyPgouserPassword(secret, "rhino"); ok {
+			t.Error("expected wrong password to fail")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if ok, _ := verifyPgouserPassword(&v1.Secret{}, ""); ok {
+			t.Error("expected a secret without a password to fail")
+		}
+	})
+}
+
+func TestRecordPgouserLoginFailure(t *testing.T) {
+	PgouserLockoutThreshold, PgouserLockoutDuration = 2, time.Minute
+	defer func() { PgouserLockoutThreshold, PgouserLockoutDuration = 0, 0 }()
+
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	secret := &v1.Secret{Data: map[string][]byte{}}
+
+	recordPgouserLoginFailure(secret, now)
+	if failed, lockedUntil := GetPgouserLockout(secret); failed != 1 || !lockedUntil.IsZero() {
+		t.Errorf("expected 1 failure and no lockout, got %d %v", failed, lockedUntil)
+	}
+
+	recordPgouserLoginFailure(secret, now)
+	if failed, lockedUntil := GetPgouserLockout(secret); failed != 2 || !lockedUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected 2 failures and a lockout, got %d %v", failed, lockedUntil)
+	}
+
+	// once the lockout has passed, a failure counts as the first one again
+	recordPgouserLoginFailure(secret, now.Add(time.Minute))
+	if failed, lockedUntil := GetPgouserLockout(secret); failed != 1 || !lockedUntil.IsZero() {
+		t.Errorf("expected 1 failure and no lockout after the lockout passed, got %d %v", failed, lockedUntil)
+	}
+
+	ResetPgouserLockout(secret)
+	if failed, lockedUntil := GetPgouserLockout(secret); failed != 0 || !lockedUntil.IsZero() {
+		t.Errorf("expected lockout to be cleared, got %d %v", failed, lockedUntil)
+	}
+}
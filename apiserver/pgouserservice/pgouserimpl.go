@@ -32,7 +32,6 @@ import (
 )
 
 const MAP_KEY_USERNAME = "username"
-const MAP_KEY_PASSWORD = "password"
 const MAP_KEY_ROLES = "roles"
 const MAP_KEY_NAMESPACES = "namespaces"
 
@@ -117,6 +116,7 @@ func ShowPgouser(clientset *kubernetes.Clientset, request *msgs.ShowPgouserReque
 			info.Role = append(info.Role, string(s.Data[MAP_KEY_ROLES]))
 			info.Namespace = make([]string, 0)
 			info.Namespace = append(info.Namespace, string(s.Data[MAP_KEY_NAMESPACES]))
+			setLockoutInfo(&info, &s)
 
 			resp.UserInfo = append(resp.UserInfo, info)
 		}
@@ -137,6 +137,7 @@ func ShowPgouser(clientset *kubernetes.Clientset, request *msgs.ShowPgouserReque
 				info.Username = v
 				info.Role = append(info.Role, string(s.Data[MAP_KEY_ROLES]))
 				info.Namespace = append(info.Namespace, string(s.Data[MAP_KEY_NAMESPACES]))
+				setLockoutInfo(&info, s)
 			}
 			resp.UserInfo = append(resp.UserInfo, info)
 		}
@@ -216,7 +217,11 @@ func UpdatePgouser(clientset *kubernetes.Clientset, updatedBy string, request *m
 	secret.Data[MAP_KEY_USERNAME] = []byte(request.PgouserName)
 
 	if request.PgouserPassword != "" {
-		secret.Data[MAP_KEY_PASSWORD] = []byte(request.PgouserPassword)
+		if err := apiserver.SetPgouserPassword(secret, request.PgouserPassword); err != nil {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		}
 	}
 	if request.PgouserRoles != "" {
 		err = validRoles(clientset, request.PgouserRoles)
@@ -293,7 +298,10 @@ func createSecret(clientset *kubernetes.Clientset, createdBy string, request *ms
 	secret.Data[MAP_KEY_USERNAME] = []byte(request.PgouserName)
 	secret.Data[MAP_KEY_ROLES] = []byte(request.PgouserRoles)
 	secret.Data[MAP_KEY_NAMESPACES] = []byte(request.PgouserNamespaces)
-	secret.Data[MAP_KEY_PASSWORD] = []byte(request.PgouserPassword)
+
+	if err := apiserver.SetPgouserPassword(&secret, request.PgouserPassword); err != nil {
+		return err
+	}
 
 	return kubeapi.CreateSecret(clientset, &secret, apiserver.PgoNamespace)
 }
@@ -333,3 +341,16 @@ func validNamespaces(namespaces string, allnamespaces bool) error {
 
 	return nil
 }
+
+// setLockoutInfo fills in the failed login and lockout details of a pgouser
+// from their Secret
+func setLockoutInfo(info *msgs.PgouserInfo, secret *v1.Secret) {
+	failedLogins, lockedUntil := apiserver.GetPgouserLockout(secret)
+
+	info.FailedLogins = failedLogins
+	info.Locked = time.Now().Before(lockedUntil)
+
+	if info.Locked {
+		info.LockedUntil = lockedUntil.Format(time.RFC3339)
+	}
+}
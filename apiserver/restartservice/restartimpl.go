@@ -0,0 +1,127 @@
+package restartservice
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/apiserver"
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	clusteroperator "github.com/crunchydata/postgres-operator/operator/cluster"
+	log "github.com/sirupsen/logrus"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Restart creates the pgtask of a rolling restart of a cluster, or of the
+// restart of a single instance when there is a target
+// pgo restart mycluster
+// pgo restart mycluster --target=mycluster-abcd
+func Restart(request *msgs.RestartRequest, ns, pgouser string) msgs.RestartResponse {
+	resp := msgs.RestartResponse{
+		Results: make([]string, 0),
+		Status:  msgs.Status{Code: msgs.Ok, Msg: ""},
+	}
+
+	cluster := crv1.Pgcluster{}
+	found, err := kubeapi.Getpgcluster(apiserver.RESTClient, &cluster, request.ClusterName, ns)
+	if !found {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "no cluster found named " + request.ClusterName
+		return resp
+	} else if err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	if cluster.Spec.Standby {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "a restart cannot be performed on standby cluster " + request.ClusterName
+		return resp
+	}
+
+	if cluster.Status.State == crv1.PgclusterStateShutdown {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "a restart cannot be performed on cluster " + request.ClusterName +
+			" as it is shut down"
+		return resp
+	}
+
+	if request.Target != "" {
+		selector := config.LABEL_PG_CLUSTER + "=" + request.ClusterName + "," +
+			config.LABEL_DEPLOYMENT_NAME + "=" + request.Target
+		deployments, err := kubeapi.GetDeployments(apiserver.Clientset, selector, ns)
+		if err != nil {
+			log.Error(err)
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = err.Error()
+			return resp
+		} else if len(deployments.Items) == 0 {
+			resp.Status.Code = msgs.Error
+			resp.Status.Msg = "no instance found named " + request.Target
+			return resp
+		}
+	}
+
+	// only one restart of a cluster is run at a time, and the pgtask of one
+	// that is in progress must not be replaced
+	if name, err := clusteroperator.ActiveRestartTask(apiserver.RESTClient, request.ClusterName, ns); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	} else if name != "" {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = "a restart of cluster " + request.ClusterName + " is already in progress (pgtask " +
+			name + ")"
+		return resp
+	}
+
+	log.Debugf("restart called for %s", request.ClusterName)
+
+	spec := crv1.PgtaskSpec{}
+	spec.Namespace = ns
+	spec.Name = request.ClusterName + "-" + crv1.PgtaskRestart
+	spec.TaskType = crv1.PgtaskRestart
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_PG_CLUSTER] = request.ClusterName
+
+	// previous restarts will leave a pgtask so remove it first
+	kubeapi.Deletepgtask(apiserver.RESTClient, spec.Name, ns)
+
+	labels := make(map[string]string)
+	labels[config.LABEL_TARGET] = request.Target
+	labels[config.LABEL_PG_CLUSTER] = request.ClusterName
+	labels[config.LABEL_PGOUSER] = pgouser
+
+	newInstance := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   spec.Name,
+			Labels: labels,
+		},
+		Spec: spec,
+	}
+
+	if err := kubeapi.Createpgtask(apiserver.RESTClient, newInstance, ns); err != nil {
+		resp.Status.Code = msgs.Error
+		resp.Status.Msg = err.Error()
+		return resp
+	}
+
+	resp.Results = append(resp.Results, "created Pgtask (restart) for cluster "+request.ClusterName)
+
+	return resp
+}
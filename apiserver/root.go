@@ -16,6 +16,7 @@ limitations under the License.
 */
 
 import (
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"errors"
@@ -168,6 +169,11 @@ func initConfig() {
 		log.Info("audit flag is set to true")
 	}
 
+	if err := initAudit(); err != nil {
+		log.Error(err)
+		os.Exit(2)
+	}
+
 	MetricsFlag = Pgo.Cluster.Metrics
 	if MetricsFlag {
 		log.Info("metrics flag is set to true")
@@ -189,6 +195,27 @@ func initConfig() {
 		}
 	}
 	log.Infof("BasicAuth is %v", BasicAuth)
+
+	if Pgo.Pgo.PGOUserLockoutThreshold != nil {
+		PgouserLockoutThreshold = *Pgo.Pgo.PGOUserLockoutThreshold
+	}
+
+	PgouserLockoutDuration = time.Duration(config.DefaultPGOUserLockoutDuration) * time.Second
+	if Pgo.Pgo.PGOUserLockoutDuration != nil {
+		PgouserLockoutDuration = time.Duration(*Pgo.Pgo.PGOUserLockoutDuration) * time.Second
+	}
+
+	if err := initOIDC(); err != nil {
+		log.Error(err)
+		os.Exit(2)
+	}
+
+	initKubernetesAuth()
+
+	if PgouserLockoutThreshold > 0 {
+		log.Infof("pgouser lockout is set to %d failed attempts for %s",
+			PgouserLockoutThreshold, PgouserLockoutDuration)
+	}
 }
 
 func BasicAuthCheck(username, password string) bool {
@@ -206,7 +233,7 @@ func BasicAuthCheck(username, password string) bool {
 		return false
 	}
 
-	return password == string(secret.Data["password"])
+	return checkPgouserPassword(secret, username, password)
 }
 
 func BasicAuthzCheck(username, perm string) bool {
@@ -229,46 +256,99 @@ func BasicAuthzCheck(username, perm string) bool {
 
 	//venture thru each role this user has looking for a perm match
 	for _, r := range roles {
-
-		//get the pgorole
-		roleSecretName := "pgorole-" + r
-		rolesecret, err := kubeapi.GetSecret(Clientset, roleSecretName, PgoNamespace)
-
+		found, err := roleHasPerm(username, r, perm)
 		if err != nil {
 			log.Errorf("could not get pgorole secret %s: %s", r, err.Error())
 			return false
 		}
-
-		permsString := strings.TrimSpace(string(rolesecret.Data["permissions"]))
-
-		// first a special case. If this is a solitary "*" indicating that this
-		// encompasses every permission, then we can exit here as true
-		if permsString == "*" {
+		if found {
 			return true
 		}
+	}
 
-		// otherwise, blow up the permission string and see if the user has explicit
-		// permission (i.e. is authorized) to access this resource
-		perms := strings.Split(permsString, ",")
+	return false
 
-		for _, p := range perms {
-			pp := strings.TrimSpace(p)
-			if pp == perm {
-				log.Debugf("%s perm found in role %s for username %s", pp, r, username)
-				return true
-			}
+}
+
+// TokenAuthzCheck checks whether a caller that authenticated with a bearer
+// token is granted the permission. A Kubernetes user is checked with a
+// SubjectAccessReview; otherwise any of the pgoroles of the caller needs to
+// grant it. Roles that do not exist as a pgorole are skipped, as they usually
+// come from unrelated groups of the identity provider
+func TokenAuthzCheck(identity TokenIdentity, perm string) bool {
+	if identity.Kubernetes != nil {
+		allowed, err := kubernetesAuthzCheck(identity.Kubernetes, perm)
+		if err != nil {
+			log.Errorf("could not authorize username %s: %s", identity.Username, err.Error())
+			return false
 		}
+		return allowed
+	}
 
+	for _, r := range identity.Roles {
+		found, err := roleHasPerm(identity.Username, r, perm)
+		if err != nil {
+			log.Debugf("skipping role %s for username %s: %s", r, identity.Username, err.Error())
+			continue
+		}
+		if found {
+			return true
+		}
 	}
 
 	return false
+}
+
+// AuthzCheck checks whether a caller that Authn has already authenticated is
+// also granted another permission, however the caller authenticated. The
+// context is that of the request of the caller
+func AuthzCheck(ctx context.Context, username, perm string) bool {
+	if identity, ok := tokenIdentityFromContext(ctx); ok {
+		return TokenAuthzCheck(identity, perm)
+	}
+
+	return BasicAuthzCheck(username, perm)
+}
 
+// roleHasPerm checks whether the pgorole grants the permission, returning an
+// error if the pgorole cannot be found
+func roleHasPerm(username, role, perm string) (bool, error) {
+	//get the pgorole
+	roleSecretName := "pgorole-" + strings.TrimSpace(role)
+	rolesecret, err := kubeapi.GetSecret(Clientset, roleSecretName, PgoNamespace)
+
+	if err != nil {
+		return false, err
+	}
+
+	permsString := strings.TrimSpace(string(rolesecret.Data["permissions"]))
+
+	// first a special case. If this is a solitary "*" indicating that this
+	// encompasses every permission, then we can exit here as true
+	if permsString == "*" {
+		return true, nil
+	}
+
+	// otherwise, blow up the permission string and see if the user has explicit
+	// permission (i.e. is authorized) to access this resource
+	perms := strings.Split(permsString, ",")
+
+	for _, p := range perms {
+		pp := strings.TrimSpace(p)
+		if pp == perm {
+			log.Debugf("%s perm found in role %s for username %s", pp, role, username)
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 //GetNamespace determines if a user has permission for
 //a namespace they are requesting
 //a valid requested namespace is required
-func GetNamespace(clientset *kubernetes.Clientset, username, requestedNS string) (string, error) {
+//the context is that of the request of the user
+func GetNamespace(ctx context.Context, clientset *kubernetes.Clientset, username, requestedNS string) (string, error) {
 
 	log.Debugf("GetNamespace username [%s] ns [%s]", username, requestedNS)
 
@@ -276,7 +356,7 @@ func GetNamespace(clientset *kubernetes.Clientset, username, requestedNS string)
 		return requestedNS, errors.New("empty namespace is not valid from pgo clients")
 	}
 
-	iAccess, uAccess, err := UserIsPermittedInNamespace(username, requestedNS)
+	iAccess, uAccess, err := UserIsPermittedInNamespace(ctx, username, requestedNS)
 	if err != nil {
 		return requestedNS, fmt.Errorf("Error when determining whether user [%s] is allowed access to "+
 			"namespace [%s]: %w", username, requestedNS, err)
@@ -299,17 +379,30 @@ func GetNamespace(clientset *kubernetes.Clientset, username, requestedNS string)
 // ...it also performs Authorization (Authz) against the user that is attempting
 // to authenticate, and as such, to truly "authenticate/authorize," one needs
 // at least a valid Operator User account.
+//
+// When the user authenticates with a bearer token, their identity is stored in
+// the context of the request, which the checks that follow, e.g. GetNamespace,
+// are passed.
 func Authn(perm string, w http.ResponseWriter, r *http.Request) (string, error) {
 	var err error
 	w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
 
+	// a bearer token takes the place of the pgouser credentials
+	if token, ok := bearerToken(r); ok {
+		identity, err := tokenAuthn(perm, token, w, r)
+		if err != nil {
+			return "", err
+		}
+
+		*r = *r.WithContext(contextWithTokenIdentity(r.Context(), identity))
+		return identity.Username, nil
+	}
+
 	// Need to run the HTTP library `BasicAuth` even if `BasicAuth == false`, as
 	// this function currently encapsulates authorization as well, and this is
 	// the call where we get the username to check the RBAC settings
 	username, password, authOK := r.BasicAuth()
-	if AuditFlag {
-		log.Infof("[audit] %s username=[%s] method=[%s] ip=[%s] ok=[%t] ", perm, username, r.Method, r.RemoteAddr, authOK)
-	}
+	auditAuthn(r, perm, username)
 
 	// Check to see if this user is authenticated
 	// If BasicAuth is "disabled", skip the authentication; o/w/ check if the
@@ -341,6 +434,35 @@ func Authn(perm string, w http.ResponseWriter, r *http.Request) (string, error)
 
 }
 
+// tokenAuthn authenticates a caller with a bearer token, which is either
+// issued by the configured OpenID Connect provider or verified with a
+// Kubernetes TokenReview, and then authorizes the caller
+func tokenAuthn(perm, token string, w http.ResponseWriter, r *http.Request) (TokenIdentity, error) {
+	identity, err := verifyBearerToken(token)
+	auditAuthn(r, perm, identity.Username)
+
+	if err == ErrBearerTokenDisabled {
+		log.Errorf("Authentication Failed %s: %s", perm, err.Error())
+		http.Error(w, "Not Authorized. "+err.Error(), 401)
+		return TokenIdentity{}, err
+	}
+
+	if err != nil {
+		log.Errorf("Authentication Failed %s: %s", perm, err.Error())
+		http.Error(w, "Not authenticated in apiserver", 401)
+		return TokenIdentity{}, errors.New("Not Authenticated")
+	}
+
+	if !TokenAuthzCheck(identity, perm) {
+		log.Errorf("Authorization Failed %s username=[%s]", perm, identity.Username)
+		http.Error(w, "Not authorized for this apiserver action", 403)
+		return TokenIdentity{}, errors.New("Not authorized for this apiserver action")
+	}
+
+	log.Debug("Authentication Success")
+	return identity, nil
+}
+
 func IsValidStorageName(name string) bool {
 	_, ok := Pgo.Storage[name]
 	return ok
@@ -359,8 +481,9 @@ func ValidateNodeLabel(nodeLabel string) error {
 
 // UserIsPermittedInNamespace returns installation access and user access.
 // Installation access means a namespace belongs to this Operator installation.
-// User access means this user has access to a namespace.
-func UserIsPermittedInNamespace(username, requestedNS string) (iAccess, uAccess bool, err error) {
+// User access means this user has access to a namespace. The context is that of
+// the request of the user.
+func UserIsPermittedInNamespace(ctx context.Context, username, requestedNS string) (iAccess, uAccess bool, err error) {
 
 	if err = ns.ValidateNamespacesWatched(Clientset, NamespaceOperatingMode(), InstallationName,
 		requestedNS); err != nil && !errors.Is(err, ns.ErrNamespaceNotWatched) {
@@ -368,6 +491,17 @@ func UserIsPermittedInNamespace(username, requestedNS string) (iAccess, uAccess
 	}
 	iAccess = true
 
+	// a caller that authenticated with a bearer token does not have a pgouser
+	// Secret, so use the namespaces from their token instead
+	if identity, ok := tokenIdentityFromContext(ctx); ok {
+		if identity.Kubernetes != nil {
+			uAccess, err = kubernetesNamespaceCheck(identity.Kubernetes, requestedNS)
+			return
+		}
+		uAccess = identity.permittedInNamespace(requestedNS)
+		return
+	}
+
 	//get the pgouser Secret for this username
 	userSecretName := "pgouser-" + username
 	userSecret, err := kubeapi.GetSecret(Clientset, userSecretName, PgoNamespace)
@@ -16,6 +16,7 @@ limitations under the License.
 */
 
 import (
+	"github.com/crunchydata/postgres-operator/apiserver/apiv2"
 	"github.com/crunchydata/postgres-operator/apiserver/backrestservice"
 	"github.com/crunchydata/postgres-operator/apiserver/catservice"
 	"github.com/crunchydata/postgres-operator/apiserver/cloneservice"
@@ -33,6 +34,7 @@ import (
 	"github.com/crunchydata/postgres-operator/apiserver/policyservice"
 	"github.com/crunchydata/postgres-operator/apiserver/pvcservice"
 	"github.com/crunchydata/postgres-operator/apiserver/reloadservice"
+	"github.com/crunchydata/postgres-operator/apiserver/restartservice"
 	"github.com/crunchydata/postgres-operator/apiserver/scheduleservice"
 	"github.com/crunchydata/postgres-operator/apiserver/statusservice"
 	"github.com/crunchydata/postgres-operator/apiserver/upgradeservice"
@@ -46,6 +48,7 @@ import (
 // RegisterAllRoutes adds all routes supported by the apiserver to the
 // provided router
 func RegisterAllRoutes(r *mux.Router) {
+	RegisterAPIV2Routes(r)
 	RegisterBackrestSvcRoutes(r)
 	RegisterCatSvcRoutes(r)
 	RegisterCloneSvcRoutes(r)
@@ -63,6 +66,7 @@ func RegisterAllRoutes(r *mux.Router) {
 	RegisterPolicySvcRoutes(r)
 	RegisterPVCSvcRoutes(r)
 	RegisterReloadSvcRoutes(r)
+	RegisterRestartSvcRoutes(r)
 	RegisterScheduleSvcRoutes(r)
 	RegisterStatusSvcRoutes(r)
 	RegisterUpgradeSvcRoutes(r)
@@ -71,6 +75,12 @@ func RegisterAllRoutes(r *mux.Router) {
 	RegisterWorkflowSvcRoutes(r)
 }
 
+// RegisterAPIV2Routes registers all routes of the v2 API, which are served
+// under /api/v2
+func RegisterAPIV2Routes(r *mux.Router) {
+	apiv2.RegisterRoutes(r)
+}
+
 // RegisterBackrestSvcRoutes registers all routes from the Backrest Service
 func RegisterBackrestSvcRoutes(r *mux.Router) {
 	r.HandleFunc("/backrestbackup", backrestservice.CreateBackupHandler).Methods("POST")
@@ -114,6 +124,7 @@ func RegisterDfSvcRoutes(r *mux.Router) {
 func RegisterFailoverSvcRoutes(r *mux.Router) {
 	r.HandleFunc("/failover", failoverservice.CreateFailoverHandler).Methods("POST")
 	r.HandleFunc("/failover/{name}", failoverservice.QueryFailoverHandler).Methods("GET")
+	r.HandleFunc("/switchover", failoverservice.CreateSwitchoverHandler).Methods("POST")
 }
 
 // RegisterLabelSvcRoutes registers all routes from the Label Service
@@ -185,6 +196,11 @@ func RegisterReloadSvcRoutes(r *mux.Router) {
 	r.HandleFunc("/reload", reloadservice.ReloadHandler).Methods("POST")
 }
 
+// RegisterRestartSvcRoutes registers all routes from the Restart Service
+func RegisterRestartSvcRoutes(r *mux.Router) {
+	r.HandleFunc("/restart", restartservice.RestartHandler).Methods("POST")
+}
+
 // RegisterScheduleSvcRoutes registers all routes from the Schedule Service
 func RegisterScheduleSvcRoutes(r *mux.Router) {
 	r.HandleFunc("/schedule", scheduleservice.CreateScheduleHandler).Methods("POST")
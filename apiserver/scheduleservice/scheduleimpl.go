@@ -18,6 +18,7 @@ package scheduleservice
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/crunchydata/postgres-operator/apiserver/backupoptions"
@@ -27,6 +28,7 @@ import (
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/pgo-scheduler/scheduler"
 	"github.com/crunchydata/postgres-operator/util"
 	log "github.com/sirupsen/logrus"
 
@@ -50,6 +52,26 @@ func (s scheduleRequest) createBackRestSchedule(cluster *crv1.Pgcluster, ns stri
 		return &PgScheduleSpec{}
 	}
 
+	// the retention policy of the cluster cannot be overridden by the options of
+	// a schedule
+	if err := apiserver.ValidateRetentionOptions(cluster, s.Request.ScheduleOptions); err != nil {
+		s.Response.Status.Code = msgs.Error
+		s.Response.Status.Msg = err.Error()
+		return &PgScheduleSpec{}
+	}
+
+	// the backups are taken into the repository that is selected, if any, and
+	// each repository has a schedule of its own
+	repoOption, err := apiserver.BackrestRepoOption(cluster, s.Request.Repo, s.Request.BackrestStorageType)
+	if err != nil {
+		s.Response.Status.Code = msgs.Error
+		s.Response.Status.Msg = err.Error()
+		return &PgScheduleSpec{}
+	}
+	if s.Request.Repo != 0 {
+		name = fmt.Sprintf("%s-repo%d", name, s.Request.Repo)
+	}
+
 	schedule := &PgScheduleSpec{
 		Name:      name,
 		Cluster:   cluster.Name,
@@ -63,7 +85,80 @@ func (s scheduleRequest) createBackRestSchedule(cluster *crv1.Pgcluster, ns stri
 			Container:   "database",
 			Type:        s.Request.PGBackRestType,
 			StorageType: s.Request.BackrestStorageType,
+			Options:     strings.TrimSpace(s.Request.ScheduleOptions + " " + repoOption),
+		},
+	}
+	return schedule
+}
+
+func (s scheduleRequest) createBackRestVerifySchedule(cluster *crv1.Pgcluster, ns string) *PgScheduleSpec {
+	name := fmt.Sprintf("%s-%s", cluster.Name, s.Request.ScheduleType)
+
+	err := util.ValidateBackrestStorageTypeOnBackupRestore(s.Request.BackrestStorageType,
+		cluster.Spec.UserLabels[config.LABEL_BACKREST_STORAGE_TYPE], true)
+	if err != nil {
+		s.Response.Status.Code = msgs.Error
+		s.Response.Status.Msg = err.Error()
+		return &PgScheduleSpec{}
+	}
+
+	schedule := &PgScheduleSpec{
+		Name:      name,
+		Cluster:   cluster.Name,
+		Version:   "v1",
+		Created:   time.Now().Format(time.RFC3339),
+		Schedule:  s.Request.Schedule,
+		Type:      s.Request.ScheduleType,
+		Namespace: ns,
+		PGBackRestVerify: PGBackRestVerify{
+			Query:       s.Request.VerifyQuery,
+			StorageType: s.Request.BackrestStorageType,
+		},
+	}
+	return schedule
+}
+
+func (s scheduleRequest) createPGDumpSchedule(cluster *crv1.Pgcluster, ns string) *PgScheduleSpec {
+	// the name of the database is made to fit the name of a ConfigMap
+	database := strings.ToLower(strings.Replace(s.Request.Database, "_", "-", -1))
+	name := fmt.Sprintf("%s-%s-%s", cluster.Name, s.Request.ScheduleType, database)
+
+	storageConfig := util.GetValueOrDefault(s.Request.StorageConfig, apiserver.Pgo.BackupStorage)
+	if !apiserver.IsValidStorageName(storageConfig) {
+		s.Response.Status.Code = msgs.Error
+		s.Response.Status.Msg = fmt.Sprintf("%s storage config was not found", storageConfig)
+		return &PgScheduleSpec{}
+	}
+	storageSpec, _ := apiserver.Pgo.GetStorageSpec(storageConfig)
+
+	// as with an on-demand pgdump backup, the backups are taken by the postgres
+	// user unless another secret is provided, and each schedule has a PVC of its
+	// own unless one is provided
+	if s.Request.Secret == "" {
+		s.Request.Secret = cluster.Name + crv1.RootSecretSuffix
+	}
+
+	pvcName := s.Request.PVCName
+	if pvcName == "" {
+		pvcName = name + "-pvc"
+	}
+
+	schedule := &PgScheduleSpec{
+		Name:      name,
+		Cluster:   cluster.Name,
+		Version:   "v1",
+		Created:   time.Now().Format(time.RFC3339),
+		Schedule:  s.Request.Schedule,
+		Type:      s.Request.ScheduleType,
+		Namespace: ns,
+		PGDump: PGDump{
+			Database:    s.Request.Database,
+			Format:      s.Request.PGDumpFormat,
+			KeepLast:    s.Request.PGDumpKeepLast,
 			Options:     s.Request.ScheduleOptions,
+			PVCName:     pvcName,
+			Secret:      s.Request.Secret,
+			StorageSpec: storageSpec,
 		},
 	}
 	return schedule
@@ -141,6 +236,18 @@ func CreateSchedule(request *msgs.CreateScheduleRequest, ns string) msgs.CreateS
 		}
 	}
 
+	if err := scheduler.ValidateConcurrencyPolicy(sr.Request.ConcurrencyPolicy); err != nil {
+		sr.Response.Status.Code = msgs.Error
+		sr.Response.Status.Msg = err.Error()
+		return *sr.Response
+	}
+
+	if err := scheduler.ValidateJitter(sr.Request.Jitter); err != nil {
+		sr.Response.Status.Code = msgs.Error
+		sr.Response.Status.Msg = err.Error()
+		return *sr.Response
+	}
+
 	log.Debug("Making schedules")
 	var schedules []*PgScheduleSpec
 	for _, cluster := range clusterList.Items {
@@ -148,6 +255,12 @@ func CreateSchedule(request *msgs.CreateScheduleRequest, ns string) msgs.CreateS
 		case "pgbackrest":
 			schedule := sr.createBackRestSchedule(&cluster, ns)
 			schedules = append(schedules, schedule)
+		case "pgbackrest-verify":
+			schedule := sr.createBackRestVerifySchedule(&cluster, ns)
+			schedules = append(schedules, schedule)
+		case "pgdump":
+			schedule := sr.createPGDumpSchedule(&cluster, ns)
+			schedules = append(schedules, schedule)
 		case "policy":
 			schedule := sr.createPolicySchedule(&cluster, ns)
 			schedules = append(schedules, schedule)
@@ -160,54 +273,105 @@ func CreateSchedule(request *msgs.CreateScheduleRequest, ns string) msgs.CreateS
 		if sr.Response.Status.Code == msgs.Error {
 			return *sr.Response
 		}
+
+		schedules[len(schedules)-1].ConcurrencyPolicy = sr.Request.ConcurrencyPolicy
+		schedules[len(schedules)-1].Jitter = sr.Request.Jitter
 	}
 
 	log.Debug("Marshalling schedules")
 	for _, schedule := range schedules {
-		log.Debug(schedule.Name, schedule.Cluster)
-		blob, err := json.Marshal(schedule)
-		if err != nil {
+		if err := saveSchedule(schedule); err != nil {
 			sr.Response.Status.Code = msgs.Error
 			sr.Response.Status.Msg = err.Error()
-		}
-
-		log.Debug("Getting configmap..")
-		_, exists := kubeapi.GetConfigMap(apiserver.Clientset, schedule.Name, schedule.Namespace)
-		if exists {
-			sr.Response.Status.Code = msgs.Error
-			sr.Response.Status.Msg = fmt.Sprintf("Schedule %s already exists", schedule.Name)
 			return *sr.Response
 		}
 
-		labels := make(map[string]string)
-		labels["pg-cluster"] = schedule.Cluster
-		labels["crunchy-scheduler"] = "true"
-
-		data := make(map[string]string)
-		data[schedule.Name] = string(blob)
+		msg := fmt.Sprintf("created schedule %s for cluster %s", schedule.Name, schedule.Cluster)
+		sr.Response.Results = append(sr.Response.Results, msg)
+	}
+	return *sr.Response
+}
 
-		configmap := &v1.ConfigMap{
-			ObjectMeta: meta_v1.ObjectMeta{
-				Name:   schedule.Name,
-				Labels: labels,
+// CreateBackrestRepoSchedules creates the schedules of the pgBackRest
+// repositories of a cluster that have one, each of which takes full backups
+// into its repository
+func CreateBackrestRepoSchedules(cluster *crv1.Pgcluster, ns string) msgs.CreateScheduleResponse {
+	sr := &scheduleRequest{
+		Response: &msgs.CreateScheduleResponse{
+			Status: msgs.Status{
+				Code: msgs.Ok,
+				Msg:  "",
 			},
-			Data: data,
+			Results: make([]string, 0),
+		},
+	}
+
+	for i, repo := range cluster.Spec.BackrestRepos {
+		if repo.Schedule == "" {
+			continue
 		}
 
-		log.Debug("Creating configmap..")
-		err = kubeapi.CreateConfigMap(apiserver.Clientset, configmap, schedule.Namespace)
-		if err != nil {
+		sr.Request = &msgs.CreateScheduleRequest{
+			ClusterName:    cluster.Name,
+			Namespace:      ns,
+			Schedule:       repo.Schedule,
+			ScheduleType:   "pgbackrest",
+			PGBackRestType: "full",
+			Repo:           i + 1,
+		}
+
+		schedule := sr.createBackRestSchedule(cluster, ns)
+		if sr.Response.Status.Code == msgs.Error {
+			return *sr.Response
+		}
+
+		if err := saveSchedule(schedule); err != nil {
 			sr.Response.Status.Code = msgs.Error
 			sr.Response.Status.Msg = err.Error()
 			return *sr.Response
 		}
 
-		msg := fmt.Sprintf("created schedule %s for cluster %s", configmap.ObjectMeta.Name, schedule.Cluster)
+		msg := fmt.Sprintf("created schedule %s for cluster %s", schedule.Name, schedule.Cluster)
 		sr.Response.Results = append(sr.Response.Results, msg)
 	}
+
 	return *sr.Response
 }
 
+// saveSchedule stores a schedule in a ConfigMap of its own, which the scheduler
+// picks up. A schedule that already exists is not replaced
+func saveSchedule(schedule *PgScheduleSpec) error {
+	log.Debug(schedule.Name, schedule.Cluster)
+	blob, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Getting configmap..")
+	_, exists := kubeapi.GetConfigMap(apiserver.Clientset, schedule.Name, schedule.Namespace)
+	if exists {
+		return fmt.Errorf("Schedule %s already exists", schedule.Name)
+	}
+
+	labels := make(map[string]string)
+	labels["pg-cluster"] = schedule.Cluster
+	labels["crunchy-scheduler"] = "true"
+
+	data := make(map[string]string)
+	data[schedule.Name] = string(blob)
+
+	configmap := &v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   schedule.Name,
+			Labels: labels,
+		},
+		Data: data,
+	}
+
+	log.Debug("Creating configmap..")
+	return kubeapi.CreateConfigMap(apiserver.Clientset, configmap, schedule.Namespace)
+}
+
 //  DeleteSchedule ...
 func DeleteSchedule(request *msgs.DeleteScheduleRequest, ns string) msgs.DeleteScheduleResponse {
 	log.Debug("Deleted schedule called")
@@ -306,11 +470,52 @@ func ShowSchedule(request *msgs.ShowScheduleRequest, ns string) msgs.ShowSchedul
 		if blob.Type == "pgbackrest" {
 			results += fmt.Sprintf("\n\tbackup-type: %s", blob.PGBackRest.Type)
 		}
+		if blob.Type == "pgbackrest-verify" && blob.PGBackRestVerify.Query != "" {
+			results += fmt.Sprintf("\n\tverify-query: %s", blob.PGBackRestVerify.Query)
+		}
+		if blob.Type == "pgdump" {
+			results += fmt.Sprintf("\n\tdatabase: %s\n\tpvc: %s", blob.PGDump.Database, blob.PGDump.PVCName)
+		}
+		if blob.ConcurrencyPolicy != "" {
+			results += fmt.Sprintf("\n\tconcurrency-policy: %s", blob.ConcurrencyPolicy)
+		}
+		if blob.Jitter != "" {
+			results += fmt.Sprintf("\n\tjitter: %s", blob.Jitter)
+		}
+		results += formatScheduleHistory(scheduler.GetScheduleHistory(cm))
 		sr.Results = append(sr.Results, results)
 	}
 	return *sr
 }
 
+// formatScheduleHistory formats the most recent runs of a schedule, starting
+// with the latest run
+func formatScheduleHistory(history []scheduler.ScheduleRun) string {
+	if len(history) == 0 {
+		return "\n\truns: none"
+	}
+
+	results := "\n\truns:"
+	for i := len(history) - 1; i >= 0; i-- {
+		run := history[i]
+		results += fmt.Sprintf("\n\t\t%s %s", run.Started.Format(time.RFC3339), run.Status)
+
+		if run.Finished != nil && run.Status != scheduler.RunStatusMissed {
+			results += fmt.Sprintf(" after %s", run.Finished.Sub(run.Started).Round(time.Second))
+		}
+		if run.Job != "" {
+			results += fmt.Sprintf(" (job %s)", run.Job)
+		} else if run.Task != "" {
+			results += fmt.Sprintf(" (task %s)", run.Task)
+		}
+		if run.Error != "" {
+			results += ": " + run.Error
+		}
+	}
+
+	return results
+}
+
 func getSchedules(clusterName, selector, ns string) ([]string, error) {
 	schedules := []string{}
 	label := "crunchy-scheduler=true"
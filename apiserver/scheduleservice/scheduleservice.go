@@ -19,21 +19,26 @@ import (
 	"encoding/json"
 	"net/http"
 
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/apiserver"
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	log "github.com/sirupsen/logrus"
 )
 
 type PgScheduleSpec struct {
-	Version    string `json:"version"`
-	Name       string `json:"name"`
-	Cluster    string `json:"cluster"`
-	Created    string `json:"created"`
-	Schedule   string `json:"schedule"`
-	Namespace  string `json:"namespace"`
-	Type       string `json:"type"`
-	PGBackRest `json:"pgbackrest,omitempty"`
-	Policy     `json:"policy,omitempty"`
+	Version           string `json:"version"`
+	Name              string `json:"name"`
+	Cluster           string `json:"cluster"`
+	Created           string `json:"created"`
+	Schedule          string `json:"schedule"`
+	Namespace         string `json:"namespace"`
+	Type              string `json:"type"`
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	Jitter            string `json:"jitter,omitempty"`
+	PGBackRest        `json:"pgbackrest,omitempty"`
+	PGBackRestVerify  `json:"verify,omitempty"`
+	PGDump            `json:"pgdump,omitempty"`
+	Policy            `json:"policy,omitempty"`
 }
 
 type Policy struct {
@@ -44,6 +49,16 @@ type Policy struct {
 	ImageTag    string `json:"imageTag,omitempty"`
 }
 
+type PGDump struct {
+	Database    string             `json:"database,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	KeepLast    int                `json:"keepLast,omitempty"`
+	Options     string             `json:"options,omitempty"`
+	PVCName     string             `json:"pvcName,omitempty"`
+	Secret      string             `json:"secret,omitempty"`
+	StorageSpec crv1.PgStorageSpec `json:"storageSpec,omitempty"`
+}
+
 type PGBackRest struct {
 	Deployment  string `json:"deployment,omitempty"`
 	Label       string `json:"label,omitempty"`
@@ -53,6 +68,11 @@ type PGBackRest struct {
 	Options     string `json:"options,omitempty"`
 }
 
+type PGBackRestVerify struct {
+	Query       string `json:"query,omitempty"`
+	StorageType string `json:"storageType,omitempty"`
+}
+
 // CreateScheduleHandler ...
 func CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation POST /schedule scheduleservice schedule
@@ -89,7 +109,7 @@ func CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp := msgs.CreateScheduleResponse{
 			Status: msgs.Status{
@@ -141,7 +161,7 @@ func DeleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp := &msgs.DeleteScheduleResponse{
 			Status: msgs.Status{
@@ -194,7 +214,7 @@ func ShowScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	ns, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	ns, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp := &msgs.ShowScheduleResponse{
 			Status: msgs.Status{
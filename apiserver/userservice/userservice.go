@@ -66,7 +66,7 @@ func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -113,7 +113,7 @@ func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	resp := msgs.CreateUserResponse{}
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status.Code = msgs.Error
 		resp.Status.Msg = err.Error()
@@ -178,7 +178,7 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, pgouser, request.Namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, pgouser, request.Namespace)
 	if err != nil {
 		resp.Status.Code = msgs.Error
 		resp.Status.Msg = err.Error()
@@ -224,7 +224,7 @@ func ShowUserHandler(w http.ResponseWriter, r *http.Request) {
 	// a special authz check here: if the ShowSystemAccounts flag is set, ensure
 	// the user is authorized to show system accounts
 	if request.ShowSystemAccounts &&
-		!apiserver.BasicAuthzCheck(username, apiserver.SHOW_SYSTEM_ACCOUNTS_PERM) {
+		!apiserver.AuthzCheck(r.Context(), username, apiserver.SHOW_SYSTEM_ACCOUNTS_PERM) {
 		log.Errorf("Authorization Failed %s username=[%s]", apiserver.SHOW_SYSTEM_ACCOUNTS_PERM, username)
 		http.Error(w, "Not authorized for this apiserver action", 403)
 		return
@@ -241,7 +241,7 @@ func ShowUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = apiserver.GetNamespace(apiserver.Clientset, username, request.Namespace)
+	_, err = apiserver.GetNamespace(r.Context(), apiserver.Clientset, username, request.Namespace)
 	if err != nil {
 		resp.Status = msgs.Status{Code: msgs.Error, Msg: err.Error()}
 		json.NewEncoder(w).Encode(resp)
@@ -15,6 +15,10 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+import (
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+)
+
 // CreateBackrestBackupResponse ...
 // swagger:model
 type CreateBackrestBackupResponse struct {
@@ -30,6 +34,17 @@ type CreateBackrestBackupRequest struct {
 	Selector            string
 	BackupOpts          string
 	BackrestStorageType string
+	// Verify restores the backup into a scratch instance once it completes, and
+	// VerifyOnly does so for an existing backup, i.e. VerifySet or the latest
+	// one, without taking a new backup. VerifyQuery is run against the restored
+	// instance
+	Verify      bool
+	VerifyOnly  bool
+	VerifySet   string
+	VerifyQuery string
+	// Repo, if set, is the number of the pgBackRest repository of the cluster
+	// that the backup is taken into
+	Repo int
 }
 
 // PgBackRestInfo and its associated structs are available for parsing the info
@@ -105,6 +120,26 @@ type ShowBackrestDetail struct {
 	Name        string
 	Info        []PgBackRestInfo
 	StorageType string
+	// Retention is the retention policy of the pgBackRest repository
+	Retention crv1.PgBackRestRetentionSpec
+	// ExpiringBackups are the labels of the backups that expire under the
+	// retention policy the next time a full or differential backup completes
+	ExpiringBackups []string
+	// Verification is the outcome of the latest verification of a backup of the
+	// repository, if any
+	Verification *PgBackRestVerification
+}
+
+// PgBackRestVerification is the outcome of the verification of a pgBackRest
+// backup, which is restored into a scratch instance where a query is run
+type PgBackRestVerification struct {
+	// BackupSet is the backup that was verified, which is the latest one when
+	// it is empty
+	BackupSet string
+	// Status is "passed" or "failed", or empty while the verification runs
+	Status    string
+	Completed string
+	Message   string
 }
 
 // ShowBackrestResponse ...
@@ -131,4 +166,13 @@ type RestoreRequest struct {
 	PITRTarget          string
 	NodeLabel           string
 	BackrestStorageType string
+	// TargetCluster, if set, is the name of a new cluster that the backup is
+	// restored into, which leaves the cluster that is restored from running.
+	// BackupLabel is the label of the backup that is restored, which defaults
+	// to the latest backup
+	TargetCluster string
+	BackupLabel   string
+	// Repo, if set, is the number of the pgBackRest repository of the cluster
+	// that the backup is restored from
+	Repo int
 }
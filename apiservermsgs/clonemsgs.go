@@ -24,10 +24,16 @@ type CloneRequest struct {
 	// BackrestStorageSource contains the accepted values for where pgBackRest
 	// repository storage exists ("local", "s3" or both)
 	BackrestStorageSource string
-	ClientVersion         string
+	// BackupLabel, if set, is the label of the pgBackRest backup that is
+	// restored, which defaults to the latest backup
+	BackupLabel   string
+	ClientVersion string
 	// EnableMetrics enables metrics support in the target cluster
 	EnableMetrics bool
 	Namespace     string
+	// PITRTarget, if set, is the point in time that the clone is recovered to,
+	// e.g. "2020-03-01 08:00:00+00"
+	PITRTarget string
 	// PVCSize, if set, is the size of the PVC to use for the primary and any
 	// replicas
 	PVCSize string
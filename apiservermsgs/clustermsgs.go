@@ -139,6 +139,13 @@ type CreateClusterRequest struct {
 	// be requested for the pgBackRest repository. Defaults to the server
 	// specified default
 	BackrestMemoryRequest string
+	// BackrestRetention, if specified, is the retention policy of the
+	// pgBackRest repository
+	BackrestRetention crv1.PgBackRestRetentionSpec
+	// BackrestRepos, if specified, are the pgBackRest repositories of the
+	// cluster, the first of which is its local repository. A backup schedule is
+	// created for each repository that has a schedule
+	BackrestRepos []crv1.PgBackRestRepoSpec
 	// BackrestStorageConfig sets the storage configuration to use for the
 	// pgBackRest local repository. This overrides the value in pgo.yaml, though
 	// the value of BackrestPVCSize can override the PVC size set in this
@@ -147,6 +154,30 @@ type CreateClusterRequest struct {
 	// BackrestS3CASecretName specifies the name of a secret to use for the
 	// pgBackRest S3 CA instead of the default
 	BackrestS3CASecretName string
+	// BackrestGCSBucket, BackrestGCSEndpoint and BackrestGCSKeyType, if
+	// specified, are the bucket, endpoint and key type of the GCS storage of the
+	// pgBackRest repository. BackrestGCSKey is its key, which defaults to the
+	// one of the Operator
+	BackrestGCSBucket   string
+	BackrestGCSEndpoint string
+	BackrestGCSKeyType  string
+	BackrestGCSKey      []byte
+	// BackrestAzureContainer, BackrestAzureEndpoint, BackrestAzureKeyType and
+	// BackrestAzureURIStyle, if specified, are the settings of the Azure storage
+	// of the pgBackRest repository. BackrestAzureAccount and BackrestAzureKey
+	// are its credentials, which default to those of the Operator
+	BackrestAzureContainer string
+	BackrestAzureEndpoint  string
+	BackrestAzureKeyType   string
+	BackrestAzureURIStyle  string
+	BackrestAzureAccount   string
+	BackrestAzureKey       string
+	// BackrestStorageNoVerifyTLS, if true, turns off the verification of the
+	// TLS certificate of the S3, GCS or Azure storage, e.g. of an emulator
+	BackrestStorageNoVerifyTLS bool
+	// SyncReplicationSettings, if specified, are the settings of synchronous
+	// replication, which they turn on unless SyncReplication is set
+	SyncReplicationSettings *crv1.SyncReplicationSpec
 }
 
 // CreateClusterDetail provides details about the PostgreSQL cluster that is
@@ -217,6 +248,9 @@ type ShowClusterPod struct {
 	Ready       bool
 	Primary     bool
 	Type        string
+	// PendingRestart is whether the instance of the Pod needs to be restarted
+	// for PostgreSQL parameters to take effect
+	PendingRestart bool
 }
 
 // ShowClusterDeployment
@@ -315,6 +349,14 @@ type UpdateClusterRequest struct {
 	// BackrestMemoryRequest, if specified, is the value of how much RAM should
 	// be requested for the pgBackRest repository.
 	BackrestMemoryRequest string
+	// BackrestRetentionFull, BackrestRetentionDiff, BackrestRetentionArchive
+	// and BackrestRetentionArchiveType, if specified, update the retention
+	// policy of the pgBackRest repository. A value of 0 or "" removes the
+	// setting from the policy
+	BackrestRetentionFull        *int
+	BackrestRetentionDiff        *int
+	BackrestRetentionArchive     *int
+	BackrestRetentionArchiveType *string
 	// CPURequest is the value of how much CPU should be requested for deploying
 	// the PostgreSQL cluster
 	CPURequest string
@@ -325,6 +367,19 @@ type UpdateClusterRequest struct {
 	Startup       bool
 	Shutdown      bool
 	Tablespaces   []ClusterTablespaceDetail
+	// SyncReplicationNodeCount, SyncReplicationStrict and
+	// SyncReplicationExcludedReplicas, if specified, update the settings of
+	// synchronous replication, which must be enabled on the cluster. An empty
+	// list of excluded replicas removes the exclusions
+	SyncReplicationNodeCount        *int
+	SyncReplicationStrict           *bool
+	SyncReplicationExcludedReplicas *[]string
+	// Parameters, if specified, set PostgreSQL parameters on the cluster. A
+	// parameter with an empty value is removed from the cluster
+	Parameters map[string]string
+	// RestartPending, if set, restarts the instances of the cluster that are
+	// pending a restart, e.g. following a change to a PostgreSQL parameter
+	RestartPending bool
 }
 
 // UpdateClusterResponse ...
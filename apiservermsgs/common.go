@@ -36,6 +36,9 @@ type BasicAuthCredentials struct {
 	Username     string
 	Password     string
 	APIServerURL string
+	// Token, if set, is sent as a bearer token in place of the username and
+	// password
+	Token string
 }
 
 func (b BasicAuthCredentials) HasUsernameAndPassword() bool {
@@ -18,11 +18,16 @@ limitations under the License.
 // FailoverTargetSpec
 // swagger:model
 type FailoverTargetSpec struct {
-	Name           string // the name of the PostgreSQL instance
-	Node           string // the node that the instance is running on
-	ReplicationLag int    // how far behind the instance is behind the primary, in MB
-	Status         string // the current status of the instance
-	Timeline       int    // the timeline the replica is on; timelines are adjusted after failover events
+	Name           string   // the name of the PostgreSQL instance
+	Node           string   // the node that the instance is running on
+	ReplicationLag int      // how far behind the instance is behind the primary, in MB
+	Status         string   // the current status of the instance
+	Timeline       int      // the timeline the replica is on; timelines are adjusted after failover events
+	Ready          bool     // whether the database container of the instance is ready
+	Restarts       int      // the number of times the database container of the instance restarted
+	Score          int      // the rank of the instance as a failover target, the higher the better
+	Eligible       bool     // whether the instance passes the health bar of a failover target
+	Reasons        []string // the reasons behind the score and the eligibility of the instance
 }
 
 // QueryFailoverResponse ...
@@ -31,6 +36,9 @@ type QueryFailoverResponse struct {
 	Results []FailoverTargetSpec
 	Status
 	Standby bool
+	// Recommended is the name of the best failover target, if any passes the
+	// health bar
+	Recommended string
 }
 
 // CreateFailoverResponse ...
@@ -50,6 +58,28 @@ type CreateFailoverRequest struct {
 	ClientVersion string
 }
 
+// CreateSwitchoverRequest ...
+// swagger:model
+type CreateSwitchoverRequest struct {
+	Namespace     string
+	ClusterName   string
+	Target        string
+	ClientVersion string
+	// MaxLagBytes is the replication lag above which a replica is refused as
+	// the candidate of the switchover
+	MaxLagBytes int64
+	// ScheduledAt is an optional RFC 3339 timestamp at which Patroni performs
+	// the switchover. When empty, the switchover is performed immediately
+	ScheduledAt string
+}
+
+// CreateSwitchoverResponse ...
+// swagger:model
+type CreateSwitchoverResponse struct {
+	Results []string
+	Status
+}
+
 // QueryFailoverRequest ...
 // swagger:model
 type QueryFailoverRequest struct {
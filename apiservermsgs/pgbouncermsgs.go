@@ -20,15 +20,25 @@ limitations under the License.
 type CreatePgbouncerRequest struct {
 	Args          []string
 	ClientVersion string
+	// Config, if specified, contains the pgBouncer settings to set, e.g.
+	// "pool_mode", which take precedence over the defaults
+	Config map[string]string
 	// CPURequest, if specified, is the value of how much CPU should be
 	// requested for deploying pgBouncer instances. Defaults to not being
 	// requested
 	CPURequest string
+	// Databases, if specified, contains the pgBouncer settings to set for the
+	// connections to specific databases, e.g. "pool_size"
+	Databases map[string]map[string]string
 	// MemoryRequest, if specified, is the value of how much RAM should
 	// be requested for deploying pgBouncer instances. Defaults to the server
 	// specified default
 	MemoryRequest string
 	Namespace     string
+	// ReadOnlyReplicas, if specified, is the total number of pods of the
+	// read-only pgBouncer to deploy, which is routed to the replicas of the
+	// PostgreSQL cluster. If 0 is passed in, there is no read-only pgBouncer
+	ReadOnlyReplicas int32
 	// Replicas represents the total number of pgBouncer pods to deploy with a
 	// PostgreSQL cluster. Must be at least 1. If 0 is passed in, it will
 	// automatically be set to 1
@@ -60,6 +70,78 @@ type DeletePgbouncerResponse struct {
 	Status
 }
 
+// PgBouncerDatabaseStats contains the statistics of the queries to a database
+// through pgBouncer, from "SHOW STATS"
+//
+// swagger:model
+type PgBouncerDatabaseStats struct {
+	// AverageQueryTime is the average time of a query, in microseconds, over the
+	// last statistics period of pgBouncer
+	AverageQueryTime int64
+	// AverageTransactionTime is the average time of a transaction, in
+	// microseconds, over the last statistics period of pgBouncer
+	AverageTransactionTime int64
+	// Database is the name of the database
+	Database string
+	// TotalQueryCount is the number of queries since pgBouncer started
+	TotalQueryCount int64
+	// TotalTransactionCount is the number of transactions since pgBouncer
+	// started
+	TotalTransactionCount int64
+}
+
+// PgBouncerPoolStats contains the statistics of a pgBouncer pool, i.e. the
+// connections of a user to a database, from "SHOW POOLS"
+//
+// swagger:model
+type PgBouncerPoolStats struct {
+	// ClientsActive is the number of clients that are linked to a server
+	// connection
+	ClientsActive int64
+	// ClientsWaiting is the number of clients that are waiting for a server
+	// connection
+	ClientsWaiting int64
+	// Database is the name of the database
+	Database string
+	// MaxWait is how long, in seconds, the oldest waiting client has waited
+	MaxWait int64
+	// PoolSize is the maximum number of server connections of the pool
+	PoolSize int64
+	// Saturation is the fraction of the pool size that is in use by active
+	// server connections
+	Saturation float64
+	// ServersActive is the number of server connections that are linked to a
+	// client
+	ServersActive int64
+	// ServersIdle is the number of server connections that are available
+	ServersIdle int64
+	// User is the name of the user
+	User string
+}
+
+// PgBouncerStats contains the statistics of a pgBouncer deployment, which are
+// gathered from the administrative console of each of its Pods and aggregated
+//
+// swagger:model
+type PgBouncerStats struct {
+	// ClientConnections is the number of connections of clients to pgBouncer,
+	// from "SHOW CLIENTS"
+	ClientConnections int64
+	// Databases contains the statistics of the queries to each database
+	Databases []PgBouncerDatabaseStats
+	// Errors contains the errors of the Pods that could not be queried
+	Errors []string
+	// Pods is the number of Pods whose statistics are included
+	Pods int
+	// Pools contains the statistics of each pool
+	Pools []PgBouncerPoolStats
+	// ServerConnections is the number of connections of pgBouncer to
+	// PostgreSQL, from "SHOW SERVERS"
+	ServerConnections int64
+	// ServiceName is the name of the Service of the pgBouncer deployment
+	ServiceName string
+}
+
 // ShowPgBouncerDetail is the specific information about a pgBouncer deployment
 // for a cluster
 //
@@ -73,6 +155,15 @@ type ShowPgBouncerDetail struct {
 	HasPgBouncer bool
 	// Password contains the password for the pgBouncer service account
 	Password string
+	// ReadOnlyServiceClusterIP contains the ClusterIP address of the Service of
+	// the read-only pgBouncer, if there is one
+	ReadOnlyServiceClusterIP string
+	// ReadOnlyServiceExternalIP contains the external IP address of the Service
+	// of the read-only pgBouncer, if it is assigned
+	ReadOnlyServiceExternalIP string
+	// ReadOnlyServiceName contains the name of the Kubernetes Service of the
+	// read-only pgBouncer, if there is one
+	ReadOnlyServiceName string
 	// ServiceClusterIP contains the ClusterIP address of the Service
 	ServiceClusterIP string
 	// ServiceExternalIP contains the external IP address of the Service, if it
@@ -80,6 +171,9 @@ type ShowPgBouncerDetail struct {
 	ServiceExternalIP string
 	// ServiceName contains the name of the Kubernetes Service
 	ServiceName string
+	// Stats contains the statistics of each pgBouncer deployment of the cluster,
+	// if they are requested
+	Stats []PgBouncerStats
 	// Username is the username for the pgBouncer service account
 	Username string
 }
@@ -103,6 +197,9 @@ type ShowPgBouncerRequest struct {
 	// Selector is optional and contains a selector to gather information about
 	// a PostgreSQL cluster's pgBouncer
 	Selector string
+	// Stats, if set, also gathers the statistics of each pgBouncer Pod from its
+	// administrative console
+	Stats bool
 }
 
 // ShowPgBouncerResponse contains the attributes that are part of the response
@@ -145,11 +242,20 @@ type UpdatePgBouncerRequest struct {
 	// updated
 	ClusterNames []string
 
+	// Config, if specified, contains the pgBouncer settings to update. A setting
+	// with an empty value is removed, which restores its default
+	Config map[string]string
+
 	// CPURequest, if specified, is the value of how much CPU should be
 	// requested for deploying pgBouncer instances. Defaults to not being
 	// requested
 	CPURequest string
 
+	// Databases, if specified, contains the pgBouncer settings to update for the
+	// connections to specific databases. A setting with an empty value is
+	// removed, as is a database that no longer has any settings
+	Databases map[string]map[string]string
+
 	// MemoryRequest, if specified, is the value of how much RAM should
 	// be requested for deploying pgBouncer instances. Defaults to the server
 	// specified default
@@ -158,6 +264,11 @@ type UpdatePgBouncerRequest struct {
 	// Namespace is the namespace to perform the query in
 	Namespace string
 
+	// ReadOnlyReplicas, if specified, is the total number of pods of the
+	// read-only pgBouncer to deploy. If 0 is passed in, the read-only pgBouncer
+	// is removed
+	ReadOnlyReplicas *int32
+
 	// Replicas represents the total number of pgBouncer pods to deploy with a
 	// PostgreSQL cluster. Must be at least 1. If 0 is passed in, it is ignored
 	Replicas int32
@@ -27,9 +27,12 @@ type ShowPgouserRequest struct {
 // PgouserInfo ...
 // swagger:model
 type PgouserInfo struct {
-	Username  string
-	Role      []string
-	Namespace []string
+	Username     string
+	Role         []string
+	Namespace    []string
+	FailedLogins int
+	Locked       bool
+	LockedUntil  string
 }
 
 // ShowPgouserResponse ...
@@ -0,0 +1,34 @@
+package apiservermsgs
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// RestartRequest ...
+// swagger:model
+type RestartRequest struct {
+	Namespace   string
+	ClusterName string
+	// Target is the instance to restart. When empty, all of the instances of
+	// the cluster are restarted
+	Target        string
+	ClientVersion string
+}
+
+// RestartResponse ...
+// swagger:model
+type RestartResponse struct {
+	Results []string
+	Status
+}
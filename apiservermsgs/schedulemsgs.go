@@ -32,6 +32,14 @@ type CreateScheduleRequest struct {
 	PolicyName          string
 	Database            string
 	Secret              string
+	PGDumpFormat        string
+	PGDumpKeepLast      int
+	ConcurrencyPolicy   string
+	Jitter              string
+	VerifyQuery         string
+	// Repo, if set, is the number of the pgBackRest repository of the cluster
+	// that the backups of a "pgbackrest" schedule are taken into
+	Repo int
 }
 
 // CreateScheduleResponse ...
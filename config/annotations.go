@@ -19,9 +19,14 @@ package config
 const (
 	ANNOTATION_PGHA_BOOTSTRAP_REPLICA    = "pgo-pgha-bootstrap-replica"
 	ANNOTATION_CLONE_BACKREST_PVC_SIZE   = "clone-backrest-pvc-size"
+	ANNOTATION_CLONE_BACKUP_LABEL        = "clone-backup-label"
 	ANNOTATION_CLONE_ENABLE_METRICS      = "clone-enable-metrics"
+	ANNOTATION_CLONE_PITR_TARGET         = "clone-pitr-target"
 	ANNOTATION_CLONE_PVC_SIZE            = "clone-pvc-size"
 	ANNOTATION_CLONE_SOURCE_CLUSTER_NAME = "clone-source-cluster-name"
 	ANNOTATION_CLONE_TARGET_CLUSTER_NAME = "clone-target-cluster-name"
 	ANNOTATION_PRIMARY_DEPLOYMENT        = "primary-deployment"
+	// ANNOTATION_SCHEDULE_HISTORY contains the most recent runs of a schedule,
+	// which the scheduler stores on the ConfigMap of the schedule
+	ANNOTATION_SCHEDULE_HISTORY = "schedule-history"
 )
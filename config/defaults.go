@@ -50,6 +50,9 @@ const (
 	// NamespaceRefreshInterval is the default informer refresh interval in seconds
 	// for the Operator's namespace controller
 	DefaultNamespaceRefreshInterval = 60
+	// DefaultReconcileInterval is the default interval in seconds at which the
+	// Operator compares each cluster against its pgcluster and corrects any drift
+	DefaultReconcileInterval = 300
 )
 
 // The following constants define the default number of workers created for the worker queues
@@ -68,3 +71,84 @@ const (
 	// in the PGTask controller
 	DefaultPGTaskWorkerCount = 1
 )
+
+// DefaultPGOUserLockoutDuration is the default number of seconds a pgouser is
+// locked out for once they reach the configured number of failed logins
+const DefaultPGOUserLockoutDuration = 900
+
+// DefaultAdmissionWebhookPort is the default port the admission webhooks of the
+// Operator are served on
+const DefaultAdmissionWebhookPort = 9443
+
+// The following constants define the default claims used when authenticating
+// to the apiserver with an OpenID Connect bearer token
+const (
+	// DefaultOIDCUsernameClaim is the default claim used as the username
+	DefaultOIDCUsernameClaim = "sub"
+	// DefaultOIDCUsernamePrefix is the default prefix of the username, which
+	// keeps it distinct from any pgouser name
+	DefaultOIDCUsernamePrefix = "oidc."
+	// DefaultOIDCGroupsClaim is the default claim containing the groups that are
+	// mapped onto pgoroles
+	DefaultOIDCGroupsClaim = "groups"
+)
+
+// DefaultKubernetesAuthAPIGroup is the default API group of the virtual
+// resources that the apiserver permissions map onto when callers are
+// authorized with Kubernetes RBAC
+const DefaultKubernetesAuthAPIGroup = "pgo.crunchydata.com"
+
+// The following constants define the defaults of the apiserver audit log
+const (
+	// DefaultAuditLogMaxSize is the size in megabytes at which the audit log
+	// file is rotated
+	DefaultAuditLogMaxSize = 100
+	// DefaultAuditLogMaxBackups is the number of rotated audit log files kept
+	DefaultAuditLogMaxBackups = 5
+	// DefaultAuditLogWebhookTimeout is the number of seconds to wait for the
+	// audit webhook to respond
+	DefaultAuditLogWebhookTimeout = 10
+)
+
+// DefaultBackrestVerifyQuery is the sanity query run against the scratch
+// instance of a pgBackRest backup verification when no other query is given
+const DefaultBackrestVerifyQuery = "SELECT count(*) FROM pg_catalog.pg_database"
+
+const (
+	// DefaultBackrestGCSKeyType is the type of key used to access a GCS
+	// pgBackRest repository when none is given, i.e. a service account key
+	DefaultBackrestGCSKeyType = "service"
+	// DefaultBackrestAzureKeyType is the type of key used to access an Azure
+	// pgBackRest repository when none is given, i.e. a shared key
+	DefaultBackrestAzureKeyType = "shared"
+)
+
+// DefaultSwitchoverMaxLagBytes is the replication lag, in bytes, above which a
+// replica is refused as the candidate of a switchover when no other maximum is
+// given. This matches the default of "maximum_lag_on_failover" in Patroni
+const DefaultSwitchoverMaxLagBytes int64 = 1048576
+
+// The following constants define how long a rolling restart waits for each
+// restarted instance to stream from the primary again
+const (
+	// DefaultRestartStreamingTimeout is the number of seconds after which the
+	// rolling restart fails if a restarted instance is not streaming
+	DefaultRestartStreamingTimeout = 300
+	// DefaultRestartStreamingPeriod is the number of seconds between the
+	// checks of whether a restarted instance is streaming
+	DefaultRestartStreamingPeriod = 5
+	// DefaultRestartStaleTimeout is the number of seconds after which a
+	// rolling restart whose progress has not been recorded is considered to
+	// have been interrupted, e.g. by a restart of the Operator
+	DefaultRestartStaleTimeout = 3600
+)
+
+const (
+	// DefaultFailoverMaxLagBytes is the replication lag, in bytes, above which a
+	// replica does not pass the health bar of a failover target
+	DefaultFailoverMaxLagBytes int64 = 1048576
+	// DefaultFailoverMaxRestarts is the number of restarts of its database
+	// container above which a replica does not pass the health bar of a failover
+	// target
+	DefaultFailoverMaxRestarts = 3
+)
@@ -85,6 +85,11 @@ const LABEL_BACKREST_BACKUP_OPTS = "backrest-backup-opts"
 const LABEL_BACKREST_OPTS = "backrest-opts"
 const LABEL_BACKREST_PITR_TARGET = "backrest-pitr-target"
 const LABEL_BACKREST_STORAGE_TYPE = "backrest-storage-type"
+const LABEL_BACKREST_VERIFY = "pgo-backrest-verify"
+const LABEL_BACKREST_VERIFY_SET = "backrest-verify-set"
+const LABEL_BACKREST_VERIFY_QUERY = "backrest-verify-query"
+const LABEL_BACKREST_VERIFY_STATUS = "backrest-verify-status"
+const LABEL_BACKREST_VERIFY_COMPLETED = "backrest-verify-completed"
 const LABEL_BADGER = "crunchy-pgbadger"
 const LABEL_BADGER_CCPIMAGE = "crunchy-pgbadger"
 const LABEL_BACKUP_TYPE_BACKREST = "pgbackrest"
@@ -99,6 +104,8 @@ const LABEL_PGDUMP_USER = "pgdump-user"
 const LABEL_PGDUMP_PORT = "pgdump-port"
 const LABEL_PGDUMP_ALL = "pgdump-all"
 const LABEL_PGDUMP_PVC = "pgdump-pvc"
+const LABEL_PGDUMP_FILENAME = "pgdump-filename"
+const LABEL_PGDUMP_KEEP = "pgdump-keep"
 
 const LABEL_RESTORE_TYPE_PGRESTORE = "pgrestore"
 const LABEL_PGRESTORE_COMMAND = "pgrestore"
@@ -163,6 +170,15 @@ const LABEL_PGO_UPDATED_BY = "pgo-updated-by"
 
 const LABEL_FAILOVER_STARTED = "failover-started"
 
+const LABEL_SWITCHOVER = "switchover"
+const LABEL_SWITCHOVER_MAX_LAG_BYTES = "switchover-max-lag-bytes"
+const LABEL_SWITCHOVER_SCHEDULED_AT = "switchover-scheduled-at"
+const LABEL_SWITCHOVER_STATUS = "switchover-status"
+const LABEL_SWITCHOVER_CANDIDATE = "switchover-candidate"
+
+const LABEL_RESTART_STATUS = "restart-status"
+const LABEL_RESTART_UPDATED = "restart-updated"
+
 const GLOBAL_CUSTOM_CONFIGMAP = "pgo-custom-pg-config"
 
 const LABEL_PGHA_SCOPE = "crunchy-pgha-scope"
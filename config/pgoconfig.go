@@ -123,6 +123,18 @@ var PgbackrestS3EnvVarsTemplate *template.Template
 
 const pgbackrestS3EnvVarsPath = "pgbackrest-s3-env-vars.json"
 
+var PgbackrestRepoEnvVarsTemplate *template.Template
+
+const pgbackrestRepoEnvVarsPath = "pgbackrest-repo-env-vars.json"
+
+var PgbackrestGCSEnvVarsTemplate *template.Template
+
+const pgbackrestGCSEnvVarsPath = "pgbackrest-gcs-env-vars.json"
+
+var PgbackrestAzureEnvVarsTemplate *template.Template
+
+const pgbackrestAzureEnvVarsPath = "pgbackrest-azure-env-vars.json"
+
 var PgbouncerTemplate *template.Template
 
 const pgbouncerTemplatePath = "pgbouncer-template.json"
@@ -155,6 +167,10 @@ var BackrestRestorejobTemplate *template.Template
 
 const backrestRestorejobPath = "backrest-restore-job.json"
 
+var BackrestVerifyjobTemplate *template.Template
+
+const backrestVerifyjobPath = "backrest-verify-job.json"
+
 var PgDumpBackupJobTemplate *template.Template
 
 const pgDumpBackupJobPath = "pgdump-job.json"
@@ -203,6 +219,13 @@ type ClusterStruct struct {
 	BackrestS3Bucket               string
 	BackrestS3Endpoint             string
 	BackrestS3Region               string
+	BackrestGCSBucket              string
+	BackrestGCSEndpoint            string
+	BackrestGCSKeyType             string
+	BackrestAzureContainer         string
+	BackrestAzureEndpoint          string
+	BackrestAzureKeyType           string
+	BackrestAzureURIStyle          string
 	DisableAutofail                bool
 	PgmonitorPassword              string
 	EnableCrunchyadm               bool
@@ -214,6 +237,12 @@ type ClusterStruct struct {
 	DefaultInstanceResourceMemory  resource.Quantity `json:"DefaultInstanceMemory"`
 	DefaultBackrestResourceMemory  resource.Quantity `json:"DefaultBackrestMemory"`
 	DefaultPgBouncerResourceMemory resource.Quantity `json:"DefaultPgBouncerMemory"`
+	// FailoverMaxLagBytes is the replication lag above which a replica does not
+	// pass the health bar of a failover target
+	FailoverMaxLagBytes *int64
+	// FailoverMaxRestarts is the number of restarts of its database container
+	// above which a replica does not pass the health bar of a failover target
+	FailoverMaxRestarts *int
 }
 
 type StorageStruct struct {
@@ -227,6 +256,8 @@ type StorageStruct struct {
 
 // PgoStruct defines various configuration settings for the PostgreSQL Operator
 type PgoStruct struct {
+	AdmissionWebhook               bool
+	AdmissionWebhookPort           *int
 	Audit                          bool
 	ConfigMapWorkerCount           *int
 	ControllerGroupRefreshInterval *int
@@ -234,12 +265,79 @@ type PgoStruct struct {
 	PGClusterWorkerCount           *int
 	PGOImagePrefix                 string
 	PGOImageTag                    string
+	PGOUserLockoutDuration         *int
+	PGOUserLockoutThreshold        *int
 	PGReplicaWorkerCount           *int
 	PGTaskWorkerCount              *int
+	ReconcileInterval              *int
+	ScheduledBackupLimit           int
+}
+
+// OIDCStruct defines the settings for authenticating to the apiserver with
+// bearer tokens issued by an OpenID Connect provider. Authentication with
+// bearer tokens is enabled when IssuerURL is set
+type OIDCStruct struct {
+	// IssuerURL must match the "iss" claim of the tokens
+	IssuerURL string
+	// ClientID must be contained in the "aud" claim of the tokens
+	ClientID string
+	// JWKSFile is the path to a local JWKS file containing the signing keys. If
+	// neither it nor JWKSURL is set, the keys are discovered from the issuer
+	JWKSFile string
+	// JWKSURL is the URL of the JWKS containing the signing keys
+	JWKSURL string
+	// UsernameClaim is the claim used as the username (defaults to "sub")
+	UsernameClaim string
+	// UsernamePrefix is prepended to the username so that it cannot collide
+	// with a pgouser (defaults to "oidc.")
+	UsernamePrefix string
+	// GroupsClaim is the claim containing the groups that are mapped onto
+	// pgoroles (defaults to "groups")
+	GroupsClaim string
+	// GroupsPrefix, if set, only maps the groups that start with it, and
+	// strips it from the name of the pgorole
+	GroupsPrefix string
+	// NamespacesClaim, if set, is the claim containing the namespaces the
+	// caller can access. If not set, the caller can access all namespaces
+	NamespacesClaim string
+}
+
+// KubernetesAuthStruct defines the settings for authenticating to the
+// apiserver with Kubernetes tokens, which are verified with a TokenReview, and
+// authorizing the callers with Kubernetes RBAC through SubjectAccessReviews
+type KubernetesAuthStruct struct {
+	// Enabled turns on authentication with Kubernetes tokens
+	Enabled bool
+	// APIGroup is the API group of the virtual resources that the apiserver
+	// permissions map onto (defaults to "pgo.crunchydata.com")
+	APIGroup string
+	// Audiences, if set, are the audiences a token must be valid for
+	Audiences []string
+}
+
+// AuditLogStruct defines where the apiserver writes its audit log when
+// Pgo.Audit is enabled. If neither File nor WebhookURL is set, the audit
+// records are written to the apiserver log
+type AuditLogStruct struct {
+	// File is the path of the file the audit records are appended to
+	File string
+	// MaxSize is the size in megabytes at which the file is rotated (defaults
+	// to 100)
+	MaxSize int
+	// MaxBackups is the number of rotated files that are kept (defaults to 5)
+	MaxBackups int
+	// WebhookURL, if set, is sent each audit record in a POST request
+	WebhookURL string
+	// WebhookTimeout is the number of seconds to wait for the webhook to
+	// respond (defaults to 10)
+	WebhookTimeout int
 }
 
 type PgoConfig struct {
+	AuditLog        AuditLogStruct
 	BasicAuth       string
+	KubernetesAuth  KubernetesAuthStruct
+	OIDC            OIDCStruct
 	Cluster         ClusterStruct
 	Pgo             PgoStruct
 	PrimaryStorage  string
@@ -326,6 +424,45 @@ func (c *PgoConfig) Validate() error {
 		}
 	}
 
+	if c.OIDC.IssuerURL != "" {
+		if c.OIDC.ClientID == "" {
+			return errors.New(errPrefix + "OIDC.ClientID is required when OIDC.IssuerURL is set")
+		}
+		if c.OIDC.UsernameClaim == "" {
+			c.OIDC.UsernameClaim = DefaultOIDCUsernameClaim
+		}
+		if c.OIDC.UsernamePrefix == "" {
+			c.OIDC.UsernamePrefix = DefaultOIDCUsernamePrefix
+		}
+		// a prefixed username can never be a valid pgouser name, which ensures
+		// the two cannot be confused
+		if len(validation.IsDNS1035Label(c.OIDC.UsernamePrefix+"x")) == 0 {
+			return errors.New(errPrefix + "OIDC.UsernamePrefix must contain a character that " +
+				"is not allowed in a pgouser name, e.g. \".\"")
+		}
+		if c.OIDC.GroupsClaim == "" {
+			c.OIDC.GroupsClaim = DefaultOIDCGroupsClaim
+		}
+	}
+
+	if c.AuditLog.MaxSize == 0 {
+		c.AuditLog.MaxSize = DefaultAuditLogMaxSize
+	}
+	if c.AuditLog.MaxBackups == 0 {
+		c.AuditLog.MaxBackups = DefaultAuditLogMaxBackups
+	}
+	if c.AuditLog.WebhookTimeout == 0 {
+		c.AuditLog.WebhookTimeout = DefaultAuditLogWebhookTimeout
+	}
+	if c.AuditLog.MaxSize < 0 || c.AuditLog.MaxBackups < 0 || c.AuditLog.WebhookTimeout < 0 {
+		return errors.New(errPrefix + "AuditLog.MaxSize, AuditLog.MaxBackups and " +
+			"AuditLog.WebhookTimeout cannot be negative")
+	}
+
+	if c.KubernetesAuth.Enabled && c.KubernetesAuth.APIGroup == "" {
+		c.KubernetesAuth.APIGroup = DefaultKubernetesAuthAPIGroup
+	}
+
 	if c.Pgo.PGOImagePrefix == "" {
 		return errors.New(errPrefix + "Pgo.PGOImagePrefix is required")
 	}
@@ -628,6 +765,21 @@ func (c *PgoConfig) GetConfig(clientset *kubernetes.Clientset, namespace string)
 		return err
 	}
 
+	PgbackrestRepoEnvVarsTemplate, err = c.LoadTemplate(cMap, rootPath, pgbackrestRepoEnvVarsPath)
+	if err != nil {
+		return err
+	}
+
+	PgbackrestGCSEnvVarsTemplate, err = c.LoadTemplate(cMap, rootPath, pgbackrestGCSEnvVarsPath)
+	if err != nil {
+		return err
+	}
+
+	PgbackrestAzureEnvVarsTemplate, err = c.LoadTemplate(cMap, rootPath, pgbackrestAzureEnvVarsPath)
+	if err != nil {
+		return err
+	}
+
 	PgbouncerTemplate, err = c.LoadTemplate(cMap, rootPath, pgbouncerTemplatePath)
 	if err != nil {
 		return err
@@ -668,6 +820,11 @@ func (c *PgoConfig) GetConfig(clientset *kubernetes.Clientset, namespace string)
 		return err
 	}
 
+	BackrestVerifyjobTemplate, err = c.LoadTemplate(cMap, rootPath, backrestVerifyjobPath)
+	if err != nil {
+		return err
+	}
+
 	PgDumpBackupJobTemplate, err = c.LoadTemplate(cMap, rootPath, pgDumpBackupJobPath)
 	if err != nil {
 		return err
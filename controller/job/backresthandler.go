@@ -162,6 +162,28 @@ func (c *Controller) handleBackrestBackupUpdate(job *apiv1.Job) error {
 	}
 	publishBackupComplete(labels[config.LABEL_PG_CLUSTER], job.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER], job.ObjectMeta.Labels[config.LABEL_PGOUSER], "pgbackrest", job.ObjectMeta.Namespace, "")
 
+	// verify the backup if a verification was requested along with it. The task
+	// of the backup is named after its Job
+	task := crv1.Pgtask{}
+	if found, _ := kubeapi.Getpgtask(c.JobClient, &task, job.Name, job.ObjectMeta.Namespace); found &&
+		task.Spec.Parameters[config.LABEL_BACKREST_VERIFY] == "true" {
+		if _, err := backrestoperator.CreateVerifyTask(c.JobClient, job.ObjectMeta.Namespace,
+			labels[config.LABEL_PG_CLUSTER], task.Spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE],
+			task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_QUERY], labels[config.LABEL_PGOUSER]); err != nil {
+			log.Errorf("could not verify the backup of cluster %s: %s", labels[config.LABEL_PG_CLUSTER], err.Error())
+		}
+	}
+
+	// report the completed backup in the status of the cluster
+	cluster := crv1.Pgcluster{}
+	if found, _ := kubeapi.Getpgcluster(c.JobClient, &cluster, labels[config.LABEL_PG_CLUSTER],
+		job.ObjectMeta.Namespace); found {
+		if err := clusteroperator.UpdateStatus(c.JobClientset, c.JobClient, c.JobConfig,
+			&cluster, 0); err != nil {
+			log.Error(err)
+		}
+	}
+
 	// If the completed backup was a cluster bootstrap backup, then mark the cluster as initialized
 	// and initiate the creation of any replicas.  Otherwise if the completed backup was taken as
 	// the result of a failover, then proceed with tremove the "primary_on_role_change" tag.
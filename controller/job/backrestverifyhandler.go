@@ -0,0 +1,111 @@
+package job
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"strings"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/batch/v1"
+)
+
+// handleBackrestVerifyUpdate is responsible for handling updates to the Jobs of
+// pgBackRest backup verifications. Once a Job finishes, its outcome is recorded
+// on its pgtask and published, and the Job is removed along with its Pod and the
+// volume the backup was restored into
+func (c *Controller) handleBackrestVerifyUpdate(job *apiv1.Job) error {
+
+	// return if the job is still running or is being deleted
+	if job.Status.Succeeded == 0 && job.Status.Failed == 0 {
+		return nil
+	}
+	if isJobInForegroundDeletion(job) {
+		log.Debugf("jobController onUpdate job %s is being deleted and will be ignored",
+			job.Name)
+		return nil
+	}
+
+	labels := job.GetObjectMeta().GetLabels()
+	namespace := job.ObjectMeta.Namespace
+
+	task := crv1.Pgtask{}
+	found, err := kubeapi.Getpgtask(c.JobClient, &task, labels[config.LABEL_PGTASK], namespace)
+	if !found {
+		log.Errorf("could not find the pgtask of backup verification job %s: %v", job.Name, err)
+		return kubeapi.DeleteJob(c.JobClientset, job.Name, namespace)
+	} else if err != nil {
+		return err
+	}
+
+	// the outcome is only recorded once, as the Job may be updated again before
+	// it is deleted
+	if task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_STATUS] != "" {
+		return nil
+	}
+
+	status, jobStatus := crv1.PgtaskBackrestVerifyPassed, crv1.JobCompletedStatus
+	if !isJobSuccessful(job) {
+		status, jobStatus = crv1.PgtaskBackrestVerifyFailed, crv1.JobErrorStatus
+	}
+	message := c.getJobTerminationMessage(job)
+
+	task.Spec.Status = jobStatus
+	task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_STATUS] = status
+	task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_COMPLETED] = time.Now().Format(time.RFC3339)
+	task.Status.Message = message
+
+	if err := kubeapi.Updatepgtask(c.JobClient, &task, task.Name, namespace); err != nil {
+		log.Errorf("error in updating pgtask %s: %s", task.Name, err.Error())
+		return err
+	}
+
+	backupSet := task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_SET]
+	if backupSet == "" {
+		backupSet = "latest"
+	}
+
+	publishBackupVerify(labels[config.LABEL_PG_CLUSTER], labels[config.LABEL_PGOUSER], namespace,
+		backupSet, status, message)
+
+	log.Debugf("jobController onUpdate backup verification job %s %s, removing it", job.Name, status)
+
+	// tear down the scratch instance, i.e. the Pod and its emptyDir volume
+	return kubeapi.DeleteJob(c.JobClientset, job.Name, namespace)
+}
+
+// getJobTerminationMessage returns the termination message of the Pod of a Job,
+// which holds either the result of the Job, or the end of its logs when it failed
+func (c *Controller) getJobTerminationMessage(job *apiv1.Job) string {
+	pods, err := kubeapi.GetPods(c.JobClientset, "job-name="+job.Name, job.ObjectMeta.Namespace)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+				return strings.TrimSpace(status.State.Terminated.Message)
+			}
+		}
+	}
+
+	return ""
+}
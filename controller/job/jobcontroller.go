@@ -74,6 +74,8 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 	switch {
 	case labels[config.LABEL_RMDATA] == "true":
 		err = c.handleRMDataUpdate(job)
+	case labels[config.LABEL_BACKREST_VERIFY] == "true":
+		err = c.handleBackrestVerifyUpdate(job)
 	case labels[config.LABEL_BACKREST] == "true" ||
 		labels[config.LABEL_BACKREST_RESTORE] == "true":
 		err = c.handleBackrestUpdate(job)
@@ -47,6 +47,31 @@ func publishBackupComplete(clusterName, clusterIdentifier, username, backuptype,
 
 }
 
+func publishBackupVerify(clusterName, username, namespace, backupSet, status, message string) {
+	topics := make([]string, 1)
+	topics[0] = events.EventTopicBackup
+
+	f := events.EventVerifyBackupFormat{
+		EventHeader: events.EventHeader{
+			Namespace: namespace,
+			Username:  username,
+			Topic:     topics,
+			Timestamp: time.Now(),
+			EventType: events.EventVerifyBackup,
+		},
+		Clustername: clusterName,
+		BackupSet:   backupSet,
+		Status:      status,
+		Message:     message,
+	}
+
+	err := events.Publish(f)
+	if err != nil {
+		log.Error(err.Error())
+	}
+
+}
+
 func publishRestoreComplete(clusterName, identifier, username, namespace string) {
 	topics := make([]string, 1)
 	topics[0] = events.EventTopicCluster
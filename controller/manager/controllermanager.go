@@ -239,6 +239,13 @@ func (c *ControllerManager) addControllerGroup(namespace string) error {
 		Queue:                workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		Informer:             pgoInformerFactory.Crunchydata().V1().Pgclusters(),
 		PgclusterWorkerCount: *c.pgoConfig.Pgo.PGClusterWorkerCount,
+		Reconcile:            *c.pgoConfig.Pgo.ReconcileInterval > 0,
+	}
+
+	pgClusterReconciler := &pgcluster.Reconciler{
+		Queue:             pgClustercontroller.Queue,
+		Informer:          pgoInformerFactory.Crunchydata().V1().Pgclusters(),
+		ReconcileInterval: time.Duration(*c.pgoConfig.Pgo.ReconcileInterval) * time.Second,
 	}
 
 	pgReplicacontroller := &pgreplica.Controller{
@@ -306,7 +313,8 @@ func (c *ControllerManager) addControllerGroup(namespace string) error {
 	// store the controllers containing worker queues so that the queues can also be started
 	// when any informers in the controller are started
 	group.controllersWithWorkers = append(group.controllersWithWorkers,
-		pgTaskcontroller, pgClustercontroller, pgReplicacontroller, configMapController)
+		pgTaskcontroller, pgClustercontroller, pgClusterReconciler, pgReplicacontroller,
+		configMapController)
 
 	c.controllers[namespace] = group
 
@@ -45,6 +45,9 @@ type Controller struct {
 	Queue                workqueue.RateLimitingInterface
 	Informer             informers.PgclusterInformer
 	PgclusterWorkerCount int
+	// Reconcile is true if clusters that have been created are reconciled when
+	// their key is processed, see Reconciler
+	Reconcile bool
 }
 
 // onAdd is called when a pgcluster is added
@@ -114,6 +117,7 @@ func (c *Controller) processNextItem() bool {
 
 	if found {
 		log.Debugf("cluster add - dep already found, not creating again")
+		c.syncCluster(keyNamespace, keyResourceName)
 		return true
 	}
 
@@ -156,23 +160,89 @@ func (c *Controller) processNextItem() bool {
 	log.Debugf("Scaled pgBackRest repo deployment %s to 1 to proceed with initializing "+
 		"cluster %s", clusterInfo.PrimaryDeployment, cluster.Name)
 
+	// the spec of the cluster has now been acted upon
+	if err := clusteroperator.UpdateStatus(c.PgclusterClientset, c.PgclusterClient, c.PgclusterConfig,
+		&cluster, cluster.Generation); err != nil {
+		log.Error(err)
+	}
+
 	return true
 }
 
+// syncCluster converges a cluster that has already been created, i.e. it shuts
+// down or starts the cluster as its spec calls for and, if enabled, reconciles
+// it. As it is called by the worker, the queue guarantees that this never runs
+// concurrently for the same cluster
+func (c *Controller) syncCluster(namespace, name string) {
+	cluster := crv1.Pgcluster{}
+	found, err := kubeapi.Getpgcluster(c.PgclusterClient, &cluster, name, namespace)
+	if !found {
+		log.Debugf("cluster sync - pgcluster %s not found", name)
+		return
+	} else if err != nil {
+		log.Error(err)
+		return
+	}
+
+	switch {
+	case shouldShutdown(&cluster):
+		if err := clusteroperator.ShutdownCluster(c.PgclusterClientset, c.PgclusterClient, cluster); err != nil {
+			log.Error(err)
+		}
+		return
+	case shouldStartup(&cluster):
+		if err := clusteroperator.StartupCluster(c.PgclusterClientset, cluster); err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	if !c.Reconcile || !isReconcilable(&cluster) {
+		return
+	}
+
+	if _, err := clusteroperator.Reconcile(c.PgclusterClientset, c.PgclusterClient,
+		c.PgclusterConfig, &cluster); err != nil {
+		log.Errorf("pgcluster Controller: could not reconcile cluster %s: %s",
+			cluster.Name, err.Error())
+	}
+
+	if err := clusteroperator.UpdateStatus(c.PgclusterClientset, c.PgclusterClient,
+		c.PgclusterConfig, &cluster, 0); err != nil {
+		log.Error(err)
+	}
+}
+
+// shouldShutdown returns true if the cluster should be shut down but is not
+func shouldShutdown(cluster *crv1.Pgcluster) bool {
+	return cluster.Spec.Shutdown && cluster.Status.State != crv1.PgclusterStateShutdown
+}
+
+// shouldStartup returns true if the cluster is shut down but should be running
+func shouldStartup(cluster *crv1.Pgcluster) bool {
+	return !cluster.Spec.Shutdown && cluster.Status.State == crv1.PgclusterStateShutdown
+}
+
 // onUpdate is called when a pgcluster is updated
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 	oldcluster := oldObj.(*crv1.Pgcluster)
 	newcluster := newObj.(*crv1.Pgcluster)
 	//	log.Debugf("pgcluster ns=%s %s onUpdate", newcluster.ObjectMeta.Namespace, newcluster.ObjectMeta.Name)
 
+	// the status is kept up to date by the Operator itself, so there is nothing
+	// to act upon when only the status has changed
+	if isStatusUpdate(oldcluster, newcluster) {
+		return
+	}
+
 	// if the 'shutdown' parameter in the pgcluster update shows that the cluster should be either
 	// shutdown or started but its current status does not properly reflect that it is, then
-	// proceed with the logic needed to either shutdown or start the cluster
-	if newcluster.Spec.Shutdown && newcluster.Status.State != crv1.PgclusterStateShutdown {
-		clusteroperator.ShutdownCluster(c.PgclusterClientset, c.PgclusterClient, *newcluster)
-	} else if !newcluster.Spec.Shutdown &&
-		newcluster.Status.State != crv1.PgclusterStateInitialized {
-		clusteroperator.StartupCluster(c.PgclusterClientset, *newcluster)
+	// queue the cluster so that it is shutdown or started by the worker, which ensures this
+	// never happens while the cluster is being reconciled
+	if shouldShutdown(newcluster) || shouldStartup(newcluster) {
+		if key, err := cache.MetaNamespaceKeyFunc(newcluster); err == nil {
+			c.Queue.Add(key)
+		}
 	}
 
 	// check to see if the "autofail" label on the pgcluster CR has been changed from either true to false, or from
@@ -229,6 +299,51 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 		}
 	}
 
+	// see if the retention policy of the pgBackRest repository has changed, and
+	// if so, update it
+	if oldcluster.Spec.BackrestRetention != newcluster.Spec.BackrestRetention {
+		if err := backrestoperator.UpdateRetention(c.PgclusterClientset, newcluster); err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
+	// see if synchronous replication has been turned on or off or its settings
+	// have changed, and if so, apply them. A cluster that is not initialized yet
+	// has them applied once its instances are ready
+	if !reflect.DeepEqual(oldcluster.Spec.SyncReplication, newcluster.Spec.SyncReplication) ||
+		!reflect.DeepEqual(oldcluster.Spec.SyncReplicationSettings, newcluster.Spec.SyncReplicationSettings) {
+		if newcluster.Status.State != crv1.PgclusterStateInitialized {
+			log.Infof("not updating the sync replication of cluster %s as it is not initialized",
+				newcluster.Name)
+		} else if err := clusteroperator.UpdateSyncReplication(c.PgclusterClientset, c.PgclusterClient,
+			c.PgclusterConfig, newcluster); err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
+	// see if the PostgreSQL parameters have changed, and if so, apply them along
+	// with the removal of those that are no longer set. A cluster that is not
+	// initialized yet has them applied as it is initialized
+	if !reflect.DeepEqual(oldcluster.Spec.Parameters, newcluster.Spec.Parameters) {
+		removed := []string{}
+		for name := range oldcluster.Spec.Parameters {
+			if _, ok := newcluster.Spec.Parameters[name]; !ok {
+				removed = append(removed, name)
+			}
+		}
+
+		if newcluster.Status.State != crv1.PgclusterStateInitialized {
+			log.Infof("not updating the PostgreSQL parameters of cluster %s as it is not initialized",
+				newcluster.Name)
+		} else if err := clusteroperator.UpdateParameters(c.PgclusterClientset, newcluster,
+			removed); err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
 	// see if any of the pgBouncer values have changed, and if so, update the
 	// pgBouncer deployment
 	if !reflect.DeepEqual(oldcluster.Spec.PgBouncer, newcluster.Spec.PgBouncer) {
@@ -246,6 +361,29 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 			return
 		}
 	}
+
+	// if the number of replicas has changed, add or remove replicas. A cluster
+	// that is not initialized yet is still creating the replicas it was created
+	// with, so it is left alone
+	if oldcluster.Spec.Replicas != newcluster.Spec.Replicas {
+		if newcluster.Status.State != crv1.PgclusterStateInitialized {
+			log.Infof("not scaling cluster %s to %s replicas as it is not initialized",
+				newcluster.Name, newcluster.Spec.Replicas)
+		} else if err := clusteroperator.ScaleReplicas(c.PgclusterClientset, c.PgclusterClient,
+			c.PgclusterConfig, newcluster); err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
+	// if the spec has changed, report that it has been acted upon. The cluster
+	// is copied as it belongs to the informer cache
+	if oldcluster.Generation != newcluster.Generation {
+		if err := clusteroperator.UpdateStatus(c.PgclusterClientset, c.PgclusterClient, c.PgclusterConfig,
+			newcluster.DeepCopy(), newcluster.Generation); err != nil {
+			log.Error(err)
+		}
+	}
 }
 
 // onDelete is called when a pgcluster is deleted
@@ -269,6 +407,15 @@ func (c *Controller) AddPGClusterEventHandler() {
 	log.Debugf("pgcluster Controller: added event handler to informer")
 }
 
+// isStatusUpdate returns true if the update of a pgcluster changed nothing but
+// its status
+func isStatusUpdate(oldCluster, newCluster *crv1.Pgcluster) bool {
+	return reflect.DeepEqual(oldCluster.Spec, newCluster.Spec) &&
+		reflect.DeepEqual(oldCluster.ObjectMeta.Labels, newCluster.ObjectMeta.Labels) &&
+		reflect.DeepEqual(oldCluster.ObjectMeta.Annotations, newCluster.ObjectMeta.Annotations) &&
+		!reflect.DeepEqual(oldCluster.Status, newCluster.Status)
+}
+
 func addIdentifier(clusterCopy *crv1.Pgcluster) {
 	u, err := ioutil.ReadFile("/proc/sys/kernel/random/uuid")
 	if err != nil {
@@ -300,7 +447,7 @@ func updatePgBouncer(c *Controller, oldCluster *crv1.Pgcluster, newCluster *crv1
 	}
 
 	// otherwise, this is an update
-	return clusteroperator.UpdatePgbouncer(c.PgclusterClientset, c.PgclusterClient, oldCluster, newCluster)
+	return clusteroperator.UpdatePgbouncer(c.PgclusterClientset, c.PgclusterClient, c.PgclusterConfig, oldCluster, newCluster)
 }
 
 // updateTablespaces updates the PostgreSQL instance Deployments to reflect the
@@ -0,0 +1,116 @@
+package pgcluster
+
+/*
+Copyright 2020 Crunchy Data Solutions, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	informers "github.com/crunchydata/postgres-operator/pkg/generated/informers/externalversions/crunchydata.com/v1"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Reconciler periodically compares every cluster in a namespace against its
+// pgcluster and corrects any drift, e.g. a Service that was deleted or a
+// Deployment that was edited by hand. The clusters are added to the queue of
+// the pgcluster Controller, which reconciles them, so that a cluster is never
+// reconciled while it is being shut down or started
+type Reconciler struct {
+	// Queue is the queue of the pgcluster Controller
+	Queue    workqueue.RateLimitingInterface
+	Informer informers.PgclusterInformer
+	// ReconcileInterval is the time between reconciliations. Clusters are not
+	// reconciled if it is 0
+	ReconcileInterval time.Duration
+}
+
+// RunWorker reconciles the clusters every reconcile interval until a message is
+// received on the stop channel
+func (r *Reconciler) RunWorker(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer func() {
+		log.Debug("pgcluster Reconciler: stopped, writing to the done channel")
+		doneCh <- struct{}{}
+	}()
+
+	if r.ReconcileInterval <= 0 {
+		log.Debug("pgcluster Reconciler: reconcile interval is 0, clusters will not be reconciled")
+		<-stopCh
+		return
+	}
+
+	ticker := time.NewTicker(r.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			r.reconcileClusters()
+		}
+	}
+}
+
+// WorkerCount returns the worker count for the reconciler, which is always 1 as
+// it only adds clusters to the queue
+func (r *Reconciler) WorkerCount() int {
+	return 1
+}
+
+// reconcileClusters queues every cluster that is neither being created, nor
+// deleted, nor upgraded to be reconciled
+func (r *Reconciler) reconcileClusters() {
+	clusters, err := r.Informer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, cluster := range clusters {
+		if !isReconcilable(cluster) {
+			log.Debugf("pgcluster Reconciler: skipping cluster %s in state %q",
+				cluster.Name, cluster.Status.State)
+			continue
+		}
+
+		key, err := cache.MetaNamespaceKeyFunc(cluster)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		r.Queue.Add(key)
+	}
+}
+
+// isReconcilable returns true if the cluster can be reconciled, i.e. it has been
+// initialized and is neither being deleted nor upgraded
+func isReconcilable(cluster *crv1.Pgcluster) bool {
+	switch {
+	case cluster.DeletionTimestamp != nil:
+		return false
+	case cluster.Labels[config.LABEL_MINOR_UPGRADE] == config.LABEL_UPGRADE_IN_PROGRESS:
+		return false
+	}
+
+	return cluster.Status.State == crv1.PgclusterStateInitialized ||
+		cluster.Status.State == crv1.PgclusterStateShutdown
+}
@@ -111,6 +111,17 @@ func (c *Controller) processNextItem() bool {
 		} else {
 			log.Debug("skipping duplicate onAdd failover task %s/%s", keyNamespace, keyResourceName)
 		}
+	case crv1.PgtaskSwitchover:
+		log.Debug("switchover task added")
+		clusteroperator.Switchover(keyNamespace, c.PgtaskClientset, c.PgtaskClient, &tmpTask, c.PgtaskConfig)
+	case crv1.PgtaskRestart:
+		log.Debug("restart task added")
+		// a rolling restart waits on each instance, so it is run in the
+		// background to not hold up the other pgtasks
+		go clusteroperator.Restart(keyNamespace, c.PgtaskClientset, c.PgtaskClient, &tmpTask, c.PgtaskConfig)
+	case crv1.PgtaskRestartPending:
+		log.Debug("restart pending instances task added")
+		go clusteroperator.RestartPending(keyNamespace, c.PgtaskClientset, c.PgtaskClient, &tmpTask, c.PgtaskConfig)
 
 	case crv1.PgtaskDeleteData:
 		log.Debug("delete data task added")
@@ -128,6 +139,9 @@ func (c *Controller) processNextItem() bool {
 	case crv1.PgtaskBackrestRestore:
 		log.Debug("backrest restore task added")
 		backrestoperator.Restore(c.PgtaskClient, keyNamespace, c.PgtaskClientset, &tmpTask)
+	case crv1.PgtaskBackrestVerify:
+		log.Debug("backrest verify task added")
+		backrestoperator.Verify(keyNamespace, c.PgtaskClientset, c.PgtaskClient, &tmpTask)
 
 	case crv1.PgtaskpgDump:
 		log.Debug("pgDump task added")
@@ -116,6 +116,14 @@ func (c *Controller) handleCommonInit(cluster *crv1.Pgcluster) error {
 	operator.UpdatePGHAConfigInitFlag(c.PodClientset, false, cluster.Name,
 		cluster.Namespace)
 
+	// the PostgreSQL parameters of the cluster are stored in its DCS configuration,
+	// which is only available once the primary is running
+	if len(cluster.Spec.Parameters) > 0 {
+		if err := clusteroperator.UpdateParameters(c.PodClientset, cluster, nil); err != nil {
+			log.Error(err)
+		}
+	}
+
 	return nil
 }
 
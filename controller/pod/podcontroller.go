@@ -21,6 +21,8 @@ import (
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	clusteroperator "github.com/crunchydata/postgres-operator/operator/cluster"
+	"github.com/crunchydata/postgres-operator/util"
 
 	log "github.com/sirupsen/logrus"
 	apiv1 "k8s.io/api/core/v1"
@@ -92,6 +94,11 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 		return
 	}
 
+	// keep the status of the cluster up to date as its Pods come and go
+	if isPodStatusChanged(oldPod, newPod) {
+		c.updateClusterStatus(&cluster)
+	}
+
 	// For the following upgrade and cluster initialization scenarios we only care about updates
 	// where the database container within the pod is becoming ready.  We can therefore return
 	// at this point if this condition is false.
@@ -132,6 +139,18 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 		}
 	}
 
+	// apply the synchronous replication settings of the cluster as its instances become
+	// ready, e.g. a replica that was just added and may need to be excluded from the
+	// synchronous standbys
+	if cluster.Spec.SyncReplicationSettings != nil && isDBContainerBecomingReady(oldPod, newPod) {
+		log.Debugf("Pod Controller: pod %s in namespace %s now ready, updating sync "+
+			"replication", newPod.Name, newPod.Namespace)
+		if err := clusteroperator.UpdateSyncReplication(c.PodClientset, c.PodClient, c.PodConfig,
+			&cluster); err != nil {
+			log.Error(err)
+		}
+	}
+
 	// First handle pod update as needed if the update was part of an ongoing upgrade
 	if cluster.Labels[config.LABEL_MINOR_UPGRADE] == config.LABEL_UPGRADE_IN_PROGRESS {
 		log.Debugf("Pod Controller: upgrade pod %s (namespace %s) now ready, calling pod upgrade "+
@@ -148,13 +167,38 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 // onDelete is called when a pgcluster is deleted
 func (c *Controller) onDelete(obj interface{}) {
 
-	pod := obj.(*apiv1.Pod)
+	pod, ok := obj.(*apiv1.Pod)
+	if !ok {
+		// the final state of the pod is unknown, and the status of its cluster
+		// is refreshed by the other pods of the cluster
+		return
+	}
 
 	labels := pod.GetObjectMeta().GetLabels()
 	if labels[config.LABEL_VENDOR] != "crunchydata" {
 		log.Debugf("Pod Controller: onDelete skipping pod that is not crunchydata %s", pod.ObjectMeta.SelfLink)
 		return
 	}
+
+	// an instance of a cluster may have been removed, so refresh its status
+	if clusterName, ok := labels[config.LABEL_PG_CLUSTER]; ok && isPostgresPod(pod) {
+		cluster := crv1.Pgcluster{}
+		found, err := kubeapi.Getpgcluster(c.PodClient, &cluster, clusterName, pod.ObjectMeta.Namespace)
+		if !found || err != nil {
+			return
+		}
+		c.updateClusterStatus(&cluster)
+	}
+}
+
+// updateClusterStatus refreshes the status of a cluster. Errors are logged, as
+// the status is refreshed again with the next change to the Pods of the cluster
+func (c *Controller) updateClusterStatus(cluster *crv1.Pgcluster) {
+	if err := clusteroperator.UpdateStatus(c.PodClientset, c.PodClient, c.PodConfig,
+		cluster, 0); err != nil {
+		log.Errorf("Pod Controller: could not update the status of cluster %s: %s",
+			cluster.Name, err.Error())
+	}
 }
 
 // AddPodEventHandler adds the pod event handler to the pod informer
@@ -224,6 +268,27 @@ func isDBContainerBecomingReady(oldPod, newPod *apiv1.Pod) bool {
 	return isContainerBecomingReady("database", oldPod, newPod)
 }
 
+// isPodStatusChanged determines whether or not a Pod update changes the status of
+// the cluster the Pod belongs to, i.e. if the Pod became ready or not ready, if
+// it has finished, if its role within the cluster has changed, or if it started
+// or stopped waiting for a restart
+func isPodStatusChanged(oldPod, newPod *apiv1.Pod) bool {
+	return isPodReady(oldPod) != isPodReady(newPod) ||
+		oldPod.Status.Phase != newPod.Status.Phase ||
+		oldPod.ObjectMeta.Labels[config.LABEL_PGHA_ROLE] != newPod.ObjectMeta.Labels[config.LABEL_PGHA_ROLE] ||
+		util.IsPendingRestart(oldPod) != util.IsPendingRestart(newPod)
+}
+
+// isPodReady determines whether or not the Pod has the Ready condition
+func isPodReady(pod *apiv1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == apiv1.PodReady {
+			return condition.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
+
 // isPostgresPod determines whether or not a pod is a PostreSQL Pod, specifically either the
 // primary or a replica pod within a PG cluster.  This is determined by checking to see if the
 // 'pgo-pg-database' label is present on the Pod (also, this controller will only process pod with
@@ -55,6 +55,11 @@ var (
 // it now has either the "promoted" or "master" role label.
 func (c *Controller) handlePostgresPodPromotion(newPod *apiv1.Pod, cluster crv1.Pgcluster) error {
 
+	// a switchover that was scheduled is performed by Patroni, so it is only
+	// known to have happened once an instance is promoted
+	clusteroperator.ReconcileScheduledSwitchover(c.PodClient, &cluster,
+		newPod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME])
+
 	if cluster.Status.State == crv1.PgclusterStateShutdown {
 		if err := c.handleStartupInit(cluster); err != nil {
 			return err
@@ -76,6 +76,15 @@ then
 		export PGO_APISERVER_PORT=8443
 fi
 
+#
+# the webhook port of the Service must match the AdmissionWebhookPort setting of
+# pgo.yaml, which defaults to 9443
+#
+
+export PGO_ADMISSION_WEBHOOK_PORT=$(sed -n 's/^ *AdmissionWebhookPort: *\([0-9]*\).*/\1/p' \
+	$PGOROOT/conf/postgres-operator/pgo.yaml)
+export PGO_ADMISSION_WEBHOOK_PORT=${PGO_ADMISSION_WEBHOOK_PORT:-9443}
+
 #
 # create the postgres-operator Deployment and Service
 #
@@ -56,9 +56,13 @@ const (
 	EventCreateLabel              = "CreateLabel"
 	EventLoad                     = "Load"
 	EventLoadCompleted            = "LoadCompleted"
+	EventReconcileCluster         = "ReconcileCluster"
+	EventRestartCluster           = "RestartCluster"
 
 	EventCreateBackup          = "CreateBackup"
 	EventCreateBackupCompleted = "CreateBackupCompleted"
+	EventScheduleRunFailure    = "ScheduleRunFailure"
+	EventVerifyBackup          = "VerifyBackup"
 
 	EventCreatePolicy = "CreatePolicy"
 	EventApplyPolicy  = "ApplyPolicy"
@@ -394,6 +398,45 @@ func (lvl EventCreateBackupCompletedFormat) String() string {
 	return msg
 }
 
+//----------------------------
+type EventScheduleRunFailureFormat struct {
+	EventHeader  `json:"eventheader"`
+	Clustername  string `json:"clustername"`
+	ScheduleName string `json:"schedulename"`
+	ScheduleType string `json:"scheduletype"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"errormessage"`
+}
+
+func (p EventScheduleRunFailureFormat) GetHeader() EventHeader {
+	return p.EventHeader
+}
+
+func (lvl EventScheduleRunFailureFormat) String() string {
+	msg := fmt.Sprintf("Event %s (schedule run failure) - clustername %s - schedule %s - type %s - status %s - error %s",
+		lvl.EventHeader, lvl.Clustername, lvl.ScheduleName, lvl.ScheduleType, lvl.Status, lvl.ErrorMessage)
+	return msg
+}
+
+//----------------------------
+type EventVerifyBackupFormat struct {
+	EventHeader `json:"eventheader"`
+	Clustername string `json:"clustername"`
+	BackupSet   string `json:"backupset"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+}
+
+func (p EventVerifyBackupFormat) GetHeader() EventHeader {
+	return p.EventHeader
+}
+
+func (lvl EventVerifyBackupFormat) String() string {
+	msg := fmt.Sprintf("Event %s (verify backup) - clustername %s - backupset %s - status %s - message %s",
+		lvl.EventHeader, lvl.Clustername, lvl.BackupSet, lvl.Status, lvl.Message)
+	return msg
+}
+
 //----------------------------
 type EventCreateLabelFormat struct {
 	EventHeader `json:"eventheader"`
@@ -658,3 +701,42 @@ func (lvl EventShutdownClusterFormat) String() string {
 		lvl.Clustername)
 	return msg
 }
+
+//----------------------------
+type EventReconcileClusterFormat struct {
+	EventHeader `json:"eventheader"`
+	Clustername string `json:"clustername"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Drift       string `json:"drift"`
+}
+
+func (p EventReconcileClusterFormat) GetHeader() EventHeader {
+	return p.EventHeader
+}
+
+func (lvl EventReconcileClusterFormat) String() string {
+	msg := fmt.Sprintf("Event %s - (drift corrected) clustername %s %s %s: %s", lvl.EventHeader,
+		lvl.Clustername, lvl.Kind, lvl.Name, lvl.Drift)
+	return msg
+}
+
+//----------------------------
+type EventRestartClusterFormat struct {
+	EventHeader `json:"eventheader"`
+	Clustername string `json:"clustername"`
+	Instance    string `json:"instance"`
+	Step        string `json:"step"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+}
+
+func (p EventRestartClusterFormat) GetHeader() EventHeader {
+	return p.EventHeader
+}
+
+func (lvl EventRestartClusterFormat) String() string {
+	msg := fmt.Sprintf("Event %s - (restart) clustername %s - instance %s - step %s - status %s - message %s",
+		lvl.EventHeader, lvl.Clustername, lvl.Instance, lvl.Step, lvl.Status, lvl.Message)
+	return msg
+}
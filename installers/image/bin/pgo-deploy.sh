@@ -20,6 +20,7 @@ export DELETE_WATCHED_NAMESPACE=${DELETE_WATCHED_NAMESPACE:-false}
 export PGO_ADD_OS_CA_STORE=${PGO_ADD_OS_CA_STORE:-false}
 export NAMESPACE_MODE=${NAMESPACE_MODE:-dynamic}
 export POD_ANTI_AFFINITY=${POD_ANTI_AFFINITY:-preferred}
+export PGO_ADMISSION_WEBHOOK_PORT=${PGO_ADMISSION_WEBHOOK_PORT:-9443}
 export PGO_APISERVER_PORT=${PGO_APISERVER_PORT:-8443}
 export PGO_APISERVER_URL=${PGO_APISERVER_URL:-https://postgres-operator}
 export PGO_CLIENT_CERT_SECRET=${PGO_CLIENT_CERT_SECRET:-pgo.tls}
@@ -62,8 +62,20 @@ then
     exit 1
 fi
 
-# Use the pgouser-admin secret to generate pgouser file
-kubectl get secret -n "${PGO_OPERATOR_NAMESPACE}" "${PGO_USER_ADMIN}" -o 'go-template={{.data.username | base64decode }}:{{.data.password | base64decode}}' > $OUTPUT_DIR/pgouser
+# The pgouser-admin secret only holds a hash of the password of the admin
+# pgouser, so the password has to be provided, as set in postgres-operator.yml
+if [ -z "${PGO_ADMIN_PASSWORD}" ]
+then
+    echo "PGO_ADMIN_PASSWORD is not set."
+    echo "Please set it to the PGO_ADMIN_PASSWORD used to deploy the PostgreSQL Operator."
+    echo "Exiting..."
+    exit 1
+fi
+
+# Use the username in the pgouser-admin secret and the provided password to
+# generate pgouser file
+PGO_ADMIN_USERNAME="$(kubectl get secret -n "${PGO_OPERATOR_NAMESPACE}" "${PGO_USER_ADMIN}" -o 'go-template={{.data.username | base64decode }}')"
+echo "${PGO_ADMIN_USERNAME}:${PGO_ADMIN_PASSWORD}" > $OUTPUT_DIR/pgouser
 # ensure this file is locked down to the specific user running this
 chmod a-rwx,u+rw "${OUTPUT_DIR}/pgouser"
 
@@ -215,8 +215,12 @@ operator() (
 		},
 		stringData: { permissions: "*", rolename: $rolename }
 	}' )"
+	# The apiserver only keeps a hash of the pgouser password, so the generated
+	# password is handed to the client separately.
+	admin_password="${RANDOM}${RANDOM}${RANDOM}"
+
 	user_secret_json="$( jq <<< '{}' \
-		--arg password "${RANDOM}${RANDOM}${RANDOM}" \
+		--arg password "$admin_password" \
 		--arg rolename admin \
 		--arg username admin \
 	'{
@@ -227,6 +231,15 @@ operator() (
 		},
 		stringData: { username: $username, password: $password, roles: $rolename }
 	}' )"
+	client_secret_json="$( jq <<< '{}' \
+		--arg password "$admin_password" \
+		--arg username admin \
+		--argjson subscription "$subscription_ownership" \
+	'{
+		apiVersion: "v1", kind: "Secret",
+		metadata: { name: "pgo-client", ownerReferences: [ $subscription ] },
+		stringData: { username: $username, password: $password }
+	}' )"
 
 	client_job_json="$( jq <<< '{}' \
 		--arg image "$client_image" \
@@ -244,8 +257,8 @@ operator() (
 				command: ["tail", "-f", "/dev/null"],
 				env: [
 					{ name: "PGO_APISERVER_URL", value: "https://postgres-operator:8443" },
-					{ name: "PGOUSERNAME", valueFrom: { secretKeyRef: { name: "pgouser-admin", key: "username" } } },
-					{ name: "PGOUSERPASS", valueFrom: { secretKeyRef: { name: "pgouser-admin", key: "password" } } },
+					{ name: "PGOUSERNAME", valueFrom: { secretKeyRef: { name: "pgo-client", key: "username" } } },
+					{ name: "PGOUSERPASS", valueFrom: { secretKeyRef: { name: "pgo-client", key: "password" } } },
 					{ name: "PGO_CA_CERT",     value: "/etc/pgo/certificates/tls.crt" },
 					{ name: "PGO_CLIENT_CERT", value: "/etc/pgo/certificates/tls.crt" },
 					{ name: "PGO_CLIENT_KEY",  value: "/etc/pgo/certificates/tls.key" }
@@ -258,6 +271,7 @@ operator() (
 	kc expose deploy postgres-operator
 	kc create --filename=- <<< "$role_secret_json"
 	kc create --filename=- <<< "$user_secret_json"
+	kc create --filename=- <<< "$client_secret_json"
 	kc create --filename=- <<< "$client_job_json"
 )
 
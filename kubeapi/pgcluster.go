@@ -150,22 +150,34 @@ func Updatepgcluster(client *rest.RESTClient, cluster *crv1.Pgcluster, name, nam
 	return err
 }
 
+// PatchpgclusterStatus sets the state and message of the status of a pgcluster,
+// leaving the rest of the status as is
 func PatchpgclusterStatus(restclient *rest.RESTClient, state crv1.PgclusterState, message string, oldCrd *crv1.Pgcluster, namespace string) error {
 
-	oldData, err := json.Marshal(oldCrd)
+	status := oldCrd.Status.DeepCopy()
+	status.State = state
+	status.Message = message
+
+	return UpdatepgclusterStatus(restclient, oldCrd, *status, namespace)
+}
+
+// UpdatepgclusterStatus updates the status of a pgcluster through the status
+// subresource. Only the fields of the status that differ from the current
+// status of the pgcluster are sent, so that fields updated concurrently by
+// another controller are not overwritten
+func UpdatepgclusterStatus(restclient *rest.RESTClient, cluster *crv1.Pgcluster, status crv1.PgclusterStatus, namespace string) error {
+
+	oldData, err := json.Marshal(cluster)
 	if err != nil {
 		return err
 	}
 
 	//change it
-	oldCrd.Status = crv1.PgclusterStatus{
-		State:   state,
-		Message: message,
-	}
+	cluster.Status = status
 
 	//create the patch
 	var newData, patchBytes []byte
-	newData, err = json.Marshal(oldCrd)
+	newData, err = json.Marshal(cluster)
 	if err != nil {
 		return err
 	}
@@ -174,17 +186,22 @@ func PatchpgclusterStatus(restclient *rest.RESTClient, state crv1.PgclusterState
 		return err
 	}
 
+	// nothing has changed, so there is nothing to send
+	if string(patchBytes) == "{}" {
+		return nil
+	}
+
 	log.Debug(string(patchBytes))
 
 	//apply patch
-	_, err6 := restclient.Patch(types.MergePatchType).
+	_, err = restclient.Patch(types.MergePatchType).
 		Namespace(namespace).
 		Resource(crv1.PgclusterResourcePlural).
-		Name(oldCrd.Spec.Name).
+		Name(cluster.Name).
+		SubResource("status").
 		Body(patchBytes).
 		Do().
 		Get()
 
-	return err6
-
+	return err
 }
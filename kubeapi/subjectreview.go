@@ -39,3 +39,29 @@ func CreateSelfSubjectAccessReview(clientset *kubernetes.Clientset,
 
 	return sarResponse, nil
 }
+
+// CreateSubjectAccessReview creates a SubjectAccessReview that checks whether
+// the user described by the user information is allowed to perform the action
+// described by the ResourceAttributes
+func CreateSubjectAccessReview(clientset *kubernetes.Clientset, user, uid string, groups []string,
+	extra map[string]authorizationapi.ExtraValue,
+	resourceAttributes *authorizationapi.ResourceAttributes) (*authorizationapi.SubjectAccessReview,
+	error) {
+
+	sarRequest := &authorizationapi.SubjectAccessReview{
+		Spec: authorizationapi.SubjectAccessReviewSpec{
+			ResourceAttributes: resourceAttributes,
+			User:               user,
+			UID:                uid,
+			Groups:             groups,
+			Extra:              extra,
+		},
+	}
+
+	sarResponse, err := clientset.AuthorizationV1().SubjectAccessReviews().Create(sarRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return sarResponse, nil
+}
@@ -0,0 +1,42 @@
+package kubeapi
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	authenticationapi "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CreateTokenReview creates a TokenReview to authenticate a bearer token with
+// the Kubernetes API server. If audiences are provided, the token must be valid
+// for at least one of them
+func CreateTokenReview(clientset *kubernetes.Clientset, token string,
+	audiences []string) (*authenticationapi.TokenReview, error) {
+
+	trRequest := &authenticationapi.TokenReview{
+		Spec: authenticationapi.TokenReviewSpec{
+			Token:     token,
+			Audiences: audiences,
+		},
+	}
+
+	trResponse, err := clientset.AuthenticationV1().TokenReviews().Create(trRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return trResponse, nil
+}
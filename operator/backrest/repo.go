@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/config"
@@ -56,6 +57,13 @@ type RepoDeploymentTemplateFields struct {
 	PodAntiAffinityLabelName  string
 	PodAntiAffinityLabelValue string
 	Replicas                  int
+
+	// the retention policy of the repository, each setting of which is only
+	// rendered if it is set
+	PgbackrestRetentionFull        int
+	PgbackrestRetentionDiff        int
+	PgbackrestRetentionArchive     int
+	PgbackrestRetentionArchiveType string
 }
 
 type RepoServiceTemplateFields struct {
@@ -73,13 +81,7 @@ func CreateRepoDeployment(clientset *kubernetes.Clientset, namespace string, clu
 	serviceName := fmt.Sprintf(util.BackrestRepoServiceName, cluster.Name)
 
 	//create backrest repo service
-	serviceFields := RepoServiceTemplateFields{
-		Name:        serviceName,
-		ClusterName: cluster.Name,
-		Port:        "2022",
-	}
-
-	err := createService(clientset, &serviceFields, namespace)
+	err := CreateRepoService(clientset, namespace, cluster)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -130,6 +132,12 @@ func CreateRepoDeployment(clientset *kubernetes.Clientset, namespace string, clu
 	}
 	log.Debugf(fields.Name)
 
+	// set the retention policy of the repository
+	fields.PgbackrestRetentionFull = cluster.Spec.BackrestRetention.Full
+	fields.PgbackrestRetentionDiff = cluster.Spec.BackrestRetention.Diff
+	fields.PgbackrestRetentionArchive = cluster.Spec.BackrestRetention.Archive
+	fields.PgbackrestRetentionArchiveType = cluster.Spec.BackrestRetention.ArchiveType
+
 	err = config.PgoBackrestRepoTemplate.Execute(&b, fields)
 	if err != nil {
 		log.Error(err.Error())
@@ -157,6 +165,18 @@ func CreateRepoDeployment(clientset *kubernetes.Clientset, namespace string, clu
 
 }
 
+// CreateRepoService creates the Service of the pgBackRest repository of a
+// cluster, if it does not already exist
+func CreateRepoService(clientset *kubernetes.Clientset, namespace string, cluster *crv1.Pgcluster) error {
+	serviceFields := RepoServiceTemplateFields{
+		Name:        fmt.Sprintf(util.BackrestRepoServiceName, cluster.Name),
+		ClusterName: cluster.Name,
+		Port:        "2022",
+	}
+
+	return createService(clientset, &serviceFields, namespace)
+}
+
 // UpdateResources updates the pgBackRest repository Deployment to reflect any
 // resource updates
 func UpdateResources(clientset *kubernetes.Clientset, restConfig *rest.Config, cluster *crv1.Pgcluster) error {
@@ -195,12 +215,14 @@ func UpdateResources(clientset *kubernetes.Clientset, restConfig *rest.Config, c
 	}
 
 	// handle the memory update. For memory, due to behavior of the OOM killer,
-	// we only set the **request*
+	// we only set the **request* and remove any limit that may have been set
+	// by hand
 	if resource, ok := cluster.Spec.BackrestResources[v1.ResourceMemory]; ok {
 		requestResourceList[v1.ResourceMemory] = resource
 	} else {
 		delete(requestResourceList, v1.ResourceMemory)
 	}
+	delete(limitResourceList, v1.ResourceMemory)
 
 	// update the requests / limits resourcelist
 	deployment.Spec.Template.Spec.Containers[0].Resources.Requests = requestResourceList
@@ -214,6 +236,85 @@ func UpdateResources(clientset *kubernetes.Clientset, restConfig *rest.Config, c
 	return nil
 }
 
+// UpdateRetention updates the pgBackRest repository Deployment to reflect the
+// retention policy of the cluster. This rolls out a new repository Pod
+func UpdateRetention(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) error {
+	deployment, err := operator.GetBackrestDeployment(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: this works as the "database" container is always first
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	retention := retentionEnv(cluster.Spec.BackrestRetention)
+
+	// remove the retention settings that are currently set, and then add back
+	// the ones that are part of the policy
+	env := []v1.EnvVar{}
+	for _, envVar := range container.Env {
+		if _, ok := retention[envVar.Name]; !ok {
+			env = append(env, envVar)
+		}
+	}
+
+	for _, name := range retentionEnvNames {
+		if value := retention[name]; value != "" {
+			env = append(env, v1.EnvVar{Name: name, Value: value})
+		}
+	}
+
+	container.Env = env
+
+	return kubeapi.UpdateDeployment(clientset, deployment)
+}
+
+// RetentionDiffers returns true if the retention policy that the pgBackRest
+// repository Deployment is running with differs from the one of the cluster
+func RetentionDiffers(deployment *appsv1.Deployment, cluster *crv1.Pgcluster) bool {
+	retention := retentionEnv(cluster.Spec.BackrestRetention)
+	current := map[string]string{}
+
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if _, ok := retention[envVar.Name]; ok {
+			current[envVar.Name] = envVar.Value
+		}
+	}
+
+	for _, name := range retentionEnvNames {
+		if current[name] != retention[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retentionEnvNames are the names of the environmental variables that hold
+// the retention policy of a pgBackRest repository
+var retentionEnvNames = []string{
+	"PGBACKREST_REPO1_RETENTION_FULL",
+	"PGBACKREST_REPO1_RETENTION_DIFF",
+	"PGBACKREST_REPO1_RETENTION_ARCHIVE",
+	"PGBACKREST_REPO1_RETENTION_ARCHIVE_TYPE",
+}
+
+// retentionEnv returns the values of the environmental variables of a
+// retention policy, keyed by name. Settings that are not part of the policy
+// have an empty value
+func retentionEnv(retention crv1.PgBackRestRetentionSpec) map[string]string {
+	env := map[string]string{}
+
+	for i, value := range []int{retention.Full, retention.Diff, retention.Archive} {
+		env[retentionEnvNames[i]] = ""
+		if value > 0 {
+			env[retentionEnvNames[i]] = strconv.Itoa(value)
+		}
+	}
+	env[retentionEnvNames[3]] = retention.ArchiveType
+
+	return env
+}
+
 func createService(clientset *kubernetes.Clientset, fields *RepoServiceTemplateFields, namespace string) error {
 	var err error
 
@@ -0,0 +1,185 @@
+package backrest
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/operator"
+	"github.com/crunchydata/postgres-operator/util"
+	log "github.com/sirupsen/logrus"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type backrestVerifyJobTemplateFields struct {
+	JobName             string
+	TaskName            string
+	ClusterName         string
+	CCPImagePrefix      string
+	CCPImage            string
+	CCPImageTag         string
+	SecurityContext     string
+	PgbackrestStanza    string
+	PgbackrestRepo1Host string
+	PgbackrestRepo1Path string
+	PgbackrestRepoType  string
+	PgbackrestS3EnvVars string
+}
+
+// Verify creates the Job of a pgBackRest backup verification. The Job restores
+// a backup into an emptyDir volume with the image of the cluster, starts
+// PostgreSQL on it and runs a sanity query. Its outcome is recorded on the task
+// by the Job controller, which then removes the Job along with its volume
+func Verify(namespace string, clientset *kubernetes.Clientset, restclient *rest.RESTClient, task *crv1.Pgtask) {
+	clusterName := task.Spec.Parameters[config.LABEL_PG_CLUSTER]
+
+	cluster := crv1.Pgcluster{}
+	found, err := kubeapi.Getpgcluster(restclient, &cluster, clusterName, namespace)
+	if !found || err != nil {
+		log.Errorf("backup verification error: could not find pgcluster %s", clusterName)
+		return
+	}
+
+	storageType := task.Spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE]
+
+	// the Job of a previous verification may remain, e.g. as it is still being
+	// removed, so it is deleted and every Job is given a unique name
+	selector := fmt.Sprintf("%s=%s,%s=true", config.LABEL_PG_CLUSTER, cluster.Name, config.LABEL_BACKREST_VERIFY)
+	if err := kubeapi.DeleteJobs(clientset, selector, namespace); err != nil {
+		log.Error(err)
+		return
+	}
+
+	jobFields := backrestVerifyJobTemplateFields{
+		JobName:             task.Spec.Parameters[config.LABEL_JOB_NAME] + "-" + util.RandStringBytesRmndr(4),
+		TaskName:            task.Name,
+		ClusterName:         cluster.Name,
+		CCPImagePrefix:      util.GetValueOrDefault(cluster.Spec.CCPImagePrefix, operator.Pgo.Cluster.CCPImagePrefix),
+		CCPImage:            cluster.Spec.CCPImage,
+		CCPImageTag:         cluster.Spec.CCPImageTag,
+		SecurityContext:     util.GetPodSecurityContext(nil),
+		PgbackrestStanza:    "db",
+		PgbackrestRepo1Host: cluster.Name + "-backrest-shared-repo",
+		PgbackrestRepo1Path: util.GetPGBackRestRepoPath(cluster),
+		PgbackrestRepoType:  operator.GetRepoType(storageType),
+	}
+
+	if jobFields.PgbackrestRepoType != "posix" {
+		jobFields.PgbackrestS3EnvVars = operator.GetPgbackrestS3EnvVars(cluster, clientset, namespace)
+	}
+
+	var doc bytes.Buffer
+	if err := config.BackrestVerifyjobTemplate.Execute(&doc, jobFields); err != nil {
+		log.Error(err.Error())
+		return
+	}
+
+	if operator.CRUNCHY_DEBUG {
+		config.BackrestVerifyjobTemplate.Execute(os.Stdout, jobFields)
+	}
+
+	job := v1batch.Job{}
+	if err := json.Unmarshal(doc.Bytes(), &job); err != nil {
+		log.Error("error unmarshalling json into Job " + err.Error())
+		return
+	}
+
+	// the backup set and the query are passed as they were given, rather than
+	// through the template, so that they need not be escaped
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env,
+		v1.EnvVar{Name: "VERIFY_SET", Value: task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_SET]},
+		v1.EnvVar{Name: "VERIFY_QUERY", Value: util.GetValueOrDefault(
+			task.Spec.Parameters[config.LABEL_BACKREST_VERIFY_QUERY], config.DefaultBackrestVerifyQuery)},
+	)
+
+	// set the container image to an override value, if one exists
+	imageName := config.CONTAINER_IMAGE_CRUNCHY_POSTGRES_HA
+	if strings.Contains(container.Image, "gis-ha") {
+		imageName = config.CONTAINER_IMAGE_CRUNCHY_POSTGRES_GIS_HA
+	}
+	operator.SetContainerImageOverride(imageName, container)
+
+	job.ObjectMeta.Labels[config.LABEL_PGOUSER] = task.ObjectMeta.Labels[config.LABEL_PGOUSER]
+	job.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER] = cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER]
+
+	if _, err := kubeapi.CreateJob(clientset, &job, namespace); err != nil {
+		log.Error(err)
+		return
+	}
+
+	log.Debugf("backup verification job %s created for cluster %s", job.Name, cluster.Name)
+}
+
+// CreateVerifyTask creates a Pgtask in order to verify the latest pgBackRest
+// backup of a cluster, e.g. once a backup that was requested with a
+// verification completes. The task of a previous verification is replaced
+func CreateVerifyTask(restclient *rest.RESTClient, namespace, clusterName, storageType, query,
+	pgouser string) (*crv1.Pgtask, error) {
+
+	cluster := crv1.Pgcluster{}
+	if _, err := kubeapi.Getpgcluster(restclient, &cluster, clusterName, namespace); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	taskName := "backrest-verify-" + cluster.Name
+
+	if err := kubeapi.Deletepgtask(restclient, taskName, namespace); err != nil && !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	spec := crv1.PgtaskSpec{}
+	spec.Name = taskName
+	spec.Namespace = namespace
+	spec.TaskType = crv1.PgtaskBackrestVerify
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_JOB_NAME] = taskName
+	spec.Parameters[config.LABEL_PG_CLUSTER] = cluster.Name
+	spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE] = storageType
+	spec.Parameters[config.LABEL_BACKREST_VERIFY_QUERY] = query
+
+	newInstance := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: taskName,
+		},
+		Spec: spec,
+	}
+	newInstance.ObjectMeta.Labels = make(map[string]string)
+	newInstance.ObjectMeta.Labels[config.LABEL_PG_CLUSTER] = cluster.Name
+	newInstance.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER] = cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER]
+	newInstance.ObjectMeta.Labels[config.LABEL_BACKREST_VERIFY] = "true"
+	newInstance.ObjectMeta.Labels[config.LABEL_PGOUSER] = pgouser
+
+	if err := kubeapi.Createpgtask(restclient, newInstance, namespace); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newInstance, nil
+}
@@ -340,9 +340,9 @@ func cloneStep2(clientset *kubernetes.Clientset, client *rest.RESTClient, restCo
 		SecurityContext:  util.GetPodSecurityContext(supplementalGroups),
 		ToClusterPVCName: targetClusterName, // the PVC name should match that of the target cluster
 		WorkflowID:       workflowID,
-		// use a delta restore in order to optimize how the restore occurs
-		CommandOpts: "--delta",
-		// PITRTarget is not supported in the first iteration of clone
+		CommandOpts: cloneRestoreOpts(task.Spec.Parameters[util.CloneParameterBackupLabel],
+			task.Spec.Parameters[util.CloneParameterPITRTarget]),
+		PITRTarget:          task.Spec.Parameters[util.CloneParameterPITRTarget],
 		PGOImagePrefix:      util.GetValueOrDefault(sourcePgcluster.Spec.PGOImagePrefix, operator.Pgo.Pgo.PGOImagePrefix),
 		PGOImageTag:         operator.Pgo.Pgo.PGOImageTag,
 		PgbackrestStanza:    pgBackRestStanza,
@@ -426,6 +426,25 @@ func cloneStep2(clientset *kubernetes.Clientset, client *rest.RESTClient, restCo
 	patchPgtaskComplete(client, namespace, task.Spec.Name)
 }
 
+// cloneRestoreOpts returns the options of the pgBackRest restore of a clone. A
+// delta restore is used in order to optimize how the restore occurs. The
+// restore is of the latest backup unless the label of another backup is given,
+// and a point-in-time recovery target is recovered to before the new cluster is
+// promoted
+func cloneRestoreOpts(backupLabel, pitrTarget string) string {
+	opts := []string{"--delta"}
+
+	if backupLabel != "" {
+		opts = append(opts, "--set="+backupLabel)
+	}
+
+	if pitrTarget != "" {
+		opts = append(opts, "--type=time", "--target-action=promote")
+	}
+
+	return strings.Join(opts, " ")
+}
+
 // cloneStep3 creates the new cluster by creating a new Pgcluster
 func cloneStep3(clientset *kubernetes.Clientset, client *rest.RESTClient, namespace string, task *crv1.Pgtask) {
 	sourceClusterName, targetClusterName, workflowID := getCloneTaskIdentifiers(task)
@@ -498,7 +517,9 @@ func createPgBackRestRepoSyncJob(clientset *kubernetes.Clientset, namespace stri
 				// these annotations are used for the subsequent steps to be
 				// able to identify how to connect these jobs
 				config.ANNOTATION_CLONE_BACKREST_PVC_SIZE:   task.Spec.Parameters[util.CloneParameterBackrestPVCSize],
+				config.ANNOTATION_CLONE_BACKUP_LABEL:        task.Spec.Parameters[util.CloneParameterBackupLabel],
 				config.ANNOTATION_CLONE_ENABLE_METRICS:      task.Spec.Parameters[util.CloneParameterEnableMetrics],
+				config.ANNOTATION_CLONE_PITR_TARGET:         task.Spec.Parameters[util.CloneParameterPITRTarget],
 				config.ANNOTATION_CLONE_PVC_SIZE:            task.Spec.Parameters[util.CloneParameterPVCSize],
 				config.ANNOTATION_CLONE_SOURCE_CLUSTER_NAME: sourcePgcluster.Spec.ClusterName,
 				config.ANNOTATION_CLONE_TARGET_CLUSTER_NAME: targetClusterName,
@@ -815,10 +836,15 @@ func createCluster(clientset *kubernetes.Clientset, client *rest.RESTClient, tas
 			TablespaceMounts: sourcePgcluster.Spec.TablespaceMounts,
 			WALStorage:       sourcePgcluster.Spec.WALStorage,
 		},
-		Status: crv1.PgclusterStatus{
-			State:   crv1.PgclusterStateCreated,
-			Message: "Created, not processed yet",
-		},
+	}
+
+	// the synchronous replication settings are carried over, except for the
+	// excluded replicas, which are the replicas of the source cluster
+	if sourcePgcluster.Spec.SyncReplicationSettings != nil {
+		targetPgcluster.Spec.SyncReplicationSettings = &crv1.SyncReplicationSpec{
+			NodeCount: sourcePgcluster.Spec.SyncReplicationSettings.NodeCount,
+			Strict:    sourcePgcluster.Spec.SyncReplicationSettings.Strict,
+		}
 	}
 
 	// if any of the PVC sizes are overridden, indicate this in the cluster spec
@@ -851,6 +877,17 @@ func createCluster(clientset *kubernetes.Clientset, client *rest.RESTClient, tas
 		return err
 	}
 
+	// the status is a subresource of the pgcluster, so it is set once the
+	// pgcluster has been created, unless it has already been processed
+	created := crv1.Pgcluster{}
+	if found, _ := kubeapi.Getpgcluster(client, &created, targetPgcluster.Name, namespace); found &&
+		created.Status.State == "" {
+		if err := kubeapi.PatchpgclusterStatus(client, crv1.PgclusterStateCreated,
+			"Created, not processed yet", &created, namespace); err != nil {
+			log.Error(err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,37 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import "testing"
+
+func TestCloneRestoreOpts(t *testing.T) {
+	tests := []struct {
+		backupLabel, pitrTarget string
+		expected                string
+	}{
+		{expected: "--delta"},
+		{backupLabel: "20200301-010000F", expected: "--delta --set=20200301-010000F"},
+		{pitrTarget: "2020-03-01 08:00:00+00", expected: "--delta --type=time --target-action=promote"},
+		{backupLabel: "20200301-010000F", pitrTarget: "2020-03-01 08:00:00+00",
+			expected: "--delta --set=20200301-010000F --type=time --target-action=promote"},
+	}
+
+	for _, test := range tests {
+		if opts := cloneRestoreOpts(test.backupLabel, test.pitrTarget); opts != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, opts)
+		}
+	}
+}
@@ -34,7 +34,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	apps_v1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
-	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -131,46 +130,10 @@ func AddClusterBase(clientset *kubernetes.Clientset, client *rest.RESTClient, cl
 		}
 		//create a CRD for each replica
 		for i := 0; i < replicaCount; i++ {
-			spec := crv1.PgreplicaSpec{}
-			//get the storage config
-			spec.ReplicaStorage = cl.Spec.ReplicaStorage
-
-			spec.UserLabels = cl.Spec.UserLabels
-
-			//the replica should not use the same node labels as the primary
-			spec.UserLabels[config.LABEL_NODE_LABEL_KEY] = ""
-			spec.UserLabels[config.LABEL_NODE_LABEL_VALUE] = ""
-
-			labels := make(map[string]string)
-			labels[config.LABEL_PG_CLUSTER] = cl.Spec.Name
-
-			spec.ClusterName = cl.Spec.Name
-			uniqueName := util.RandStringBytesRmndr(4)
-			labels[config.LABEL_NAME] = cl.Spec.Name + "-" + uniqueName
-			spec.Name = labels[config.LABEL_NAME]
-			newInstance := &crv1.Pgreplica{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:   labels[config.LABEL_NAME],
-					Labels: labels,
-				},
-				Spec: spec,
-				Status: crv1.PgreplicaStatus{
-					State:   crv1.PgreplicaStateCreated,
-					Message: "Created, not processed yet",
-				},
-			}
-			result := crv1.Pgreplica{}
-
-			err = client.Post().
-				Resource(crv1.PgreplicaResourcePlural).
-				Namespace(namespace).
-				Body(newInstance).
-				Do().Into(&result)
-			if err != nil {
+			if _, err := createPgreplica(client, cl, namespace); err != nil {
 				log.Error(" in creating Pgreplica instance" + err.Error())
 				publishClusterCreateFailure(cl, err.Error())
 			}
-
 		}
 	}
 
@@ -363,6 +326,10 @@ func UpdateResources(clientset *kubernetes.Clientset, restConfig *rest.Config, c
 			delete(requestResourceList, v1.ResourceMemory)
 		}
 
+		// due to behavior of the OOM killer, memory is never limited, so remove
+		// any limit that may have been set by hand
+		delete(limitResourceList, v1.ResourceMemory)
+
 		// update the requests resourcelist
 		deployment.Spec.Template.Spec.Containers[0].Resources.Requests = requestResourceList
 		deployment.Spec.Template.Spec.Containers[0].Resources.Limits = limitResourceList
@@ -0,0 +1,84 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	cfg "github.com/crunchydata/postgres-operator/operator/config"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// UpdateParameters applies the PostgreSQL parameters of a cluster by storing
+// them in its DCS configuration, which is then applied to the cluster by the
+// ConfigMap controller. The parameters that were removed from the cluster are
+// removed from the DCS configuration as well, so that their defaults apply
+// again. Patroni reloads each instance once the parameters change, and flags
+// the instances that need a restart for a parameter to take effect
+func UpdateParameters(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster, removed []string) error {
+	log.Debugf("updating the PostgreSQL parameters of cluster %s: parameters [%v] removed [%v]",
+		cluster.Name, cluster.Spec.Parameters, removed)
+
+	dcs, dcsConfig, err := getDCSConfig(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	if !setParameters(dcsConfig, cluster.Spec.Parameters, removed) {
+		return nil
+	}
+
+	return dcs.Update(dcsConfig)
+}
+
+// setParameters sets the PostgreSQL parameters in the DCS configuration of a
+// cluster, removes the parameters that were removed from the cluster, and
+// returns whether the configuration changed
+func setParameters(dcsConfig *cfg.DCSConfig, parameters map[string]string, removed []string) bool {
+	if dcsConfig.PostgreSQL == nil {
+		dcsConfig.PostgreSQL = &cfg.PostgresDCS{}
+	}
+	if dcsConfig.PostgreSQL.Parameters == nil {
+		dcsConfig.PostgreSQL.Parameters = map[string]interface{}{}
+	}
+
+	changed := false
+
+	for _, name := range removed {
+		if _, ok := parameters[name]; ok {
+			continue
+		}
+		if _, ok := dcsConfig.PostgreSQL.Parameters[name]; ok {
+			delete(dcsConfig.PostgreSQL.Parameters, name)
+			changed = true
+		}
+	}
+
+	// values read from the DCS may be numbers or booleans rather than strings,
+	// so they are compared by how they are written
+	for name, value := range parameters {
+		if current, ok := dcsConfig.PostgreSQL.Parameters[name]; ok && fmt.Sprint(current) == value {
+			continue
+		}
+		dcsConfig.PostgreSQL.Parameters[name] = value
+		changed = true
+	}
+
+	return changed
+}
@@ -0,0 +1,68 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	cfg "github.com/crunchydata/postgres-operator/operator/config"
+)
+
+func TestSetParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    map[string]interface{}
+		parameters map[string]string
+		removed    []string
+		expected   map[string]interface{}
+		changed    bool
+	}{
+		{name: "none", current: nil, parameters: nil, removed: nil,
+			expected: map[string]interface{}{}, changed: false},
+		{name: "added", current: map[string]interface{}{"wal_level": "logical"},
+			parameters: map[string]string{"work_mem": "8MB"}, removed: nil,
+			expected: map[string]interface{}{"wal_level": "logical", "work_mem": "8MB"}, changed: true},
+		{name: "changed", current: map[string]interface{}{"work_mem": "4MB"},
+			parameters: map[string]string{"work_mem": "8MB"}, removed: nil,
+			expected: map[string]interface{}{"work_mem": "8MB"}, changed: true},
+		{name: "number unchanged", current: map[string]interface{}{"max_connections": float64(100)},
+			parameters: map[string]string{"max_connections": "100"}, removed: nil,
+			expected: map[string]interface{}{"max_connections": float64(100)}, changed: false},
+		{name: "removed", current: map[string]interface{}{"work_mem": "8MB", "wal_level": "logical"},
+			parameters: map[string]string{}, removed: []string{"work_mem"},
+			expected: map[string]interface{}{"wal_level": "logical"}, changed: true},
+		{name: "removed and set again", current: map[string]interface{}{"work_mem": "8MB"},
+			parameters: map[string]string{"work_mem": "8MB"}, removed: []string{"work_mem"},
+			expected: map[string]interface{}{"work_mem": "8MB"}, changed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dcsConfig := cfg.DCSConfig{}
+			if test.current != nil {
+				dcsConfig.PostgreSQL = &cfg.PostgresDCS{Parameters: test.current}
+			}
+
+			if changed := setParameters(&dcsConfig, test.parameters, test.removed); changed != test.changed {
+				t.Errorf("expected changed to be %t, got %t", test.changed, changed)
+			}
+			if !reflect.DeepEqual(dcsConfig.PostgreSQL.Parameters, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, dcsConfig.PostgreSQL.Parameters)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -50,12 +51,30 @@ type PgbouncerPasswdFields struct {
 type PgbouncerConfFields struct {
 	PG_PRIMARY_SERVICE_NAME string
 	PG_PORT                 string
+	// Databases are the databases that have settings of their own, in order
+	Databases []PgbouncerConfDatabase
+	// Settings are the settings of the "[pgbouncer]" section, in order
+	Settings []PgbouncerConfSetting
+}
+
+// PgbouncerConfDatabase is a database of the "[databases]" section of the
+// pgbouncer.ini file along with the settings of the connections to it
+type PgbouncerConfDatabase struct {
+	Name     string
+	Settings []PgbouncerConfSetting
+}
+
+// PgbouncerConfSetting is a setting of the pgbouncer.ini file
+type PgbouncerConfSetting struct {
+	Name  string
+	Value string
 }
 
 type pgBouncerTemplateFields struct {
 	Name                      string
 	ClusterName               string
 	PGBouncerSecret           string
+	PGBouncerConfKey          string
 	CCPImagePrefix            string
 	CCPImageTag               string
 	Port                      string
@@ -71,9 +90,38 @@ type pgBouncerTemplateFields struct {
 // manages pgBouncer, and follows the format "<clusterName>-pgbouncer"
 const pgBouncerDeploymentFormat = "%s-pgbouncer"
 
+// pgBouncerReadOnlyDeploymentFormat is the name of the Kubernetes Deployment
+// that manages the read-only pgBouncer, which pools connections to the replicas,
+// and follows the format "<clusterName>-pgbouncer-ro"
+const pgBouncerReadOnlyDeploymentFormat = "%s-pgbouncer-ro"
+
+const (
+	// pgBouncerConfKey is the key of the pgBouncer secret that holds the
+	// pgbouncer.ini file of the pgBouncer that is routed to the primary
+	pgBouncerConfKey = "pgbouncer.ini"
+	// pgBouncerReadOnlyConfKey is the key of the pgBouncer secret that holds the
+	// pgbouncer.ini file of the read-only pgBouncer, which is routed to the
+	// replicas
+	pgBouncerReadOnlyConfKey = "pgbouncer-ro.ini"
+)
+
 // ...the default PostgreSQL port
 const pgPort = "5432"
 
+// pgBouncerDefaultSettings are the settings of the "[pgbouncer]" section of the
+// pgbouncer.ini file that are used unless the cluster sets them otherwise
+var pgBouncerDefaultSettings = map[string]string{
+	"default_pool_size":         "20",
+	"ignore_startup_parameters": "extra_float_digits",
+	"max_client_conn":           "100",
+	"max_db_connections":        "0",
+	"min_pool_size":             "0",
+	"pool_mode":                 "session",
+	"query_timeout":             "0",
+	"reserve_pool_size":         "0",
+	"reserve_pool_timeout":      "5",
+}
+
 const (
 	// the path to the pgbouncer uninstallation script script
 	pgBouncerUninstallScript = "/opt/cpm/bin/sql/pgbouncer/pgbouncer-uninstall.sql"
@@ -119,6 +167,15 @@ var (
 	// this command allows one to view the users.txt file secret to determine if
 	// it has propagated
 	cmdViewPgBouncerUsersSecret = []string{"cat", "/pgconf/users.txt"}
+	// cmdViewPgBouncerConf allows one to view the pgbouncer.ini file to determine
+	// if the changes to the secret have propagated
+	cmdViewPgBouncerConf = []string{"cat", "/pgconf/pgbouncer.ini"}
+	// cmdReloadPgBouncer has pgBouncer reload the pgbouncer.ini file through its
+	// administrative console, authenticating as the "pgbouncer" user with the
+	// password that is set in the environment of the container
+	cmdReloadPgBouncer = []string{"sh", "-c",
+		fmt.Sprintf(`PGPASSWORD="${PG_PASSWORD}" psql -h localhost -p %s -U %s -d pgbouncer -c RELOAD`,
+			pgPort, crv1.PGUserPgBouncer)}
 	// sqlUninstallPgBouncer provides the final piece of SQL to uninstall
 	// pgbouncer, which is to remove the user
 	sqlUninstallPgBouncer = fmt.Sprintf(`DROP ROLE "%s";`, crv1.PGUserPgBouncer)
@@ -176,15 +233,22 @@ func AddPgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClient,
 	}
 
 	// next, create the pgBouncer deployment
-	if err := createPgBouncerDeployment(clientset, cluster); err != nil {
+	if err := createPgBouncerDeployment(clientset, cluster, false); err != nil {
 		return err
 	}
 
-	// finally, try to create the pgBouncer service
-	if err := createPgBouncerService(clientset, cluster); err != nil {
+	// then, try to create the pgBouncer service
+	if err := createPgBouncerService(clientset, cluster, false); err != nil {
 		return err
 	}
 
+	// finally, if there is to be a read-only pgBouncer, create it as well
+	if cluster.Spec.PgBouncer.ReadOnlyEnabled() {
+		if err := addPgBouncerReadOnly(clientset, restconfig, cluster); err != nil {
+			return err
+		}
+	}
+
 	log.Debugf("added pgbouncer to cluster [%s]", cluster.Name)
 
 	// publish an event
@@ -232,6 +296,9 @@ func DeletePgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClien
 		log.Warn(err)
 	}
 
+	// then the read-only pgBouncer, if there is one
+	deletePgBouncerReadOnly(clientset, cluster)
+
 	// remove the secret. again, if this fails, just log the error and apss
 	// through
 	secretName := util.GeneratePgBouncerSecretName(clusterName)
@@ -385,18 +452,21 @@ func UninstallPgBouncer(clientset *kubernetes.Clientset, restconfig *rest.Config
 //
 // Any errors that are returned should be logged in the calling function, though
 // some logging occurs in this function as well
-func UpdatePgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClient, oldCluster, newCluster *crv1.Pgcluster) error {
+func UpdatePgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClient, restconfig *rest.Config,
+	oldCluster, newCluster *crv1.Pgcluster) error {
 	clusterName := newCluster.Name
 	namespace := newCluster.Namespace
 
 	log.Debugf("update pgbouncer from cluster [%s] in namespace [%s]", clusterName, namespace)
 
-	// we need to detect what has changed. presently, two "groups" of things could
-	// have changed
+	// we need to detect what has changed. presently, four "groups" of things
+	// could have changed
 	// 1. The # of replicas to maintain
-	// 2. The pgBouncer container resources
+	// 2. The # of read-only replicas to maintain
+	// 3. The pgBouncer settings
+	// 4. The pgBouncer container resources
 	//
-	// As #2 is a bit more destructive, we'll do that last
+	// As #4 is a bit more destructive, we'll do that last
 
 	// check if the replicas differ
 	if oldCluster.Spec.PgBouncer.Replicas != newCluster.Spec.PgBouncer.Replicas {
@@ -405,6 +475,22 @@ func UpdatePgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClien
 		}
 	}
 
+	// check if the read-only replicas differ, which may add or remove the
+	// read-only pgBouncer altogether
+	if oldCluster.Spec.PgBouncer.ReadOnlyReplicas != newCluster.Spec.PgBouncer.ReadOnlyReplicas {
+		if err := updatePgBouncerReadOnly(clientset, restconfig, newCluster); err != nil {
+			return err
+		}
+	}
+
+	// check if the settings differ. These are reloaded without a restart
+	if !reflect.DeepEqual(oldCluster.Spec.PgBouncer.Config, newCluster.Spec.PgBouncer.Config) ||
+		!reflect.DeepEqual(oldCluster.Spec.PgBouncer.Databases, newCluster.Spec.PgBouncer.Databases) {
+		if err := updatePgBouncerConfig(clientset, restconfig, newCluster); err != nil {
+			return err
+		}
+	}
+
 	// check if the resources differ
 	if !reflect.DeepEqual(oldCluster.Spec.PgBouncer.Resources, newCluster.Spec.PgBouncer.Resources) {
 		if err := updatePgBouncerResources(clientset, restclient, newCluster); err != nil {
@@ -419,6 +505,23 @@ func UpdatePgbouncer(clientset *kubernetes.Clientset, restclient *rest.RESTClien
 	return nil
 }
 
+// addPgBouncerReadOnly adds the read-only pgBouncer to a cluster that already
+// has pgBouncer. The pgbouncer.ini file that routes it to the replicas is first
+// added to the pgBouncer secret, should the secret predate it
+func addPgBouncerReadOnly(clientset *kubernetes.Clientset, restconfig *rest.Config, cluster *crv1.Pgcluster) error {
+	log.Debugf("adding a read-only pgbouncer to cluster [%s]", cluster.Name)
+
+	if err := updatePgBouncerConfig(clientset, restconfig, cluster); err != nil {
+		return err
+	}
+
+	if err := createPgBouncerDeployment(clientset, cluster, true); err != nil {
+		return err
+	}
+
+	return createPgBouncerService(clientset, cluster, true)
+}
+
 // checkPgBouncerInstall checks to see if pgBouncer is installed in the
 // PostgreSQL custer, which involves check to see if the pgBouncer role is
 // present in the PostgreSQL cluster
@@ -449,13 +552,21 @@ func checkPgBouncerInstall(clientset *kubernetes.Clientset, restconfig *rest.Con
 	}
 }
 
-// createPgBouncerDeployment creates the Kubernetes Deployment for pgBouncer
-func createPgBouncerDeployment(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) error {
+// createPgBouncerDeployment creates the Kubernetes Deployment for pgBouncer, or
+// for the read-only pgBouncer if readOnly is set
+func createPgBouncerDeployment(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster, readOnly bool) error {
 	log.Debugf("creating pgbouncer deployment: %s", cluster.Name)
 
 	// derive the name of the Deployment...which is also used as the name of the
 	// service
-	pgbouncerDeploymentName := fmt.Sprintf(pgBouncerDeploymentFormat, cluster.Name)
+	pgbouncerDeploymentName := pgBouncerDeploymentName(cluster, readOnly)
+
+	// the read-only pgBouncer reads the pgbouncer.ini file that is routed to the
+	// replicas, and has a number of replicas of its own
+	confKey, replicas := pgBouncerConfKey, cluster.Spec.PgBouncer.Replicas
+	if readOnly {
+		confKey, replicas = pgBouncerReadOnlyConfKey, cluster.Spec.PgBouncer.ReadOnlyReplicas
+	}
 
 	// get the fields that will be substituted in the pgBouncer template
 	fields := pgBouncerTemplateFields{
@@ -465,13 +576,14 @@ func createPgBouncerDeployment(clientset *kubernetes.Clientset, cluster *crv1.Pg
 		CCPImageTag:        cluster.Spec.CCPImageTag,
 		Port:               operator.Pgo.Cluster.Port,
 		PGBouncerSecret:    util.GeneratePgBouncerSecretName(cluster.Name),
+		PGBouncerConfKey:   confKey,
 		ContainerResources: operator.GetResourcesJSON(cluster.Spec.PgBouncer.Resources),
 		PodAntiAffinity: operator.GetPodAntiAffinity(cluster,
 			crv1.PodAntiAffinityDeploymentPgBouncer, cluster.Spec.PodAntiAffinity.PgBouncer),
 		PodAntiAffinityLabelName: config.LABEL_POD_ANTI_AFFINITY,
 		PodAntiAffinityLabelValue: string(operator.GetPodAntiAffinityType(cluster,
 			crv1.PodAntiAffinityDeploymentPgBouncer, cluster.Spec.PodAntiAffinity.PgBouncer)),
-		Replicas: cluster.Spec.PgBouncer.Replicas,
+		Replicas: replicas,
 	}
 
 	// For debugging purposes, put the template substitution in stdout
@@ -522,8 +634,9 @@ func createPgbouncerSecret(clientset *kubernetes.Clientset, cluster *crv1.Pgclus
 	// - the pgbouncer "users.txt" file that contains the credentials for the
 	// "pgbouncer" user
 
-	// first, generate the pgbouncer.ini information
-	pgBouncerConf, err := generatePgBouncerConf(cluster)
+	// first, generate the pgbouncer.ini information, both for the pgBouncer that
+	// is routed to the primary and for the one that is routed to the replicas
+	pgBouncerConfs, err := generatePgBouncerConfs(cluster)
 
 	if err != nil {
 		log.Error(err)
@@ -549,9 +662,10 @@ func createPgbouncerSecret(clientset *kubernetes.Clientset, cluster *crv1.Pgclus
 			},
 		},
 		Data: map[string][]byte{
-			"password":      []byte(password),
-			"pgbouncer.ini": pgBouncerConf,
-			"pg_hba.conf":   pgbouncerHBA,
+			"password":               []byte(password),
+			pgBouncerConfKey:         pgBouncerConfs[pgBouncerConfKey],
+			pgBouncerReadOnlyConfKey: pgBouncerConfs[pgBouncerReadOnlyConfKey],
+			"pg_hba.conf":            pgbouncerHBA,
 			"users.txt": util.GeneratePgBouncerUsersFileBytes(
 				util.GeneratePostgreSQLMD5Password(crv1.PGUserPgBouncer, password)),
 		},
@@ -565,11 +679,12 @@ func createPgbouncerSecret(clientset *kubernetes.Clientset, cluster *crv1.Pgclus
 	return nil
 }
 
-// createPgBouncerService creates the Kubernetes Service for pgBouncer
-func createPgBouncerService(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) error {
+// createPgBouncerService creates the Kubernetes Service for pgBouncer, or for
+// the read-only pgBouncer if readOnly is set
+func createPgBouncerService(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster, readOnly bool) error {
 	// pgBouncerServiceName is the name of the Service of the pgBouncer, which
 	// matches that for the Deploymnt
-	pgBouncerServiceName := fmt.Sprintf(pgBouncerDeploymentFormat, cluster.Name)
+	pgBouncerServiceName := pgBouncerDeploymentName(cluster, readOnly)
 
 	// set up the service template fields
 	fields := ServiceTemplateFields{
@@ -588,6 +703,25 @@ func createPgBouncerService(clientset *kubernetes.Clientset, cluster *crv1.Pgclu
 	return nil
 }
 
+// deletePgBouncerReadOnly deletes the Service and Deployment of the read-only
+// pgBouncer of a cluster, if they exist. As with the rest of the pgBouncer
+// objects, any failures are logged and passed through
+func deletePgBouncerReadOnly(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) {
+	name := pgBouncerDeploymentName(cluster, true)
+
+	if _, found, _ := kubeapi.GetService(clientset, name, cluster.Namespace); found {
+		if err := kubeapi.DeleteService(clientset, name, cluster.Namespace); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	if _, found, _ := kubeapi.GetDeployment(clientset, name, cluster.Namespace); found {
+		if err := kubeapi.DeleteDeployment(clientset, name, cluster.Namespace); err != nil {
+			log.Warn(err)
+		}
+	}
+}
+
 // disablePgBouncer executes codes on the primary PostgreSQL pod in order to
 // disable the "pgbouncer" role from being able to log in. It keeps the
 // artificats that were created during normal pgBouncer operation
@@ -680,8 +814,9 @@ func generatePassword() (string, error) {
 }
 
 // generatePgBouncerConf generates the content that is stored in the secret
-// for the "pgbouncer.ini" file
-func generatePgBouncerConf(cluster *crv1.Pgcluster) ([]byte, error) {
+// for the "pgbouncer.ini" file, which routes the connections to the Service
+// provided
+func generatePgBouncerConf(cluster *crv1.Pgcluster, serviceName string) ([]byte, error) {
 	// first, get the port
 	port := cluster.Spec.Port
 	// if the "port" value is not set, default to the PostgreSQL port.
@@ -691,8 +826,10 @@ func generatePgBouncerConf(cluster *crv1.Pgcluster) ([]byte, error) {
 
 	// set up the substitution fields for the pgbouncer.ini file
 	fields := PgbouncerConfFields{
-		PG_PRIMARY_SERVICE_NAME: cluster.Name,
+		PG_PRIMARY_SERVICE_NAME: serviceName,
 		PG_PORT:                 port,
+		Databases:               pgBouncerConfDatabases(cluster.Spec.PgBouncer.Databases),
+		Settings:                pgBouncerConfSettings(cluster.Spec.PgBouncer.Config),
 	}
 
 	// perform the substitution
@@ -711,6 +848,28 @@ func generatePgBouncerConf(cluster *crv1.Pgcluster) ([]byte, error) {
 	return doc.Bytes(), nil
 }
 
+// generatePgBouncerConfs generates the pgbouncer.ini files of the pgBouncer
+// secret, keyed by the keys of the secret: one routes the connections to the
+// primary and the other, which the read-only pgBouncer reads, to the replicas
+func generatePgBouncerConfs(cluster *crv1.Pgcluster) (map[string][]byte, error) {
+	primaryConf, err := generatePgBouncerConf(cluster, cluster.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	replicaConf, err := generatePgBouncerConf(cluster, cluster.Name+ReplicaSuffix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		pgBouncerConfKey:         primaryConf,
+		pgBouncerReadOnlyConfKey: replicaConf,
+	}, nil
+}
+
 // generatePgBouncerConf generates the pgBouncer host-based authentication file
 // using the template that is vailable
 func generatePgBouncerHBA() ([]byte, error) {
@@ -821,6 +980,63 @@ func installPgBouncer(clientset *kubernetes.Clientset, restconfig *rest.Config,
 	return nil
 }
 
+// pgBouncerConfDatabases returns the databases that have settings of their own,
+// in order, for the "[databases]" section of the pgbouncer.ini file
+func pgBouncerConfDatabases(databases map[string]map[string]string) []PgbouncerConfDatabase {
+	result := []PgbouncerConfDatabase{}
+
+	for name, settings := range databases {
+		result = append(result, PgbouncerConfDatabase{
+			Name:     name,
+			Settings: sortPgBouncerConfSettings(settings),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// pgBouncerConfSettings returns the settings of the "[pgbouncer]" section of the
+// pgbouncer.ini file, in order, which are the defaults of the Operator overridden
+// by the settings of the cluster
+func pgBouncerConfSettings(clusterSettings map[string]string) []PgbouncerConfSetting {
+	settings := map[string]string{}
+
+	for name, value := range pgBouncerDefaultSettings {
+		settings[name] = value
+	}
+
+	for name, value := range clusterSettings {
+		settings[name] = value
+	}
+
+	return sortPgBouncerConfSettings(settings)
+}
+
+// pgBouncerConfsDiffer returns true if the pgbouncer.ini files of the pgBouncer
+// secret differ from those provided
+func pgBouncerConfsDiffer(secret *v1.Secret, pgBouncerConfs map[string][]byte) bool {
+	for key, pgBouncerConf := range pgBouncerConfs {
+		if !bytes.Equal(secret.Data[key], pgBouncerConf) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pgBouncerDeploymentName returns the name of the pgBouncer Deployment of a
+// cluster, which is also the name of its Service, or that of the read-only
+// pgBouncer if readOnly is set
+func pgBouncerDeploymentName(cluster *crv1.Pgcluster, readOnly bool) string {
+	if readOnly {
+		return fmt.Sprintf(pgBouncerReadOnlyDeploymentFormat, cluster.Name)
+	}
+
+	return fmt.Sprintf(pgBouncerDeploymentFormat, cluster.Name)
+}
+
 // publishPgBouncerEvent publishes one of the events on the event stream
 func publishPgBouncerEvent(eventType string, cluster *crv1.Pgcluster) {
 	var event events.EventInterface
@@ -861,6 +1077,51 @@ func publishPgBouncerEvent(eventType string, cluster *crv1.Pgcluster) {
 	}
 }
 
+// reloadPgBouncer waits for the pgbouncer.ini files provided to propagate to
+// each pgBouncer Pod of a cluster and then has pgBouncer reload them, which
+// applies the new settings without interrupting the connections of the clients.
+// The Pods of the read-only pgBouncer are told apart by their Service
+func reloadPgBouncer(clientset *kubernetes.Clientset, restconfig *rest.Config, cluster *crv1.Pgcluster,
+	pgBouncerConfs map[string][]byte) {
+	namespace := cluster.Namespace
+	readOnlyServiceName := pgBouncerDeploymentName(cluster, true)
+	selector := fmt.Sprintf("%s=%s,%s=true", config.LABEL_PG_CLUSTER, cluster.Name,
+		config.LABEL_PGBOUNCER)
+
+	pods, err := kubeapi.GetPods(clientset, selector, namespace)
+
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		// Pods that are not running yet read the new file when they start
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		pgBouncerConf := pgBouncerConfs[pgBouncerConfKey]
+		if pod.Labels[config.LABEL_SERVICE_NAME] == readOnlyServiceName {
+			pgBouncerConf = pgBouncerConfs[pgBouncerReadOnlyConfKey]
+		}
+
+		if !waitForPgBouncerConf(clientset, restconfig, &pod, pgBouncerConf) {
+			log.Warnf("pgbouncer.ini did not propagate to pod %s in time, its settings are applied "+
+				"once pgbouncer is reloaded or the pod is restarted", pod.Name)
+			continue
+		}
+
+		if _, stderr, err := kubeapi.ExecToPodThroughAPI(restconfig, clientset,
+			cmdReloadPgBouncer, "pgbouncer", pod.Name, pod.Namespace, nil); err != nil {
+			log.Errorf("could not reload pgbouncer in pod %s: %s %s", pod.Name, err.Error(), stderr)
+			continue
+		}
+
+		log.Debugf("reloaded pgbouncer in pod %s", pod.Name)
+	}
+}
+
 // setPostgreSQLPassword updates the pgBouncer password in the PostgreSQL
 // cluster by executing into the primary Pod and changing it
 func setPostgreSQLPassword(clientset *kubernetes.Clientset, restconfig *rest.Config, pod *v1.Pod, port, password string) error {
@@ -881,6 +1142,77 @@ func setPostgreSQLPassword(clientset *kubernetes.Clientset, restconfig *rest.Con
 	return nil
 }
 
+// sortPgBouncerConfSettings returns the settings of a map ordered by their names
+func sortPgBouncerConfSettings(settings map[string]string) []PgbouncerConfSetting {
+	result := []PgbouncerConfSetting{}
+
+	for name, value := range settings {
+		result = append(result, PgbouncerConfSetting{Name: name, Value: value})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// updatePgBouncerConfig regenerates the pgbouncer.ini files of the pgBouncer
+// secret from the settings of the cluster and, if they changed, has the running
+// pgBouncer Pods reload them. As the secret takes a while to propagate to the
+// Pods, the reload happens in the background
+func updatePgBouncerConfig(clientset *kubernetes.Clientset, restconfig *rest.Config, cluster *crv1.Pgcluster) error {
+	log.Debugf("update pgbouncer settings of cluster %s", cluster.Name)
+
+	secret, err := kubeapi.GetSecret(clientset, util.GeneratePgBouncerSecretName(cluster.Name), cluster.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	pgBouncerConfs, err := generatePgBouncerConfs(cluster)
+
+	if err != nil {
+		return err
+	}
+
+	// if nothing changed, there is nothing to reload
+	if !pgBouncerConfsDiffer(secret, pgBouncerConfs) {
+		return nil
+	}
+
+	for key, pgBouncerConf := range pgBouncerConfs {
+		secret.Data[key] = pgBouncerConf
+	}
+
+	if err := kubeapi.UpdateSecret(clientset, secret, cluster.Namespace); err != nil {
+		return err
+	}
+
+	go reloadPgBouncer(clientset, restconfig, cluster, pgBouncerConfs)
+
+	return nil
+}
+
+// updatePgBouncerReadOnly adds, removes or scales the read-only pgBouncer of a
+// cluster so that it matches the spec
+func updatePgBouncerReadOnly(clientset *kubernetes.Clientset, restconfig *rest.Config, cluster *crv1.Pgcluster) error {
+	log.Debugf("scale read-only pgbouncer replicas to [%d]", cluster.Spec.PgBouncer.ReadOnlyReplicas)
+
+	deployment, found, _ := kubeapi.GetDeployment(clientset, pgBouncerDeploymentName(cluster, true),
+		cluster.Namespace)
+
+	switch {
+	case !cluster.Spec.PgBouncer.ReadOnlyEnabled():
+		deletePgBouncerReadOnly(clientset, cluster)
+		return nil
+	case !found:
+		return addPgBouncerReadOnly(clientset, restconfig, cluster)
+	}
+
+	deployment.Spec.Replicas = &cluster.Spec.PgBouncer.ReadOnlyReplicas
+
+	return kubeapi.UpdateDeployment(clientset, deployment)
+}
+
 // updatePgBouncerReplicas updates the pgBouncer Deployment with the number
 // of replicas (Pods) that it should run. Presently, this is fairly naive, but
 // as pgBouncer is "semi-stateful" we may want to improve upon this in the
@@ -919,18 +1251,54 @@ func updatePgBouncerResources(clientset *kubernetes.Clientset, restclient *rest.
 		return err
 	}
 
-	// the pgBouncer container is the first one, the resources can be updated
-	// from it
-	deployment.Spec.Template.Spec.Containers[0].Resources.Requests = cluster.Spec.PgBouncer.Resources.DeepCopy()
-	deployment.Spec.Template.Spec.Containers[0].Resources.Limits = cluster.Spec.PgBouncer.Resources.DeepCopy()
-	// delete the memory limit
-	delete(deployment.Spec.Template.Spec.Containers[0].Resources.Limits, v1.ResourceMemory)
+	deployments := []*appsv1.Deployment{deployment}
 
-	// and update the deployment
-	// update the deployment with the new values
-	if err := kubeapi.UpdateDeployment(clientset, deployment); err != nil {
-		return err
+	// the read-only pgBouncer, if there is one, has the same resources
+	if cluster.Spec.PgBouncer.ReadOnlyEnabled() {
+		if readOnly, found, _ := kubeapi.GetDeployment(clientset, pgBouncerDeploymentName(cluster, true),
+			cluster.Namespace); found {
+			deployments = append(deployments, readOnly)
+		}
+	}
+
+	for _, deployment := range deployments {
+		// the pgBouncer container is the first one, the resources can be updated
+		// from it
+		deployment.Spec.Template.Spec.Containers[0].Resources.Requests = cluster.Spec.PgBouncer.Resources.DeepCopy()
+		deployment.Spec.Template.Spec.Containers[0].Resources.Limits = cluster.Spec.PgBouncer.Resources.DeepCopy()
+		// delete the memory limit
+		delete(deployment.Spec.Template.Spec.Containers[0].Resources.Limits, v1.ResourceMemory)
+
+		// and update the deployment
+		// update the deployment with the new values
+		if err := kubeapi.UpdateDeployment(clientset, deployment); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// waitForPgBouncerConf waits for the pgbouncer.ini file of a pgBouncer Pod to
+// match the one provided, and returns false if it does not before the timeout
+func waitForPgBouncerConf(clientset *kubernetes.Clientset, restconfig *rest.Config, pod *v1.Pod,
+	pgBouncerConf []byte) bool {
+	timeout := time.After(pgBouncerSecretPropagationTimeout * time.Second)
+	tick := time.NewTicker(pgBouncerSecretPropagationPeriod * time.Second)
+	defer tick.Stop()
+
+	for {
+		stdout, _, err := kubeapi.ExecToPodThroughAPI(restconfig, clientset,
+			cmdViewPgBouncerConf, "pgbouncer", pod.Name, pod.Namespace, nil)
+
+		if err == nil && stdout == string(pgBouncerConf) {
+			return true
+		}
+
+		select {
+		case <-tick.C:
+		case <-timeout:
+			return false
+		}
+	}
+}
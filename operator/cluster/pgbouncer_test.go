@@ -0,0 +1,102 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPgBouncerConf(t *testing.T) {
+	tmpl, err := template.ParseFiles("../../conf/postgres-operator/pgbouncer.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := crv1.PgBouncerSpec{
+		Config: map[string]string{"pool_mode": "transaction", "max_client_conn": "500"},
+		Databases: map[string]map[string]string{
+			"orders": {"pool_size": "50", "pool_mode": "session"},
+			"events": {"pool_size": "10"},
+		},
+	}
+
+	fields := PgbouncerConfFields{
+		PG_PRIMARY_SERVICE_NAME: "hippo",
+		PG_PORT:                 "5432",
+		Databases:               pgBouncerConfDatabases(spec.Databases),
+		Settings:                pgBouncerConfSettings(spec.Config),
+	}
+
+	doc := bytes.Buffer{}
+	if err := tmpl.Execute(&doc, fields); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := doc.String()
+
+	expected := []string{
+		"[databases]\n" +
+			"events = host=hippo port=5432 auth_user=pgbouncer pool_size=10\n" +
+			"orders = host=hippo port=5432 auth_user=pgbouncer pool_mode=session pool_size=50\n" +
+			"* = host=hippo port=5432 auth_user=pgbouncer\n",
+		// the settings of the cluster take precedence over the defaults
+		"max_client_conn = 500\n",
+		"pool_mode = transaction\n",
+		// the defaults are kept for everything else
+		"default_pool_size = 20\n",
+		"ignore_startup_parameters = extra_float_digits\n",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(conf, e) {
+			t.Errorf("expected pgbouncer.ini to contain %q, got:\n%s", e, conf)
+		}
+	}
+
+	if strings.Contains(conf, "pool_mode = session") || strings.Contains(conf, "max_client_conn = 100") {
+		t.Errorf("expected the defaults to be overridden, got:\n%s", conf)
+	}
+}
+
+func TestPgBouncerConfsDiffer(t *testing.T) {
+	secret := &v1.Secret{Data: map[string][]byte{
+		pgBouncerConfKey: []byte("primary"),
+		"users.txt":      []byte("users"),
+	}}
+
+	if pgBouncerConfsDiffer(secret, map[string][]byte{pgBouncerConfKey: []byte("primary")}) {
+		t.Error("expected no difference")
+	}
+
+	// a secret that predates the read-only pgBouncer lacks its pgbouncer.ini
+	if !pgBouncerConfsDiffer(secret, map[string][]byte{
+		pgBouncerConfKey:         []byte("primary"),
+		pgBouncerReadOnlyConfKey: []byte("replica"),
+	}) {
+		t.Error("expected the missing read-only pgbouncer.ini to differ")
+	}
+
+	if !pgBouncerConfsDiffer(secret, map[string][]byte{pgBouncerConfKey: []byte("changed")}) {
+		t.Error("expected the changed pgbouncer.ini to differ")
+	}
+}
@@ -0,0 +1,520 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/events"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/operator"
+	"github.com/crunchydata/postgres-operator/operator/backrest"
+	"github.com/crunchydata/postgres-operator/operator/pvc"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Drift describes an object of a cluster that did not match what the spec of
+// the pgcluster calls for, and that has been corrected
+type Drift struct {
+	// Kind is the kind of the object, e.g. "Deployment"
+	Kind string
+	// Name is the name of the object
+	Name string
+	// Message describes what did not match
+	Message string
+}
+
+// reconciler gathers the drift corrected while reconciling a cluster
+type reconciler struct {
+	clientset  *kubernetes.Clientset
+	restclient *rest.RESTClient
+	restconfig *rest.Config
+	cluster    *crv1.Pgcluster
+	drift      []Drift
+}
+
+// Reconcile compares the Deployments, Services, ConfigMaps and PVCs of a cluster
+// against those the spec of the pgcluster calls for and converges them, e.g. by
+// recreating a Service that was deleted or by reverting container resources that
+// were edited by hand. It returns the drift that was corrected, which is also
+// published as events.
+//
+// Reconcile is level based: it does not depend on the changes that were made to
+// the pgcluster, so it is safe to call at any time on a cluster that has been
+// initialized. It must not be called while the cluster is being shut down or
+// started, which is why the pgcluster controller calls it from its queue
+func Reconcile(clientset *kubernetes.Clientset, restclient *rest.RESTClient, restconfig *rest.Config,
+	cluster *crv1.Pgcluster) ([]Drift, error) {
+	log.Debugf("reconciling cluster %s", cluster.Name)
+
+	r := &reconciler{
+		clientset:  clientset,
+		restclient: restclient,
+		restconfig: restconfig,
+		cluster:    cluster,
+	}
+
+	// a shutdown cluster has its Deployments scaled to 0, and shutting down or
+	// starting a cluster is left to the pgcluster controller, so there is
+	// nothing to reconcile until the cluster is running
+	if isShutdownOrShuttingDown(cluster) {
+		log.Debugf("cluster %s is shut down or shutting down, not reconciling it", cluster.Name)
+		return nil, nil
+	}
+
+	steps := []func() error{
+		r.reconcileInstances,
+		r.reconcileBackrestRepo,
+		r.reconcilePgBouncer,
+		r.reconcileServices,
+		r.reconcileConfigMaps,
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return r.publish(), err
+		}
+	}
+
+	return r.publish(), nil
+}
+
+// add records drift that is being corrected
+func (r *reconciler) add(kind, name, message string) {
+	log.Infof("cluster %s: correcting %s %s: %s", r.cluster.Name, kind, name, message)
+	r.drift = append(r.drift, Drift{Kind: kind, Name: name, Message: message})
+}
+
+// publish publishes an event for each drift that was corrected and returns the
+// drift
+func (r *reconciler) publish() []Drift {
+	for _, drift := range r.drift {
+		topics := []string{events.EventTopicCluster}
+
+		f := events.EventReconcileClusterFormat{
+			EventHeader: events.EventHeader{
+				Namespace: r.cluster.Namespace,
+				Username:  r.cluster.Spec.UserLabels[config.LABEL_PGOUSER],
+				Topic:     topics,
+				Timestamp: time.Now(),
+				EventType: events.EventReconcileCluster,
+			},
+			Clustername: r.cluster.Name,
+			Kind:        drift.Kind,
+			Name:        drift.Name,
+			Drift:       drift.Message,
+		}
+
+		if err := events.Publish(f); err != nil {
+			log.Error(err.Error())
+		}
+	}
+
+	return r.drift
+}
+
+// reconcileInstances converges the Deployments of the PostgreSQL instances, i.e.
+// the number of Pods, the container resources and the tablespace volumes, along
+// with the PVCs of the replicas
+func (r *reconciler) reconcileInstances() error {
+	// the pgcluster being reconciled may be out of date, so whether the cluster
+	// is being shut down is checked again right before any instance is scaled,
+	// as a shut down cluster has its Deployments scaled to 0
+	current := crv1.Pgcluster{}
+	if _, err := kubeapi.Getpgcluster(r.restclient, &current, r.cluster.Name, r.cluster.Namespace); err != nil {
+		return err
+	}
+
+	if isShutdownOrShuttingDown(r.cluster) || isShutdownOrShuttingDown(&current) {
+		log.Debugf("cluster %s is shut down or shutting down, not reconciling its instances", r.cluster.Name)
+		return nil
+	}
+
+	deployments, err := operator.GetInstanceDeployments(r.clientset, r.cluster)
+	if err != nil {
+		return err
+	}
+
+	resourcesDrifted := false
+	missingTablespaces := map[string]crv1.PgStorageSpec{}
+
+	for _, deployment := range deployments.Items {
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 1 {
+			r.add("Deployment", deployment.Name, "instance should have exactly one Pod")
+			if err := kubeapi.ScaleDeployment(r.clientset, deployment, 1); err != nil {
+				return err
+			}
+		}
+
+		// the "database" container is always the first container
+		if resourcesDiffer(deployment.Spec.Template.Spec.Containers[0].Resources,
+			r.cluster.Spec.Resources) {
+			r.add("Deployment", deployment.Name, "container resources differ from the spec")
+			resourcesDrifted = true
+		}
+
+		for tablespaceName, storageSpec := range r.cluster.Spec.TablespaceMounts {
+			if !hasVolume(deployment, operator.GetTablespaceVolumeName(tablespaceName)) {
+				r.add("Deployment", deployment.Name, fmt.Sprintf("tablespace %s is not mounted", tablespaceName))
+				missingTablespaces[tablespaceName] = storageSpec
+			}
+		}
+	}
+
+	// both of these update every instance, so they are only done once
+	if resourcesDrifted {
+		if err := UpdateResources(r.clientset, r.restconfig, r.cluster); err != nil {
+			return err
+		}
+	}
+
+	if len(missingTablespaces) > 0 {
+		if err := UpdateTablespaces(r.clientset, r.restconfig, r.cluster, missingTablespaces); err != nil {
+			return err
+		}
+	}
+
+	return r.reconcileInstancePVCs(deployments)
+}
+
+// isShutdownOrShuttingDown returns true if a cluster is shut down, is being shut
+// down, or is still starting up after having been shut down, i.e. when its
+// instances are not all expected to have a Pod
+func isShutdownOrShuttingDown(cluster *crv1.Pgcluster) bool {
+	return cluster.Spec.Shutdown || cluster.Status.State == crv1.PgclusterStateShutdown
+}
+
+// reconcileInstancePVCs recreates the PVCs of instances that are missing. As the
+// PVC of an instance cannot be removed while its Pod is running, the instance
+// will be bootstrapped again as a replica of the current primary. The PVCs are
+// only recreated while there is a primary, and never for the primary itself
+func (r *reconciler) reconcileInstancePVCs(deployments *appsv1.DeploymentList) error {
+	primary, err := util.GetPrimaryPod(r.clientset, r.cluster)
+	if err != nil {
+		log.Debugf("not reconciling the PVCs of cluster %s without a primary: %s", r.cluster.Name, err.Error())
+		return nil
+	}
+
+	for _, deployment := range deployments.Items {
+		if deployment.Name == primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] {
+			continue
+		}
+
+		// the original primary Deployment is named after the cluster and uses the
+		// primary storage, whereas replicas use the replica storage
+		dataStorage := r.cluster.Spec.ReplicaStorage
+		if deployment.Name == r.cluster.Name {
+			dataStorage = r.cluster.Spec.PrimaryStorage
+		}
+
+		volumes := map[string]crv1.PgStorageSpec{
+			deployment.Name:          dataStorage,
+			deployment.Name + "-wal": r.cluster.Spec.WALStorage,
+		}
+		for tablespaceName, storageSpec := range r.cluster.Spec.TablespaceMounts {
+			volumes[operator.GetTablespacePVCName(deployment.Name, tablespaceName)] = storageSpec
+		}
+
+		for pvcName, storageSpec := range volumes {
+			if storageSpec.StorageType != "create" && storageSpec.StorageType != "dynamic" {
+				continue
+			}
+
+			if pvc.Exists(r.clientset, pvcName, r.cluster.Namespace) {
+				continue
+			}
+
+			r.add("PersistentVolumeClaim", pvcName, "PVC of the instance does not exist")
+			if _, err := pvc.CreateIfNotExists(r.clientset, storageSpec, pvcName,
+				r.cluster.Name, r.cluster.Namespace); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileBackrestRepo converges the Deployment of the pgBackRest repository.
+// The PVC of the repository is not recreated, as the backups it held cannot be
+// recovered this way
+func (r *reconciler) reconcileBackrestRepo() error {
+	if r.cluster.Labels[config.LABEL_BACKREST] != "true" {
+		return nil
+	}
+
+	name := fmt.Sprintf(util.BackrestRepoDeploymentName, r.cluster.Name)
+
+	deployment, found, _ := kubeapi.GetDeployment(r.clientset, name, r.cluster.Namespace)
+	if !found {
+		r.add("Deployment", name, "pgBackRest repository does not exist")
+		return backrest.CreateRepoDeployment(r.clientset, r.cluster.Namespace, r.cluster, false, 1)
+	}
+
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 1 {
+		r.add("Deployment", name, "pgBackRest repository should have exactly one Pod")
+		if err := kubeapi.ScaleDeployment(r.clientset, *deployment, 1); err != nil {
+			return err
+		}
+	}
+
+	if backrest.RetentionDiffers(deployment, r.cluster) {
+		r.add("Deployment", name, "retention policy differs from the spec")
+		if err := backrest.UpdateRetention(r.clientset, r.cluster); err != nil {
+			return err
+		}
+	}
+
+	if resourcesDiffer(deployment.Spec.Template.Spec.Containers[0].Resources,
+		r.cluster.Spec.BackrestResources) {
+		r.add("Deployment", name, "container resources differ from the spec")
+		return backrest.UpdateResources(r.clientset, r.restconfig, r.cluster)
+	}
+
+	return nil
+}
+
+// reconcilePgBouncer converges the Deployment of pgBouncer, i.e. whether it
+// exists, its number of Pods, its settings and its container resources, as well
+// as the Deployment of the read-only pgBouncer
+func (r *reconciler) reconcilePgBouncer() error {
+	name := fmt.Sprintf(pgBouncerDeploymentFormat, r.cluster.Name)
+
+	deployment, found, _ := kubeapi.GetDeployment(r.clientset, name, r.cluster.Namespace)
+
+	switch {
+	case !r.cluster.Spec.PgBouncer.Enabled() && found:
+		r.add("Deployment", name, "pgBouncer is not enabled")
+		return DeletePgbouncer(r.clientset, r.restclient, r.restconfig, r.cluster)
+	case !r.cluster.Spec.PgBouncer.Enabled():
+		return nil
+	case !found:
+		r.add("Deployment", name, "pgBouncer does not exist")
+		return AddPgbouncer(r.clientset, r.restclient, r.restconfig, r.cluster)
+	}
+
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != r.cluster.Spec.PgBouncer.Replicas {
+		r.add("Deployment", name, fmt.Sprintf("pgBouncer should have %d Pods", r.cluster.Spec.PgBouncer.Replicas))
+		if err := updatePgBouncerReplicas(r.clientset, r.restclient, r.cluster); err != nil {
+			return err
+		}
+	}
+
+	secretName := util.GeneratePgBouncerSecretName(r.cluster.Name)
+	if secret, err := kubeapi.GetSecret(r.clientset, secretName, r.cluster.Namespace); err == nil {
+		if pgBouncerConfs, err := generatePgBouncerConfs(r.cluster); err == nil &&
+			pgBouncerConfsDiffer(secret, pgBouncerConfs) {
+			r.add("Secret", secretName, "pgBouncer settings differ from the spec")
+			if err := updatePgBouncerConfig(r.clientset, r.restconfig, r.cluster); err != nil {
+				return err
+			}
+		}
+	}
+
+	readOnlyName := pgBouncerDeploymentName(r.cluster, true)
+	readOnly, readOnlyFound, _ := kubeapi.GetDeployment(r.clientset, readOnlyName, r.cluster.Namespace)
+
+	switch {
+	case !r.cluster.Spec.PgBouncer.ReadOnlyEnabled() && readOnlyFound:
+		r.add("Deployment", readOnlyName, "read-only pgBouncer is not enabled")
+		deletePgBouncerReadOnly(r.clientset, r.cluster)
+		readOnlyFound = false
+	case r.cluster.Spec.PgBouncer.ReadOnlyEnabled() && !readOnlyFound:
+		r.add("Deployment", readOnlyName, "read-only pgBouncer does not exist")
+		if err := addPgBouncerReadOnly(r.clientset, r.restconfig, r.cluster); err != nil {
+			return err
+		}
+	case readOnlyFound && (readOnly.Spec.Replicas == nil ||
+		*readOnly.Spec.Replicas != r.cluster.Spec.PgBouncer.ReadOnlyReplicas):
+		r.add("Deployment", readOnlyName, fmt.Sprintf("read-only pgBouncer should have %d Pods",
+			r.cluster.Spec.PgBouncer.ReadOnlyReplicas))
+		if err := updatePgBouncerReadOnly(r.clientset, r.restconfig, r.cluster); err != nil {
+			return err
+		}
+	}
+
+	if resourcesDiffer(deployment.Spec.Template.Spec.Containers[0].Resources,
+		r.cluster.Spec.PgBouncer.Resources) {
+		r.add("Deployment", name, "container resources differ from the spec")
+		return updatePgBouncerResources(r.clientset, r.restclient, r.cluster)
+	}
+
+	if readOnlyFound && resourcesDiffer(readOnly.Spec.Template.Spec.Containers[0].Resources,
+		r.cluster.Spec.PgBouncer.Resources) {
+		r.add("Deployment", readOnlyName, "container resources differ from the spec")
+		return updatePgBouncerResources(r.clientset, r.restclient, r.cluster)
+	}
+
+	return nil
+}
+
+// reconcileServices recreates the Services of the cluster that are missing
+func (r *reconciler) reconcileServices() error {
+	if !r.serviceExists(r.cluster.Name) {
+		r.add("Service", r.cluster.Name, "Service of the primary does not exist")
+		if err := addClusterCreateMissingService(r.clientset, r.cluster, r.cluster.Namespace); err != nil {
+			return err
+		}
+	}
+
+	// the replica Service is only needed when there are replicas
+	replicas := crv1.PgreplicaList{}
+	selector := config.LABEL_PG_CLUSTER + "=" + r.cluster.Name
+	if err := kubeapi.GetpgreplicasBySelector(r.restclient, &replicas, selector, r.cluster.Namespace); err != nil {
+		return err
+	}
+
+	if replicaService := r.cluster.Name + ReplicaSuffix; len(replicas.Items) > 0 && !r.serviceExists(replicaService) {
+		r.add("Service", replicaService, "Service of the replicas does not exist")
+		if err := scaleReplicaCreateMissingService(r.clientset, &replicas.Items[0], r.cluster,
+			r.cluster.Namespace); err != nil {
+			return err
+		}
+	}
+
+	if repoService := fmt.Sprintf(util.BackrestRepoServiceName, r.cluster.Name); r.cluster.Labels[config.LABEL_BACKREST] == "true" &&
+		!r.serviceExists(repoService) {
+		r.add("Service", repoService, "Service of the pgBackRest repository does not exist")
+		if err := backrest.CreateRepoService(r.clientset, r.cluster.Namespace, r.cluster); err != nil {
+			return err
+		}
+	}
+
+	if pgBouncerService := fmt.Sprintf(pgBouncerDeploymentFormat, r.cluster.Name); r.cluster.Spec.PgBouncer.Enabled() &&
+		!r.serviceExists(pgBouncerService) {
+		r.add("Service", pgBouncerService, "Service of pgBouncer does not exist")
+		if err := createPgBouncerService(r.clientset, r.cluster, false); err != nil {
+			return err
+		}
+	}
+
+	if readOnlyService := pgBouncerDeploymentName(r.cluster, true); r.cluster.Spec.PgBouncer.ReadOnlyEnabled() &&
+		!r.serviceExists(readOnlyService) {
+		r.add("Service", readOnlyService, "Service of the read-only pgBouncer does not exist")
+		if err := createPgBouncerService(r.clientset, r.cluster, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileConfigMaps recreates the postgres-ha ConfigMap if it is missing, and
+// ensures that the autofail setting held by the Patroni ConfigMap matches the
+// autofail label of the pgcluster
+func (r *reconciler) reconcileConfigMaps() error {
+	name := r.cluster.Name + "-" + operator.PGHAConfigMapSuffix
+
+	if _, found := kubeapi.GetConfigMap(r.clientset, name, r.cluster.Namespace); !found {
+		r.add("ConfigMap", name, "postgres-ha ConfigMap does not exist")
+
+		if err := operator.CreatePGHAConfigMap(r.clientset, r.cluster, r.cluster.Namespace); err != nil {
+			return err
+		}
+
+		// the cluster has already been initialized, which must not happen again
+		configMap, found := kubeapi.GetConfigMap(r.clientset, name, r.cluster.Namespace)
+		if !found {
+			return fmt.Errorf("could not find ConfigMap %s after creating it", name)
+		}
+
+		configMap.Data[operator.PGHAConfigInitSetting] = "false"
+
+		if err := kubeapi.UpdateConfigMap(r.clientset, configMap, r.cluster.Namespace); err != nil {
+			return err
+		}
+	}
+
+	autofail, err := strconv.ParseBool(r.cluster.ObjectMeta.Labels[config.LABEL_AUTOFAIL])
+	if err != nil {
+		// without a valid label there is nothing to converge to
+		return nil
+	}
+
+	scope := r.cluster.ObjectMeta.Labels[config.LABEL_PGHA_SCOPE]
+
+	// the ConfigMap is owned by Patroni, which creates it when it first starts
+	configMap, found := kubeapi.GetConfigMap(r.clientset, scope+"-config", r.cluster.Namespace)
+	if !found || configMap.ObjectMeta.Annotations["config"] == "" {
+		return nil
+	}
+
+	patroniConfig := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configMap.ObjectMeta.Annotations["config"]), &patroniConfig); err != nil {
+		return err
+	}
+
+	if paused := patroniConfig["pause"] == true; paused == autofail {
+		r.add("ConfigMap", configMap.Name, fmt.Sprintf("autofail should be %t", autofail))
+		return util.ToggleAutoFailover(r.clientset, autofail, scope, r.cluster.Namespace)
+	}
+
+	return nil
+}
+
+// serviceExists returns true if the Service exists
+func (r *reconciler) serviceExists(name string) bool {
+	_, found, _ := kubeapi.GetService(r.clientset, name, r.cluster.Namespace)
+	return found
+}
+
+// hasVolume returns true if the Pods of the Deployment have the volume
+func hasVolume(deployment appsv1.Deployment, name string) bool {
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resourcesDiffer returns true if the CPU and memory requests or limits of a
+// container differ from those of the spec. The spec is applied to the requests,
+// while the limits only contain the CPU of the spec, as a memory limit would
+// have the container killed by the OOM killer
+func resourcesDiffer(resources v1.ResourceRequirements, spec v1.ResourceList) bool {
+	limits := v1.ResourceList{}
+	if cpu, ok := spec[v1.ResourceCPU]; ok {
+		limits[v1.ResourceCPU] = cpu
+	}
+
+	return resourceListDiffers(resources.Requests, spec) || resourceListDiffers(resources.Limits, limits)
+}
+
+// resourceListDiffers returns true if the CPU or memory of a resource list
+// differs from the desired one
+func resourceListDiffers(list, desiredList v1.ResourceList) bool {
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		actual, actualOK := list[name]
+		desired, desiredOK := desiredList[name]
+
+		if actualOK != desiredOK || (actualOK && actual.Cmp(desired) != 0) {
+			return true
+		}
+	}
+	return false
+}
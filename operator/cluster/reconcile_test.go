@@ -0,0 +1,151 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourcesDiffer(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources v1.ResourceRequirements
+		spec      v1.ResourceList
+		expected  bool
+	}{
+		{
+			name:      "empty",
+			resources: v1.ResourceRequirements{Requests: v1.ResourceList{}},
+			spec:      nil,
+			expected:  false,
+		},
+		{
+			name: "equal quantities in different units",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			spec:     v1.ResourceList{v1.ResourceMemory: resource.MustParse("1024Mi")},
+			expected: false,
+		},
+		{
+			name: "different memory",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+			},
+			spec:     v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+			expected: true,
+		},
+		{
+			name: "cpu removed from the spec",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			},
+			spec:     v1.ResourceList{},
+			expected: true,
+		},
+		{
+			name:      "cpu added to the spec",
+			resources: v1.ResourceRequirements{},
+			spec:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			expected:  true,
+		},
+		{
+			name: "cpu request and limit match the spec",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("0.5")},
+			},
+			spec:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			expected: false,
+		},
+		{
+			name: "different cpu limit",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			},
+			spec:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			expected: true,
+		},
+		{
+			name: "missing cpu limit",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			},
+			spec:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			expected: true,
+		},
+		{
+			name: "memory limit",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			spec:     v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+			expected: true,
+		},
+		{
+			name: "other resources are ignored",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+				Limits:   v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+			},
+			spec:     v1.ResourceList{},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := resourcesDiffer(test.resources, test.spec); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsShutdownOrShuttingDown(t *testing.T) {
+	tests := []struct {
+		name     string
+		shutdown bool
+		state    crv1.PgclusterState
+		expected bool
+	}{
+		{name: "running", shutdown: false, state: crv1.PgclusterStateInitialized, expected: false},
+		{name: "shutting down", shutdown: true, state: crv1.PgclusterStateInitialized, expected: true},
+		{name: "shut down", shutdown: true, state: crv1.PgclusterStateShutdown, expected: true},
+		{name: "starting up", shutdown: false, state: crv1.PgclusterStateShutdown, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := &crv1.Pgcluster{
+				Spec:   crv1.PgclusterSpec{Shutdown: test.shutdown},
+				Status: crv1.PgclusterStatus{State: test.state},
+			}
+
+			if actual := isShutdownOrShuttingDown(cluster); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
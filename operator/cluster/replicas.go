@@ -0,0 +1,289 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/operator"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// rmdataTaskSuffix is appended to the name of an instance to name the pgtask
+// that removes it, which matches what "pgo scaledown" uses
+const rmdataTaskSuffix = "-rmdata"
+
+// ScaleReplicas creates or removes replicas so that the number of replicas in
+// the cluster matches spec.replicas.
+//
+// Replicas are added by creating pgreplicas. When scaling down, the replicas
+// that are the furthest behind the primary are removed first, i.e. those that
+// are not running, followed by those with the most replication lag. The
+// current primary is never removed.
+func ScaleReplicas(clientset *kubernetes.Clientset, restclient *rest.RESTClient, restconfig *rest.Config,
+	cluster *crv1.Pgcluster) error {
+	desired := 0
+	if cluster.Spec.Replicas != "" {
+		var err error
+		if desired, err = strconv.Atoi(cluster.Spec.Replicas); err != nil || desired < 0 {
+			return fmt.Errorf("invalid number of replicas %q for cluster %s", cluster.Spec.Replicas, cluster.Name)
+		}
+	}
+
+	instances, err := getScalableInstances(clientset, restclient, cluster)
+	if err != nil {
+		return err
+	}
+
+	// one of the instances is the primary
+	current := len(instances) - 1
+
+	log.Debugf("cluster %s has %d replicas, %d requested", cluster.Name, current, desired)
+
+	switch {
+	case desired > current:
+		for i := current; i < desired; i++ {
+			name, err := createPgreplica(restclient, cluster, cluster.Namespace)
+			if err != nil {
+				return err
+			}
+			log.Infof("created pgreplica %s to scale cluster %s to %d replicas", name, cluster.Name, desired)
+		}
+	case desired < current:
+		primary, err := util.GetPrimaryPod(clientset, cluster)
+		if err != nil {
+			return fmt.Errorf("not scaling down cluster %s as its primary could not be determined: %w",
+				cluster.Name, err)
+		}
+
+		replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+			RESTConfig:  restconfig,
+			Clientset:   clientset,
+			Namespace:   cluster.Namespace,
+			ClusterName: cluster.Name,
+			LagBytes:    true,
+		})
+		if err != nil {
+			return fmt.Errorf("not scaling down cluster %s as the replication lag of its replicas "+
+				"could not be determined: %w", cluster.Name, err)
+		}
+
+		replicas := []string{}
+		for _, name := range instances {
+			if name != primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] {
+				replicas = append(replicas, name)
+			}
+		}
+
+		for _, name := range selectReplicasToRemove(replicas, replicationStatus.Instances, current-desired) {
+			if err := createReplicaRMDataTask(restclient, cluster, name); err != nil {
+				return err
+			}
+			log.Infof("removing replica %s to scale cluster %s to %d replicas", name, cluster.Name, desired)
+		}
+	}
+
+	return nil
+}
+
+// ReplicaCount returns the number of replicas of a cluster, including those
+// that are being created but excluding those that are already being removed.
+// It is what spec.replicas is set to when replicas are added or removed
+// directly, e.g. with "pgo scale" and "pgo scaledown", so that ScaleReplicas
+// has nothing to do as a result
+func ReplicaCount(clientset *kubernetes.Clientset, restclient *rest.RESTClient,
+	cluster *crv1.Pgcluster) (int, error) {
+	instances, err := getScalableInstances(clientset, restclient, cluster)
+	if err != nil {
+		return 0, err
+	}
+
+	// one of the instances is the primary
+	if len(instances) == 0 {
+		return 0, nil
+	}
+	return len(instances) - 1, nil
+}
+
+// getScalableInstances returns the names of the instances of the cluster,
+// including those that are being created but excluding those that are already
+// being removed
+func getScalableInstances(clientset *kubernetes.Clientset, restclient *rest.RESTClient,
+	cluster *crv1.Pgcluster) ([]string, error) {
+	deployments, err := operator.GetInstanceDeployments(clientset, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := crv1.PgreplicaList{}
+	selector := config.LABEL_PG_CLUSTER + "=" + cluster.Name
+	if err := kubeapi.GetpgreplicasBySelector(restclient, &replicas, selector, cluster.Namespace); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, deployment := range deployments.Items {
+		names[deployment.Name] = true
+	}
+	for _, replica := range replicas.Items {
+		names[replica.Spec.Name] = true
+	}
+
+	instances := []string{}
+	for name := range names {
+		task := crv1.Pgtask{}
+		if found, _ := kubeapi.Getpgtask(restclient, &task, name+rmdataTaskSuffix, cluster.Namespace); found {
+			continue
+		}
+		instances = append(instances, name)
+	}
+
+	return instances, nil
+}
+
+// selectReplicasToRemove returns up to count of the replicas provided, starting
+// with those that are the furthest behind. Replicas unknown to Patroni or that
+// are not running come first, followed by the others in order of decreasing
+// replication lag in bytes, where a lag that could not be determined counts as
+// the greatest
+func selectReplicasToRemove(replicas []string, status []util.InstanceReplicationInfo, count int) []string {
+	type candidate struct {
+		name    string
+		running bool
+		lag     int64
+	}
+
+	candidates := make([]candidate, len(replicas))
+	for i, name := range replicas {
+		candidates[i] = candidate{name: name}
+
+		for _, instance := range status {
+			if instance.Name == name {
+				candidates[i].running = instance.Status == "running"
+				candidates[i].lag = instance.ReplicationLagBytes
+				if candidates[i].lag == util.ReplicationLagUnknown {
+					candidates[i].lag = math.MaxInt64
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].running != candidates[j].running {
+			return !candidates[i].running
+		}
+		if candidates[i].lag != candidates[j].lag {
+			return candidates[i].lag > candidates[j].lag
+		}
+		return strings.Compare(candidates[i].name, candidates[j].name) < 0
+	})
+
+	selected := []string{}
+	for i := 0; i < count && i < len(candidates); i++ {
+		selected = append(selected, candidates[i].name)
+	}
+
+	return selected
+}
+
+// createPgreplica creates a pgreplica for a new replica of the cluster, and
+// returns its name
+func createPgreplica(client *rest.RESTClient, cluster *crv1.Pgcluster, namespace string) (string, error) {
+	spec := crv1.PgreplicaSpec{}
+	//get the storage config
+	spec.ReplicaStorage = cluster.Spec.ReplicaStorage
+
+	spec.UserLabels = map[string]string{}
+	for k, v := range cluster.Spec.UserLabels {
+		spec.UserLabels[k] = v
+	}
+
+	//the replica should not use the same node labels as the primary
+	spec.UserLabels[config.LABEL_NODE_LABEL_KEY] = ""
+	spec.UserLabels[config.LABEL_NODE_LABEL_VALUE] = ""
+
+	labels := make(map[string]string)
+	labels[config.LABEL_PG_CLUSTER] = cluster.Spec.Name
+	labels[config.LABEL_PGOUSER] = cluster.ObjectMeta.Labels[config.LABEL_PGOUSER]
+	labels[config.LABEL_PG_CLUSTER_IDENTIFIER] = cluster.ObjectMeta.Labels[config.LABEL_PG_CLUSTER_IDENTIFIER]
+
+	spec.ClusterName = cluster.Spec.Name
+	uniqueName := util.RandStringBytesRmndr(4)
+	labels[config.LABEL_NAME] = cluster.Spec.Name + "-" + uniqueName
+	spec.Namespace = namespace
+	spec.Name = labels[config.LABEL_NAME]
+
+	newInstance := &crv1.Pgreplica{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   labels[config.LABEL_NAME],
+			Labels: labels,
+		},
+		Spec: spec,
+		Status: crv1.PgreplicaStatus{
+			State:   crv1.PgreplicaStateCreated,
+			Message: "Created, not processed yet",
+		},
+	}
+
+	if err := kubeapi.Createpgreplica(client, newInstance, namespace); err != nil {
+		return "", err
+	}
+
+	return newInstance.Name, nil
+}
+
+// createReplicaRMDataTask creates the pgtask that removes a replica along with
+// its data, in the same way as "pgo scaledown"
+func createReplicaRMDataTask(client *rest.RESTClient, cluster *crv1.Pgcluster, replicaName string) error {
+	spec := crv1.PgtaskSpec{}
+	spec.Namespace = cluster.Namespace
+	spec.Name = replicaName + rmdataTaskSuffix
+	spec.TaskType = crv1.PgtaskDeleteData
+
+	spec.Parameters = make(map[string]string)
+	spec.Parameters[config.LABEL_DELETE_DATA] = "true"
+	spec.Parameters[config.LABEL_DELETE_BACKUPS] = "false"
+	spec.Parameters[config.LABEL_IS_REPLICA] = "true"
+	spec.Parameters[config.LABEL_IS_BACKUP] = "false"
+	spec.Parameters[config.LABEL_PG_CLUSTER] = cluster.Name
+	spec.Parameters[config.LABEL_REPLICA_NAME] = replicaName
+	spec.Parameters[config.LABEL_PGHA_SCOPE] = cluster.ObjectMeta.Labels[config.LABEL_PGHA_SCOPE]
+
+	task := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: spec.Name,
+			Labels: map[string]string{
+				config.LABEL_PG_CLUSTER: cluster.Name,
+				config.LABEL_RMDATA:     "true",
+			},
+		},
+		Spec: spec,
+	}
+
+	return kubeapi.Createpgtask(client, task, cluster.Namespace)
+}
@@ -0,0 +1,53 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/crunchydata/postgres-operator/util"
+)
+
+func TestSelectReplicasToRemove(t *testing.T) {
+	status := []util.InstanceReplicationInfo{
+		{Name: "hippo-abcd", Status: "running", ReplicationLag: 0, ReplicationLagBytes: 1024},
+		{Name: "hippo-efgh", Status: "running", ReplicationLag: 16, ReplicationLagBytes: 16777216},
+		{Name: "hippo-ijkl", Status: "stopped", ReplicationLag: 0, ReplicationLagBytes: 0},
+		{Name: "hippo-mnop", Status: "running", ReplicationLag: 0, ReplicationLagBytes: 4096},
+		{Name: "hippo-uvwx", Status: "running", ReplicationLagBytes: util.ReplicationLagUnknown},
+	}
+
+	// "hippo-qrst" is not known to Patroni, e.g. as it is still being created
+	replicas := []string{"hippo-abcd", "hippo-efgh", "hippo-ijkl", "hippo-mnop", "hippo-qrst", "hippo-uvwx"}
+
+	tests := []struct {
+		count    int
+		expected []string
+	}{
+		{count: 0, expected: []string{}},
+		{count: 1, expected: []string{"hippo-ijkl"}},
+		{count: 3, expected: []string{"hippo-ijkl", "hippo-qrst", "hippo-uvwx"}},
+		{count: 6, expected: []string{"hippo-ijkl", "hippo-qrst", "hippo-uvwx", "hippo-efgh", "hippo-mnop", "hippo-abcd"}},
+		{count: 9, expected: []string{"hippo-ijkl", "hippo-qrst", "hippo-uvwx", "hippo-efgh", "hippo-mnop", "hippo-abcd"}},
+	}
+
+	for _, test := range tests {
+		if actual := selectReplicasToRemove(replicas, status, test.count); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("count %d: expected %v, got %v", test.count, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,570 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/events"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// the steps of a rolling restart that events are published for
+const (
+	restartStepRestart    = "restart"
+	restartStepSwitchover = "switchover"
+	restartStepCompleted  = "completed"
+)
+
+// restartRequest is the body of a request to the restart endpoint of the
+// Patroni REST API
+type restartRequest struct {
+	// RestartPending only restarts the instance if it is pending a restart
+	RestartPending bool `json:"restart_pending,omitempty"`
+}
+
+// RestartPending restarts the instances of a cluster that Patroni reports as
+// pending a restart, i.e. those with PostgreSQL parameters that only take
+// effect once they are restarted. The replicas are restarted one at a time
+// before the primary, and the restart stops at the first instance that fails to
+// restart. The progress and outcome of the restart are recorded on the pgtask
+func RestartPending(namespace string, clientset *kubernetes.Clientset, client *rest.RESTClient,
+	task *crv1.Pgtask, restconfig *rest.Config) {
+	clusterName := task.Spec.Parameters[config.LABEL_PG_CLUSTER]
+
+	// a restart is only attempted once, as the instances that were restarted are
+	// no longer pending a restart
+	if task.Spec.Parameters[config.LABEL_RESTART_STATUS] != "" {
+		log.Debugf("skipping restart task %s as it was already processed", task.Name)
+		return
+	}
+
+	if !startRestart(client, task, clusterName, namespace) {
+		return
+	}
+
+	log.Infof("restart of pending instances called on [%s]", clusterName)
+
+	selector := fmt.Sprintf("%s=%s,%s", config.LABEL_PG_CLUSTER, clusterName, config.LABEL_PG_DATABASE)
+
+	pods, err := kubeapi.GetPods(clientset, selector, namespace)
+	if err != nil {
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed, err.Error())
+		return
+	}
+
+	pending := []v1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.ObjectMeta.DeletionTimestamp == nil && util.IsPendingRestart(&pod) {
+			pending = append(pending, pod)
+		}
+	}
+
+	if len(pending) == 0 {
+		updateRestartStatus(client, task, crv1.PgtaskRestartCompleted, "no instance is pending a restart")
+		return
+	}
+
+	restarted := []string{}
+
+	for _, pod := range restartOrder(pending) {
+		instance := pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME]
+
+		updateRestartStatus(client, task, crv1.PgtaskRestartInProgress,
+			fmt.Sprintf("restarting instance %s (pod %s)", instance, pod.Name))
+
+		if err := restartInstance(clientset, restconfig, pod.Name, namespace, true); err != nil {
+			updateRestartStatus(client, task, crv1.PgtaskRestartFailed,
+				fmt.Sprintf("could not restart instance %s: %s", instance, err.Error()))
+			return
+		}
+
+		restarted = append(restarted, instance)
+	}
+
+	updateRestartStatus(client, task, crv1.PgtaskRestartCompleted,
+		fmt.Sprintf("restarted instances: %s", strings.Join(restarted, ", ")))
+}
+
+// Restart performs a rolling restart of a cluster, or of a single instance when
+// the pgtask has a target. The replicas are restarted one at a time, each only
+// once the previous one is streaming from the primary again. The primary is
+// then switched over to the replica with the least replication lag, and
+// restarted once it has rejoined the cluster as a replica, so that the cluster
+// only goes through the brief interruption of a switchover. An event is
+// published for each step, and the restart stops at the first step that fails.
+// The progress and outcome of the restart are recorded on the pgtask
+func Restart(namespace string, clientset *kubernetes.Clientset, client *rest.RESTClient, task *crv1.Pgtask,
+	restconfig *rest.Config) {
+	clusterName := task.Spec.Parameters[config.LABEL_PG_CLUSTER]
+	target := task.ObjectMeta.Labels[config.LABEL_TARGET]
+
+	// a restart is only attempted once, as its instances may have already been
+	// restarted
+	if task.Spec.Parameters[config.LABEL_RESTART_STATUS] != "" {
+		log.Debugf("skipping restart task %s as it was already processed", task.Name)
+		return
+	}
+
+	if !startRestart(client, task, clusterName, namespace) {
+		return
+	}
+
+	log.Infof("restart called on [%s] target [%s]", clusterName, target)
+
+	cluster := crv1.Pgcluster{}
+	if _, err := kubeapi.Getpgcluster(client, &cluster, clusterName, namespace); err != nil {
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed, err.Error())
+		return
+	}
+
+	if cluster.Status.State == crv1.PgclusterStateShutdown {
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed,
+			fmt.Sprintf("cluster %s is shut down", clusterName))
+		return
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s", config.LABEL_PG_CLUSTER, clusterName, config.LABEL_PG_DATABASE)
+
+	pods, err := kubeapi.GetPods(clientset, selector, namespace)
+	if err != nil {
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed, err.Error())
+		return
+	}
+
+	replicas, primary, err := selectRestartPods(pods.Items, target)
+	if err != nil {
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed, err.Error())
+		return
+	}
+
+	// fail publishes the failure of a step and records it on the pgtask
+	fail := func(instance, step, message string) {
+		publishRestartEvent(task, clusterName, instance, step, crv1.PgtaskRestartFailed, message)
+		updateRestartStatus(client, task, crv1.PgtaskRestartFailed, message)
+	}
+
+	restarted := []string{}
+
+	for _, pod := range replicas {
+		instance := pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME]
+
+		updateRestartStatus(client, task, crv1.PgtaskRestartInProgress,
+			fmt.Sprintf("restarting replica %s (pod %s)", instance, pod.Name))
+
+		if err := restartInstance(clientset, restconfig, pod.Name, namespace, false); err != nil {
+			fail(instance, restartStepRestart, fmt.Sprintf("could not restart replica %s: %s", instance, err.Error()))
+			return
+		}
+
+		if err := waitForStreaming(clientset, restconfig, namespace, clusterName, instance,
+			config.DefaultRestartStreamingTimeout, config.DefaultRestartStreamingPeriod); err != nil {
+			fail(instance, restartStepRestart, err.Error())
+			return
+		}
+
+		publishRestartEvent(task, clusterName, instance, restartStepRestart, crv1.PgtaskRestartCompleted,
+			fmt.Sprintf("replica %s restarted and streaming", instance))
+		restarted = append(restarted, instance)
+	}
+
+	if primary != nil {
+		instance := primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME]
+
+		updateRestartStatus(client, task, crv1.PgtaskRestartInProgress,
+			fmt.Sprintf("switching over from primary %s (pod %s)", instance, primary.Name))
+
+		candidate, err := restartSwitchover(clientset, client, restconfig, &cluster, primary)
+		if err != nil {
+			fail(instance, restartStepSwitchover, fmt.Sprintf("could not switch over from primary %s: %s",
+				instance, err.Error()))
+			return
+		}
+
+		publishRestartEvent(task, clusterName, instance, restartStepSwitchover, crv1.PgtaskRestartCompleted,
+			fmt.Sprintf("switched over from %s to %s", instance, candidate))
+
+		// the former primary is only restarted once it has rejoined the cluster
+		// as a replica of the new primary
+		updateRestartStatus(client, task, crv1.PgtaskRestartInProgress,
+			fmt.Sprintf("restarting former primary %s (pod %s)", instance, primary.Name))
+
+		if err := waitForStreaming(clientset, restconfig, namespace, clusterName, instance,
+			config.DefaultRestartStreamingTimeout, config.DefaultRestartStreamingPeriod); err != nil {
+			fail(instance, restartStepRestart, err.Error())
+			return
+		}
+
+		if err := restartInstance(clientset, restconfig, primary.Name, namespace, false); err != nil {
+			fail(instance, restartStepRestart, fmt.Sprintf("could not restart former primary %s: %s",
+				instance, err.Error()))
+			return
+		}
+
+		if err := waitForStreaming(clientset, restconfig, namespace, clusterName, instance,
+			config.DefaultRestartStreamingTimeout, config.DefaultRestartStreamingPeriod); err != nil {
+			fail(instance, restartStepRestart, err.Error())
+			return
+		}
+
+		publishRestartEvent(task, clusterName, instance, restartStepRestart, crv1.PgtaskRestartCompleted,
+			fmt.Sprintf("former primary %s restarted and streaming from %s", instance, candidate))
+		restarted = append(restarted, instance)
+	}
+
+	message := fmt.Sprintf("restarted instances: %s", strings.Join(restarted, ", "))
+	publishRestartEvent(task, clusterName, target, restartStepCompleted, crv1.PgtaskRestartCompleted, message)
+	updateRestartStatus(client, task, crv1.PgtaskRestartCompleted, message)
+}
+
+// selectRestartPods returns the Pods of the replicas that a rolling restart
+// restarts, in order, along with the Pod of the primary if it is restarted as
+// well. When there is a target, only the Pod of the target is returned. A
+// Pod that is being deleted is not restarted, and the primary is only restarted
+// when there is a replica to switch over to
+func selectRestartPods(pods []v1.Pod, target string) ([]v1.Pod, *v1.Pod, error) {
+	replicas := []v1.Pod{}
+	var primary *v1.Pod
+
+	for _, pod := range restartOrder(pods) {
+		if pod.ObjectMeta.DeletionTimestamp != nil {
+			continue
+		}
+
+		if pod.ObjectMeta.Labels[config.LABEL_PGHA_ROLE] == config.LABEL_PGHA_ROLE_PRIMARY {
+			pod := pod
+			primary = &pod
+			continue
+		}
+
+		replicas = append(replicas, pod)
+	}
+
+	if primary != nil && len(replicas) == 0 {
+		return nil, nil, fmt.Errorf("the primary %s cannot be restarted without a replica to switch over to",
+			primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME])
+	}
+
+	if target == "" {
+		if primary == nil && len(replicas) == 0 {
+			return nil, nil, errors.New("no instance found to restart")
+		}
+		return replicas, primary, nil
+	}
+
+	if primary != nil && primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] == target {
+		return []v1.Pod{}, primary, nil
+	}
+
+	for _, pod := range replicas {
+		if pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] == target {
+			return []v1.Pod{pod}, nil, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no instance found named %s", target)
+}
+
+// restartSwitchover switches a cluster over from its primary to the running
+// replica with the least replication lag, so that the primary can be restarted
+// as a replica, and returns the name of the new primary
+func restartSwitchover(clientset *kubernetes.Clientset, client *rest.RESTClient, restconfig *rest.Config,
+	cluster *crv1.Pgcluster, primary *v1.Pod) (string, error) {
+	replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+		RESTConfig:  restconfig,
+		Clientset:   clientset,
+		Namespace:   cluster.Namespace,
+		ClusterName: cluster.Name,
+		LagBytes:    true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	candidate, err := SelectSwitchoverCandidate(replicationStatus.Instances, "",
+		config.DefaultSwitchoverMaxLagBytes)
+	if err != nil {
+		return "", err
+	}
+
+	candidatePod, err := util.GetPod(clientset, candidate.Name, cluster.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	code, message, err := switchover(clientset, restconfig, primary.Name, candidatePod.Name, "",
+		primary.Spec.Containers[0].Name, cluster.Namespace)
+	if err != nil {
+		return "", err
+	} else if code != "200" {
+		return "", fmt.Errorf("switchover to %s refused by Patroni (%s): %s", candidate.Name, code, message)
+	}
+
+	// update the pgcluster current-primary to the new primary, in the same way
+	// as a switchover
+	cluster.Spec.UserLabels[config.LABEL_CURRENT_PRIMARY] = candidate.Name
+	if err := util.PatchClusterCRD(client, cluster.Spec.UserLabels, cluster, cluster.Namespace); err != nil {
+		log.Errorf("restart: could not patch pgcluster %s with labels", cluster.Name)
+	}
+
+	return candidate.Name, nil
+}
+
+// waitForStreaming waits until Patroni reports an instance as a running
+// replica, i.e. one that is streaming from the primary, or times out
+func waitForStreaming(clientset *kubernetes.Clientset, restconfig *rest.Config, namespace, clusterName,
+	instance string, timeoutSecs, periodSecs time.Duration) error {
+	timeout := time.After(timeoutSecs * time.Second)
+	ticker := time.NewTicker(periodSecs * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for instance %s to stream from the primary", instance)
+		case <-ticker.C:
+			response, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+				RESTConfig:     restconfig,
+				Clientset:      clientset,
+				Namespace:      namespace,
+				ClusterName:    clusterName,
+				IncludePrimary: true,
+			})
+			// the replication status may be unavailable while the instance
+			// restarts, so it is asked for again
+			if err != nil {
+				log.Debugf("could not get the replication status of cluster %s: %s", clusterName, err.Error())
+				continue
+			}
+
+			if isStreaming(response.Instances, instance) {
+				return nil
+			}
+		}
+	}
+}
+
+// isStreaming returns true if Patroni reports an instance as a running replica
+func isStreaming(instances []util.InstanceReplicationInfo, instance string) bool {
+	for _, info := range instances {
+		if info.Name == instance {
+			return info.Role != config.LABEL_PGHA_ROLE_PRIMARY && info.Status == patroniStateRunning
+		}
+	}
+
+	return false
+}
+
+// publishRestartEvent publishes an event for a step of a rolling restart
+func publishRestartEvent(task *crv1.Pgtask, clusterName, instance, step, status, message string) {
+	topics := make([]string, 1)
+	topics[0] = events.EventTopicCluster
+
+	f := events.EventRestartClusterFormat{
+		EventHeader: events.EventHeader{
+			Namespace: task.ObjectMeta.Namespace,
+			Username:  task.ObjectMeta.Labels[config.LABEL_PGOUSER],
+			Topic:     topics,
+			Timestamp: time.Now(),
+			EventType: events.EventRestartCluster,
+		},
+		Clustername: clusterName,
+		Instance:    instance,
+		Step:        step,
+		Status:      status,
+		Message:     message,
+	}
+
+	if err := events.Publish(f); err != nil {
+		log.Error(err)
+	}
+}
+
+// restartOrder returns the Pods of the instances of a cluster in the order
+// they are restarted, i.e. the replicas in the order of their names followed by
+// the primary, so that the primary is only restarted once its replicas are
+// running with the same settings
+func restartOrder(pods []v1.Pod) []v1.Pod {
+	ordered := make([]v1.Pod, len(pods))
+	copy(ordered, pods)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		iPrimary := ordered[i].ObjectMeta.Labels[config.LABEL_PGHA_ROLE] == config.LABEL_PGHA_ROLE_PRIMARY
+		jPrimary := ordered[j].ObjectMeta.Labels[config.LABEL_PGHA_ROLE] == config.LABEL_PGHA_ROLE_PRIMARY
+		if iPrimary != jPrimary {
+			return jPrimary
+		}
+		return ordered[i].ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] <
+			ordered[j].ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME]
+	})
+
+	return ordered
+}
+
+// restartInstance restarts the instance of a Pod through Patroni, and returns
+// an error if the restart fails or is refused
+func restartInstance(clientset *kubernetes.Clientset, restconfig *rest.Config, podName, namespace string,
+	pendingOnly bool) error {
+	code, message, err := restart(clientset, restconfig, podName, namespace, pendingOnly)
+	if err != nil {
+		return err
+	} else if code != "200" {
+		return fmt.Errorf("restart refused by Patroni (%s): %s", code, message)
+	}
+
+	return nil
+}
+
+// restart sends the restart request to the Patroni REST API from within the
+// Pod of an instance, and returns the HTTP status code and message of the
+// response. Patroni only responds once PostgreSQL is running again
+func restart(clientset *kubernetes.Clientset, restconfig *rest.Config, podName, namespace string,
+	pendingOnly bool) (string, string, error) {
+	body, err := json.Marshal(restartRequest{RestartPending: pendingOnly})
+	if err != nil {
+		return "", "", err
+	}
+
+	// the status code is written on a line of its own after the response
+	command := []string{"/bin/bash", "-c", fmt.Sprintf("curl -s -w '\\n%%{http_code}' "+
+		"http://127.0.0.1:%s/restart -XPOST -d '%s'", config.DEFAULT_PATRONI_PORT, body)}
+
+	log.Debugf("running Exec command '%s' with namespace=[%s] podname=[%s]", command, namespace, podName)
+	stdout, stderr, err := kubeapi.ExecToPodThroughAPI(restconfig, clientset, command,
+		"database", podName, namespace, nil)
+	log.Debugf("stdout=[%s] stderr=[%s]", stdout, stderr)
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout = strings.TrimSpace(stdout)
+	i := strings.LastIndex(stdout, "\n")
+
+	return stdout[i+1:], strings.TrimSpace(stdout[:i+1]), nil
+}
+
+// ActiveRestartTask returns the name of the restart pgtask of a cluster that
+// has yet to be processed or is still in progress, if there is one. Only one
+// restart of a cluster, whether of all of its instances or of those pending a
+// restart, is run at a time
+func ActiveRestartTask(client *rest.RESTClient, clusterName, namespace string) (string, error) {
+	for _, name := range restartTaskNames(clusterName) {
+		task := crv1.Pgtask{}
+		found, err := kubeapi.Getpgtask(client, &task, name, namespace)
+		if !found {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		switch task.Spec.Parameters[config.LABEL_RESTART_STATUS] {
+		case "":
+			return name, nil
+		case crv1.PgtaskRestartInProgress:
+			if !isRestartStale(&task, time.Now()) {
+				return name, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// restartTaskNames returns the names of the pgtasks that restart a cluster
+func restartTaskNames(clusterName string) []string {
+	return []string{
+		clusterName + "-" + crv1.PgtaskRestart,
+		clusterName + "-" + crv1.PgtaskRestartPending,
+	}
+}
+
+// startRestart marks a restart pgtask as in progress, and returns false if
+// another restart of the cluster is already in progress, in which case the
+// pgtask is marked as failed. As the pgtask is marked before the other restart
+// tasks are checked, two restarts that start at the same time cannot both
+// proceed
+func startRestart(client *rest.RESTClient, task *crv1.Pgtask, clusterName, namespace string) bool {
+	updateRestartStatus(client, task, crv1.PgtaskRestartInProgress, "starting restart")
+
+	for _, name := range restartTaskNames(clusterName) {
+		if name == task.Name {
+			continue
+		}
+
+		other := crv1.Pgtask{}
+		if found, _ := kubeapi.Getpgtask(client, &other, name, namespace); !found {
+			continue
+		}
+
+		if other.Spec.Parameters[config.LABEL_RESTART_STATUS] == crv1.PgtaskRestartInProgress &&
+			!isRestartStale(&other, time.Now()) {
+			updateRestartStatus(client, task, crv1.PgtaskRestartFailed,
+				fmt.Sprintf("another restart of cluster %s is already in progress (pgtask %s)", clusterName, name))
+			return false
+		}
+	}
+
+	return true
+}
+
+// isRestartStale returns true if the progress of an in progress restart has not
+// been recorded on its pgtask for DefaultRestartStaleTimeout, in which case the
+// restart is no longer running, e.g. as the Operator was restarted, and does not
+// prevent another restart of the cluster
+func isRestartStale(task *crv1.Pgtask, now time.Time) bool {
+	updated, err := time.Parse(time.RFC3339, task.Spec.Parameters[config.LABEL_RESTART_UPDATED])
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(updated) > config.DefaultRestartStaleTimeout*time.Second
+}
+
+// updateRestartStatus records the state of a restart on its pgtask along with
+// a message describing it and the time it was recorded
+func updateRestartStatus(client *rest.RESTClient, task *crv1.Pgtask, status, message string) {
+	log.Debugf("updateRestartStatus taskName=[%s] status=[%s] message=[%s]", task.Name, status, message)
+
+	if status == crv1.PgtaskRestartFailed {
+		log.Errorf("restart %s failed: %s", task.Name, message)
+	}
+
+	if _, err := kubeapi.Getpgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+		return
+	}
+
+	task.Spec.Parameters[config.LABEL_RESTART_STATUS] = status
+	task.Spec.Parameters[config.LABEL_RESTART_UPDATED] = time.Now().UTC().Format(time.RFC3339)
+	task.Status.Message = message
+
+	if err := kubeapi.Updatepgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,164 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// restartPod returns the Pod of an instance with the given role
+func restartPod(instance, role string) v1.Pod {
+	return v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: instance + "-pod",
+		Labels: map[string]string{
+			config.LABEL_DEPLOYMENT_NAME: instance,
+			config.LABEL_PGHA_ROLE:       role,
+		},
+	}}
+}
+
+func TestRestartOrder(t *testing.T) {
+	pods := []v1.Pod{
+		restartPod("hippo-efgh", config.LABEL_PGHA_ROLE_REPLICA),
+		restartPod("hippo", config.LABEL_PGHA_ROLE_PRIMARY),
+		restartPod("hippo-abcd", config.LABEL_PGHA_ROLE_REPLICA),
+	}
+
+	ordered := []string{}
+	for _, pod := range restartOrder(pods) {
+		ordered = append(ordered, pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME])
+	}
+
+	expected := []string{"hippo-abcd", "hippo-efgh", "hippo"}
+	if !reflect.DeepEqual(ordered, expected) {
+		t.Errorf("expected %v, got %v", expected, ordered)
+	}
+
+	// the Pods are left as they are
+	if pods[0].ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME] != "hippo-efgh" {
+		t.Errorf("expected the Pods not to be reordered in place")
+	}
+}
+
+func TestSelectRestartPods(t *testing.T) {
+	deleted := restartPod("hippo-ijkl", config.LABEL_PGHA_ROLE_REPLICA)
+	deleted.ObjectMeta.DeletionTimestamp = &metav1.Time{}
+
+	pods := []v1.Pod{
+		restartPod("hippo-efgh", config.LABEL_PGHA_ROLE_REPLICA),
+		restartPod("hippo", config.LABEL_PGHA_ROLE_PRIMARY),
+		deleted,
+		restartPod("hippo-abcd", config.LABEL_PGHA_ROLE_REPLICA),
+	}
+
+	tests := []struct {
+		name     string
+		pods     []v1.Pod
+		target   string
+		replicas []string
+		primary  string
+		err      bool
+	}{
+		{name: "cluster", pods: pods, target: "",
+			replicas: []string{"hippo-abcd", "hippo-efgh"}, primary: "hippo"},
+		{name: "replica", pods: pods, target: "hippo-efgh",
+			replicas: []string{"hippo-efgh"}, primary: ""},
+		{name: "primary", pods: pods, target: "hippo",
+			replicas: []string{}, primary: "hippo"},
+		{name: "deleted", pods: pods, target: "hippo-ijkl", err: true},
+		{name: "unknown", pods: pods, target: "hippo-mnop", err: true},
+		{name: "no replicas", pods: pods[1:2], target: "", err: true},
+		{name: "no pods", pods: []v1.Pod{}, target: "", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replicas, primary, err := selectRestartPods(test.pods, test.target)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %q", err.Error())
+			}
+
+			names := []string{}
+			for _, pod := range replicas {
+				names = append(names, pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME])
+			}
+			if !reflect.DeepEqual(names, test.replicas) {
+				t.Errorf("expected replicas %v, got %v", test.replicas, names)
+			}
+
+			name := ""
+			if primary != nil {
+				name = primary.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME]
+			}
+			if name != test.primary {
+				t.Errorf("expected primary %q, got %q", test.primary, name)
+			}
+		})
+	}
+}
+
+func TestIsStreaming(t *testing.T) {
+	instances := []util.InstanceReplicationInfo{
+		{Name: "hippo", Role: config.LABEL_PGHA_ROLE_PRIMARY, Status: "running"},
+		{Name: "hippo-abcd", Role: config.LABEL_PGHA_ROLE_REPLICA, Status: "running"},
+		{Name: "hippo-efgh", Role: config.LABEL_PGHA_ROLE_REPLICA, Status: "starting"},
+	}
+
+	for instance, expected := range map[string]bool{
+		"hippo":      false,
+		"hippo-abcd": true,
+		"hippo-efgh": false,
+		"hippo-ijkl": false,
+	} {
+		if streaming := isStreaming(instances, instance); streaming != expected {
+			t.Errorf("expected %s streaming to be %t, got %t", instance, expected, streaming)
+		}
+	}
+}
+
+func TestIsRestartStale(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	for updated, expected := range map[string]bool{
+		"":                     true,
+		"2020-04-01T11:59:00Z": false,
+		"2020-04-01T11:00:00Z": false,
+		"2020-04-01T10:59:59Z": true,
+	} {
+		task := &crv1.Pgtask{Spec: crv1.PgtaskSpec{
+			Parameters: map[string]string{config.LABEL_RESTART_UPDATED: updated},
+		}}
+
+		if stale := isRestartStale(task, now); stale != expected {
+			t.Errorf("expected restart updated at %q to be stale %t, got %t", updated, expected, stale)
+		}
+	}
+}
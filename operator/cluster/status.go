@@ -0,0 +1,400 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// instanceRolePrimary is the role reported for the primary in the status of
+	// a pgcluster
+	instanceRolePrimary = "primary"
+	// instanceRoleReplica is the role reported for a replica in the status of a
+	// pgcluster
+	instanceRoleReplica = "replica"
+	// patroniStateRunning is the state Patroni reports for a running instance,
+	// which for a replica means it is streaming from the primary
+	patroniStateRunning = "running"
+)
+
+// UpdateStatus refreshes the conditions, instances, primary and last backup
+// time in the status of a pgcluster from the Pods, Deployments and Jobs of the
+// cluster and writes the result through the status subresource.
+//
+// The observed generation of the status is moved forward to observedGeneration
+// if it is newer, which allows callers that have not acted on the spec of the
+// pgcluster to pass 0
+func UpdateStatus(clientset *kubernetes.Clientset, restclient *rest.RESTClient, restconfig *rest.Config,
+	cluster *crv1.Pgcluster, observedGeneration int64) error {
+	log.Debugf("updating status of cluster %s", cluster.Name)
+
+	status := cluster.Status.DeepCopy()
+
+	if observedGeneration > status.ObservedGeneration {
+		status.ObservedGeneration = observedGeneration
+	}
+
+	if err := setInstanceStatus(clientset, restconfig, cluster, status); err != nil {
+		return err
+	}
+
+	if err := setBackupStatus(clientset, cluster, status); err != nil {
+		return err
+	}
+
+	if err := setPgBouncerStatus(clientset, cluster, status); err != nil {
+		return err
+	}
+
+	setReadyStatus(cluster, status)
+
+	return kubeapi.UpdatepgclusterStatus(restclient, cluster, *status, cluster.Namespace)
+}
+
+// setInstanceStatus sets the instances and the primary of the status, along
+// with the PrimaryAvailable and ReplicasStreaming conditions
+func setInstanceStatus(clientset *kubernetes.Clientset, restconfig *rest.Config,
+	cluster *crv1.Pgcluster, status *crv1.PgclusterStatus) error {
+	selector := fmt.Sprintf("%s=%s,%s", config.LABEL_PG_CLUSTER, cluster.Name, config.LABEL_PG_DATABASE)
+
+	pods, err := kubeapi.GetPods(clientset, selector, cluster.Namespace)
+	if err != nil {
+		return err
+	}
+
+	instances := []crv1.PgclusterInstanceStatus{}
+	anyReady := false
+
+	for _, pod := range pods.Items {
+		// a Pod that is being deleted, e.g. during a rolling update, no longer
+		// represents its instance
+		if pod.ObjectMeta.DeletionTimestamp != nil {
+			continue
+		}
+
+		instance := crv1.PgclusterInstanceStatus{
+			Name:  pod.ObjectMeta.Labels[config.LABEL_DEPLOYMENT_NAME],
+			Pod:   pod.Name,
+			Role:  instanceRoleReplica,
+			Ready: isPodReady(&pod),
+			// Patroni flags the instances that need a restart on their Pods
+			PendingRestart: util.IsPendingRestart(&pod),
+		}
+
+		if pod.ObjectMeta.Labels[config.LABEL_PGHA_ROLE] == config.LABEL_PGHA_ROLE_PRIMARY {
+			instance.Role = instanceRolePrimary
+		}
+
+		anyReady = anyReady || instance.Ready
+		instances = append(instances, instance)
+	}
+
+	// Patroni knows the timeline and the lag of each instance, as well as
+	// whether or not each replica is streaming. It can only be asked from within
+	// a running instance
+	streaming := map[string]bool{}
+	replicationKnown := false
+
+	if anyReady {
+		request := util.ReplicationStatusRequest{
+			RESTConfig:     restconfig,
+			Clientset:      clientset,
+			Namespace:      cluster.Namespace,
+			ClusterName:    cluster.Name,
+			IncludePrimary: true,
+		}
+
+		if response, err := util.ReplicationStatus(request); err != nil {
+			log.Warnf("could not get the replication status of cluster %s: %s", cluster.Name, err.Error())
+		} else {
+			replicationKnown = true
+
+			for _, info := range response.Instances {
+				for i := range instances {
+					if instances[i].Name != info.Name {
+						continue
+					}
+
+					instances[i].Timeline = info.Timeline
+					instances[i].ReplicationLagMB = info.ReplicationLag
+
+					if info.Role == config.LABEL_PGHA_ROLE_PRIMARY {
+						instances[i].Role = instanceRolePrimary
+					}
+
+					streaming[info.Name] = info.Status == patroniStateRunning
+				}
+			}
+		}
+	}
+
+	// keep the order stable so that the status only changes when an instance
+	// does
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Name < instances[j].Name })
+
+	status.Instances = instances
+	status.Primary = ""
+
+	replicas := 0
+	notStreaming := []string{}
+
+	for _, instance := range instances {
+		if instance.Role == instanceRolePrimary {
+			status.Primary = instance.Name
+			continue
+		}
+
+		replicas++
+
+		if !instance.Ready || !streaming[instance.Name] {
+			notStreaming = append(notStreaming, instance.Name)
+		}
+	}
+
+	// determine if the primary is available
+	primary := crv1.PgclusterCondition{Type: crv1.PgclusterConditionPrimaryAvailable}
+
+	switch {
+	case status.State == crv1.PgclusterStateShutdown:
+		primary.Status, primary.Reason = v1.ConditionFalse, "Shutdown"
+		primary.Message = "the cluster is shut down"
+	case status.Primary == "":
+		primary.Status, primary.Reason = v1.ConditionFalse, "NoPrimary"
+		primary.Message = "no instance is the primary"
+	case !instanceReady(instances, status.Primary):
+		primary.Status, primary.Reason = v1.ConditionFalse, "PrimaryNotReady"
+		primary.Message = fmt.Sprintf("primary %s is not ready", status.Primary)
+	default:
+		primary.Status, primary.Reason = v1.ConditionTrue, "PrimaryReady"
+		primary.Message = fmt.Sprintf("primary %s is ready", status.Primary)
+	}
+
+	status.SetCondition(primary)
+
+	// determine if all of the replicas are streaming
+	streamingCondition := crv1.PgclusterCondition{Type: crv1.PgclusterConditionReplicasStreaming}
+
+	switch {
+	case replicas == 0:
+		streamingCondition.Status, streamingCondition.Reason = v1.ConditionTrue, "NoReplicas"
+		streamingCondition.Message = "the cluster has no replicas"
+	case !replicationKnown:
+		streamingCondition.Status, streamingCondition.Reason = v1.ConditionUnknown, "ReplicationStatusUnavailable"
+		streamingCondition.Message = "the replication status could not be retrieved from Patroni"
+	case len(notStreaming) > 0:
+		streamingCondition.Status, streamingCondition.Reason = v1.ConditionFalse, "ReplicasNotStreaming"
+		streamingCondition.Message = fmt.Sprintf("replicas not streaming: %s", strings.Join(notStreaming, ", "))
+	default:
+		streamingCondition.Status, streamingCondition.Reason = v1.ConditionTrue, "ReplicasStreaming"
+		streamingCondition.Message = fmt.Sprintf("%d of %d replicas streaming", replicas, replicas)
+	}
+
+	status.SetCondition(streamingCondition)
+
+	return nil
+}
+
+// setBackupStatus sets the last backup time of the status and the
+// BackupsHealthy condition, which reflects the outcome of the most recent
+// pgBackRest backup Job that finished
+func setBackupStatus(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster, status *crv1.PgclusterStatus) error {
+	selector := fmt.Sprintf("%s=%s,%s=%s", config.LABEL_PG_CLUSTER, cluster.Name,
+		config.LABEL_BACKREST_COMMAND, crv1.PgtaskBackrestBackup)
+
+	jobs, err := kubeapi.GetJobs(clientset, selector, cluster.Namespace)
+	if err != nil {
+		return err
+	}
+
+	var latest *batchv1.Job
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		succeeded := job.Status.Succeeded > 0
+		if !succeeded && !isJobFailed(job) {
+			continue
+		}
+
+		if succeeded && job.Status.CompletionTime != nil &&
+			(status.LastBackupTime == nil || status.LastBackupTime.Before(job.Status.CompletionTime)) {
+			status.LastBackupTime = job.Status.CompletionTime.DeepCopy()
+		}
+
+		if latest == nil || latest.CreationTimestamp.Before(&job.CreationTimestamp) {
+			latest = job
+		}
+	}
+
+	condition := crv1.PgclusterCondition{Type: crv1.PgclusterConditionBackupsHealthy}
+
+	switch {
+	case latest == nil && status.LastBackupTime == nil:
+		condition.Status, condition.Reason = v1.ConditionUnknown, "NoBackups"
+		condition.Message = "no backup has finished yet"
+	case latest == nil:
+		// the Jobs of earlier backups have been removed, so the last known
+		// outcome stands
+		return nil
+	case latest.Status.Succeeded > 0:
+		condition.Status, condition.Reason = v1.ConditionTrue, "BackupSucceeded"
+		condition.Message = fmt.Sprintf("backup %s succeeded", latest.Name)
+	default:
+		condition.Status, condition.Reason = v1.ConditionFalse, "BackupFailed"
+		condition.Message = fmt.Sprintf("backup %s failed", latest.Name)
+	}
+
+	status.SetCondition(condition)
+
+	return nil
+}
+
+// setPgBouncerStatus sets the PgBouncerReady condition of the status
+func setPgBouncerStatus(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster, status *crv1.PgclusterStatus) error {
+	condition := crv1.PgclusterCondition{Type: crv1.PgclusterConditionPgBouncerReady}
+
+	if !cluster.Spec.PgBouncer.Enabled() {
+		condition.Status, condition.Reason = v1.ConditionFalse, "NotEnabled"
+		condition.Message = "pgBouncer is not enabled"
+		status.SetCondition(condition)
+		return nil
+	}
+
+	name := fmt.Sprintf(pgBouncerDeploymentFormat, cluster.Name)
+
+	deployment, found, err := kubeapi.GetDeployment(clientset, name, cluster.Namespace)
+
+	switch {
+	case kerrors.IsNotFound(err):
+		condition.Status, condition.Reason = v1.ConditionFalse, "DeploymentNotFound"
+		condition.Message = fmt.Sprintf("pgBouncer deployment %s does not exist", name)
+	case !found:
+		return err
+	case deployment.Status.ReadyReplicas < cluster.Spec.PgBouncer.Replicas:
+		condition.Status, condition.Reason = v1.ConditionFalse, "PodsNotReady"
+		condition.Message = fmt.Sprintf("%d of %d pgBouncer pods ready",
+			deployment.Status.ReadyReplicas, cluster.Spec.PgBouncer.Replicas)
+	default:
+		condition.Status, condition.Reason = v1.ConditionTrue, "PodsReady"
+		condition.Message = fmt.Sprintf("%d of %d pgBouncer pods ready",
+			deployment.Status.ReadyReplicas, cluster.Spec.PgBouncer.Replicas)
+	}
+
+	// the read-only pgBouncer, if there is one, has to be ready as well
+	if condition.Status == v1.ConditionTrue && cluster.Spec.PgBouncer.ReadOnlyEnabled() {
+		name := pgBouncerDeploymentName(cluster, true)
+
+		readOnly, found, err := kubeapi.GetDeployment(clientset, name, cluster.Namespace)
+
+		switch {
+		case kerrors.IsNotFound(err):
+			condition.Status, condition.Reason = v1.ConditionFalse, "DeploymentNotFound"
+			condition.Message = fmt.Sprintf("read-only pgBouncer deployment %s does not exist", name)
+		case !found:
+			return err
+		case readOnly.Status.ReadyReplicas < cluster.Spec.PgBouncer.ReadOnlyReplicas:
+			condition.Status, condition.Reason = v1.ConditionFalse, "PodsNotReady"
+			condition.Message = fmt.Sprintf("%d of %d read-only pgBouncer pods ready",
+				readOnly.Status.ReadyReplicas, cluster.Spec.PgBouncer.ReadOnlyReplicas)
+		default:
+			condition.Message += fmt.Sprintf(", %d of %d read-only pgBouncer pods ready",
+				readOnly.Status.ReadyReplicas, cluster.Spec.PgBouncer.ReadOnlyReplicas)
+		}
+	}
+
+	status.SetCondition(condition)
+
+	return nil
+}
+
+// setReadyStatus sets the Ready condition of the status from the other
+// conditions
+func setReadyStatus(cluster *crv1.Pgcluster, status *crv1.PgclusterStatus) {
+	condition := crv1.PgclusterCondition{
+		Type:   crv1.PgclusterConditionReady,
+		Status: v1.ConditionTrue,
+		Reason: "ClusterReady",
+	}
+
+	notReady := []string{}
+
+	for _, instance := range status.Instances {
+		if !instance.Ready {
+			notReady = append(notReady, instance.Name)
+		}
+	}
+
+	switch {
+	case !status.IsConditionTrue(crv1.PgclusterConditionPrimaryAvailable):
+		condition.Status, condition.Reason = v1.ConditionFalse, "PrimaryNotAvailable"
+		condition.Message = status.GetCondition(crv1.PgclusterConditionPrimaryAvailable).Message
+	case len(notReady) > 0:
+		condition.Status, condition.Reason = v1.ConditionFalse, "InstancesNotReady"
+		condition.Message = fmt.Sprintf("instances not ready: %s", strings.Join(notReady, ", "))
+	case cluster.Spec.PgBouncer.Enabled() && !status.IsConditionTrue(crv1.PgclusterConditionPgBouncerReady):
+		condition.Status, condition.Reason = v1.ConditionFalse, "PgBouncerNotReady"
+		condition.Message = status.GetCondition(crv1.PgclusterConditionPgBouncerReady).Message
+	default:
+		condition.Message = "the cluster is ready"
+	}
+
+	status.SetCondition(condition)
+}
+
+// instanceReady returns true if the instance with the given name is ready
+func instanceReady(instances []crv1.PgclusterInstanceStatus, name string) bool {
+	for _, instance := range instances {
+		if instance.Name == name {
+			return instance.Ready
+		}
+	}
+	return false
+}
+
+// isJobFailed returns true if the Job has failed
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodReady returns true if the Pod has the Ready condition
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,372 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// switchoverLagCheckLead is how long before the scheduled time of a switchover
+// the replication lag of its candidate is checked again
+const switchoverLagCheckLead = 30 * time.Second
+
+// switchoverRequest is the body of a request to the switchover endpoint of the
+// Patroni REST API
+type switchoverRequest struct {
+	Leader      string `json:"leader"`
+	Candidate   string `json:"candidate"`
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+}
+
+// Switchover performs a graceful switchover of a cluster, i.e. the primary is
+// demoted by Patroni and the candidate is only promoted once it has caught up
+// with it. The replication lag of the candidate is checked right before acting,
+// and the progress and outcome of the switchover are recorded on the pgtask
+func Switchover(namespace string, clientset *kubernetes.Clientset, client *rest.RESTClient, task *crv1.Pgtask,
+	restconfig *rest.Config) {
+	clusterName := task.Spec.Parameters[config.LABEL_PG_CLUSTER]
+	target := task.ObjectMeta.Labels[config.LABEL_TARGET]
+	scheduledAt := task.Spec.Parameters[config.LABEL_SWITCHOVER_SCHEDULED_AT]
+
+	// a switchover is only attempted once, as Patroni may have already acted on
+	// it
+	if task.Spec.Parameters[config.LABEL_SWITCHOVER_STATUS] != "" {
+		log.Debugf("skipping switchover task %s as it was already processed", task.Name)
+		return
+	}
+
+	log.Infof("switchover called on [%s] target [%s]", clusterName, target)
+
+	cluster := crv1.Pgcluster{}
+	if _, err := kubeapi.Getpgcluster(client, &cluster, clusterName, namespace); err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed, err.Error())
+		return
+	}
+
+	maxLagBytes, err := strconv.ParseInt(task.Spec.Parameters[config.LABEL_SWITCHOVER_MAX_LAG_BYTES], 10, 64)
+	if err != nil {
+		maxLagBytes = config.DefaultSwitchoverMaxLagBytes
+	}
+
+	updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverInProgress,
+		"checking the replication lag of the switchover candidates")
+
+	replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+		RESTConfig:  restconfig,
+		Clientset:   clientset,
+		Namespace:   namespace,
+		ClusterName: clusterName,
+		LagBytes:    true,
+	})
+	if err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed,
+			"could not determine the replication lag of the replicas: "+err.Error())
+		return
+	}
+
+	candidate, err := SelectSwitchoverCandidate(replicationStatus.Instances, target, maxLagBytes)
+	if err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed, err.Error())
+		return
+	}
+
+	primary, err := util.GetPrimaryPod(clientset, &cluster)
+	if err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed, err.Error())
+		return
+	}
+
+	candidatePod, err := util.GetPod(clientset, candidate.Name, namespace)
+	if err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed, err.Error())
+		return
+	}
+
+	updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverInProgress,
+		fmt.Sprintf("switching over from pod %s to pod %s, which is %d bytes behind the primary",
+			primary.Name, candidatePod.Name, candidate.ReplicationLagBytes))
+
+	code, message, err := switchover(clientset, restconfig, primary.Name, candidatePod.Name, scheduledAt,
+		primary.Spec.Containers[0].Name, namespace)
+	if err != nil {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed, err.Error())
+		return
+	}
+
+	// Patroni responds with a 202 once a switchover is scheduled, and with a 200
+	// once it has been performed. A scheduled switchover is completed when the
+	// candidate is promoted, and the replication lag of the candidate is checked
+	// again right before the scheduled time
+	if code == "202" {
+		scheduleSwitchover(client, task, candidate.Name,
+			fmt.Sprintf("switchover to %s scheduled at %s: %s", candidate.Name, scheduledAt, message))
+
+		if scheduled, err := time.Parse(time.RFC3339, scheduledAt); err == nil {
+			go checkScheduledSwitchover(clientset, client, restconfig, *task, &cluster, candidate.Name,
+				scheduled, maxLagBytes)
+		}
+		return
+	} else if code != "200" {
+		updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverFailed,
+			fmt.Sprintf("switchover to %s refused by Patroni (%s): %s", candidate.Name, code, message))
+		return
+	}
+
+	// update the pgcluster current-primary to the new primary, in the same way
+	// as a failover
+	cluster.Spec.UserLabels[config.LABEL_CURRENT_PRIMARY] = candidate.Name
+	if err := util.PatchClusterCRD(client, cluster.Spec.UserLabels, &cluster, namespace); err != nil {
+		log.Errorf("switchover: could not patch pgcluster %s with labels", clusterName)
+	}
+
+	updateSwitchoverStatus(client, task, crv1.PgtaskSwitchoverCompleted,
+		fmt.Sprintf("switched over to %s: %s", candidate.Name, message))
+}
+
+// SelectSwitchoverCandidate returns the replica to switch over to. When a target
+// is given, it is returned provided it is running and its replication lag does
+// not exceed maxLagBytes. Otherwise, the running replica with the least
+// replication lag is selected among those within maxLagBytes.
+//
+// The replication lag is the ReplicationLagBytes of the instances, and a replica
+// whose lag is unknown is never selected
+func SelectSwitchoverCandidate(instances []util.InstanceReplicationInfo, target string,
+	maxLagBytes int64) (util.InstanceReplicationInfo, error) {
+	candidates := []util.InstanceReplicationInfo{}
+
+	for _, instance := range instances {
+		if instance.Role == config.LABEL_PGHA_ROLE_PRIMARY {
+			continue
+		}
+		if target != "" && instance.Name != target {
+			continue
+		}
+
+		lagBytes := instance.ReplicationLagBytes
+
+		switch {
+		case instance.Status != "running" && target != "":
+			return instance, fmt.Errorf("replica %s is not running (%s)", instance.Name, instance.Status)
+		case lagBytes == util.ReplicationLagUnknown && target != "":
+			return instance, fmt.Errorf("the replication lag of replica %s is unknown", instance.Name)
+		case lagBytes > maxLagBytes && target != "":
+			return instance, fmt.Errorf("replica %s is %d bytes behind the primary, which exceeds "+
+				"the maximum lag of %d bytes", instance.Name, lagBytes, maxLagBytes)
+		case instance.Status == "running" && lagBytes != util.ReplicationLagUnknown && lagBytes <= maxLagBytes:
+			candidates = append(candidates, instance)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if target != "" {
+			return util.InstanceReplicationInfo{}, fmt.Errorf("no replica found named %s", target)
+		}
+		return util.InstanceReplicationInfo{}, fmt.Errorf("no running replica is within the "+
+			"maximum lag of %d bytes", maxLagBytes)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].ReplicationLagBytes != candidates[j].ReplicationLagBytes {
+			return candidates[i].ReplicationLagBytes < candidates[j].ReplicationLagBytes
+		}
+		return strings.Compare(candidates[i].Name, candidates[j].Name) < 0
+	})
+
+	return candidates[0], nil
+}
+
+// ReconcileScheduledSwitchover completes the scheduled switchover of a cluster
+// once Patroni has promoted an instance, as the switchover is performed by
+// Patroni at the scheduled time rather than when it is requested. The
+// current-primary of the pgcluster is updated to the promoted instance, and the
+// switchover is recorded as completed if the instance is the candidate it was
+// scheduled for, and as failed otherwise
+func ReconcileScheduledSwitchover(client *rest.RESTClient, cluster *crv1.Pgcluster, instance string) {
+	task := crv1.Pgtask{}
+	name := cluster.Name + "-" + config.LABEL_SWITCHOVER
+
+	if found, _ := kubeapi.Getpgtask(client, &task, name, cluster.Namespace); !found ||
+		task.Spec.Parameters[config.LABEL_SWITCHOVER_STATUS] != crv1.PgtaskSwitchoverScheduled {
+		return
+	}
+
+	cluster.Spec.UserLabels[config.LABEL_CURRENT_PRIMARY] = instance
+	if err := util.PatchClusterCRD(client, cluster.Spec.UserLabels, cluster, cluster.Namespace); err != nil {
+		log.Errorf("switchover: could not patch pgcluster %s with labels", cluster.Name)
+	}
+
+	candidate := task.Spec.Parameters[config.LABEL_SWITCHOVER_CANDIDATE]
+	if candidate != "" && candidate != instance {
+		updateSwitchoverStatus(client, &task, crv1.PgtaskSwitchoverFailed,
+			fmt.Sprintf("%s was promoted instead of the scheduled switchover candidate %s", instance, candidate))
+		return
+	}
+
+	updateSwitchoverStatus(client, &task, crv1.PgtaskSwitchoverCompleted,
+		fmt.Sprintf("switched over to %s at the scheduled time", instance))
+}
+
+// checkScheduledSwitchover checks the replication lag of the candidate of a
+// scheduled switchover shortly before the scheduled time, and cancels the
+// switchover if the candidate is no longer within the maximum lag. Nothing is
+// done if the switchover is no longer scheduled by then
+func checkScheduledSwitchover(clientset *kubernetes.Clientset, client *rest.RESTClient, restconfig *rest.Config,
+	task crv1.Pgtask, cluster *crv1.Pgcluster, candidate string, scheduled time.Time, maxLagBytes int64) {
+	time.Sleep(time.Until(scheduled.Add(-switchoverLagCheckLead)))
+
+	current := crv1.Pgtask{}
+	if found, _ := kubeapi.Getpgtask(client, &current, task.Name, task.Namespace); !found ||
+		current.UID != task.UID ||
+		current.Spec.Parameters[config.LABEL_SWITCHOVER_STATUS] != crv1.PgtaskSwitchoverScheduled {
+		return
+	}
+
+	replicationStatus, err := util.ReplicationStatus(util.ReplicationStatusRequest{
+		RESTConfig:  restconfig,
+		Clientset:   clientset,
+		Namespace:   cluster.Namespace,
+		ClusterName: cluster.Name,
+		LagBytes:    true,
+	})
+	if err == nil {
+		if _, err = SelectSwitchoverCandidate(replicationStatus.Instances, candidate, maxLagBytes); err == nil {
+			return
+		}
+	}
+
+	primary, perr := util.GetPrimaryPod(clientset, cluster)
+	if perr != nil {
+		updateSwitchoverStatus(client, &current, crv1.PgtaskSwitchoverFailed,
+			fmt.Sprintf("could not cancel the scheduled switchover to %s (%s): %s", candidate, err.Error(),
+				perr.Error()))
+		return
+	}
+
+	code, message, cerr := patroniSwitchoverRequest(clientset, restconfig, "DELETE", nil, primary.Name,
+		primary.Spec.Containers[0].Name, cluster.Namespace)
+	if cerr == nil && code != "200" {
+		cerr = fmt.Errorf("refused by Patroni (%s): %s", code, message)
+	}
+	if cerr != nil {
+		updateSwitchoverStatus(client, &current, crv1.PgtaskSwitchoverFailed,
+			fmt.Sprintf("could not cancel the scheduled switchover to %s (%s): %s", candidate, err.Error(),
+				cerr.Error()))
+		return
+	}
+
+	updateSwitchoverStatus(client, &current, crv1.PgtaskSwitchoverFailed,
+		fmt.Sprintf("scheduled switchover to %s cancelled: %s", candidate, err.Error()))
+}
+
+// switchover sends the switchover request to the Patroni REST API from within
+// the primary pod, and returns the HTTP status code and message of the response
+func switchover(clientset *kubernetes.Clientset, restconfig *rest.Config, leader, candidate, scheduledAt,
+	containerName, namespace string) (string, string, error) {
+	body, err := json.Marshal(switchoverRequest{
+		Leader:      leader,
+		Candidate:   candidate,
+		ScheduledAt: scheduledAt,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return patroniSwitchoverRequest(clientset, restconfig, "POST", body, leader, containerName, namespace)
+}
+
+// patroniSwitchoverRequest sends a request to the switchover endpoint of the
+// Patroni REST API from within a pod, i.e. a POST to request a switchover or a
+// DELETE to cancel a scheduled one, and returns the HTTP status code and message
+// of the response
+func patroniSwitchoverRequest(clientset *kubernetes.Clientset, restconfig *rest.Config, method string,
+	body []byte, podName, containerName, namespace string) (string, string, error) {
+	// the status code is written on a line of its own after the response
+	request := fmt.Sprintf("curl -s -w '\\n%%{http_code}' http://127.0.0.1:%s/switchover -X%s",
+		config.DEFAULT_PATRONI_PORT, method)
+	if body != nil {
+		request += fmt.Sprintf(" -d '%s'", body)
+	}
+	command := []string{"/bin/bash", "-c", request}
+
+	log.Debugf("running Exec command '%s' with namespace=[%s] podname=[%s] container name=[%s]",
+		command, namespace, podName, containerName)
+	stdout, stderr, err := kubeapi.ExecToPodThroughAPI(restconfig, clientset, command,
+		containerName, podName, namespace, nil)
+	log.Debugf("stdout=[%s] stderr=[%s]", stdout, stderr)
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout = strings.TrimSpace(stdout)
+	i := strings.LastIndex(stdout, "\n")
+
+	return stdout[i+1:], strings.TrimSpace(stdout[:i+1]), nil
+}
+
+// scheduleSwitchover records on its pgtask that a switchover was scheduled,
+// along with the candidate it was scheduled for
+func scheduleSwitchover(client *rest.RESTClient, task *crv1.Pgtask, candidate, message string) {
+	log.Debugf("scheduleSwitchover taskName=[%s] candidate=[%s] message=[%s]", task.Name, candidate, message)
+
+	if _, err := kubeapi.Getpgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+		return
+	}
+
+	task.Spec.Parameters[config.LABEL_SWITCHOVER_STATUS] = crv1.PgtaskSwitchoverScheduled
+	task.Spec.Parameters[config.LABEL_SWITCHOVER_CANDIDATE] = candidate
+	task.Status.Message = message
+
+	if err := kubeapi.Updatepgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+	}
+}
+
+// updateSwitchoverStatus records the state of a switchover on its pgtask along
+// with a message describing it
+func updateSwitchoverStatus(client *rest.RESTClient, task *crv1.Pgtask, status, message string) {
+	log.Debugf("updateSwitchoverStatus taskName=[%s] status=[%s] message=[%s]", task.Name, status, message)
+
+	if status == crv1.PgtaskSwitchoverFailed {
+		log.Errorf("switchover %s failed: %s", task.Name, message)
+	}
+
+	if _, err := kubeapi.Getpgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+		return
+	}
+
+	task.Spec.Parameters[config.LABEL_SWITCHOVER_STATUS] = status
+	task.Status.Message = message
+
+	if err := kubeapi.Updatepgtask(client, task, task.ObjectMeta.Name, task.ObjectMeta.Namespace); err != nil {
+		log.Error(err)
+	}
+}
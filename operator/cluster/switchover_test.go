@@ -0,0 +1,64 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+
+	"github.com/crunchydata/postgres-operator/util"
+)
+
+func TestSelectSwitchoverCandidate(t *testing.T) {
+	status := []util.InstanceReplicationInfo{
+		{Name: "hippo", Role: "master", Status: "running", ReplicationLagBytes: 0},
+		{Name: "hippo-abcd", Role: "replica", Status: "running", ReplicationLagBytes: 16 * 1048576},
+		{Name: "hippo-efgh", Role: "replica", Status: "stopped", ReplicationLagBytes: 0},
+		{Name: "hippo-ijkl", Role: "replica", Status: "running", ReplicationLagBytes: 1048576},
+		{Name: "hippo-mnop", Role: "replica", Status: "running", ReplicationLagBytes: 1048576},
+		{Name: "hippo-qrst", Role: "replica", Status: "running", ReplicationLagBytes: 1048577},
+		{Name: "hippo-uvwx", Role: "replica", Status: "running", ReplicationLagBytes: util.ReplicationLagUnknown},
+	}
+
+	tests := []struct {
+		target      string
+		maxLagBytes int64
+		expected    string
+	}{
+		{target: "", maxLagBytes: 1048576, expected: "hippo-ijkl"},
+		{target: "", maxLagBytes: 0, expected: ""},
+		{target: "hippo-abcd", maxLagBytes: 16 * 1048576, expected: "hippo-abcd"},
+		{target: "hippo-abcd", maxLagBytes: 1048576, expected: ""},
+		{target: "hippo-efgh", maxLagBytes: 1048576, expected: ""},
+		{target: "hippo-mnop", maxLagBytes: 1048576, expected: "hippo-mnop"},
+		{target: "hippo", maxLagBytes: 1048576, expected: ""},
+		{target: "hippo-qrst", maxLagBytes: 1048576, expected: ""},
+		{target: "hippo-qrst", maxLagBytes: 1048577, expected: "hippo-qrst"},
+		{target: "hippo-uvwx", maxLagBytes: 1048576, expected: ""},
+		{target: "hippo-yzab", maxLagBytes: 1048576, expected: ""},
+	}
+
+	for _, test := range tests {
+		candidate, err := SelectSwitchoverCandidate(status, test.target, test.maxLagBytes)
+
+		if test.expected == "" && err == nil {
+			t.Errorf("target %q lag %d: expected an error, got %s", test.target, test.maxLagBytes, candidate.Name)
+		} else if test.expected != "" && err != nil {
+			t.Errorf("target %q lag %d: expected %s, got error %v", test.target, test.maxLagBytes, test.expected, err)
+		} else if test.expected != "" && candidate.Name != test.expected {
+			t.Errorf("target %q lag %d: expected %s, got %s", test.target, test.maxLagBytes, test.expected, candidate.Name)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/operator"
+	cfg "github.com/crunchydata/postgres-operator/operator/config"
+	"github.com/crunchydata/postgres-operator/util"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// UpdateSyncReplication applies the synchronous replication settings of a
+// cluster. Whether synchronous replication is on, whether it is strict and the
+// number of synchronous standbys are stored in the DCS configuration of the
+// cluster, and the excluded replicas are tagged "nosync" in their local
+// configuration. Both are then applied to the cluster by the ConfigMap
+// controller
+func UpdateSyncReplication(clientset *kubernetes.Clientset, client *rest.RESTClient, restConfig *rest.Config,
+	cluster *crv1.Pgcluster) error {
+	enabled := operator.GetSyncReplication(cluster.Spec.SyncReplication)
+	settings := cluster.Spec.SyncReplicationSettings
+
+	log.Debugf("updating sync replication of cluster %s: enabled [%t] settings [%+v]",
+		cluster.Name, enabled, settings)
+
+	dcs, dcsConfig, err := getDCSConfig(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	if setSyncReplication(dcsConfig, enabled, settings) {
+		if err := dcs.Update(dcsConfig); err != nil {
+			return err
+		}
+	}
+
+	configMap, err := getPGHAConfigMap(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	deployments, err := operator.GetInstanceDeployments(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	// as with the DCS configuration, the local configuration of each instance is
+	// synced if any is missing
+	localDB, err := cfg.NewLocalDB(configMap, restConfig, clientset, client)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if _, err := localDB.GetLocalDBConfig(cfg.GetLocalDBConfigName(deployment.Name)); errors.Is(err,
+			cfg.ErrMissingClusterConfig) {
+			if err := localDB.Sync(); err != nil {
+				return err
+			}
+			if configMap, err = getPGHAConfigMap(clientset, cluster); err != nil {
+				return err
+			}
+			if localDB, err = cfg.NewLocalDB(configMap, restConfig, clientset, client); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	// the local configuration of an instance is only updated when its tag
+	// changes, as applying it reloads Patroni on the instance
+	for _, deployment := range deployments.Items {
+		configName := cfg.GetLocalDBConfigName(deployment.Name)
+
+		localConfig, err := localDB.GetLocalDBConfig(configName)
+		if err != nil {
+			return err
+		}
+
+		noSync := enabled && settings != nil && util.IsStringOneOf(deployment.Name, settings.ExcludedReplicas...)
+
+		if !setNoSyncTag(localConfig, noSync) {
+			continue
+		}
+
+		log.Debugf("setting the nosync tag of instance %s in cluster %s to %t", deployment.Name,
+			cluster.Name, noSync)
+
+		if err := localDB.Update(configName, *localConfig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setSyncReplication sets the synchronous replication settings in the DCS
+// configuration of a cluster, and returns whether it changed. The settings only
+// apply while synchronous replication is enabled, and are otherwise reset to
+// the defaults of Patroni
+func setSyncReplication(dcsConfig *cfg.DCSConfig, enabled bool, settings *crv1.SyncReplicationSpec) bool {
+	strict, nodeCount := false, 0
+	if enabled && settings != nil {
+		strict, nodeCount = settings.Strict, settings.NodeCount
+	}
+
+	if dcsConfig.SynchronousMode == enabled && dcsConfig.SynchronousModeStrict == strict &&
+		dcsConfig.SynchronousNodeCount == nodeCount {
+		return false
+	}
+
+	dcsConfig.SynchronousMode = enabled
+	dcsConfig.SynchronousModeStrict = strict
+	dcsConfig.SynchronousNodeCount = nodeCount
+
+	return true
+}
+
+// setNoSyncTag sets the Patroni "nosync" tag in the local configuration of an
+// instance, which keeps it from being chosen as a synchronous standby, and
+// returns whether it changed
+func setNoSyncTag(localConfig *cfg.LocalDBConfig, noSync bool) bool {
+	if localConfig.Tags == nil {
+		// an instance that was never tagged is not excluded
+		if !noSync {
+			return false
+		}
+		localConfig.Tags = &cfg.TagsLocalDB{}
+	}
+
+	if localConfig.Tags.NoSync == noSync {
+		return false
+	}
+
+	localConfig.Tags.NoSync = noSync
+
+	return true
+}
+
+// getDCSConfig returns the DCS configuration of a cluster. The DCS
+// configuration is only stored in the "<clustername>-pgha-config" configMap
+// once it is first synced, so it is synced now if it is missing
+func getDCSConfig(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) (*cfg.DCS, *cfg.DCSConfig, error) {
+	configMap, err := getPGHAConfigMap(clientset, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dcs := cfg.NewDCS(configMap, clientset)
+	if _, _, err := dcs.GetDCSConfig(); errors.Is(err, cfg.ErrMissingClusterConfig) {
+		if err := dcs.Sync(); err != nil {
+			return nil, nil, err
+		}
+		if configMap, err = getPGHAConfigMap(clientset, cluster); err != nil {
+			return nil, nil, err
+		}
+		dcs = cfg.NewDCS(configMap, clientset)
+	}
+
+	dcsConfig, _, err := dcs.GetDCSConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dcs, dcsConfig, nil
+}
+
+// getPGHAConfigMap returns the "<clustername>-pgha-config" configMap of a
+// cluster
+func getPGHAConfigMap(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) (*v1.ConfigMap, error) {
+	configMapName := fmt.Sprintf("%s-%s", cluster.Labels[config.LABEL_PGHA_SCOPE], operator.PGHAConfigMapSuffix)
+
+	return clientset.CoreV1().ConfigMaps(cluster.Namespace).Get(configMapName, metav1.GetOptions{})
+}
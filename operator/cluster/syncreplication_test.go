@@ -0,0 +1,102 @@
+package cluster
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	cfg "github.com/crunchydata/postgres-operator/operator/config"
+)
+
+func TestSetSyncReplication(t *testing.T) {
+	settings := &crv1.SyncReplicationSpec{NodeCount: 2, Strict: true}
+
+	tests := []struct {
+		name     string
+		current  cfg.DCSConfig
+		enabled  bool
+		settings *crv1.SyncReplicationSpec
+		expected cfg.DCSConfig
+		changed  bool
+	}{
+		{name: "off", current: cfg.DCSConfig{}, enabled: false, settings: nil,
+			expected: cfg.DCSConfig{}, changed: false},
+		{name: "turned on", current: cfg.DCSConfig{}, enabled: true, settings: nil,
+			expected: cfg.DCSConfig{SynchronousMode: true}, changed: true},
+		{name: "settings", current: cfg.DCSConfig{SynchronousMode: true}, enabled: true, settings: settings,
+			expected: cfg.DCSConfig{SynchronousMode: true, SynchronousModeStrict: true, SynchronousNodeCount: 2},
+			changed:  true},
+		{name: "unchanged",
+			current: cfg.DCSConfig{SynchronousMode: true, SynchronousModeStrict: true, SynchronousNodeCount: 2},
+			enabled: true, settings: settings,
+			expected: cfg.DCSConfig{SynchronousMode: true, SynchronousModeStrict: true, SynchronousNodeCount: 2},
+			changed:  false},
+		{name: "turned off",
+			current: cfg.DCSConfig{SynchronousMode: true, SynchronousModeStrict: true, SynchronousNodeCount: 2},
+			enabled: false, settings: settings,
+			expected: cfg.DCSConfig{}, changed: true},
+		{name: "other settings kept", current: cfg.DCSConfig{TTL: 30}, enabled: true, settings: settings,
+			expected: cfg.DCSConfig{TTL: 30, SynchronousMode: true, SynchronousModeStrict: true,
+				SynchronousNodeCount: 2},
+			changed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dcsConfig := test.current
+
+			if changed := setSyncReplication(&dcsConfig, test.enabled, test.settings); changed != test.changed {
+				t.Errorf("expected changed to be %t, got %t", test.changed, changed)
+			}
+			if !reflect.DeepEqual(dcsConfig, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, dcsConfig)
+			}
+		})
+	}
+}
+
+func TestSetNoSyncTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     *cfg.TagsLocalDB
+		noSync   bool
+		expected *cfg.TagsLocalDB
+		changed  bool
+	}{
+		{name: "never tagged", tags: nil, noSync: false, expected: nil, changed: false},
+		{name: "excluded", tags: nil, noSync: true, expected: &cfg.TagsLocalDB{NoSync: true}, changed: true},
+		{name: "still excluded", tags: &cfg.TagsLocalDB{NoSync: true}, noSync: true,
+			expected: &cfg.TagsLocalDB{NoSync: true}, changed: false},
+		{name: "no longer excluded", tags: &cfg.TagsLocalDB{NoSync: true}, noSync: false,
+			expected: &cfg.TagsLocalDB{NoSync: false}, changed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localConfig := &cfg.LocalDBConfig{Tags: test.tags}
+
+			if changed := setNoSyncTag(localConfig, test.noSync); changed != test.changed {
+				t.Errorf("expected changed to be %t, got %t", test.changed, changed)
+			}
+			if (localConfig.Tags == nil) != (test.expected == nil) ||
+				(localConfig.Tags != nil && *localConfig.Tags != *test.expected) {
+				t.Errorf("expected tags %+v, got %+v", test.expected, localConfig.Tags)
+			}
+		})
+	}
+}
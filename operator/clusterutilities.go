@@ -111,12 +111,58 @@ type PgbackrestEnvVarsTemplateFields struct {
 }
 
 type PgbackrestS3EnvVarsTemplateFields struct {
-	PgbackrestS3Bucket     string
-	PgbackrestS3Endpoint   string
-	PgbackrestS3Region     string
-	PgbackrestS3Key        string
-	PgbackrestS3KeySecret  string
-	PgbackrestS3SecretName string
+	PgbackrestS3Bucket           string
+	PgbackrestS3Endpoint         string
+	PgbackrestS3Region           string
+	PgbackrestS3Key              string
+	PgbackrestS3KeySecret        string
+	PgbackrestS3SecretName       string
+	PgbackrestStorageNoVerifyTLS bool
+}
+
+type PgbackrestGCSEnvVarsTemplateFields struct {
+	PgbackrestGCSBucket          string
+	PgbackrestGCSEndpoint        string
+	PgbackrestGCSKeyType         string
+	PgbackrestGCSKey             string
+	PgbackrestGCSSecretName      string
+	PgbackrestStorageNoVerifyTLS bool
+}
+
+type PgbackrestAzureEnvVarsTemplateFields struct {
+	PgbackrestAzureContainer     string
+	PgbackrestAzureEndpoint      string
+	PgbackrestAzureKeyType       string
+	PgbackrestAzureURIStyle      string
+	PgbackrestAzureAccount       string
+	PgbackrestAzureKey           string
+	PgbackrestAzureSecretName    string
+	PgbackrestStorageNoVerifyTLS bool
+}
+
+// PgbackrestRepoEnvVarsTemplateFields are the settings of one of the remote
+// pgBackRest repositories of a cluster, i.e. of repository "Repo"
+type PgbackrestRepoEnvVarsTemplateFields struct {
+	Repo                 int
+	Type                 string
+	Path                 string
+	SecretName           string
+	S3Bucket             string
+	S3Endpoint           string
+	S3Region             string
+	S3Key                string
+	S3KeySecret          string
+	AzureContainer       string
+	AzureEndpoint        string
+	AzureKeyType         string
+	AzureURIStyle        string
+	AzureAccount         string
+	AzureKey             string
+	StorageNoVerifyTLS   bool
+	RetentionFull        int
+	RetentionDiff        int
+	RetentionArchive     int
+	RetentionArchiveType string
 }
 
 type PgmonitorEnvVarsTemplateFields struct {
@@ -696,25 +742,38 @@ func GetPgmonitorEnvVars(metricsEnabled string) string {
 // pgBackRest environment variables required to enable S3 support.  After the template has been
 // executed with the proper values, the result is then returned a string for inclusion in the PG
 // and pgBackRest deployments.
+//
+// A cluster that stores its backups in GCS or Azure instead gets the settings of
+// that storage, which are returned in the same manner
 func GetPgbackrestS3EnvVars(cluster crv1.Pgcluster, clientset *kubernetes.Clientset,
 	ns string) string {
 
-	if !strings.Contains(cluster.Spec.UserLabels[config.LABEL_BACKREST_STORAGE_TYPE], "s3") {
-		return ""
+	// the remote repositories of the cluster, if any, are configured along with
+	// its S3 storage
+	repoEnvVars := GetPgbackrestRepoEnvVars(cluster)
+
+	switch storageType := cluster.Spec.UserLabels[config.LABEL_BACKREST_STORAGE_TYPE]; {
+	case storageType == "gcs":
+		return GetPgbackrestGCSEnvVars(cluster, clientset) + repoEnvVars
+	case storageType == "azure":
+		return GetPgbackrestAzureEnvVars(cluster, clientset) + repoEnvVars
+	case !strings.Contains(storageType, "s3"):
+		return repoEnvVars
 	}
 
 	// determine the secret for getting the credentials for using S3 as a
 	// pgBackRest repository. If we can't do that, then we can't move on
 	if _, err := util.GetS3CredsFromBackrestRepoSecret(clientset, cluster.Namespace, cluster.Name); err != nil {
-		return ""
+		return repoEnvVars
 	}
 
 	// populate the S3 bucket, endpoint and region using either the values in the pgcluster
 	// spec (if present), otherwise populate using the values from the pgo.yaml config file
 	s3EnvVars := PgbackrestS3EnvVarsTemplateFields{
-		PgbackrestS3Key:        util.BackRestRepoSecretKeyAWSS3KeyAWSS3Key,
-		PgbackrestS3KeySecret:  util.BackRestRepoSecretKeyAWSS3KeyAWSS3KeySecret,
-		PgbackrestS3SecretName: fmt.Sprintf("%s-%s", cluster.Name, config.LABEL_BACKREST_REPO_SECRET),
+		PgbackrestS3Key:              util.BackRestRepoSecretKeyAWSS3KeyAWSS3Key,
+		PgbackrestS3KeySecret:        util.BackRestRepoSecretKeyAWSS3KeyAWSS3KeySecret,
+		PgbackrestS3SecretName:       fmt.Sprintf("%s-%s", cluster.Name, config.LABEL_BACKREST_REPO_SECRET),
+		PgbackrestStorageNoVerifyTLS: cluster.Spec.BackrestStorageNoVerifyTLS,
 	}
 
 	if cluster.Spec.BackrestS3Bucket != "" {
@@ -742,6 +801,118 @@ func GetPgbackrestS3EnvVars(cluster crv1.Pgcluster, clientset *kubernetes.Client
 		return ""
 	}
 
+	return doc.String() + repoEnvVars
+}
+
+// GetPgbackrestGCSEnvVars renders the settings of the GCS storage of the
+// pgBackRest repository of a cluster, i.e. its bucket, endpoint and the type of
+// its key, each falling back to the pgo.yaml config file. A service account key
+// is read from the backrest repository secret that is mounted in the
+// containers, and a token is read from it into an environmental variable
+func GetPgbackrestGCSEnvVars(cluster crv1.Pgcluster, clientset *kubernetes.Clientset) string {
+	if _, err := util.GetGCSAzureCredsFromBackrestRepoSecret(clientset, cluster.Namespace, cluster.Name); err != nil {
+		return ""
+	}
+
+	gcsEnvVars := PgbackrestGCSEnvVarsTemplateFields{
+		PgbackrestGCSBucket: util.GetValueOrDefault(cluster.Spec.BackrestGCSBucket,
+			Pgo.Cluster.BackrestGCSBucket),
+		PgbackrestGCSEndpoint: util.GetValueOrDefault(cluster.Spec.BackrestGCSEndpoint,
+			Pgo.Cluster.BackrestGCSEndpoint),
+		PgbackrestGCSKeyType: util.GetValueOrDefault(cluster.Spec.BackrestGCSKeyType,
+			util.GetValueOrDefault(Pgo.Cluster.BackrestGCSKeyType, config.DefaultBackrestGCSKeyType)),
+		PgbackrestGCSKey:             util.BackRestRepoSecretKeyGCSKey,
+		PgbackrestGCSSecretName:      fmt.Sprintf("%s-%s", cluster.Name, config.LABEL_BACKREST_REPO_SECRET),
+		PgbackrestStorageNoVerifyTLS: cluster.Spec.BackrestStorageNoVerifyTLS,
+	}
+
+	doc := bytes.Buffer{}
+
+	if err := config.PgbackrestGCSEnvVarsTemplate.Execute(&doc, gcsEnvVars); err != nil {
+		log.Error(err.Error())
+		return ""
+	}
+
+	return doc.String()
+}
+
+// GetPgbackrestAzureEnvVars renders the settings of the Azure storage of the
+// pgBackRest repository of a cluster, i.e. its container, endpoint, the type of
+// its key and the style of its URIs, each falling back to the pgo.yaml config
+// file. The storage account and key are read from the backrest repository
+// secret
+func GetPgbackrestAzureEnvVars(cluster crv1.Pgcluster, clientset *kubernetes.Clientset) string {
+	if _, err := util.GetGCSAzureCredsFromBackrestRepoSecret(clientset, cluster.Namespace, cluster.Name); err != nil {
+		return ""
+	}
+
+	azureEnvVars := PgbackrestAzureEnvVarsTemplateFields{
+		PgbackrestAzureContainer: util.GetValueOrDefault(cluster.Spec.BackrestAzureContainer,
+			Pgo.Cluster.BackrestAzureContainer),
+		PgbackrestAzureEndpoint: util.GetValueOrDefault(cluster.Spec.BackrestAzureEndpoint,
+			Pgo.Cluster.BackrestAzureEndpoint),
+		PgbackrestAzureKeyType: util.GetValueOrDefault(cluster.Spec.BackrestAzureKeyType,
+			util.GetValueOrDefault(Pgo.Cluster.BackrestAzureKeyType, config.DefaultBackrestAzureKeyType)),
+		PgbackrestAzureURIStyle: util.GetValueOrDefault(cluster.Spec.BackrestAzureURIStyle,
+			Pgo.Cluster.BackrestAzureURIStyle),
+		PgbackrestAzureAccount:       util.BackRestRepoSecretKeyAzureAccount,
+		PgbackrestAzureKey:           util.BackRestRepoSecretKeyAzureKey,
+		PgbackrestAzureSecretName:    fmt.Sprintf("%s-%s", cluster.Name, config.LABEL_BACKREST_REPO_SECRET),
+		PgbackrestStorageNoVerifyTLS: cluster.Spec.BackrestStorageNoVerifyTLS,
+	}
+
+	doc := bytes.Buffer{}
+
+	if err := config.PgbackrestAzureEnvVarsTemplate.Execute(&doc, azureEnvVars); err != nil {
+		log.Error(err.Error())
+		return ""
+	}
+
+	return doc.String()
+}
+
+// GetPgbackrestRepoEnvVars renders the settings of the remote pgBackRest
+// repositories of a cluster, i.e. the repositories that follow its local one,
+// into the "repoN-*" environmental variables of pgBackRest. The credentials of
+// each repository are read from its Secret
+func GetPgbackrestRepoEnvVars(cluster crv1.Pgcluster) string {
+	if len(cluster.Spec.BackrestRepos) < 2 {
+		return ""
+	}
+
+	repos := []PgbackrestRepoEnvVarsTemplateFields{}
+	for i, repo := range cluster.Spec.BackrestRepos[1:] {
+		repos = append(repos, PgbackrestRepoEnvVarsTemplateFields{
+			Repo:                 i + 2,
+			Type:                 repo.Type,
+			Path:                 util.GetValueOrDefault(repo.Path, util.GetPGBackRestRepoPath(cluster)),
+			SecretName:           repo.Secret,
+			S3Bucket:             repo.S3Bucket,
+			S3Endpoint:           repo.S3Endpoint,
+			S3Region:             repo.S3Region,
+			S3Key:                util.BackRestRepoSecretKeyAWSS3KeyAWSS3Key,
+			S3KeySecret:          util.BackRestRepoSecretKeyAWSS3KeyAWSS3KeySecret,
+			AzureContainer:       repo.AzureContainer,
+			AzureEndpoint:        repo.AzureEndpoint,
+			AzureKeyType:         util.GetValueOrDefault(repo.AzureKeyType, config.DefaultBackrestAzureKeyType),
+			AzureURIStyle:        repo.AzureURIStyle,
+			AzureAccount:         util.BackRestRepoSecretKeyAzureAccount,
+			AzureKey:             util.BackRestRepoSecretKeyAzureKey,
+			StorageNoVerifyTLS:   repo.StorageNoVerifyTLS,
+			RetentionFull:        repo.Retention.Full,
+			RetentionDiff:        repo.Retention.Diff,
+			RetentionArchive:     repo.Retention.Archive,
+			RetentionArchiveType: repo.Retention.ArchiveType,
+		})
+	}
+
+	doc := bytes.Buffer{}
+
+	if err := config.PgbackrestRepoEnvVarsTemplate.Execute(&doc, repos); err != nil {
+		log.Error(err.Error())
+		return ""
+	}
+
 	return doc.String()
 }
 
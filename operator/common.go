@@ -127,6 +127,14 @@ func Initialize(clientset *kubernetes.Clientset) {
 	// set controller refresh intervals and worker counts
 	initializeControllerRefreshIntervals()
 	initializeControllerWorkerCounts()
+
+	// set the port of the admission webhooks if not provided in the pgo.yaml
+	if Pgo.Pgo.AdmissionWebhookPort == nil {
+		log.Debugf("AdmissionWebhookPort not set, defaulting to %d",
+			config.DefaultAdmissionWebhookPort)
+		defaultVal := int(config.DefaultAdmissionWebhookPort)
+		Pgo.Pgo.AdmissionWebhookPort = &defaultVal
+	}
 }
 
 // GetResourcesJSON is a pseudo-legacy method that creates JSON that applies the
@@ -166,9 +174,10 @@ func GetResourcesJSON(resources v1.ResourceList) string {
 // GetRepoType returns the proper repo type to set in container based on the
 // backrest storage type provided
 func GetRepoType(backrestStorageType string) string {
-	if backrestStorageType != "" && backrestStorageType == "s3" {
-		return "s3"
-	} else {
+	switch backrestStorageType {
+	case "s3", "gcs", "azure":
+		return backrestStorageType
+	default:
 		return "posix"
 	}
 }
@@ -241,6 +250,17 @@ func initializeControllerRefreshIntervals() {
 		log.Debugf("ControllerGroupRefreshInterval is set, using %d seconds",
 			*Pgo.Pgo.ControllerGroupRefreshInterval)
 	}
+
+	// set the reconcile interval if not provided in the pgo.yaml
+	if Pgo.Pgo.ReconcileInterval == nil {
+		log.Debugf("ReconcileInterval not set, defaulting to %d seconds",
+			config.DefaultReconcileInterval)
+		defaultVal := int(config.DefaultReconcileInterval)
+		Pgo.Pgo.ReconcileInterval = &defaultVal
+	} else {
+		log.Debugf("ReconcileInterval is set, using %d seconds",
+			*Pgo.Pgo.ReconcileInterval)
+	}
 }
 
 // initControllerWorkerCounts sets the number of workers that will be created for any worker
@@ -60,6 +60,7 @@ type DCSConfig struct {
 	MasterStartTimeout    int                `json:"master_start_timeout,omitempty"`
 	SynchronousMode       bool               `json:"synchronous_mode,omitempty"`
 	SynchronousModeStrict bool               `json:"synchronous_mode_strict,omitempty"`
+	SynchronousNodeCount  int                `json:"synchronous_node_count,omitempty"`
 	PostgreSQL            *PostgresDCS       `json:"postgresql,omitempty"`
 	StandbyCluster        *StandbyDCS        `json:"standby_cluster,omitempty"`
 	Slots                 map[string]SlotDCS `json:"slots,omitempty"`
@@ -68,6 +68,7 @@ type LocalDB struct {
 // and not any configuration that is controlled/managed by the Operator itself.
 type LocalDBConfig struct {
 	PostgreSQL PostgresLocalDB `json:"postgresql,omitempty"`
+	Tags       *TagsLocalDB    `json:"tags,omitempty"`
 }
 
 // PostgresLocalDB represents the PostgreSQL settings that can be applied to an individual
@@ -91,6 +92,13 @@ type PostgresLocalDB struct {
 	PGBackRestStandby                      *CreateReplicaMethod   `json:"pgbackrest_standby,omitempty"`
 }
 
+// TagsLocalDB represents the Patroni tags that can be applied to an individual PostgreSQL server
+// within a PostgreSQL cluster.  "nosync" is always included so that removing it from a server is
+// also applied to that server.
+type TagsLocalDB struct {
+	NoSync bool `json:"nosync"`
+}
+
 // Callbacks defines the various Patroni callbacks
 type Callbacks struct {
 	OnReload     string `json:"on_reload,omitempty"`
@@ -362,6 +370,24 @@ func (l *LocalDB) getLocalConfig(configName string) (string, error) {
 	return localYAML, nil
 }
 
+// GetLocalDBConfig returns the current local configuration included in the LocalDB's configMap
+// for a specific database server, i.e. the contents of the "<servername-local-config>"
+// configuration unmarshalled into a LocalDBConfig struct.
+func (l *LocalDB) GetLocalDBConfig(configName string) (*LocalDBConfig, error) {
+
+	localYAML, err := l.getLocalConfig(configName)
+	if err != nil {
+		return nil, err
+	}
+
+	localDBConfig := &LocalDBConfig{}
+	if err := yaml.Unmarshal([]byte(localYAML), localDBConfig); err != nil {
+		return nil, err
+	}
+
+	return localDBConfig, nil
+}
+
 // refresh updates the local configuration for a specific database server in the Refresh's
 // configMap with the current local configuration for that server.  Specifically, it is updated
 // with the contents of the Patroni YAML configuration file stored in the container running the
@@ -418,3 +444,9 @@ func GetLocalDBConfigNames(kubeclientset kubernetes.Interface, clusterName,
 
 	return localConfigNames, nil
 }
+
+// GetLocalDBConfigName returns the name of the local configuration of a database server, i.e. of
+// its Deployment, as stored in the <clusterName>-pgha-config configMap.
+func GetLocalDBConfigName(deploymentName string) string {
+	return fmt.Sprintf(pghaLocalConfigName, deploymentName)
+}
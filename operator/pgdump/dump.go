@@ -52,6 +52,7 @@ type pgDumpJobTemplateFields struct {
 	PgDumpFilename   string
 	PgDumpAll        string
 	PgDumpPVC        string
+	PgDumpKeep       string
 }
 
 // Dump ...
@@ -115,9 +116,17 @@ func Dump(namespace string, clientset *kubernetes.Clientset, client *rest.RESTCl
 		PgDumpPort:       task.Spec.Parameters[config.LABEL_PGDUMP_PORT],
 		PgDumpOpts:       task.Spec.Parameters[config.LABEL_PGDUMP_OPTS],
 		PgDumpAll:        task.Spec.Parameters[config.LABEL_PGDUMP_ALL],
+		PgDumpFilename:   task.Spec.Parameters[config.LABEL_PGDUMP_FILENAME],
 		PgDumpPVC:        pvcName,
 	}
 
+	// the older backups are pruned once the new one has been taken, which
+	// requires the file name of the backup to tell them apart from the other
+	// backups that are on the PVC
+	if jobFields.PgDumpFilename != "" {
+		jobFields.PgDumpKeep = task.Spec.Parameters[config.LABEL_PGDUMP_KEEP]
+	}
+
 	var doc2 bytes.Buffer
 	err = config.PgDumpBackupJobTemplate.Execute(&doc2, jobFields)
 	if err != nil {
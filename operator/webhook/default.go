@@ -0,0 +1,197 @@
+package webhook
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"strconv"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// defaultCCPImage is the container image of the PostgreSQL instances when none
+// is set, which is the same as when a cluster is created through the apiserver
+const defaultCCPImage = "crunchy-postgres-ha"
+
+// mutate is the admitFunc of the mutating webhook. When a Pgcluster is created,
+// it fills in the settings that are missing from it with the defaults that the
+// apiserver would have used
+func (s *Server) mutate(request *admissionv1beta1.AdmissionRequest) ([]jsonPatchOperation, error) {
+	if request.Operation != admissionv1beta1.Create ||
+		request.Resource.Resource != crv1.PgclusterResourcePlural {
+		return nil, nil
+	}
+
+	cluster := crv1.Pgcluster{}
+	if err := decode(request, &cluster, nil); err != nil {
+		return nil, err
+	}
+
+	// the fields of the spec can only be added to if the spec itself exists
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(request.Object.Raw, &fields); err != nil {
+		return nil, err
+	}
+
+	patch := []jsonPatchOperation{}
+	if _, ok := fields["spec"]; !ok {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec", Value: map[string]interface{}{}})
+	}
+
+	cluster.Namespace = request.Namespace
+
+	return append(patch, defaultPgcluster(&cluster, s.PgoConfig)...), nil
+}
+
+// defaultPgcluster returns the JSON patch that sets the defaults of each setting
+// of a Pgcluster that is not set
+func defaultPgcluster(cluster *crv1.Pgcluster, pgoConfig *config.PgoConfig) []jsonPatchOperation {
+	patch := []jsonPatchOperation{}
+	spec := cluster.Spec
+
+	add := func(path string, value interface{}) {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: path, Value: value})
+	}
+
+	// the names of the cluster follow the name of the Pgcluster
+	if spec.Name == "" {
+		add("/spec/name", cluster.Name)
+	}
+	if spec.ClusterName == "" {
+		add("/spec/clustername", cluster.Name)
+	}
+	if spec.Namespace == "" {
+		add("/spec/namespace", cluster.Namespace)
+	}
+	if spec.PrimaryHost == "" {
+		add("/spec/primaryhost", cluster.Name)
+	}
+
+	// the JSON keys of the storage specifications are the names of their fields
+	storage := []struct {
+		path string
+		spec crv1.PgStorageSpec
+		name string
+	}{
+		{path: "/spec/PrimaryStorage", spec: spec.PrimaryStorage, name: pgoConfig.PrimaryStorage},
+		{path: "/spec/ReplicaStorage", spec: spec.ReplicaStorage, name: pgoConfig.ReplicaStorage},
+		{path: "/spec/BackrestStorage", spec: spec.BackrestStorage, name: pgoConfig.BackrestStorage},
+	}
+
+	for _, s := range storage {
+		if s.spec != (crv1.PgStorageSpec{}) || s.name == "" {
+			continue
+		}
+		// the storage configurations of the pgo.yaml file are validated when the
+		// Operator starts, so there is nothing more to do if one is not found
+		if storageSpec, err := pgoConfig.GetStorageSpec(s.name); err == nil {
+			add(s.path, storageSpec)
+		}
+	}
+
+	// the memory requests default to those of the pgo.yaml configuration
+	if _, ok := spec.Resources[v1.ResourceMemory]; !ok {
+		add("/spec/resources", withMemory(spec.Resources, pgoConfig.Cluster.DefaultInstanceResourceMemory))
+	}
+	if _, ok := spec.BackrestResources[v1.ResourceMemory]; !ok {
+		add("/spec/backrestResources", withMemory(spec.BackrestResources,
+			pgoConfig.Cluster.DefaultBackrestResourceMemory))
+	}
+	if _, ok := spec.PgBouncer.Resources[v1.ResourceMemory]; spec.PgBouncer.Enabled() && !ok {
+		add("/spec/pgBouncer/resources", withMemory(spec.PgBouncer.Resources,
+			pgoConfig.Cluster.DefaultPgBouncerResourceMemory))
+	}
+
+	defaults := []struct {
+		path, value, defaultValue string
+	}{
+		{path: "/spec/ccpimage", value: spec.CCPImage, defaultValue: defaultCCPImage},
+		{path: "/spec/ccpimagetag", value: spec.CCPImageTag, defaultValue: pgoConfig.Cluster.CCPImageTag},
+		{path: "/spec/ccpimageprefix", value: spec.CCPImagePrefix, defaultValue: pgoConfig.Cluster.CCPImagePrefix},
+		{path: "/spec/pgoimageprefix", value: spec.PGOImagePrefix, defaultValue: pgoConfig.Pgo.PGOImagePrefix},
+		{path: "/spec/port", value: spec.Port, defaultValue: pgoConfig.Cluster.Port},
+		{path: "/spec/pgbadgerport", value: spec.PGBadgerPort, defaultValue: pgoConfig.Cluster.PGBadgerPort},
+		{path: "/spec/exporterport", value: spec.ExporterPort, defaultValue: pgoConfig.Cluster.ExporterPort},
+		{path: "/spec/user", value: spec.User, defaultValue: pgoConfig.Cluster.User},
+		{path: "/spec/database", value: spec.Database, defaultValue: pgoConfig.Cluster.Database},
+		{path: "/spec/replicas", value: spec.Replicas, defaultValue: pgoConfig.Cluster.Replicas},
+	}
+
+	for _, d := range defaults {
+		if d.value == "" && d.defaultValue != "" {
+			add(d.path, d.defaultValue)
+		}
+	}
+
+	// as with the apiserver, the database is named after the cluster if the
+	// pgo.yaml configuration does not provide a name
+	if spec.Database == "" && pgoConfig.Cluster.Database == "" {
+		add("/spec/database", cluster.Name)
+	}
+
+	if labels := defaultLabels(cluster, pgoConfig); labels != nil {
+		add("/metadata/labels", labels)
+	}
+
+	return patch
+}
+
+// defaultLabels returns the labels of a Pgcluster with the defaults of the labels
+// that are not set, or nil if every label is already set
+func defaultLabels(cluster *crv1.Pgcluster, pgoConfig *config.PgoConfig) map[string]string {
+	labels := map[string]string{}
+	for k, v := range cluster.Labels {
+		labels[k] = v
+	}
+
+	defaults := map[string]string{
+		config.LABEL_NAME:     cluster.Name,
+		config.LABEL_AUTOFAIL: strconv.FormatBool(!pgoConfig.Cluster.DisableAutofail),
+		config.LABEL_BADGER:   strconv.FormatBool(pgoConfig.Cluster.Badger),
+		// pgBackRest is always enabled
+		config.LABEL_BACKREST: "true",
+	}
+
+	changed := false
+	for k, v := range defaults {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return labels
+}
+
+// withMemory returns a copy of a resource list with its memory request set
+func withMemory(resources v1.ResourceList, memory resource.Quantity) v1.ResourceList {
+	list := v1.ResourceList{}
+	for k, v := range resources {
+		list[k] = v
+	}
+	list[v1.ResourceMemory] = memory
+
+	return list
+}
@@ -0,0 +1,198 @@
+package webhook
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/tlsutil"
+
+	log "github.com/sirupsen/logrus"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// serviceName is the name of the Service of the Operator, which the webhooks
+	// are reached through
+	serviceName = "postgres-operator"
+	// validatingWebhookName and mutatingWebhookName are the names of the webhooks
+	validatingWebhookName = "validate.pgo.crunchydata.com"
+	mutatingWebhookName   = "mutate.pgo.crunchydata.com"
+)
+
+// Register creates the self-signed certificate the webhooks are served with, and
+// then creates or updates the ValidatingWebhookConfiguration and the
+// MutatingWebhookConfiguration of the installation so that they trust it. It
+// returns the PEM encoded certificate and private key
+func Register(clientset *kubernetes.Clientset, namespace, installationName string,
+	port int) ([]byte, []byte, error) {
+	key, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the API server reaches the webhooks through the Service of the Operator
+	cert, err := tlsutil.NewSelfSignedServingCertificate(key,
+		serviceName,
+		fmt.Sprintf("%s.%s", serviceName, namespace),
+		fmt.Sprintf("%s.%s.svc", serviceName, namespace))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM, keyPEM := tlsutil.EncodeCertificatePEM(cert), tlsutil.EncodePrivateKeyPEM(key)
+
+	// the webhooks only apply to the namespaces of this installation
+	namespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			config.LABEL_VENDOR:                config.LABEL_CRUNCHY,
+			config.LABEL_PGO_INSTALLATION_NAME: installationName,
+		},
+	}
+
+	// if the Operator is unavailable, objects are admitted without being checked
+	// rather than blocking every change to them
+	failurePolicy := admissionregistrationv1beta1.Ignore
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	servicePort := int32(port)
+	validatePath, mutatePath := ValidatePath, MutatePath
+
+	name := fmt.Sprintf("pgo-admission-%s", installationName)
+
+	validating := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				config.LABEL_VENDOR:                config.LABEL_CRUNCHY,
+				config.LABEL_PGO_INSTALLATION_NAME: installationName,
+			},
+		},
+		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{{
+			Name: validatingWebhookName,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: namespace,
+					Name:      serviceName,
+					Path:      &validatePath,
+					Port:      &servicePort,
+				},
+				CABundle: certPEM,
+			},
+			Rules: []admissionregistrationv1beta1.RuleWithOperations{
+				rule(crv1.PgclusterResourcePlural, crv1.PgreplicaResourcePlural,
+					crv1.PgtaskResourcePlural, crv1.PgpolicyResourcePlural),
+			},
+			FailurePolicy:           &failurePolicy,
+			NamespaceSelector:       namespaceSelector,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1beta1"},
+		}},
+	}
+
+	mutating := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: validating.ObjectMeta,
+		Webhooks: []admissionregistrationv1beta1.MutatingWebhook{{
+			Name: mutatingWebhookName,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: namespace,
+					Name:      serviceName,
+					Path:      &mutatePath,
+					Port:      &servicePort,
+				},
+				CABundle: certPEM,
+			},
+			Rules: []admissionregistrationv1beta1.RuleWithOperations{
+				rule(crv1.PgclusterResourcePlural),
+			},
+			FailurePolicy:           &failurePolicy,
+			NamespaceSelector:       namespaceSelector,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1beta1"},
+		}},
+	}
+
+	if err := applyValidatingWebhookConfiguration(clientset, validating); err != nil {
+		return nil, nil, err
+	}
+
+	if err := applyMutatingWebhookConfiguration(clientset, mutating); err != nil {
+		return nil, nil, err
+	}
+
+	log.Infof("admission webhooks registered as %s", name)
+
+	return certPEM, keyPEM, nil
+}
+
+// applyValidatingWebhookConfiguration creates the ValidatingWebhookConfiguration
+// provided, or replaces the webhooks of the one that already exists
+func applyValidatingWebhookConfiguration(clientset *kubernetes.Clientset,
+	configuration *admissionregistrationv1beta1.ValidatingWebhookConfiguration) error {
+	client := clientset.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+
+	existing, err := client.Get(configuration.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = client.Create(configuration)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Webhooks = configuration.Webhooks
+	_, err = client.Update(existing)
+	return err
+}
+
+// applyMutatingWebhookConfiguration creates the MutatingWebhookConfiguration
+// provided, or replaces the webhooks of the one that already exists
+func applyMutatingWebhookConfiguration(clientset *kubernetes.Clientset,
+	configuration *admissionregistrationv1beta1.MutatingWebhookConfiguration) error {
+	client := clientset.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+
+	existing, err := client.Get(configuration.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = client.Create(configuration)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Webhooks = configuration.Webhooks
+	_, err = client.Update(existing)
+	return err
+}
+
+// rule returns the rule that matches the creation and update of the custom
+// resources provided
+func rule(resources ...string) admissionregistrationv1beta1.RuleWithOperations {
+	return admissionregistrationv1beta1.RuleWithOperations{
+		Operations: []admissionregistrationv1beta1.OperationType{
+			admissionregistrationv1beta1.Create,
+			admissionregistrationv1beta1.Update,
+		},
+		Rule: admissionregistrationv1beta1.Rule{
+			APIGroups:   []string{crv1.GroupName},
+			APIVersions: []string{crv1.SchemeGroupVersion.Version},
+			Resources:   resources,
+		},
+	}
+}
@@ -0,0 +1,285 @@
+package webhook
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/util"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// validate is the admitFunc of the validating webhook. It decodes the custom
+// resource of the request and rejects it if any of its settings are invalid
+func (s *Server) validate(request *admissionv1beta1.AdmissionRequest) ([]jsonPatchOperation, error) {
+	// objects that are being deleted are always admitted
+	if request.Operation == admissionv1beta1.Delete {
+		return nil, nil
+	}
+
+	var errs []string
+
+	switch request.Resource.Resource {
+	case crv1.PgclusterResourcePlural:
+		cluster, oldCluster := crv1.Pgcluster{}, crv1.Pgcluster{}
+
+		if err := decode(request, &cluster, &oldCluster); err != nil {
+			return nil, err
+		}
+
+		// if the spec is unchanged, e.g. as only the status or the labels of the
+		// cluster were updated, there is nothing to validate
+		if request.Operation == admissionv1beta1.Update && reflect.DeepEqual(cluster.Spec, oldCluster.Spec) {
+			return nil, nil
+		}
+
+		errs = validatePgcluster(&cluster, s.PgoConfig)
+
+		// the TLS Secrets are looked up only when the TLS settings are first set, as
+		// they may be removed at a later time without affecting the cluster
+		if request.Operation == admissionv1beta1.Create ||
+			cluster.Spec.TLSOnly != oldCluster.Spec.TLSOnly || cluster.Spec.TLS != oldCluster.Spec.TLS {
+			if err := util.ValidateClusterTLS(s.Clientset, request.Namespace, cluster.Spec.TLSOnly,
+				cluster.Spec.TLS.TLSSecret, cluster.Spec.TLS.CASecret); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	case crv1.PgreplicaResourcePlural:
+		replica := crv1.Pgreplica{}
+
+		if err := decode(request, &replica, nil); err != nil {
+			return nil, err
+		}
+
+		errs = validatePgreplica(&replica)
+	case crv1.PgtaskResourcePlural:
+		task := crv1.Pgtask{}
+
+		if err := decode(request, &task, nil); err != nil {
+			return nil, err
+		}
+
+		errs = validatePgtask(&task)
+	case crv1.PgpolicyResourcePlural:
+		policy := crv1.Pgpolicy{}
+
+		if err := decode(request, &policy, nil); err != nil {
+			return nil, err
+		}
+
+		errs = validatePgpolicy(&policy)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil, nil
+}
+
+// decode decodes the object of a request, as well as the previous version of the
+// object if one is provided and the request is an update
+func decode(request *admissionv1beta1.AdmissionRequest, obj, oldObj interface{}) error {
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		return fmt.Errorf("could not decode %s: %s", request.Kind.Kind, err.Error())
+	}
+
+	if oldObj != nil && request.Operation == admissionv1beta1.Update {
+		if err := json.Unmarshal(request.OldObject.Raw, oldObj); err != nil {
+			return fmt.Errorf("could not decode %s: %s", request.Kind.Kind, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validatePgcluster validates the settings of a Pgcluster that do not require a
+// lookup in Kubernetes, and returns an error message for each invalid setting
+func validatePgcluster(cluster *crv1.Pgcluster, pgoConfig *config.PgoConfig) []string {
+	errs := []string{}
+	spec := cluster.Spec
+
+	if spec.Name != "" && spec.Name != cluster.Name {
+		errs = append(errs, fmt.Sprintf("spec.name %q does not match the name of the pgcluster %q",
+			spec.Name, cluster.Name))
+	}
+
+	storage := []struct {
+		name string
+		spec crv1.PgStorageSpec
+	}{
+		{name: "primary storage", spec: spec.PrimaryStorage},
+		{name: "replica storage", spec: spec.ReplicaStorage},
+		{name: "pgBackRest storage", spec: spec.BackrestStorage},
+		{name: "WAL storage", spec: spec.WALStorage},
+	}
+
+	tablespaces := make([]string, 0, len(spec.TablespaceMounts))
+	for name := range spec.TablespaceMounts {
+		tablespaces = append(tablespaces, name)
+	}
+	sort.Strings(tablespaces)
+
+	for _, name := range tablespaces {
+		storage = append(storage, struct {
+			name string
+			spec crv1.PgStorageSpec
+		}{name: "storage of tablespace " + name, spec: spec.TablespaceMounts[name]})
+	}
+
+	for _, s := range storage {
+		if err := util.ValidateStorageSpec(s.spec); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", s.name, err.Error()))
+		}
+	}
+
+	affinities := []struct {
+		name     string
+		affinity crv1.PodAntiAffinityType
+	}{
+		{name: "default", affinity: spec.PodAntiAffinity.Default},
+		{name: "pgBackRest", affinity: spec.PodAntiAffinity.PgBackRest},
+		{name: "pgBouncer", affinity: spec.PodAntiAffinity.PgBouncer},
+	}
+
+	for _, a := range affinities {
+		if err := a.affinity.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s pod anti-affinity: %s", a.name, err.Error()))
+		}
+	}
+
+	// the S3, GCS and Azure settings of the cluster fall back to those of the pgo.yaml
+	// configuration, just like when the cluster is created through the apiserver
+	backrestStorageType := spec.UserLabels[config.LABEL_BACKREST_STORAGE_TYPE]
+	if err := util.ValidateBackrestStorageType(backrestStorageType,
+		util.GetValueOrDefault(spec.BackrestS3Bucket, pgoConfig.Cluster.BackrestS3Bucket),
+		util.GetValueOrDefault(spec.BackrestS3Endpoint, pgoConfig.Cluster.BackrestS3Endpoint),
+		util.GetValueOrDefault(spec.BackrestS3Region, pgoConfig.Cluster.BackrestS3Region)); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := util.ValidateBackrestGCSAzureStorage(backrestStorageType,
+		util.GetValueOrDefault(spec.BackrestGCSBucket, pgoConfig.Cluster.BackrestGCSBucket),
+		util.GetValueOrDefault(spec.BackrestGCSKeyType, pgoConfig.Cluster.BackrestGCSKeyType),
+		util.GetValueOrDefault(spec.BackrestAzureContainer, pgoConfig.Cluster.BackrestAzureContainer),
+		util.GetValueOrDefault(spec.BackrestAzureKeyType, pgoConfig.Cluster.BackrestAzureKeyType),
+		util.GetValueOrDefault(spec.BackrestAzureURIStyle, pgoConfig.Cluster.BackrestAzureURIStyle)); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if spec.Standby {
+		if err := util.ValidateStandbyCluster(backrestStorageType, spec.BackrestRepoPath); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if spec.Replicas != "" {
+		if replicas, err := strconv.Atoi(spec.Replicas); err != nil || replicas < 0 {
+			errs = append(errs, fmt.Sprintf("replicas %q must be a whole number that is 0 or greater",
+				spec.Replicas))
+		}
+	}
+
+	if err := util.ValidateBackrestRetention(spec.BackrestRetention); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := util.ValidateBackrestRepos(spec.BackrestRepos, backrestStorageType); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := util.ValidateSyncReplicationSettings(spec.SyncReplicationSettings); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := util.ValidatePostgresParameters(spec.Parameters); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if spec.PgBouncer.Replicas < 0 {
+		errs = append(errs, "pgBouncer replicas must be 0 or greater")
+	}
+
+	if spec.PgBouncer.ReadOnlyReplicas < 0 {
+		errs = append(errs, "read-only pgBouncer replicas must be 0 or greater")
+	}
+
+	if err := util.ValidatePgBouncerConfig(spec.PgBouncer.Config, spec.PgBouncer.Databases); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	return errs
+}
+
+// validatePgreplica validates the settings of a Pgreplica
+func validatePgreplica(replica *crv1.Pgreplica) []string {
+	errs := []string{}
+
+	if replica.Spec.ClusterName == "" {
+		errs = append(errs, "spec.clustername is required")
+	}
+
+	if err := util.ValidateStorageSpec(replica.Spec.ReplicaStorage); err != nil {
+		errs = append(errs, "replica storage: "+err.Error())
+	}
+
+	return errs
+}
+
+// validatePgtask validates the settings of a Pgtask
+func validatePgtask(task *crv1.Pgtask) []string {
+	errs := []string{}
+
+	if task.Spec.TaskType == "" {
+		errs = append(errs, "spec.tasktype is required")
+	}
+
+	if err := util.ValidateStorageSpec(task.Spec.StorageSpec); err != nil {
+		errs = append(errs, "storage: "+err.Error())
+	}
+
+	if storageType, ok := task.Spec.Parameters[config.LABEL_BACKREST_STORAGE_TYPE]; ok &&
+		storageType != "" && !util.IsValidBackrestStorageType(storageType) {
+		errs = append(errs, fmt.Sprintf("invalid pgBackRest storage type %q. The following values are allowed: %s",
+			storageType, "\""+strings.Join(crv1.BackrestStorageTypes, "\", \"")+"\""))
+	}
+
+	return errs
+}
+
+// validatePgpolicy validates the settings of a Pgpolicy
+func validatePgpolicy(policy *crv1.Pgpolicy) []string {
+	errs := []string{}
+
+	if policy.Spec.Name == "" {
+		errs = append(errs, "spec.name is required")
+	}
+
+	if policy.Spec.URL == "" && policy.Spec.SQL == "" {
+		errs = append(errs, "either spec.url or spec.sql is required")
+	}
+
+	return errs
+}
@@ -0,0 +1,287 @@
+package webhook
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidatePgcluster(t *testing.T) {
+	pgoConfig := &config.PgoConfig{}
+
+	tests := []struct {
+		name  string
+		spec  crv1.PgclusterSpec
+		valid bool
+	}{
+		{name: "empty", spec: crv1.PgclusterSpec{}, valid: true},
+		{name: "name mismatch", spec: crv1.PgclusterSpec{Name: "rhino"}, valid: false},
+		{name: "storage type", spec: crv1.PgclusterSpec{
+			PrimaryStorage: crv1.PgStorageSpec{StorageType: "hippo"},
+		}, valid: false},
+		{name: "storage size", spec: crv1.PgclusterSpec{
+			PrimaryStorage: crv1.PgStorageSpec{StorageType: "dynamic", Size: "1 Gi"},
+		}, valid: false},
+		{name: "tablespace storage", spec: crv1.PgclusterSpec{
+			TablespaceMounts: map[string]crv1.PgStorageSpec{"ts": {MatchLabels: "hippo"}},
+		}, valid: false},
+		{name: "pod anti-affinity", spec: crv1.PgclusterSpec{
+			PodAntiAffinity: crv1.PodAntiAffinitySpec{PgBouncer: "sometimes"},
+		}, valid: false},
+		{name: "s3 without a bucket", spec: crv1.PgclusterSpec{
+			UserLabels: map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "s3"},
+		}, valid: false},
+		{name: "s3", spec: crv1.PgclusterSpec{
+			UserLabels:         map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "s3"},
+			BackrestS3Bucket:   "bucket",
+			BackrestS3Endpoint: "endpoint",
+			BackrestS3Region:   "region",
+		}, valid: true},
+		{name: "gcs without a bucket", spec: crv1.PgclusterSpec{
+			UserLabels: map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "gcs"},
+		}, valid: false},
+		{name: "gcs", spec: crv1.PgclusterSpec{
+			UserLabels:          map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "gcs"},
+			BackrestGCSBucket:   "bucket",
+			BackrestGCSEndpoint: "fake-gcs-server:4443",
+			BackrestGCSKeyType:  "token",
+		}, valid: true},
+		{name: "gcs key type", spec: crv1.PgclusterSpec{
+			UserLabels:         map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "gcs"},
+			BackrestGCSBucket:  "bucket",
+			BackrestGCSKeyType: "password",
+		}, valid: false},
+		{name: "local and gcs", spec: crv1.PgclusterSpec{
+			UserLabels:        map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "local,gcs"},
+			BackrestGCSBucket: "bucket",
+		}, valid: false},
+		{name: "azure without a container", spec: crv1.PgclusterSpec{
+			UserLabels: map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "azure"},
+		}, valid: false},
+		{name: "azure", spec: crv1.PgclusterSpec{
+			UserLabels:                 map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "azure"},
+			BackrestAzureContainer:     "container",
+			BackrestAzureEndpoint:      "azurite:10000",
+			BackrestAzureURIStyle:      "path",
+			BackrestStorageNoVerifyTLS: true,
+		}, valid: true},
+		{name: "azure uri style", spec: crv1.PgclusterSpec{
+			UserLabels:             map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "azure"},
+			BackrestAzureContainer: "container",
+			BackrestAzureURIStyle:  "virtual",
+		}, valid: false},
+		{name: "standby without s3", spec: crv1.PgclusterSpec{
+			Standby:          true,
+			BackrestRepoPath: "/backrestrepo/hippo-backrest-shared-repo",
+		}, valid: false},
+		{name: "replicas", spec: crv1.PgclusterSpec{Replicas: "-1"}, valid: false},
+		{name: "pgBackRest retention", spec: crv1.PgclusterSpec{
+			BackrestRetention: crv1.PgBackRestRetentionSpec{Full: 2, ArchiveType: "weekly"},
+		}, valid: false},
+		{name: "pgBackRest local repository", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{{Type: "local", Schedule: "0 1 * * *"}},
+		}, valid: true},
+		{name: "pgBackRest repositories", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "local", Schedule: "0 1 * * *"},
+				{Type: "s3", Secret: "hippo-repo2", S3Bucket: "bucket", S3Endpoint: "endpoint", S3Region: "region",
+					Retention: crv1.PgBackRestRetentionSpec{Full: 7}},
+			},
+		}, valid: true},
+		{name: "pgBackRest azure repository", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "local"},
+				{Type: "azure", Secret: "hippo-repo2", AzureContainer: "container", AzureKeyType: "sas"},
+			},
+		}, valid: true},
+		{name: "pgBackRest azure repository without a container", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "local"},
+				{Type: "azure", Secret: "hippo-repo2"},
+			},
+		}, valid: false},
+		{name: "pgBackRest gcs repository", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "local"},
+				{Type: "gcs", Secret: "hippo-repo2"},
+			},
+		}, valid: false},
+		{name: "pgBackRest remote first repository", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "s3", Secret: "hippo-repo1", S3Bucket: "bucket", S3Endpoint: "endpoint", S3Region: "region"},
+			},
+		}, valid: false},
+		{name: "pgBackRest repository without a secret", spec: crv1.PgclusterSpec{
+			BackrestRepos: []crv1.PgBackRestRepoSpec{
+				{Type: "local"},
+				{Type: "s3", S3Bucket: "bucket", S3Endpoint: "endpoint", S3Region: "region"},
+			},
+		}, valid: false},
+		{name: "pgBackRest repositories with local and s3 storage", spec: crv1.PgclusterSpec{
+			UserLabels:         map[string]string{config.LABEL_BACKREST_STORAGE_TYPE: "local,s3"},
+			BackrestS3Bucket:   "bucket",
+			BackrestS3Endpoint: "endpoint",
+			BackrestS3Region:   "region",
+			BackrestRepos:      []crv1.PgBackRestRepoSpec{{Type: "local"}},
+		}, valid: false},
+		{name: "sync replication settings", spec: crv1.PgclusterSpec{
+			SyncReplicationSettings: &crv1.SyncReplicationSpec{
+				NodeCount: 1, Strict: true, ExcludedReplicas: []string{"hippo-abcd"},
+			},
+		}, valid: true},
+		{name: "sync replication node count", spec: crv1.PgclusterSpec{
+			SyncReplicationSettings: &crv1.SyncReplicationSpec{NodeCount: -1},
+		}, valid: false},
+		{name: "sync replication node count above one", spec: crv1.PgclusterSpec{
+			SyncReplicationSettings: &crv1.SyncReplicationSpec{NodeCount: 2},
+		}, valid: false},
+		{name: "sync replication excluded twice", spec: crv1.PgclusterSpec{
+			SyncReplicationSettings: &crv1.SyncReplicationSpec{ExcludedReplicas: []string{"hippo-abcd", "hippo-abcd"}},
+		}, valid: false},
+		{name: "postgres parameters", spec: crv1.PgclusterSpec{
+			Parameters: map[string]string{
+				"max_connections": "200", "shared_buffers": "256MB", "work_mem": "4096",
+				"checkpoint_timeout": "5min", "synchronous_commit": "remote_apply", "log_checkpoints": "on",
+			},
+		}, valid: true},
+		{name: "postgres parameter out of range", spec: crv1.PgclusterSpec{
+			Parameters: map[string]string{"shared_buffers": "64kB"},
+		}, valid: false},
+		{name: "postgres parameter unit", spec: crv1.PgclusterSpec{
+			Parameters: map[string]string{"statement_timeout": "5 parsecs"},
+		}, valid: false},
+		{name: "postgres managed parameter", spec: crv1.PgclusterSpec{
+			Parameters: map[string]string{"port": "5433"},
+		}, valid: false},
+		{name: "pgBouncer replicas", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{Replicas: -1},
+		}, valid: false},
+		{name: "read-only pgBouncer replicas", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{Replicas: 1, ReadOnlyReplicas: -1},
+		}, valid: false},
+		{name: "pgBouncer settings", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{
+				Config:    map[string]string{"pool_mode": "transaction", "default_pool_size": "50"},
+				Databases: map[string]map[string]string{"hippo": {"pool_size": "100"}},
+			},
+		}, valid: true},
+		{name: "pgBouncer pool mode", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{Config: map[string]string{"pool_mode": "sometimes"}},
+		}, valid: false},
+		{name: "pgBouncer managed setting", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{Config: map[string]string{"listen_port": "6432"}},
+		}, valid: false},
+		{name: "pgBouncer database setting", spec: crv1.PgclusterSpec{
+			PgBouncer: crv1.PgBouncerSpec{
+				Databases: map[string]map[string]string{"hippo": {"host": "elsewhere"}},
+			},
+		}, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := &crv1.Pgcluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "hippo"},
+				Spec:       test.spec,
+			}
+
+			if errs := validatePgcluster(cluster, pgoConfig); (len(errs) == 0) != test.valid {
+				t.Errorf("expected valid to be %t, got errors %v", test.valid, errs)
+			}
+		})
+	}
+}
+
+func TestDefaultPgcluster(t *testing.T) {
+	pgoConfig := &config.PgoConfig{
+		PrimaryStorage: "nfsstorage",
+		Storage: map[string]config.StorageStruct{
+			"nfsstorage": {StorageType: "create", Size: "1G"},
+		},
+	}
+	pgoConfig.Cluster.Port = "5432"
+	pgoConfig.Cluster.DefaultInstanceResourceMemory = config.DefaultInstanceResourceMemory
+
+	cluster := &crv1.Pgcluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hippo",
+			Namespace: "pgo",
+			Labels:    map[string]string{config.LABEL_AUTOFAIL: "false"},
+		},
+		Spec: crv1.PgclusterSpec{
+			User: "hippo",
+		},
+	}
+
+	values := map[string]interface{}{}
+	for _, op := range defaultPgcluster(cluster, pgoConfig) {
+		if op.Op != "add" {
+			t.Errorf("expected only add operations, got %q", op.Op)
+		}
+		values[op.Path] = op.Value
+	}
+
+	if values["/spec/name"] != "hippo" || values["/spec/clustername"] != "hippo" {
+		t.Errorf("expected the names to follow the pgcluster, got %v", values)
+	}
+	if values["/spec/namespace"] != "pgo" {
+		t.Errorf("expected namespace %q, got %v", "pgo", values["/spec/namespace"])
+	}
+	if values["/spec/port"] != "5432" {
+		t.Errorf("expected port %q, got %v", "5432", values["/spec/port"])
+	}
+	if values["/spec/ccpimage"] != defaultCCPImage {
+		t.Errorf("expected ccpimage %q, got %v", defaultCCPImage, values["/spec/ccpimage"])
+	}
+	if values["/spec/database"] != "hippo" {
+		t.Errorf("expected database %q, got %v", "hippo", values["/spec/database"])
+	}
+	if _, ok := values["/spec/user"]; ok {
+		t.Error("expected the user that is set to be kept")
+	}
+
+	if storage, ok := values["/spec/PrimaryStorage"].(crv1.PgStorageSpec); !ok || storage.Size != "1G" {
+		t.Errorf("expected the primary storage to be defaulted, got %v", values["/spec/PrimaryStorage"])
+	}
+	if _, ok := values["/spec/ReplicaStorage"]; ok {
+		t.Error("expected no replica storage as none is configured")
+	}
+
+	resources, ok := values["/spec/resources"].(v1.ResourceList)
+	if memory := resources[v1.ResourceMemory]; !ok || memory.Cmp(config.DefaultInstanceResourceMemory) != 0 {
+		t.Errorf("expected the memory request to be defaulted, got %v", values["/spec/resources"])
+	}
+	if _, ok := values["/spec/pgBouncer/resources"]; ok {
+		t.Error("expected no pgBouncer resources as pgBouncer is not enabled")
+	}
+
+	labels, ok := values["/metadata/labels"].(map[string]string)
+	if !ok {
+		t.Fatalf("expected labels, got %v", values["/metadata/labels"])
+	}
+	if labels[config.LABEL_AUTOFAIL] != "false" {
+		t.Errorf("expected the autofail label to be kept, got %q", labels[config.LABEL_AUTOFAIL])
+	}
+	if labels[config.LABEL_NAME] != "hippo" || labels[config.LABEL_BACKREST] != "true" {
+		t.Errorf("expected the labels to be defaulted, got %v", labels)
+	}
+}
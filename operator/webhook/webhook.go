@@ -0,0 +1,170 @@
+// Package webhook serves the validating and mutating admission webhooks of the
+// Operator, which check the Pgcluster, Pgreplica, Pgtask and Pgpolicy custom
+// resources when they are applied, including those that are applied directly
+// rather than through the apiserver
+package webhook
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crunchydata/postgres-operator/config"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ValidatePath is the path the validating webhook is served under
+	ValidatePath = "/validate"
+	// MutatePath is the path the mutating webhook is served under
+	MutatePath = "/mutate"
+)
+
+// Server serves the admission webhooks
+type Server struct {
+	Clientset *kubernetes.Clientset
+	// PgoConfig is the configuration of the Operator, which provides the defaults
+	// of a Pgcluster as well as the storage configurations
+	PgoConfig *config.PgoConfig
+}
+
+// admitFunc decides whether or not the object of an admission request is
+// admitted, and may return a JSON patch to apply to it
+type admitFunc func(request *admissionv1beta1.AdmissionRequest) (patch []jsonPatchOperation, err error)
+
+// jsonPatchOperation is a single operation of a JSON patch, see RFC 6902
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Handler returns the HTTP handler that serves both webhooks
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, s.validate)
+	})
+	mux.HandleFunc(MutatePath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, s.mutate)
+	})
+	return mux
+}
+
+// ListenAndServe serves the webhooks over TLS on the port provided, using the
+// certificate and key provided, until a message is received on the stop channel
+func (s *Server) ListenAndServe(port int, certPEM, keyPEM []byte, stopCh <-chan struct{}) error {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: s.Handler(),
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		},
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-stopCh
+		if err := server.Close(); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	log.Infof("admission webhooks listening on port %d", port)
+
+	if err := server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// serve decodes the AdmissionReview of a request, admits its object with the
+// admitFunc and writes the response
+func serve(w http.ResponseWriter, r *http.Request, admit admitFunc) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "could not decode the AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = respond(review.Request, admit)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Error(err)
+	}
+}
+
+// respond admits the object of a request and returns the response
+func respond(request *admissionv1beta1.AdmissionRequest, admit admitFunc) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+
+	patch, err := admit(request)
+	if err != nil {
+		log.Infof("admission webhook: rejected %s %s/%s: %s", strings.ToLower(request.Kind.Kind),
+			request.Namespace, request.Name, err.Error())
+
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+		return response
+	}
+
+	if len(patch) > 0 {
+		patchBytes, err := json.Marshal(patch)
+		if err != nil {
+			log.Error(err)
+			return response
+		}
+
+		patchType := admissionv1beta1.PatchTypeJSONPatch
+		response.Patch = patchBytes
+		response.PatchType = &patchType
+	}
+
+	return response
+}
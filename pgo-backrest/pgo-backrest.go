@@ -32,6 +32,7 @@ const backrestInfoCommand = `info`
 const backrestStanzaCreateCommand = `stanza-create`
 const containername = "database"
 const repoTypeFlagS3 = "--repo-type=s3"
+const repoTypeFlag = "--repo-type="
 
 func main() {
 	log.Info("pgo-backrest starts")
@@ -113,9 +114,9 @@ func main() {
 		cmdStrs = append(cmdStrs, strings.Join(firstCmd, " "))
 		cmdStrs = append(cmdStrs, repoTypeFlagS3)
 		log.Info("backrest command will be executed for both local and s3 storage")
-	} else if REPO_TYPE == "s3" {
-		cmdStrs = append(cmdStrs, repoTypeFlagS3)
-		log.Info("s3 flag enabled for backrest command")
+	} else if REPO_TYPE == "s3" || REPO_TYPE == "gcs" || REPO_TYPE == "azure" {
+		cmdStrs = append(cmdStrs, repoTypeFlag+REPO_TYPE)
+		log.Infof("%s flag enabled for backrest command", REPO_TYPE)
 	}
 
 	log.Infof("command to execute is [%s]", strings.Join(cmdStrs, " "))
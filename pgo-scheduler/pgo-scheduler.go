@@ -17,6 +17,7 @@ limitations under the License.
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
 	"os/signal"
 	"strconv"
@@ -53,6 +54,10 @@ var timeout time.Duration
 var seconds int
 var kubeClient *kubernetes.Clientset
 
+// backupLimit is the number of scheduled backups that may run at the same time
+// in a namespace, as set in the Pgo configuration
+var backupLimit int
+
 // this is used to prevent a race condition where an informer is being created
 // twice when a new scheduler-enabled ConfigMap is added.
 var informerNsMutex sync.Mutex
@@ -112,6 +117,7 @@ func init() {
 	if err := Pgo.GetConfig(kubeClient, pgoNamespace); err != nil {
 		log.WithFields(log.Fields{}).Fatalf("error in Pgo configuration: %s", err)
 	}
+	backupLimit = Pgo.Pgo.ScheduledBackupLimit
 
 	// Configure namespaces for the Scheduler.  This includes determining the namespace
 	// operating mode and obtaining a valid list of target namespaces for the operator install.
@@ -123,10 +129,15 @@ func init() {
 
 func main() {
 	log.Info("Starting Crunchy Scheduler")
+
+	// seed the random delays that spread out the runs of the schedules that
+	// have a jitter
+	rand.Seed(time.Now().UnixNano())
+
 	//give time for pgo-event to start up
 	time.Sleep(time.Duration(5) * time.Second)
 
-	scheduler := scheduler.New(schedulerLabel, pgoNamespace, kubeClient)
+	scheduler := scheduler.New(schedulerLabel, pgoNamespace, backupLimit, kubeClient)
 	scheduler.CronClient.Start()
 
 	sigs := make(chan os.Signal, 1)
@@ -0,0 +1,101 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// the concurrency policies of a schedule, which decide what happens when a
+// schedule is due while its previous run has not finished. They follow those of
+// a Kubernetes CronJob
+const (
+	// ConcurrencyAllow runs the schedule alongside its previous runs
+	ConcurrencyAllow = "Allow"
+	// ConcurrencyForbid skips the run of the schedule
+	ConcurrencyForbid = "Forbid"
+	// ConcurrencyReplace stops the previous run and starts a new one, which is
+	// the default
+	ConcurrencyReplace = "Replace"
+)
+
+// ConcurrencyPolicies are the concurrency policies a schedule may have
+var ConcurrencyPolicies = []string{ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace}
+
+// backupScheduleTypes are the types of schedules whose runs count towards the
+// limit of scheduled backups running at the same time in a namespace. A
+// verification counts as it reads from the backup repository
+var backupScheduleTypes = map[string]bool{"pgbackrest": true, "pgbackrest-verify": true, "pgdump": true}
+
+// backupSlots limits the number of scheduled backups that run at the same time
+// in each namespace. A limit of 0 means there is no limit
+type backupSlots struct {
+	mutex   sync.Mutex
+	limit   int
+	running map[string]int
+}
+
+func newBackupSlots(limit int) *backupSlots {
+	return &backupSlots{
+		limit:   limit,
+		running: map[string]int{},
+	}
+}
+
+// acquire takes a slot in a namespace if one is available, and returns whether
+// a slot was taken
+func (b *backupSlots) acquire(namespace string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.limit > 0 && b.running[namespace] >= b.limit {
+		return false
+	}
+
+	b.running[namespace]++
+	return true
+}
+
+// add takes a slot in a namespace regardless of the limit, e.g. for a backup
+// that was already running when the scheduler started
+func (b *backupSlots) add(namespace string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.running[namespace]++
+}
+
+// release gives back a slot in a namespace
+func (b *backupSlots) release(namespace string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.running[namespace] > 0 {
+		b.running[namespace]--
+	}
+}
+
+// jitterDelay returns a random delay between 0 and the jitter of a schedule,
+// which spreads out the runs of schedules that are due at the same time
+func jitterDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackupSlots(t *testing.T) {
+	slots := newBackupSlots(2)
+
+	if !slots.acquire("pgouser1") || !slots.acquire("pgouser1") {
+		t.Fatal("expected two slots to be available")
+	}
+	if slots.acquire("pgouser1") {
+		t.Error("expected the limit of the namespace to be reached")
+	}
+	if !slots.acquire("pgouser2") {
+		t.Error("expected the limit to apply to each namespace")
+	}
+
+	slots.release("pgouser1")
+	if !slots.acquire("pgouser1") {
+		t.Error("expected a released slot to be available")
+	}
+
+	unlimited := newBackupSlots(0)
+	for i := 0; i < 10; i++ {
+		if !unlimited.acquire("pgouser1") {
+			t.Fatal("expected no limit")
+		}
+	}
+}
+
+func TestJitterDelay(t *testing.T) {
+	if delay := jitterDelay(0); delay != 0 {
+		t.Errorf("expected no delay without a jitter, got %s", delay)
+	}
+
+	for i := 0; i < 100; i++ {
+		if delay := jitterDelay(time.Minute); delay < 0 || delay >= time.Minute {
+			t.Fatalf("expected a delay of less than a minute, got %s", delay)
+		}
+	}
+}
+
+func TestActiveRuns(t *testing.T) {
+	start := time.Date(2020, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	history := []ScheduleRun{
+		{Started: start, Status: RunStatusSucceeded},
+		{Started: start.Add(time.Hour), Status: RunStatusSubmitted},
+		{Started: start.Add(2 * time.Hour), Status: RunStatusReplaced},
+	}
+
+	if active := activeRuns(history); len(active) != 1 || !active[0].Started.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the submitted run to be active, got %+v", active)
+	}
+
+	if !isRunActive(history, ScheduleRun{Started: start.Add(time.Hour)}) {
+		t.Error("expected the submitted run to be active")
+	}
+	if isRunActive(history, ScheduleRun{Started: start.Add(2 * time.Hour)}) {
+		t.Error("expected the replaced run not to be active")
+	}
+	if isRunActive(history, ScheduleRun{Started: start.Add(3 * time.Hour)}) {
+		t.Error("expected a run missing from the history not to be active")
+	}
+}
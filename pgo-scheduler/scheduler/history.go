@@ -0,0 +1,473 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/events"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+
+	cv2 "github.com/robfig/cron"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// the statuses of a run of a schedule
+const (
+	RunStatusSubmitted = "submitted"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+	RunStatusMissed    = "missed"
+	RunStatusSkipped   = "skipped"
+	RunStatusReplaced  = "replaced"
+)
+
+// scheduleHistoryLimit is the number of runs of a schedule that are kept in its
+// history
+const scheduleHistoryLimit = 10
+
+// runPollInterval is how often the Job of a run is checked for its outcome, and
+// runTimeout is how long a run may take before it is considered failed
+var runPollInterval = 30 * time.Second
+var runTimeout = 24 * time.Hour
+
+// ScheduleRun is a run of a schedule, as stored in the history of the schedule
+type ScheduleRun struct {
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Task     string     `json:"task,omitempty"`
+	Job      string     `json:"job,omitempty"`
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// scheduleRunner carries out a run of a schedule, filling in the Pgtask or the
+// Job it creates. The suffix is appended to the names of what the run creates,
+// so that runs of a schedule that are allowed to overlap do not replace one
+// another, and cleanup removes what a finished run created
+type scheduleRunner interface {
+	run(run *ScheduleRun, suffix string) error
+	cleanup(run ScheduleRun)
+}
+
+// scheduleJob is the cron job of a schedule. It records each run of the schedule
+// in the history of the schedule, and follows it until its Job finishes
+type scheduleJob struct {
+	name              string
+	namespace         string
+	cluster           string
+	scheduleType      string
+	concurrencyPolicy string
+	jitter            time.Duration
+	schedule          cv2.Schedule
+	slots             *backupSlots
+	runner            scheduleRunner
+}
+
+func (j scheduleJob) Run() {
+	time.Sleep(jitterDelay(j.jitter))
+
+	run := ScheduleRun{Started: time.Now(), Status: RunStatusSubmitted}
+
+	history := j.getHistory()
+	active := activeRuns(history)
+
+	switch {
+	case len(active) > 0 && j.concurrencyPolicy == ConcurrencyForbid:
+		j.finish(run, RunStatusSkipped,
+			fmt.Sprintf("the run that started at %s has not finished", active[0].Started.Format(time.RFC3339)))
+		return
+	case j.concurrencyPolicy == ConcurrencyAllow:
+		for _, previous := range history {
+			if previous.Status != RunStatusSubmitted {
+				j.runner.cleanup(previous)
+			}
+		}
+	default:
+		// the runs are stopped when the new run removes what they created
+		for _, previous := range active {
+			j.finish(previous, RunStatusReplaced,
+				fmt.Sprintf("replaced by the run that started at %s", run.Started.Format(time.RFC3339)))
+		}
+	}
+
+	if !j.acquireSlot(run) {
+		j.finish(run, RunStatusSkipped, fmt.Sprintf(
+			"the limit of %d scheduled backups running at the same time in the namespace was reached",
+			j.slots.limit))
+		return
+	}
+
+	suffix := ""
+	if j.concurrencyPolicy == ConcurrencyAllow {
+		suffix = "-" + run.Started.Format("20060102150405")
+	}
+
+	if err := j.runner.run(&run, suffix); err != nil {
+		j.releaseSlot()
+		j.finish(run, RunStatusFailed, err.Error())
+		return
+	}
+
+	j.record(run)
+	go j.follow(run)
+}
+
+// isLimited returns whether the runs of the schedule count towards the limit of
+// scheduled backups running at the same time in a namespace
+func (j scheduleJob) isLimited() bool {
+	return j.slots != nil && backupScheduleTypes[j.scheduleType]
+}
+
+// acquireSlot waits for a scheduled backup to be allowed to run in the namespace
+// of the schedule. It gives up once the schedule is due again, so that the runs
+// of the schedule do not pile up
+func (j scheduleJob) acquireSlot(run ScheduleRun) bool {
+	if !j.isLimited() {
+		return true
+	}
+
+	deadline := run.Started.Add(runTimeout)
+	if j.schedule != nil {
+		deadline = j.schedule.Next(run.Started)
+	}
+
+	for !j.slots.acquire(j.namespace) {
+		if time.Now().Add(runPollInterval).After(deadline) {
+			return false
+		}
+
+		time.Sleep(runPollInterval)
+	}
+
+	return true
+}
+
+// releaseSlot gives back the slot taken by a run of the schedule
+func (j scheduleJob) releaseSlot() {
+	if j.isLimited() {
+		j.slots.release(j.namespace)
+	}
+}
+
+// finish records the outcome of a run, and publishes an event if the run did
+// not succeed. A run that was replaced by a newer run is not reported
+func (j scheduleJob) finish(run ScheduleRun, status, message string) {
+	finished := time.Now()
+	run.Finished = &finished
+	run.Status = status
+	run.Error = message
+
+	j.record(run)
+
+	if status == RunStatusSucceeded || status == RunStatusReplaced {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"schedule":  j.name,
+		"namespace": j.namespace,
+		"status":    status,
+		"error":     message,
+	}).Error("schedule run did not succeed")
+
+	f := events.EventScheduleRunFailureFormat{
+		EventHeader: events.EventHeader{
+			Namespace: j.namespace,
+			Topic:     []string{events.EventTopicBackup},
+			Timestamp: time.Now(),
+			EventType: events.EventScheduleRunFailure,
+		},
+		Clustername:  j.cluster,
+		ScheduleName: j.name,
+		ScheduleType: j.scheduleType,
+		Status:       status,
+		ErrorMessage: message,
+	}
+
+	if err := events.Publish(f); err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// follow waits for the Job of a run to finish and records its outcome. It stops
+// once the run is no longer submitted, e.g. when it was replaced by a newer run
+func (j scheduleJob) follow(run ScheduleRun) {
+	defer j.releaseSlot()
+
+	for {
+		if !isRunActive(j.getHistory(), run) {
+			return
+		}
+
+		job, found := j.getRunJob(&run)
+
+		if found {
+			if status, message, finished := jobRunStatus(job); finished {
+				j.finish(run, status, message)
+				return
+			}
+		} else if status, message, finished := j.taskRunStatus(run); finished {
+			j.finish(run, status, message)
+			return
+		}
+
+		if time.Since(run.Started) > runTimeout {
+			j.finish(run, RunStatusFailed, fmt.Sprintf("the run did not finish within %s", runTimeout))
+			return
+		}
+
+		time.Sleep(runPollInterval)
+	}
+}
+
+// getRunJob returns the Job of a run. When the Job is created by the Operator,
+// it is found by the label of the Pgtask of the run
+func (j scheduleJob) getRunJob(run *ScheduleRun) (*v1batch.Job, bool) {
+	if run.Job != "" {
+		return kubeapi.GetJob(kubeClient, run.Job, j.namespace)
+	}
+
+	selector := fmt.Sprintf("%s=%s", config.LABEL_PGTASK, run.Task)
+	jobs, err := kubeapi.GetJobs(kubeClient, selector, j.namespace)
+	if err != nil || len(jobs.Items) == 0 {
+		return nil, false
+	}
+
+	run.Job = jobs.Items[0].Name
+	return &jobs.Items[0], true
+}
+
+// taskRunStatus returns the outcome of a run whose Job is not found, and
+// whether the run has finished. The Job may still be waiting on the Operator to
+// create it, or may have been removed by the Operator once its outcome was
+// recorded on the Pgtask of the run
+func (j scheduleJob) taskRunStatus(run ScheduleRun) (status, message string, finished bool) {
+	if run.Task == "" {
+		return RunStatusFailed, "the job of the run no longer exists", true
+	}
+
+	task := crv1.Pgtask{}
+	if _, err := kubeapi.Getpgtask(restClient, &task, run.Task, j.namespace); kerrors.IsNotFound(err) {
+		return RunStatusFailed, "the job of the run no longer exists", true
+	} else if err != nil {
+		return RunStatusSubmitted, "", false
+	}
+
+	return pgtaskRunStatus(&task)
+}
+
+// getHistory returns the history of the schedule from its ConfigMap
+func (j scheduleJob) getHistory() []ScheduleRun {
+	cm, found := kubeapi.GetConfigMap(kubeClient, j.name, j.namespace)
+	if !found {
+		return []ScheduleRun{}
+	}
+
+	return GetScheduleHistory(cm)
+}
+
+// record stores a run in the history on the ConfigMap of the schedule,
+// replacing the run that started at the same time if there is one
+func (j scheduleJob) record(run ScheduleRun) {
+	// the runs of a schedule may finish at the same time, so the update is
+	// retried if the ConfigMap was changed in the meantime
+	for attempt := 0; attempt < 3; attempt++ {
+		cm, found := kubeapi.GetConfigMap(kubeClient, j.name, j.namespace)
+		if !found {
+			log.WithFields(log.Fields{
+				"schedule":  j.name,
+				"namespace": j.namespace,
+			}).Error("could not find the configmap of the schedule to record its run")
+			return
+		}
+
+		history := addScheduleRun(GetScheduleHistory(cm), run, scheduleHistoryLimit)
+
+		data, err := json.Marshal(history)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[config.ANNOTATION_SCHEDULE_HISTORY] = string(data)
+
+		err = kubeapi.UpdateConfigMap(kubeClient, cm, j.namespace)
+		if err == nil || !kerrors.IsConflict(err) {
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"schedule":  j.name,
+		"namespace": j.namespace,
+	}).Error("could not record the run of the schedule")
+}
+
+// resume is called when a schedule is added to the scheduler, e.g. when the
+// scheduler starts. It records the runs that were missed while the scheduler was
+// not running, and resumes following the runs that had not finished
+func (j scheduleJob) resume(cm *v1.ConfigMap, st ScheduleTemplate) {
+	history := GetScheduleHistory(cm)
+
+	for _, run := range history {
+		if run.Status == RunStatusSubmitted {
+			// the runs that were running before the scheduler started take their
+			// slots regardless of the limit
+			if j.isLimited() {
+				j.slots.add(j.namespace)
+			}
+			go j.follow(run)
+		}
+	}
+
+	// a schedule without any runs is checked from when it was created
+	last := cm.CreationTimestamp.Time
+	if len(history) > 0 {
+		last = history[len(history)-1].Started
+	}
+
+	schedule, err := cv2.ParseStandard(st.Schedule)
+	if err != nil {
+		return
+	}
+
+	// the missed runs are recorded as a single run at the time of the last of
+	// them, so that they are not reported again
+	if first, latest, missed := missedRuns(schedule, last, time.Now()); missed > 0 {
+		j.finish(ScheduleRun{Started: latest}, RunStatusMissed,
+			fmt.Sprintf("%d scheduled run(s) were missed since %s while the scheduler was not running",
+				missed, first.Format(time.RFC3339)))
+	}
+}
+
+// addScheduleRun adds a run to a history, or replaces the run that started at
+// the same time, and keeps only the most recent runs of the history
+func addScheduleRun(history []ScheduleRun, run ScheduleRun, limit int) []ScheduleRun {
+	replaced := false
+	for i := range history {
+		if history[i].Started.Equal(run.Started) {
+			history[i] = run
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		history = append(history, run)
+	}
+
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	return history
+}
+
+// activeRuns returns the runs of a history that have not finished
+func activeRuns(history []ScheduleRun) []ScheduleRun {
+	active := []ScheduleRun{}
+	for _, run := range history {
+		if run.Status == RunStatusSubmitted {
+			active = append(active, run)
+		}
+	}
+
+	return active
+}
+
+// isRunActive returns whether a run is still submitted in a history. A run that
+// is no longer in the history is not followed any further
+func isRunActive(history []ScheduleRun, run ScheduleRun) bool {
+	for _, r := range history {
+		if r.Started.Equal(run.Started) {
+			return r.Status == RunStatusSubmitted
+		}
+	}
+
+	return false
+}
+
+// GetScheduleHistory returns the history of a schedule from its ConfigMap
+func GetScheduleHistory(cm *v1.ConfigMap) []ScheduleRun {
+	history := []ScheduleRun{}
+
+	if data, ok := cm.Annotations[config.ANNOTATION_SCHEDULE_HISTORY]; ok {
+		if err := json.Unmarshal([]byte(data), &history); err != nil {
+			log.Warnf("ignoring the invalid history of schedule %s: %s", cm.Name, err)
+			return []ScheduleRun{}
+		}
+	}
+
+	return history
+}
+
+// jobRunStatus returns the outcome of the Job of a run, and whether the Job has
+// finished
+func jobRunStatus(job *v1batch.Job) (status, message string, finished bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case v1batch.JobComplete:
+			return RunStatusSucceeded, "", true
+		case v1batch.JobFailed:
+			return RunStatusFailed, fmt.Sprintf("%s: %s", condition.Reason, condition.Message), true
+		}
+	}
+
+	return RunStatusSubmitted, "", false
+}
+
+// pgtaskRunStatus returns the outcome of a run as recorded on its Pgtask, and
+// whether the run has finished
+func pgtaskRunStatus(task *crv1.Pgtask) (status, message string, finished bool) {
+	switch {
+	case strings.HasPrefix(task.Spec.Status, crv1.JobCompletedStatus):
+		return RunStatusSucceeded, "", true
+	case strings.HasPrefix(task.Spec.Status, crv1.JobErrorStatus):
+		return RunStatusFailed, task.Status.Message, true
+	}
+
+	return RunStatusSubmitted, "", false
+}
+
+// missedRuns returns the first and the last time, as well as the number of
+// times, that a schedule was due after its last run, allowing a minute for a run
+// to start
+func missedRuns(schedule cv2.Schedule, last, now time.Time) (first, latest time.Time, missed int) {
+	for next := schedule.Next(last); next.Add(time.Minute).Before(now); next = schedule.Next(next) {
+		if missed == 0 {
+			first = next
+		}
+		latest = next
+		missed++
+	}
+
+	return first, latest, missed
+}
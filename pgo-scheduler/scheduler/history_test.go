@@ -0,0 +1,161 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+	"time"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+
+	cv2 "github.com/robfig/cron"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddScheduleRun(t *testing.T) {
+	start := time.Date(2020, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	history := []ScheduleRun{}
+	for i := 0; i < 4; i++ {
+		history = addScheduleRun(history, ScheduleRun{
+			Started: start.Add(time.Duration(i) * time.Hour),
+			Status:  RunStatusSubmitted,
+		}, 3)
+	}
+
+	if len(history) != 3 {
+		t.Fatalf("expected the history to be limited to 3 runs, got %d", len(history))
+	}
+	if !history[0].Started.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the oldest run to be removed, got %v", history[0].Started)
+	}
+
+	history = addScheduleRun(history, ScheduleRun{
+		Started: start.Add(3 * time.Hour),
+		Status:  RunStatusSucceeded,
+	}, 3)
+
+	if len(history) != 3 || history[2].Status != RunStatusSucceeded {
+		t.Errorf("expected the run to be replaced, got %+v", history)
+	}
+}
+
+func TestGetScheduleHistory(t *testing.T) {
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{
+			config.ANNOTATION_SCHEDULE_HISTORY: `[{"started":"2020-03-01T01:00:00Z","status":"failed","error":"oops"}]`,
+		},
+	}}
+
+	history := GetScheduleHistory(cm)
+	if len(history) != 1 || history[0].Status != RunStatusFailed || history[0].Error != "oops" {
+		t.Errorf("expected the run of the annotation, got %+v", history)
+	}
+
+	cm.Annotations[config.ANNOTATION_SCHEDULE_HISTORY] = "{"
+	if history := GetScheduleHistory(cm); len(history) != 0 {
+		t.Errorf("expected an invalid history to be ignored, got %+v", history)
+	}
+}
+
+func TestJobRunStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []v1batch.JobCondition
+		status     string
+		finished   bool
+	}{
+		{name: "running", status: RunStatusSubmitted, finished: false},
+		{name: "complete", conditions: []v1batch.JobCondition{
+			{Type: v1batch.JobComplete, Status: v1.ConditionTrue},
+		}, status: RunStatusSucceeded, finished: true},
+		{name: "failed", conditions: []v1batch.JobCondition{
+			{Type: v1batch.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+		}, status: RunStatusFailed, finished: true},
+		{name: "not yet failed", conditions: []v1batch.JobCondition{
+			{Type: v1batch.JobFailed, Status: v1.ConditionFalse},
+		}, status: RunStatusSubmitted, finished: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job := &v1batch.Job{Status: v1batch.JobStatus{Conditions: test.conditions}}
+
+			if status, _, finished := jobRunStatus(job); status != test.status || finished != test.finished {
+				t.Errorf("expected %q and %t, got %q and %t", test.status, test.finished, status, finished)
+			}
+		})
+	}
+}
+
+func TestPgtaskRunStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		message  string
+		expected string
+		finished bool
+	}{
+		{name: "pending", status: "", expected: RunStatusSubmitted, finished: false},
+		{name: "completed", status: crv1.JobCompletedStatus, expected: RunStatusSucceeded, finished: true},
+		{name: "error", status: crv1.JobErrorStatus + " [verify]", message: "query failed",
+			expected: RunStatusFailed, finished: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			task := &crv1.Pgtask{
+				Spec:   crv1.PgtaskSpec{Status: test.status},
+				Status: crv1.PgtaskStatus{Message: test.message},
+			}
+
+			status, message, finished := pgtaskRunStatus(task)
+			if status != test.expected || finished != test.finished || message != test.message {
+				t.Errorf("expected %q, %q and %t, got %q, %q and %t",
+					test.expected, test.message, test.finished, status, message, finished)
+			}
+		})
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	schedule, err := cv2.ParseStandard("0 1 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := time.Date(2020, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	if _, _, missed := missedRuns(schedule, last, last.Add(23*time.Hour)); missed != 0 {
+		t.Errorf("expected no missed runs before the next run is due, got %d", missed)
+	}
+
+	// the next run is given a minute to start
+	if _, _, missed := missedRuns(schedule, last, last.Add(24*time.Hour+30*time.Second)); missed != 0 {
+		t.Errorf("expected no missed runs while the next run is starting, got %d", missed)
+	}
+
+	first, latest, missed := missedRuns(schedule, last, last.Add(72*time.Hour+time.Hour))
+	if missed != 3 {
+		t.Errorf("expected 3 missed runs, got %d", missed)
+	}
+	if !first.Equal(last.Add(24*time.Hour)) || !latest.Equal(last.Add(72*time.Hour)) {
+		t.Errorf("expected the missed runs to span the 2nd to the 4th, got %v to %v", first, latest)
+	}
+}
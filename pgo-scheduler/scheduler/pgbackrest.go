@@ -22,6 +22,7 @@ import (
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/config"
 	"github.com/crunchydata/postgres-operator/kubeapi"
+	"github.com/crunchydata/postgres-operator/util"
 	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -48,11 +49,11 @@ func (s *ScheduleTemplate) NewBackRestSchedule() BackRestBackupJob {
 		container:   s.PGBackRest.Container,
 		cluster:     s.Cluster,
 		storageType: s.PGBackRest.StorageType,
-		options:     s.Options,
+		options:     s.PGBackRest.Options,
 	}
 }
 
-func (b BackRestBackupJob) Run() {
+func (b BackRestBackupJob) run(run *ScheduleRun, suffix string) error {
 	contextLogger := log.WithFields(log.Fields{
 		"namespace":   b.namespace,
 		"deployment":  b.deployment,
@@ -71,15 +72,15 @@ func (b BackRestBackupJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("pgCluster not found")
-		return
+		return fmt.Errorf("pgcluster %s not found", b.cluster)
 	} else if err != nil {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("error retrieving pgCluster")
-		return
+		return err
 	}
 
-	taskName := fmt.Sprintf("%s-%s-sch-backup", b.cluster, b.backupType)
+	taskName := fmt.Sprintf("%s-%s-sch-backup%s", b.cluster, b.backupType, suffix)
 
 	result := crv1.Pgtask{}
 	found, err = kubeapi.Getpgtask(restClient, &result, taskName, b.namespace)
@@ -91,7 +92,7 @@ func (b BackRestBackupJob) Run() {
 				"task":  taskName,
 				"error": err,
 			}).Error("error deleting pgTask")
-			return
+			return err
 		}
 
 		job, found := kubeapi.GetJob(kubeClient, taskName, b.namespace)
@@ -103,7 +104,7 @@ func (b BackRestBackupJob) Run() {
 					"task":  taskName,
 					"error": err,
 				}).Error("error deleting backup job")
-				return
+				return err
 			}
 
 			timeout := time.Second * 60
@@ -113,7 +114,7 @@ func (b BackRestBackupJob) Run() {
 					"task":  taskName,
 					"error": err,
 				}).Error("error waiting for job to delete")
-				return
+				return err
 			}
 		}
 	} else if err != nil && !kerrors.IsNotFound(err) {
@@ -121,7 +122,7 @@ func (b BackRestBackupJob) Run() {
 			"task":  taskName,
 			"error": err,
 		}).Error("error getting pgTask")
-		return
+		return err
 	}
 
 	selector := fmt.Sprintf("%s=%s,pgo-backrest-repo=true", config.LABEL_PG_CLUSTER, b.cluster)
@@ -131,7 +132,7 @@ func (b BackRestBackupJob) Run() {
 			"selector": selector,
 			"error":    err,
 		}).Error("error getting pods from selector")
-		return
+		return err
 	}
 
 	if len(pods.Items) != 1 {
@@ -140,7 +141,19 @@ func (b BackRestBackupJob) Run() {
 			"error":     err,
 			"podsFound": len(pods.Items),
 		}).Error("pods returned does not equal 1, it should")
-		return
+		return fmt.Errorf("found %d pgBackRest repository pods instead of 1", len(pods.Items))
+	}
+
+	// the retention policy of the cluster takes precedence over any retention
+	// options of a schedule that was created before the policy was set
+	options := b.options
+	if cluster.Spec.BackrestRetention.IsSet() {
+		var found []string
+		if found, options = util.SplitPgBackRestRetentionOptions(options); len(found) > 0 {
+			contextLogger.WithFields(log.Fields{
+				"options": found,
+			}).Warn("ignoring the retention options of the schedule as the cluster has a retention policy")
+		}
 	}
 
 	backrest := pgBackRestTask{
@@ -148,7 +161,7 @@ func (b BackRestBackupJob) Run() {
 		taskName:      taskName,
 		podName:       pods.Items[0].Name,
 		containerName: "database",
-		backupOptions: fmt.Sprintf("--type=%s %s", b.backupType, b.options),
+		backupOptions: fmt.Sprintf("--type=%s %s", b.backupType, options),
 		stanza:        b.stanza,
 		storageType:   b.storageType,
 		imagePrefix:   cluster.Spec.PGOImagePrefix,
@@ -159,6 +172,37 @@ func (b BackRestBackupJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("could not create new pgtask")
+		return err
+	}
+
+	// the Job of the backup is named after its task
+	run.Task, run.Job = taskName, taskName
+
+	return nil
+}
+
+// cleanup removes the task and the Job of a finished backup, while the backup
+// itself is kept in the pgBackRest repository
+func (b BackRestBackupJob) cleanup(run ScheduleRun) {
+	if run.Task != "" {
+		if err := kubeapi.Deletepgtask(restClient, run.Task, b.namespace); err != nil && !kerrors.IsNotFound(err) {
+			log.WithFields(log.Fields{
+				"task":  run.Task,
+				"error": err,
+			}).Error("error deleting pgTask")
+		}
+	}
+
+	if run.Job == "" {
 		return
 	}
+
+	if _, found := kubeapi.GetJob(kubeClient, run.Job, b.namespace); found {
+		if err := kubeapi.DeleteJob(kubeClient, run.Job, b.namespace); err != nil {
+			log.WithFields(log.Fields{
+				"job":   run.Job,
+				"error": err,
+			}).Error("error deleting backup job")
+		}
+	}
 }
@@ -0,0 +1,168 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+type PGDumpBackupJob struct {
+	name        string
+	namespace   string
+	cluster     string
+	database    string
+	format      string
+	keepLast    int
+	options     string
+	pvcName     string
+	secret      string
+	storageSpec crv1.PgStorageSpec
+}
+
+func (s *ScheduleTemplate) NewPGDumpSchedule() PGDumpBackupJob {
+	return PGDumpBackupJob{
+		name:        s.Name,
+		namespace:   s.Namespace,
+		cluster:     s.Cluster,
+		database:    s.PGDump.Database,
+		format:      s.PGDump.Format,
+		keepLast:    s.PGDump.KeepLast,
+		options:     s.PGDump.Options,
+		pvcName:     s.PGDump.PVCName,
+		secret:      s.PGDump.Secret,
+		storageSpec: s.PGDump.StorageSpec,
+	}
+}
+
+func (p PGDumpBackupJob) run(run *ScheduleRun, suffix string) error {
+	contextLogger := log.WithFields(log.Fields{
+		"namespace": p.namespace,
+		"cluster":   p.cluster,
+		"database":  p.database,
+		"format":    p.format,
+		"pvc":       p.pvcName})
+
+	contextLogger.Info("Running pgdump backup")
+
+	cluster := crv1.Pgcluster{}
+	found, err := kubeapi.Getpgcluster(restClient, &cluster, p.cluster, p.namespace)
+
+	if !found {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("pgCluster not found")
+		return fmt.Errorf("pgcluster %s not found", p.cluster)
+	} else if err != nil {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("error retrieving pgCluster")
+		return err
+	}
+
+	taskName := fmt.Sprintf("%s-sch-backup%s", p.name, suffix)
+
+	// the task and the Job of the previous backup are removed, while the
+	// backup itself is kept on the PVC
+	result := crv1.Pgtask{}
+	found, err = kubeapi.Getpgtask(restClient, &result, taskName, p.namespace)
+
+	if found {
+		if err := kubeapi.Deletepgtask(restClient, taskName, p.namespace); err != nil {
+			contextLogger.WithFields(log.Fields{
+				"task":  taskName,
+				"error": err,
+			}).Error("error deleting pgTask")
+			return err
+		}
+
+		selector := fmt.Sprintf("%s=%s", config.LABEL_PGTASK, taskName)
+		if err := kubeapi.DeleteJobs(kubeClient, selector, p.namespace); err != nil {
+			contextLogger.WithFields(log.Fields{
+				"task":  taskName,
+				"error": err,
+			}).Error("error deleting backup job")
+			return err
+		}
+	} else if err != nil && !kerrors.IsNotFound(err) {
+		contextLogger.WithFields(log.Fields{
+			"task":  taskName,
+			"error": err,
+		}).Error("error getting pgTask")
+		return err
+	}
+
+	options := p.options
+	if p.format != "" {
+		options = strings.TrimSpace(fmt.Sprintf("%s --format=%s", options, p.format))
+	}
+
+	dump := pgDumpTask{
+		clusterName: cluster.Name,
+		taskName:    taskName,
+		host:        cluster.Name,
+		port:        cluster.Spec.Port,
+		database:    p.database,
+		secret:      p.secret,
+		options:     options,
+		filename:    p.database,
+		keepLast:    p.keepLast,
+		pvcName:     p.pvcName,
+		storageSpec: p.storageSpec,
+	}
+
+	err = kubeapi.Createpgtask(restClient, dump.NewPgDumpTask(), p.namespace)
+	if err != nil {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("could not create new pgtask")
+		return err
+	}
+
+	// the Job is created by the Operator, and is found by the label of the task
+	run.Task = taskName
+
+	return nil
+}
+
+// cleanup removes the task and the Job of a finished backup, while the backup
+// itself is kept on the PVC
+func (p PGDumpBackupJob) cleanup(run ScheduleRun) {
+	if run.Task == "" {
+		return
+	}
+
+	if err := kubeapi.Deletepgtask(restClient, run.Task, p.namespace); err != nil && !kerrors.IsNotFound(err) {
+		log.WithFields(log.Fields{
+			"task":  run.Task,
+			"error": err,
+		}).Error("error deleting pgTask")
+	}
+
+	selector := fmt.Sprintf("%s=%s", config.LABEL_PGTASK, run.Task)
+	if err := kubeapi.DeleteJobs(kubeClient, selector, p.namespace); err != nil {
+		log.WithFields(log.Fields{
+			"task":  run.Task,
+			"error": err,
+		}).Error("error deleting backup job")
+	}
+}
@@ -56,7 +56,7 @@ func (s *ScheduleTemplate) NewPolicySchedule() PolicyJob {
 	}
 }
 
-func (p PolicyJob) Run() {
+func (p PolicyJob) run(run *ScheduleRun, suffix string) error {
 	contextLogger := log.WithFields(log.Fields{
 		"namespace": p.namespace,
 		"policy":    p.policy,
@@ -70,12 +70,12 @@ func (p PolicyJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("pgCluster not found")
-		return
+		return fmt.Errorf("pgcluster %s not found", p.cluster)
 	} else if err != nil {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("error retrieving pgCluster")
-		return
+		return err
 	}
 
 	policy := crv1.Pgpolicy{}
@@ -84,15 +84,15 @@ func (p PolicyJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("pgPolicy not found")
-		return
+		return fmt.Errorf("pgpolicy %s not found", p.policy)
 	} else if err != nil {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("error retrieving pgPolicy")
-		return
+		return err
 	}
 
-	name := fmt.Sprintf("policy-%s-%s-schedule", p.cluster, p.policy)
+	name := fmt.Sprintf("policy-%s-%s-schedule%s", p.cluster, p.policy, suffix)
 
 	filename := fmt.Sprintf("%s.sql", p.policy)
 	data := make(map[string]string)
@@ -119,7 +119,7 @@ func (p PolicyJob) Run() {
 			"error":     err,
 			"configMap": name,
 		}).Error("could not delete policy configmap")
-		return
+		return err
 	}
 
 	log.Debug("Creating configmap..")
@@ -128,7 +128,7 @@ func (p PolicyJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("could not create policy configmap")
-		return
+		return err
 	}
 
 	policyJob := PolicyTemplate{
@@ -147,7 +147,7 @@ func (p PolicyJob) Run() {
 	if err := config.PolicyJobTemplate.Execute(&doc, policyJob); err != nil {
 		contextLogger.WithFields(log.Fields{
 			"error": err}).Error("Failed to render job template")
-		return
+		return err
 	}
 
 	oldJob, found := kubeapi.GetJob(kubeClient, name, p.namespace)
@@ -158,7 +158,7 @@ func (p PolicyJob) Run() {
 				"job":   name,
 				"error": err,
 			}).Error("error deleting policy job")
-			return
+			return err
 		}
 
 		timeout := time.Second * 60
@@ -168,7 +168,7 @@ func (p PolicyJob) Run() {
 				"job":   name,
 				"error": err,
 			}).Error("error waiting for job to delete")
-			return
+			return err
 		}
 	}
 
@@ -177,7 +177,7 @@ func (p PolicyJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("Failed unmarshaling job template")
-		return
+		return err
 	}
 
 	// set the container image to an override value, if one exists
@@ -189,6 +189,34 @@ func (p PolicyJob) Run() {
 		contextLogger.WithFields(log.Fields{
 			"error": err,
 		}).Error("Failed creating policy job")
+		return err
+	}
+
+	run.Job = name
+
+	return nil
+}
+
+// cleanup removes the Job of a finished run and the ConfigMap of its SQL, which
+// are both named after the Job
+func (p PolicyJob) cleanup(run ScheduleRun) {
+	if run.Job == "" {
 		return
 	}
+
+	if _, found := kubeapi.GetJob(kubeClient, run.Job, p.namespace); found {
+		if err := kubeapi.DeleteJob(kubeClient, run.Job, p.namespace); err != nil {
+			log.WithFields(log.Fields{
+				"job":   run.Job,
+				"error": err,
+			}).Error("error deleting policy job")
+		}
+	}
+
+	if err := kubeapi.DeleteConfigMap(apiserver.Clientset, run.Job, p.namespace); err != nil && !kerrors.IsNotFound(err) {
+		log.WithFields(log.Fields{
+			"configMap": run.Job,
+			"error":     err,
+		}).Error("could not delete policy configmap")
+	}
 }
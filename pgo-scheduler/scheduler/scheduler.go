@@ -30,7 +30,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func New(label, namespace string, client *kubernetes.Clientset) *Scheduler {
+// New returns a scheduler. The backup limit is the number of scheduled backups
+// that may run at the same time in a namespace, where 0 means there is no limit
+func New(label, namespace string, backupLimit int, client *kubernetes.Clientset) *Scheduler {
 	apiserver.ConnectToKube()
 	restClient = apiserver.RESTClient
 	kubeClient = client
@@ -43,6 +45,7 @@ func New(label, namespace string, client *kubernetes.Clientset) *Scheduler {
 		label:      label,
 		CronClient: cronClient,
 		entries:    make(map[string]cv2.EntryID),
+		slots:      newBackupSlots(backupLimit),
 	}
 }
 
@@ -67,11 +70,18 @@ func (s *Scheduler) AddSchedule(config *v1.ConfigMap) error {
 		return fmt.Errorf("Failed to validate schedule: %s", err)
 	}
 
-	id, err := s.schedule(schedule)
+	job, err := s.newScheduleJob(config, schedule)
 	if err != nil {
 		return fmt.Errorf("Failed to schedule configmap: %s", err)
 	}
 
+	id, err := s.CronClient.AddJob(schedule.Schedule, job)
+	if err != nil {
+		return fmt.Errorf("Failed to schedule configmap: %s", err)
+	}
+
+	job.resume(config, schedule)
+
 	log.WithFields(log.Fields{
 		"configMap":  string(config.Name),
 		"type":       schedule.Type,
@@ -96,19 +106,45 @@ func (s *Scheduler) DeleteSchedule(config *v1.ConfigMap) {
 	delete(s.entries, name)
 }
 
-func (s *Scheduler) schedule(st ScheduleTemplate) (cv2.EntryID, error) {
-	var job cv2.Job
+// newScheduleJob returns the cron job of a schedule, which keeps the history of
+// the schedule on its ConfigMap
+func (s *Scheduler) newScheduleJob(config *v1.ConfigMap, st ScheduleTemplate) (scheduleJob, error) {
+	job := scheduleJob{
+		name:              config.Name,
+		namespace:         config.Namespace,
+		cluster:           st.Cluster,
+		scheduleType:      st.Type,
+		concurrencyPolicy: st.ConcurrencyPolicy,
+		slots:             s.slots,
+	}
+
+	if st.Jitter != "" {
+		jitter, err := time.ParseDuration(st.Jitter)
+		if err != nil {
+			return job, err
+		}
+		job.jitter = jitter
+	}
+
+	schedule, err := cv2.ParseStandard(st.Schedule)
+	if err != nil {
+		return job, err
+	}
+	job.schedule = schedule
 
 	switch st.Type {
 	case "pgbackrest":
-		job = st.NewBackRestSchedule()
+		job.runner = st.NewBackRestSchedule()
+	case "pgbackrest-verify":
+		job.runner = st.NewBackRestVerifySchedule()
+	case "pgdump":
+		job.runner = st.NewPGDumpSchedule()
 	case "policy":
-		job = st.NewPolicySchedule()
+		job.runner = st.NewPolicySchedule()
 	default:
-		var id cv2.EntryID
-		return id, fmt.Errorf("schedule type not implemented yet")
+		return job, fmt.Errorf("schedule type not implemented yet")
 	}
-	return s.CronClient.AddJob(st.Schedule, job)
+	return job, nil
 }
 
 // phony implements a no-op schedule job to prevent a bug that runs newly
@@ -17,6 +17,7 @@ package scheduler
 
 import (
 	"fmt"
+	"strconv"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/config"
@@ -55,3 +56,82 @@ func (p pgBackRestTask) NewBackRestTask() *crv1.Pgtask {
 		},
 	}
 }
+
+type pgDumpTask struct {
+	clusterName string
+	taskName    string
+	host        string
+	port        string
+	database    string
+	secret      string
+	options     string
+	filename    string
+	keepLast    int
+	pvcName     string
+	storageSpec crv1.PgStorageSpec
+}
+
+// NewPgDumpTask returns the same pgdump Pgtask as that of an on-demand pgdump
+// backup, which is then carried out by the Operator
+func (p pgDumpTask) NewPgDumpTask() *crv1.Pgtask {
+	task := &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: p.taskName,
+		},
+		Spec: crv1.PgtaskSpec{
+			Name:     p.taskName,
+			TaskType: crv1.PgtaskpgDump,
+			Parameters: map[string]string{
+				config.LABEL_PG_CLUSTER:      p.clusterName,
+				config.LABEL_PGDUMP_HOST:     p.host,
+				config.LABEL_CONTAINER_NAME:  "database",
+				config.LABEL_PGDUMP_COMMAND:  crv1.PgtaskpgDump,
+				config.LABEL_PGDUMP_OPTS:     p.options,
+				config.LABEL_PGDUMP_DB:       p.database,
+				config.LABEL_PGDUMP_USER:     p.secret,
+				config.LABEL_PGDUMP_PORT:     p.port,
+				config.LABEL_PGDUMP_ALL:      "false",
+				config.LABEL_PGDUMP_FILENAME: p.filename,
+				config.LABEL_PVC_NAME:        p.pvcName,
+			},
+			StorageSpec: p.storageSpec,
+		},
+	}
+
+	if p.keepLast > 0 {
+		task.Spec.Parameters[config.LABEL_PGDUMP_KEEP] = strconv.Itoa(p.keepLast)
+	}
+
+	return task
+}
+
+type pgBackRestVerifyTask struct {
+	clusterName string
+	taskName    string
+	query       string
+	storageType string
+}
+
+// NewBackRestVerifyTask returns the Pgtask of a verification of the latest
+// pgBackRest backup of a cluster, which is then carried out by the Operator
+func (p pgBackRestVerifyTask) NewBackRestVerifyTask() *crv1.Pgtask {
+	return &crv1.Pgtask{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: p.taskName,
+			Labels: map[string]string{
+				config.LABEL_PG_CLUSTER:      p.clusterName,
+				config.LABEL_BACKREST_VERIFY: "true",
+			},
+		},
+		Spec: crv1.PgtaskSpec{
+			Name:     p.taskName,
+			TaskType: crv1.PgtaskBackrestVerify,
+			Parameters: map[string]string{
+				config.LABEL_JOB_NAME:              p.taskName,
+				config.LABEL_PG_CLUSTER:            p.clusterName,
+				config.LABEL_BACKREST_VERIFY_QUERY: p.query,
+				config.LABEL_BACKREST_STORAGE_TYPE: p.storageType,
+			},
+		},
+	}
+}
@@ -18,6 +18,8 @@ package scheduler
 import (
 	"time"
 
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -34,18 +36,26 @@ type Scheduler struct {
 	namespace     string
 	namespaceList []string
 	scheduleTypes []string
+	slots         *backupSlots
 }
 
 type ScheduleTemplate struct {
-	Version    string    `json:"version"`
-	Name       string    `json:"name"`
-	Created    time.Time `json:"created"`
-	Schedule   string    `json:"schedule"`
-	Namespace  string    `json:"namespace"`
-	Type       string    `json:"type"`
-	Cluster    string    `json:"cluster"`
-	PGBackRest `json:"pgbackrest,omitempty"`
-	Policy     `json:"policy,omitempty"`
+	Version   string    `json:"version"`
+	Name      string    `json:"name"`
+	Created   time.Time `json:"created"`
+	Schedule  string    `json:"schedule"`
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Cluster   string    `json:"cluster"`
+	// ConcurrencyPolicy is what happens when the schedule is due while its
+	// previous run has not finished, and Jitter is the longest random delay
+	// before a run starts, e.g. "5m"
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	Jitter            string `json:"jitter,omitempty"`
+	PGBackRest        `json:"pgbackrest,omitempty"`
+	PGBackRestVerify  `json:"verify,omitempty"`
+	PGDump            `json:"pgdump,omitempty"`
+	Policy            `json:"policy,omitempty"`
 }
 
 type PGBackRest struct {
@@ -57,6 +67,26 @@ type PGBackRest struct {
 	Options     string `json:"options"`
 }
 
+// PGBackRestVerify contains the settings of a schedule of verifications of the
+// latest pgBackRest backup of a cluster, which is restored into a scratch
+// instance where the query is run
+type PGBackRestVerify struct {
+	Query       string `json:"query,omitempty"`
+	StorageType string `json:"storageType,omitempty"`
+}
+
+// PGDump contains the settings of a schedule of logical backups of a database
+// with pg_dump
+type PGDump struct {
+	Database    string             `json:"database"`
+	Format      string             `json:"format,omitempty"`
+	KeepLast    int                `json:"keepLast,omitempty"`
+	Options     string             `json:"options,omitempty"`
+	PVCName     string             `json:"pvcName,omitempty"`
+	Secret      string             `json:"secret"`
+	StorageSpec crv1.PgStorageSpec `json:"storageSpec"`
+}
+
 type Policy struct {
 	Secret      string `json:"secret"`
 	Name        string `json:"name"`
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	cv3 "github.com/robfig/cron"
 )
@@ -32,11 +33,27 @@ func validate(s ScheduleTemplate) error {
 		return err
 	}
 
+	if err := ValidateConcurrencyPolicy(s.ConcurrencyPolicy); err != nil {
+		return err
+	}
+
+	if err := ValidateJitter(s.Jitter); err != nil {
+		return err
+	}
+
 	if err := ValidateBackRestSchedule(s.Type, s.Deployment, s.Label, s.PGBackRest.Type,
 		s.PGBackRest.StorageType); err != nil {
 		return err
 	}
 
+	if err := ValidateBackRestVerifySchedule(s.Type, s.PGBackRestVerify.StorageType); err != nil {
+		return err
+	}
+
+	if err := ValidatePGDumpSchedule(s.Type, s.PGDump.Database, s.PGDump.Format, s.PGDump.KeepLast); err != nil {
+		return err
+	}
+
 	if err := ValidatePolicySchedule(s.Type, s.Policy.Name, s.Policy.Database); err != nil {
 		return err
 	}
@@ -58,6 +75,8 @@ func ValidateSchedule(schedule string) error {
 func ValidateScheduleType(schedule string) error {
 	scheduleTypes := []string{
 		"pgbackrest",
+		"pgbackrest-verify",
+		"pgdump",
 		"policy",
 	}
 
@@ -71,6 +90,42 @@ func ValidateScheduleType(schedule string) error {
 	return fmt.Errorf("%s is not a valid schedule type", schedule)
 }
 
+// ValidateConcurrencyPolicy validates the concurrency policy of a schedule, which
+// defaults to Replace when it is not set
+func ValidateConcurrencyPolicy(policy string) error {
+	if policy == "" {
+		return nil
+	}
+
+	for _, p := range ConcurrencyPolicies {
+		if policy == p {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not a valid concurrency policy, it must be one of %s",
+		policy, strings.Join(ConcurrencyPolicies, ", "))
+}
+
+// ValidateJitter validates the jitter of a schedule, which is a duration such as
+// "90s" or "5m"
+func ValidateJitter(jitter string) error {
+	if jitter == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(jitter)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid jitter: %s", jitter, err)
+	}
+
+	if d < 0 {
+		return fmt.Errorf("the jitter must not be negative: %s", jitter)
+	}
+
+	return nil
+}
+
 func ValidateBackRestSchedule(scheduleType, deployment, label, backupType, storageType string) error {
 	if scheduleType == "pgbackrest" {
 		if deployment == "" && label == "" {
@@ -95,7 +150,7 @@ func ValidateBackRestSchedule(scheduleType, deployment, label, backupType, stora
 			return fmt.Errorf("pgBackRest Backup Type invalid: %s", backupType)
 		}
 
-		validStorageTypes := []string{"local", "s3"}
+		validStorageTypes := []string{"local", "s3", "gcs", "azure"}
 		for _, sType := range validStorageTypes {
 			if storageType == sType {
 				valid = true
@@ -106,6 +161,55 @@ func ValidateBackRestSchedule(scheduleType, deployment, label, backupType, stora
 		if !valid {
 			return fmt.Errorf("pgBackRest Backup Type invalid: %s", backupType)
 		}
+	} else if scheduleType != "pgbackrest-verify" && storageType != "" {
+		return fmt.Errorf("pgBackRest storage type not supported for %s schedules", scheduleType)
+	}
+	return nil
+}
+
+// ValidateBackRestVerifySchedule validates the settings of a pgbackrest-verify
+// schedule. The storage type defaults to that of the local repository
+func ValidateBackRestVerifySchedule(scheduleType, storageType string) error {
+	if scheduleType == "pgbackrest-verify" {
+		validStorageTypes := []string{"", "local", "s3", "gcs", "azure"}
+
+		for _, sType := range validStorageTypes {
+			if storageType == sType {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("pgBackRest storage type invalid: %s", storageType)
+	}
+	return nil
+}
+
+// ValidatePGDumpSchedule validates the settings of a pgdump schedule. The
+// database is required, while the dump format defaults to that of pg_dump
+func ValidatePGDumpSchedule(scheduleType, database, format string, keepLast int) error {
+	if scheduleType == "pgdump" {
+		if database == "" {
+			return errors.New("Database name required for pgdump schedules")
+		}
+
+		// pg_dump writes to a single file, so its directory format is not supported
+		validFormats := []string{"", "p", "plain", "c", "custom", "t", "tar"}
+
+		var valid bool
+		for _, f := range validFormats {
+			if format == f {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return fmt.Errorf("pgdump format invalid: %s", format)
+		}
+
+		if keepLast < 0 {
+			return fmt.Errorf("the number of pgdump backups to keep must be 0 or greater: %d", keepLast)
+		}
 	}
 	return nil
 }
@@ -52,10 +52,13 @@ func TestValidScheduleType(t *testing.T) {
 		valid    bool
 	}{
 		{"pgbackrest", true},
+		{"pgbackrest-verify", true},
+		{"pgdump", true},
 		{"policy", true},
 		{"PGBACKREST", true},
 		{"POLICY", true},
 		{"pgBackRest", true},
+		{"PgDump", true},
 		{"PoLiCY", true},
 		{"FOO", false},
 		{"BAR", false},
@@ -85,6 +88,8 @@ func TestValidBackRestSchedule(t *testing.T) {
 		{"pgbackrest", "", "testlabel=label", "diff", "local", true},
 		{"pgbackrest", "testdeployment", "", "full", "s3", true},
 		{"pgbackrest", "", "testlabel=label", "diff", "s3", true},
+		{"pgbackrest", "testdeployment", "", "full", "gcs", true},
+		{"pgbackrest", "testdeployment", "", "full", "azure", true},
 		{"policy", "", "", "", "local", false},
 		{"pgbackrest", "", "", "", "local", false},
 		{"pgbackrest", "", "", "full", "local", false},
@@ -107,6 +112,60 @@ func TestValidBackRestSchedule(t *testing.T) {
 	}
 }
 
+func TestValidPGDumpSchedule(t *testing.T) {
+	tests := []struct {
+		schedule, database, format string
+		keepLast                   int
+		valid                      bool
+	}{
+		{"pgdump", "mydatabase", "", 0, true},
+		{"pgdump", "mydatabase", "custom", 7, true},
+		{"pgdump", "mydatabase", "p", 1, true},
+		{"pgbackrest", "", "", 0, true},
+		{"pgdump", "", "custom", 0, false},
+		{"pgdump", "mydatabase", "directory", 0, false},
+		{"pgdump", "mydatabase", "foobar", 0, false},
+		{"pgdump", "mydatabase", "", -1, false},
+	}
+
+	for i, test := range tests {
+		err := ValidatePGDumpSchedule(test.schedule, test.database, test.format, test.keepLast)
+		if test.valid && err != nil {
+			t.Fatalf("tests[%d] - invalid schedule type. expected valid, got invalid: %s",
+				i, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("tests[%d] - valid schedule. expected invalid, got valid: %s",
+				i, err)
+		}
+	}
+}
+
+func TestValidBackRestVerifySchedule(t *testing.T) {
+	tests := []struct {
+		schedule, storageType string
+		valid                 bool
+	}{
+		{"pgbackrest-verify", "", true},
+		{"pgbackrest-verify", "local", true},
+		{"pgbackrest-verify", "s3", true},
+		{"pgbackrest-verify", "gcs", true},
+		{"pgbackrest-verify", "azure", true},
+		{"pgbackrest", "foo", true},
+		{"pgbackrest-verify", "foo", false},
+	}
+
+	for i, test := range tests {
+		err := ValidateBackRestVerifySchedule(test.schedule, test.storageType)
+		if test.valid && err != nil {
+			t.Fatalf("tests[%d] - invalid schedule type. expected valid, got invalid: %s",
+				i, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("tests[%d] - valid schedule. expected invalid, got valid: %s",
+				i, err)
+		}
+	}
+}
+
 func TestValidSQLSchedule(t *testing.T) {
 	tests := []struct {
 		schedule, policy, database string
@@ -128,3 +187,54 @@ func TestValidSQLSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestValidConcurrencyPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		valid  bool
+	}{
+		{"", true},
+		{"Allow", true},
+		{"Forbid", true},
+		{"Replace", true},
+		{"allow", false},
+		{"Never", false},
+	}
+
+	for i, test := range tests {
+		err := ValidateConcurrencyPolicy(test.policy)
+		if test.valid && err != nil {
+			t.Fatalf("tests[%d] - invalid concurrency policy. expected valid, got invalid: %s",
+				i, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("tests[%d] - valid concurrency policy. expected invalid, got valid: %s",
+				i, err)
+		}
+	}
+}
+
+func TestValidJitter(t *testing.T) {
+	tests := []struct {
+		jitter string
+		valid  bool
+	}{
+		{"", true},
+		{"0s", true},
+		{"90s", true},
+		{"5m", true},
+		{"-1m", false},
+		{"5", false},
+		{"five minutes", false},
+	}
+
+	for i, test := range tests {
+		err := ValidateJitter(test.jitter)
+		if test.valid && err != nil {
+			t.Fatalf("tests[%d] - invalid jitter. expected valid, got invalid: %s",
+				i, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("tests[%d] - valid jitter. expected invalid, got valid: %s",
+				i, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// BackRestVerifyJob verifies the latest pgBackRest backup of a cluster by
+// restoring it into a scratch instance
+type BackRestVerifyJob struct {
+	name        string
+	namespace   string
+	cluster     string
+	query       string
+	storageType string
+}
+
+func (s *ScheduleTemplate) NewBackRestVerifySchedule() BackRestVerifyJob {
+	return BackRestVerifyJob{
+		name:        s.Name,
+		namespace:   s.Namespace,
+		cluster:     s.Cluster,
+		query:       s.PGBackRestVerify.Query,
+		storageType: s.PGBackRestVerify.StorageType,
+	}
+}
+
+func (v BackRestVerifyJob) run(run *ScheduleRun, suffix string) error {
+	contextLogger := log.WithFields(log.Fields{
+		"namespace":   v.namespace,
+		"cluster":     v.cluster,
+		"storageType": v.storageType})
+
+	contextLogger.Info("Running pgBackRest backup verification")
+
+	cluster := crv1.Pgcluster{}
+	found, err := kubeapi.Getpgcluster(restClient, &cluster, v.cluster, v.namespace)
+
+	if !found {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("pgCluster not found")
+		return fmt.Errorf("pgcluster %s not found", v.cluster)
+	} else if err != nil {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("error retrieving pgCluster")
+		return err
+	}
+
+	taskName := fmt.Sprintf("%s-sch-verify%s", v.name, suffix)
+
+	// the Job of a previous verification is removed by the Operator once it
+	// finishes, so only its task may be left
+	if err := kubeapi.Deletepgtask(restClient, taskName, v.namespace); err != nil && !kerrors.IsNotFound(err) {
+		contextLogger.WithFields(log.Fields{
+			"task":  taskName,
+			"error": err,
+		}).Error("error deleting pgTask")
+		return err
+	}
+
+	verify := pgBackRestVerifyTask{
+		clusterName: cluster.Name,
+		taskName:    taskName,
+		query:       v.query,
+		storageType: v.storageType,
+	}
+
+	err = kubeapi.Createpgtask(restClient, verify.NewBackRestVerifyTask(), v.namespace)
+	if err != nil {
+		contextLogger.WithFields(log.Fields{
+			"error": err,
+		}).Error("could not create new pgtask")
+		return err
+	}
+
+	// the Job of the verification is named after its task
+	run.Task, run.Job = taskName, taskName
+
+	return nil
+}
+
+// cleanup removes the task of a run along with its Job, unless the Job was
+// already removed by the Operator once the verification finished
+func (v BackRestVerifyJob) cleanup(run ScheduleRun) {
+	if run.Task != "" {
+		if err := kubeapi.Deletepgtask(restClient, run.Task, v.namespace); err != nil && !kerrors.IsNotFound(err) {
+			log.WithFields(log.Fields{
+				"task":  run.Task,
+				"error": err,
+			}).Error("error deleting pgTask")
+		}
+	}
+
+	if run.Job == "" {
+		return
+	}
+
+	if _, found := kubeapi.GetJob(kubeClient, run.Job, v.namespace); found {
+		if err := kubeapi.DeleteJob(kubeClient, run.Job, v.namespace); err != nil {
+			log.WithFields(log.Fields{
+				"job":   run.Job,
+				"error": err,
+			}).Error("error deleting verification job")
+		}
+	}
+}
@@ -62,6 +62,44 @@ func CreateFailover(httpclient *http.Client, SessionCredentials *msgs.BasicAuthC
 	return response, err
 }
 
+func CreateSwitchover(httpclient *http.Client, SessionCredentials *msgs.BasicAuthCredentials, request *msgs.CreateSwitchoverRequest) (msgs.CreateSwitchoverResponse, error) {
+
+	var response msgs.CreateSwitchoverResponse
+
+	jsonValue, _ := json.Marshal(request)
+	url := SessionCredentials.APIServerURL + "/switchover"
+
+	log.Debugf("create switchover called [%s]", url)
+
+	action := "POST"
+	req, err := http.NewRequest(action, url, bytes.NewBuffer(jsonValue))
+	if err != nil {
+		return response, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(SessionCredentials.Username, SessionCredentials.Password)
+
+	resp, err := httpclient.Do(req)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("%v", resp)
+	err = StatusCheck(resp)
+	if err != nil {
+		return response, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		log.Printf("%v\n", resp.Body)
+		log.Println(err)
+		return response, err
+	}
+
+	return response, err
+}
+
 func QueryFailover(httpclient *http.Client, arg string, SessionCredentials *msgs.BasicAuthCredentials, ns string) (msgs.QueryFailoverResponse, error) {
 
 	var response msgs.QueryFailoverResponse
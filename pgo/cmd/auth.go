@@ -37,6 +37,7 @@ const (
 	pgoUserFileEnvVar     = "PGOUSER"
 	pgoUserNameEnvVar     = "PGOUSERNAME"
 	pgoUserPasswordEnvVar = "PGOUSERPASS"
+	pgoTokenEnvVar        = "PGO_TOKEN"
 )
 
 // SessionCredentials stores the PGO user, PGO password and the PGO APIServer URL
@@ -191,6 +192,21 @@ func getCredentialsFromEnvironment() msgs.BasicAuthCredentials {
 func SetSessionUserCredentials() {
 	log.Debug("GetSessionCredentials called")
 
+	// Priority: Flag -> ENV. A bearer token takes the place of the pgouser
+	// credentials
+	token := PGO_TOKEN
+	if token == "" {
+		token = os.Getenv(pgoTokenEnvVar)
+	}
+	if token != "" {
+		log.Debug("using bearer token for authentication")
+		SessionCredentials = msgs.BasicAuthCredentials{
+			APIServerURL: APIServerURL,
+			Token:        strings.TrimSpace(token),
+		}
+		return
+	}
+
 	SessionCredentials = getCredentialsFromEnvironment()
 
 	if !SessionCredentials.HasUsernameAndPassword() {
@@ -198,6 +214,34 @@ func SetSessionUserCredentials() {
 	}
 }
 
+// bearerTokenTransport is an http.RoundTripper that authenticates every
+// request with a bearer token, replacing any Basic Authentication credentials
+// set on the request
+type bearerTokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip sets the Authorization header on a copy of the request and sends
+// it with the wrapped transport
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+
+	return t.next.RoundTrip(r)
+}
+
+// setBearerTokenTransport ensures that every request made by the client is
+// authenticated with the bearer token
+func setBearerTokenTransport(client *http.Client, token string) {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	client.Transport = &bearerTokenTransport{token: token, next: next}
+}
+
 // GetTLSTransport returns an http.Transport configured with environmental
 // TLS client settings
 func GetTLSTransport() (*http.Transport, error) {
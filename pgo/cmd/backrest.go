@@ -24,6 +24,7 @@ import (
 
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/pgo/api"
+	"github.com/crunchydata/postgres-operator/util"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -37,6 +38,11 @@ func createBackrestBackup(args []string, ns string) {
 	request.Selector = Selector
 	request.BackupOpts = BackupOpts
 	request.BackrestStorageType = BackrestStorageType
+	request.Verify = BackrestVerify
+	request.VerifyOnly = BackrestVerifyOnly
+	request.VerifySet = BackrestVerifySet
+	request.VerifyQuery = BackrestVerifyQuery
+	request.Repo = BackrestRepo
 
 	response, err := api.CreateBackrestBackup(httpclient, &SessionCredentials, request)
 	if err != nil {
@@ -132,4 +138,57 @@ func printBackrest(result *msgs.ShowBackrestDetail) {
 			}
 		}
 	}
+
+	printBackrestRetention(result)
+	printBackrestVerification(result)
+}
+
+// printBackrestVerification prints the outcome of the latest verification of a
+// backup of a pgBackRest repository
+func printBackrestVerification(result *msgs.ShowBackrestDetail) {
+	if result.Verification == nil {
+		return
+	}
+
+	fmt.Println("verification:")
+	fmt.Printf("    backup: %s\n", util.GetValueOrDefault(result.Verification.BackupSet, "latest"))
+
+	if result.Verification.Status == "" {
+		fmt.Printf("    status: running\n\n")
+		return
+	}
+
+	fmt.Printf("    status: %s\n", result.Verification.Status)
+	fmt.Printf("    completed: %s\n", result.Verification.Completed)
+	if result.Verification.Message != "" {
+		fmt.Printf("    message: %s\n", result.Verification.Message)
+	}
+	fmt.Println()
+}
+
+// printBackrestRetention prints the retention policy of a pgBackRest
+// repository, along with the backups that expire next under it
+func printBackrestRetention(result *msgs.ShowBackrestDetail) {
+	if !result.Retention.IsSet() {
+		return
+	}
+
+	fmt.Println("retention policy:")
+	if result.Retention.Full > 0 {
+		fmt.Printf("    full backups: %d\n", result.Retention.Full)
+	}
+	if result.Retention.Diff > 0 {
+		fmt.Printf("    differential backups: %d\n", result.Retention.Diff)
+	}
+	if result.Retention.Archive > 0 {
+		fmt.Printf("    WAL archive: %d %s backup(s)\n", result.Retention.Archive,
+			util.GetValueOrDefault(result.Retention.ArchiveType, "full"))
+	}
+
+	if len(result.ExpiringBackups) == 0 {
+		fmt.Printf("    expiring next: none\n\n")
+		return
+	}
+
+	fmt.Printf("    expiring next: %s\n\n", strings.Join(result.ExpiringBackups, ", "))
 }
@@ -26,12 +26,31 @@ import (
 
 var PVCName string
 
+// BackrestVerify, BackrestVerifyOnly, BackrestVerifySet and BackrestVerifyQuery
+// request the verification of a pgBackRest backup, which is restored into a
+// scratch instance where the query is run
+var BackrestVerify, BackrestVerifyOnly bool
+var BackrestVerifySet, BackrestVerifyQuery string
+
+// BackrestRepo is the number of the pgBackRest repository of a cluster that a
+// backup is taken into, or restored from
+var BackrestRepo int
+
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Perform a Backup",
 	Long: `BACKUP performs a Backup, for example:
 
-  pgo backup mycluster`,
+  pgo backup mycluster
+
+  # verify the backup once it completes by restoring it into a scratch instance
+  pgo backup mycluster --verify
+
+  # verify an existing backup without taking a new one
+  pgo backup mycluster --verify-only --verify-set=20200301-010000F
+
+  # take the backup into the second pgBackRest repository of the cluster
+  pgo backup mycluster --repo=2`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if Namespace == "" {
 			Namespace = PGONamespace
@@ -54,6 +73,31 @@ var backupCmd = &cobra.Command{
 					exitNow = true
 				}
 
+				if BackrestVerify && BackrestVerifyOnly {
+					fmt.Println("Error: --verify and --verify-only cannot be used together.")
+					exitNow = true
+				}
+
+				if BackrestVerifySet != "" && !BackrestVerifyOnly {
+					fmt.Println("Error: --verify-set is only allowed with --verify-only.")
+					exitNow = true
+				}
+
+				if BackrestVerifyQuery != "" && !BackrestVerify && !BackrestVerifyOnly {
+					fmt.Println("Error: --verify-query is only allowed with --verify or --verify-only.")
+					exitNow = true
+				}
+
+				if BackrestRepo < 0 {
+					fmt.Println("Error: --repo must be the number of a pgBackRest repository, starting at 1.")
+					exitNow = true
+				}
+
+				if BackrestRepo != 0 && BackrestStorageType != "" {
+					fmt.Println("Error: --repo and --pgbackrest-storage-type cannot be used together.")
+					exitNow = true
+				}
+
 				if exitNow {
 					return
 				}
@@ -83,7 +127,12 @@ func init() {
 	backupCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
 	backupCmd.Flags().StringVarP(&PVCName, "pvc-name", "", "", "The PVC name to use for the backup instead of the default.")
 	backupCmd.Flags().StringVar(&backupType, "backup-type", "pgbackrest", "The backup type to perform. Default is pgbackrest. Valid backup types are pgbackrest and pgdump.")
-	backupCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use when scheduling pgBackRest backups. Either \"local\", \"s3\" or both, comma separated. (default \"local\")")
+	backupCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use when scheduling pgBackRest backups. Either \"local\", \"s3\" or both, comma separated, or \"gcs\" or \"azure\". (default \"local\")")
+	backupCmd.Flags().IntVar(&BackrestRepo, "repo", 0, "The number of the pgBackRest repository of the cluster to take the backup into. Defaults to the first repository.")
+	backupCmd.Flags().BoolVar(&BackrestVerify, "verify", false, "Verifies the pgBackRest backup once it completes by restoring it into a scratch instance and running a query against it.")
+	backupCmd.Flags().BoolVar(&BackrestVerifyOnly, "verify-only", false, "Verifies an existing pgBackRest backup rather than taking a new one.")
+	backupCmd.Flags().StringVar(&BackrestVerifySet, "verify-set", "", "The pgBackRest backup set to verify with --verify-only. Defaults to the latest backup.")
+	backupCmd.Flags().StringVar(&BackrestVerifyQuery, "verify-query", "", "The query to run against the scratch instance of a verification. Defaults to a query of the databases.")
 
 }
 
@@ -18,11 +18,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/pgo/api"
 	"github.com/crunchydata/postgres-operator/pgo/util"
@@ -168,6 +170,9 @@ func printCluster(detail *msgs.ShowClusterDetail) {
 
 	for _, pod := range detail.Pods {
 		podType := "(" + pod.Type + ")"
+		if pod.PendingRestart {
+			podType += " (pending restart)"
+		}
 
 		podStr := fmt.Sprintf("%spod : %s (%s) on %s (%s) %s", TreeBranch, pod.Name, string(pod.Phase), pod.NodeName, pod.ReadyStatus, podType)
 		fmt.Println(podStr)
@@ -280,6 +285,16 @@ func createCluster(args []string, ns string, createClusterCmd *cobra.Command) {
 	r.BackrestS3Bucket = BackrestS3Bucket
 	r.BackrestS3Region = BackrestS3Region
 	r.BackrestS3Endpoint = BackrestS3Endpoint
+	r.BackrestGCSBucket = BackrestGCSBucket
+	r.BackrestGCSEndpoint = BackrestGCSEndpoint
+	r.BackrestGCSKeyType = BackrestGCSKeyType
+	r.BackrestAzureAccount = BackrestAzureAccount
+	r.BackrestAzureKey = BackrestAzureKey
+	r.BackrestAzureContainer = BackrestAzureContainer
+	r.BackrestAzureEndpoint = BackrestAzureEndpoint
+	r.BackrestAzureKeyType = BackrestAzureKeyType
+	r.BackrestAzureURIStyle = BackrestAzureURIStyle
+	r.BackrestStorageNoVerifyTLS = !BackrestStorageVerifyTLS
 	r.PVCSize = PVCSize
 	r.BackrestPVCSize = BackrestPVCSize
 	r.Username = Username
@@ -290,6 +305,12 @@ func createCluster(args []string, ns string, createClusterCmd *cobra.Command) {
 	r.CASecret = CASecret
 	r.Standby = Standby
 	r.BackrestRepoPath = BackrestRepoPath
+	r.BackrestRetention = crv1.PgBackRestRetentionSpec{
+		Full:        BackrestRetentionFull,
+		Diff:        BackrestRetentionDiff,
+		Archive:     BackrestRetentionArchive,
+		ArchiveType: BackrestRetentionArchiveType,
+	}
 	// set the container resource requests
 	r.CPURequest = CPURequest
 	r.MemoryRequest = MemoryRequest
@@ -304,11 +325,33 @@ func createCluster(args []string, ns string, createClusterCmd *cobra.Command) {
 	r.WALStorageConfig = WALStorageConfig
 	r.WALPVCSize = WALPVCSize
 
+	// the GCS key is read from a file, as it is usually a service account key
+	if BackrestGCSKey != "" {
+		gcsKey, err := ioutil.ReadFile(BackrestGCSKey)
+		if err != nil {
+			fmt.Println("Error: could not read the GCS key: " + err.Error())
+			os.Exit(1)
+		}
+		r.BackrestGCSKey = gcsKey
+	}
+
 	// only set SyncReplication in the request if actually provided via the CLI
 	if createClusterCmd.Flag("sync-replication").Changed {
 		r.SyncReplication = &SyncReplication
 	}
 
+	// the settings of synchronous replication are only sent when provided, as
+	// they turn it on
+	if createClusterCmd.Flag("sync-replication-node-count").Changed ||
+		createClusterCmd.Flag("sync-replication-strict").Changed ||
+		createClusterCmd.Flag("sync-replication-exclude").Changed {
+		r.SyncReplicationSettings = &crv1.SyncReplicationSpec{
+			NodeCount:        SyncReplicationNodeCount,
+			Strict:           SyncReplicationStrict,
+			ExcludedReplicas: SyncReplicationExcludedReplicas,
+		}
+	}
+
 	// if the user provided resources for CPU or Memory, validate them to ensure
 	// they are valid Kubernetes values
 	if err := util.ValidateQuantity(r.CPURequest, "cpu"); err != nil {
@@ -440,7 +483,7 @@ func isTablespaceParam(param string) bool {
 }
 
 // updateCluster ...
-func updateCluster(args []string, ns string) {
+func updateCluster(cmd *cobra.Command, args []string, ns string) {
 	log.Debugf("updateCluster called %v", args)
 
 	r := msgs.UpdateClusterRequest{}
@@ -451,6 +494,46 @@ func updateCluster(args []string, ns string) {
 	r.BackrestCPURequest = BackrestCPURequest
 	r.BackrestMemoryRequest = BackrestMemoryRequest
 	r.Clustername = args
+	// only the settings of the retention policy that are provided are updated
+	if cmd.Flags().Changed("pgbackrest-retention-full") {
+		r.BackrestRetentionFull = &BackrestRetentionFull
+	}
+	if cmd.Flags().Changed("pgbackrest-retention-diff") {
+		r.BackrestRetentionDiff = &BackrestRetentionDiff
+	}
+	if cmd.Flags().Changed("pgbackrest-retention-archive") {
+		r.BackrestRetentionArchive = &BackrestRetentionArchive
+	}
+	if cmd.Flags().Changed("pgbackrest-retention-archive-type") {
+		r.BackrestRetentionArchiveType = &BackrestRetentionArchiveType
+	}
+	// likewise, only the settings of synchronous replication that are provided
+	// are updated
+	if cmd.Flags().Changed("sync-replication-node-count") {
+		r.SyncReplicationNodeCount = &SyncReplicationNodeCount
+	}
+	if cmd.Flags().Changed("sync-replication-strict") {
+		r.SyncReplicationStrict = &SyncReplicationStrict
+	}
+	if cmd.Flags().Changed("sync-replication-exclude") {
+		r.SyncReplicationExcludedReplicas = &SyncReplicationExcludedReplicas
+	}
+	// set the PostgreSQL parameters, which are in the format "name=value"
+	for _, parameter := range PostgresParameters {
+		kv := strings.SplitN(parameter, "=", 2)
+
+		if len(kv) != 2 || kv[0] == "" {
+			fmt.Printf("Error: PostgreSQL parameter %q must be in the format \"name=value\"\n", parameter)
+			os.Exit(1)
+		}
+
+		if r.Parameters == nil {
+			r.Parameters = map[string]string{}
+		}
+
+		r.Parameters[kv[0]] = kv[1]
+	}
+	r.RestartPending = RestartPending
 	r.Startup = Startup
 	r.Shutdown = Shutdown
 	// set the container resource requests
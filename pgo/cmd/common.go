@@ -26,22 +26,34 @@ type unitType int
 
 // values for the headings
 const (
-	headingCapacity     = "CAPACITY"
-	headingCluster      = "CLUSTER"
-	headingClusterIP    = "CLUSTER IP"
-	headingErrorMessage = "ERROR"
-	headingExpires      = "EXPIRES"
-	headingExternalIP   = "EXTERNAL IP"
-	headingInstance     = "INSTANCE"
-	headingPassword     = "PASSWORD"
-	headingPercentUsed  = "% USED"
-	headingPod          = "POD"
-	headingPVC          = "PVC"
-	headingService      = "SERVICE"
-	headingStatus       = "STATUS"
-	headingPVCType      = "TYPE"
-	headingUsed         = "USED"
-	headingUsername     = "USERNAME"
+	headingAvgQuery       = "AVG QUERY"
+	headingAvgTransaction = "AVG TRANSACTION"
+	headingCapacity       = "CAPACITY"
+	headingClientsActive  = "CLIENTS ACTIVE"
+	headingClientsWaiting = "CLIENTS WAITING"
+	headingCluster        = "CLUSTER"
+	headingClusterIP      = "CLUSTER IP"
+	headingDatabase       = "DATABASE"
+	headingErrorMessage   = "ERROR"
+	headingExpires        = "EXPIRES"
+	headingExternalIP     = "EXTERNAL IP"
+	headingInstance       = "INSTANCE"
+	headingMaxWait        = "MAX WAIT"
+	headingPassword       = "PASSWORD"
+	headingPercentUsed    = "% USED"
+	headingPod            = "POD"
+	headingPoolSize       = "POOL SIZE"
+	headingPVC            = "PVC"
+	headingQueries        = "QUERIES"
+	headingSaturation     = "SATURATION"
+	headingServersActive  = "SERVERS ACTIVE"
+	headingServersIdle    = "SERVERS IDLE"
+	headingService        = "SERVICE"
+	headingStatus         = "STATUS"
+	headingTransactions   = "TRANSACTIONS"
+	headingPVCType        = "TYPE"
+	headingUsed           = "USED"
+	headingUsername       = "USERNAME"
 )
 
 // unitSize recommends the unit we will use to size things
@@ -47,6 +47,10 @@ var ScheduleType string
 var SchedulePolicy string
 var ScheduleDatabase string
 var ScheduleSecret string
+var ScheduleConcurrencyPolicy string
+var ScheduleJitter string
+var PGDumpFormat string
+var PGDumpKeepLast int
 var PGBackRestType string
 var Secret string
 var PgouserPassword, PgouserRoles, PgouserNamespaces string
@@ -75,6 +79,31 @@ var (
 	PgBouncerCPURequest, PgBouncerMemoryRequest string
 )
 
+// the retention policy of the pgBackRest repository
+var (
+	BackrestRetentionFull, BackrestRetentionDiff, BackrestRetentionArchive int
+	BackrestRetentionArchiveType                                           string
+)
+
+// the settings of synchronous replication
+var (
+	SyncReplicationNodeCount        int
+	SyncReplicationStrict           bool
+	SyncReplicationExcludedReplicas []string
+)
+
+// the GCS and Azure storage of the pgBackRest repository. BackrestGCSKey is the
+// path of the file that holds the GCS key
+var (
+	BackrestGCSBucket, BackrestGCSEndpoint, BackrestGCSKey, BackrestGCSKeyType string
+	BackrestAzureAccount, BackrestAzureKey, BackrestAzureContainer             string
+	BackrestAzureEndpoint, BackrestAzureKeyType, BackrestAzureURIStyle         string
+)
+
+// BackrestStorageVerifyTLS determines whether the TLS certificate of the S3, GCS
+// or Azure storage of the pgBackRest repository is verified
+var BackrestStorageVerifyTLS bool
+
 // BackrestS3CASecretName, if provided, is the name of a secret to use that
 // contains a CA certificate to use for the pgBackRest repo
 var BackrestS3CASecretName string
@@ -202,7 +231,9 @@ var createPgbouncerCmd = &cobra.Command{
 	Short: "Create a pgbouncer ",
 	Long: `Create a pgbouncer. For example:
 
-	pgo create pgbouncer mycluster`,
+	pgo create pgbouncer mycluster
+	pgo create pgbouncer mycluster --setting=pool_mode=transaction
+	pgo create pgbouncer mycluster --read-only-replicas=1`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if Namespace == "" {
@@ -220,6 +251,11 @@ var createPgbouncerCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if PgBouncerReadOnlyReplicas < 0 {
+			fmt.Println("Error: The number of read-only replicas cannot be negative.")
+			os.Exit(1)
+		}
+
 		createPgbouncer(args, Namespace)
 	},
 }
@@ -230,7 +266,10 @@ var createScheduleCmd = &cobra.Command{
 	Short: "Create a cron-like scheduled task",
 	Long: `Schedule creates a cron-like scheduled task.  For example:
 
-    pgo create schedule --schedule="* * * * *" --schedule-type=pgbackrest --pgbackrest-backup-type=full mycluster`,
+    pgo create schedule --schedule="* * * * *" --schedule-type=pgbackrest --pgbackrest-backup-type=full mycluster
+    pgo create schedule --schedule="0 1 * * *" --schedule-type=pgdump --database=mydb --pgdump-format=custom --pgdump-keep-last=7 mycluster
+    pgo create schedule --schedule="0 * * * *" --schedule-type=pgbackrest --pgbackrest-backup-type=incr --concurrency-policy=Forbid --jitter=10m mycluster
+    pgo create schedule --schedule="0 3 * * 0" --schedule-type=pgbackrest-verify mycluster`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if Namespace == "" {
@@ -306,9 +345,46 @@ func init() {
 		"the pgBackRest repository. Defaults to server value (48Mi).")
 	createClusterCmd.Flags().StringVarP(&BackrestPVCSize, "pgbackrest-pvc-size", "", "",
 		`The size of the PVC capacity for the pgBackRest repository. Overrides the value set in the storage class. This is ignored if the storage type of "local" is not used. Must follow the standard Kubernetes format, e.g. "10.1Gi"`)
+	createClusterCmd.Flags().StringVar(&BackrestAzureAccount, "pgbackrest-azure-account", "",
+		"The Azure storage account that should be utilized for the cluster when the \"azure\" "+
+			"storage type is enabled for pgBackRest.")
+	createClusterCmd.Flags().StringVar(&BackrestAzureContainer, "pgbackrest-azure-container", "",
+		"The Azure container that should be utilized for the cluster when the \"azure\" "+
+			"storage type is enabled for pgBackRest.")
+	createClusterCmd.Flags().StringVar(&BackrestAzureEndpoint, "pgbackrest-azure-endpoint", "",
+		"The Azure endpoint that should be utilized for the cluster when the \"azure\" "+
+			"storage type is enabled for pgBackRest, e.g. the address of an Azurite emulator.")
+	createClusterCmd.Flags().StringVar(&BackrestAzureKey, "pgbackrest-azure-key", "",
+		"The Azure shared or SAS key that should be utilized for the cluster when the \"azure\" "+
+			"storage type is enabled for pgBackRest.")
+	createClusterCmd.Flags().StringVar(&BackrestAzureKeyType, "pgbackrest-azure-key-type", "",
+		"The type of the Azure key, i.e. \"shared\" or \"sas\". (default \"shared\")")
+	createClusterCmd.Flags().StringVar(&BackrestAzureURIStyle, "pgbackrest-azure-uri-style", "",
+		"The style of the Azure URIs, i.e. \"host\" or \"path\". Emulators such as Azurite use \"path\". (default \"host\")")
+	createClusterCmd.Flags().StringVar(&BackrestGCSBucket, "pgbackrest-gcs-bucket", "",
+		"The GCS bucket that should be utilized for the cluster when the \"gcs\" "+
+			"storage type is enabled for pgBackRest.")
+	createClusterCmd.Flags().StringVar(&BackrestGCSEndpoint, "pgbackrest-gcs-endpoint", "",
+		"The GCS endpoint that should be utilized for the cluster when the \"gcs\" "+
+			"storage type is enabled for pgBackRest, e.g. the address of a fake-gcs-server emulator.")
+	createClusterCmd.Flags().StringVar(&BackrestGCSKey, "pgbackrest-gcs-key", "",
+		"The path of a file that holds the GCS service account key, or token, that should be "+
+			"utilized for the cluster when the \"gcs\" storage type is enabled for pgBackRest.")
+	createClusterCmd.Flags().StringVar(&BackrestGCSKeyType, "pgbackrest-gcs-key-type", "",
+		"The type of the GCS key, i.e. \"service\" or \"token\". (default \"service\")")
 	createClusterCmd.Flags().StringVarP(&BackrestRepoPath, "pgbackrest-repo-path", "", "",
 		"The pgBackRest repository path that should be utilized instead of the default. Required "+
 			"for standby\nclusters to define the location of an existing pgBackRest repository.")
+	createClusterCmd.Flags().IntVar(&BackrestRetentionArchive, "pgbackrest-retention-archive", 0,
+		"The number of backups of the type set by \"--pgbackrest-retention-archive-type\" to retain the WAL archive of. "+
+			"Defaults to the pgBackRest default.")
+	createClusterCmd.Flags().StringVar(&BackrestRetentionArchiveType, "pgbackrest-retention-archive-type", "",
+		"The type of backup, i.e. \"full\", \"diff\" or \"incr\", that \"--pgbackrest-retention-archive\" refers to.")
+	createClusterCmd.Flags().IntVar(&BackrestRetentionDiff, "pgbackrest-retention-diff", 0,
+		"The number of differential backups to retain in the pgBackRest repository. Defaults to retaining them until "+
+			"their full backup expires.")
+	createClusterCmd.Flags().IntVar(&BackrestRetentionFull, "pgbackrest-retention-full", 0,
+		"The number of full backups to retain in the pgBackRest repository. Defaults to retaining all of them.")
 	createClusterCmd.Flags().StringVarP(&BackrestS3Key, "pgbackrest-s3-key", "", "",
 		"The AWS S3 key that should be utilized for the cluster when the \"s3\" "+
 			"storage type is enabled for pgBackRest.")
@@ -328,7 +404,10 @@ func init() {
 		"The AWS S3 region that should be utilized for the cluster when the \"s3\" "+
 			"storage type is enabled for pgBackRest.")
 	createClusterCmd.Flags().StringVar(&BackrestStorageConfig, "pgbackrest-storage-config", "", "The name of the storage config in pgo.yaml to use for the pgBackRest local repository.")
-	createClusterCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use with pgBackRest. Either \"local\", \"s3\" or both, comma separated. (default \"local\")")
+	createClusterCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use with pgBackRest. Either \"local\", \"s3\" or both, comma separated, or \"gcs\" or \"azure\". (default \"local\")")
+	createClusterCmd.Flags().BoolVar(&BackrestStorageVerifyTLS, "pgbackrest-storage-verify-tls", true,
+		"Whether the TLS certificate of the S3, GCS or Azure storage of pgBackRest is verified. "+
+			"Turn it off for emulators with self-signed certificates.")
 	createClusterCmd.Flags().BoolVarP(&BadgerFlag, "pgbadger", "", false, "Adds the crunchy-pgbadger container to the database pod.")
 	createClusterCmd.Flags().BoolVarP(&PgbouncerFlag, "pgbouncer", "", false, "Adds a crunchy-pgbouncer deployment to the cluster.")
 	createClusterCmd.Flags().StringVar(&PgBouncerCPURequest, "pgbouncer-cpu", "", "Set the number of millicores to request for CPU "+
@@ -364,6 +443,14 @@ func init() {
 	createClusterCmd.Flags().StringVarP(&StorageConfig, "storage-config", "", "", "The name of a Storage config in pgo.yaml to use for the cluster storage.")
 	createClusterCmd.Flags().BoolVarP(&SyncReplication, "sync-replication", "", false,
 		"Enables synchronous replication for the cluster.")
+	createClusterCmd.Flags().StringSliceVar(&SyncReplicationExcludedReplicas, "sync-replication-exclude", []string{},
+		"The names of the replicas that are never chosen as synchronous standbys, e.g. a replica used for "+
+			"disaster recovery. Enables synchronous replication.")
+	createClusterCmd.Flags().IntVar(&SyncReplicationNodeCount, "sync-replication-node-count", 0,
+		"The number of synchronous standbys, which defaults to 1. More than 1 requires Patroni 2.0. "+
+			"Enables synchronous replication.")
+	createClusterCmd.Flags().BoolVar(&SyncReplicationStrict, "sync-replication-strict", false,
+		"Stops writes on the primary when no synchronous standby is available. Enables synchronous replication.")
 	createClusterCmd.Flags().BoolVar(&TLSOnly, "tls-only", false, "If true, forces all PostgreSQL connections to be over TLS. "+
 		"Must also set \"server-tls-secret\" and \"server-ca-secret\"")
 	createClusterCmd.Flags().BoolVarP(&Standby, "standby", "", false, "Creates a standby cluster "+
@@ -388,10 +475,16 @@ func init() {
 	// pgo create pgbouncer
 	createPgbouncerCmd.Flags().StringVar(&PgBouncerCPURequest, "cpu", "", "Set the number of millicores to request for CPU "+
 		"for pgBouncer. Defaults to being unset.")
+	createPgbouncerCmd.Flags().StringArrayVar(&PgBouncerDatabaseSettings, "database-setting", []string{},
+		"Set a pgBouncer setting of the connections to a database, e.g. \"mydb:pool_size=50\". Can be specified multiple times.")
 	createPgbouncerCmd.Flags().StringVar(&PgBouncerMemoryRequest, "memory", "", "Set the amount of Memory to request for "+
 		"pgBouncer. Defaults to server value (24Mi).")
+	createPgbouncerCmd.Flags().Int32Var(&PgBouncerReadOnlyReplicas, "read-only-replicas", 0, "Set the total number of instances "+
+		"of a read-only pgBouncer to deploy, which is routed to the replicas. If not set, no read-only pgBouncer is deployed.")
 	createPgbouncerCmd.Flags().Int32Var(&PgBouncerReplicas, "replicas", 0, "Set the total number of pgBouncer instances to deploy. If not set, defaults to 1.")
 	createPgbouncerCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
+	createPgbouncerCmd.Flags().StringArrayVar(&PgBouncerSettings, "setting", []string{},
+		"Set a pgBouncer setting, e.g. \"pool_mode=transaction\". Can be specified multiple times.")
 
 	// "pgo create pgouser" flags
 	createPgouserCmd.Flags().BoolVarP(&AllNamespaces, "all-namespaces", "", false, "specifies this user will have access to all namespaces.")
@@ -405,16 +498,24 @@ func init() {
 	createPolicyCmd.Flags().StringVarP(&PolicyURL, "url", "u", "", "The url to use for adding a policy.")
 
 	// "pgo create schedule" flags
-	createScheduleCmd.Flags().StringVarP(&ScheduleDatabase, "database", "", "", "The database to run the SQL policy against.")
+	createScheduleCmd.Flags().StringVarP(&ScheduleConcurrencyPolicy, "concurrency-policy", "", "", "What to do when the schedule is due while its previous run has not finished: Allow runs both, Forbid skips the new run and Replace stops the previous run. (default \"Replace\")")
+	createScheduleCmd.Flags().StringVarP(&ScheduleJitter, "jitter", "", "", "The longest random delay before each run of the schedule starts, e.g. \"10m\", which spreads out schedules that are due at the same time.")
+	createScheduleCmd.Flags().StringVarP(&ScheduleDatabase, "database", "", "", "The database to run the SQL policy against, or to back up with pgdump schedules.")
 	createScheduleCmd.Flags().StringVarP(&PGBackRestType, "pgbackrest-backup-type", "", "", "The type of pgBackRest backup to schedule (full, diff or incr).")
-	createScheduleCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use when scheduling pgBackRest backups. Either \"local\", \"s3\" or both, comma separated. (default \"local\")")
+	createScheduleCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use when scheduling pgBackRest backups. Either \"local\", \"s3\" or both, comma separated, or \"gcs\" or \"azure\". (default \"local\")")
+	createScheduleCmd.Flags().StringVarP(&PGDumpFormat, "pgdump-format", "", "", "The format of pgdump backups (plain, custom or tar). Defaults to the plain format of pg_dump.")
+	createScheduleCmd.Flags().IntVarP(&PGDumpKeepLast, "pgdump-keep-last", "", 0, "The number of pgdump backups of the database to keep on the PVC. Older backups are removed once a new backup has been taken successfully. Defaults to keeping every backup.")
 	createScheduleCmd.Flags().StringVarP(&CCPImageTag, "ccp-image-tag", "c", "", "The CCPImageTag to use for cluster creation. If specified, overrides the pgo.yaml setting.")
 	createScheduleCmd.Flags().StringVarP(&SchedulePolicy, "policy", "", "", "The policy to use for SQL schedules.")
 	createScheduleCmd.Flags().StringVarP(&Schedule, "schedule", "", "", "The schedule assigned to the cron task.")
+	createScheduleCmd.Flags().IntVar(&BackrestRepo, "repo", 0, "The number of the pgBackRest repository of the cluster to take the backups of pgbackrest schedules into. Defaults to the first repository.")
 	createScheduleCmd.Flags().StringVarP(&ScheduleOptions, "schedule-opts", "", "", "The custom options passed to the create schedule API.")
-	createScheduleCmd.Flags().StringVarP(&ScheduleType, "schedule-type", "", "", "The type of schedule to be created (pgbackrest or policy).")
-	createScheduleCmd.Flags().StringVarP(&ScheduleSecret, "secret", "", "", "The secret name for the username and password of the PostgreSQL role for SQL schedules.")
+	createScheduleCmd.Flags().StringVarP(&ScheduleType, "schedule-type", "", "", "The type of schedule to be created (pgbackrest, pgbackrest-verify, pgdump or policy).")
+	createScheduleCmd.Flags().StringVarP(&PVCName, "pvc-name", "", "", "The PVC name to store pgdump backups on instead of the default.")
+	createScheduleCmd.Flags().StringVarP(&ScheduleSecret, "secret", "", "", "The secret name for the username and password of the PostgreSQL role for SQL and pgdump schedules.")
 	createScheduleCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
+	createScheduleCmd.Flags().StringVarP(&StorageConfig, "storage-config", "", "", "The name of a Storage config in pgo.yaml to use for the PVC of pgdump backups.")
+	createScheduleCmd.Flags().StringVar(&BackrestVerifyQuery, "verify-query", "", "The query to run against the scratch instance of pgbackrest-verify schedules. Defaults to a query of the databases.")
 
 	// "pgo create user" flags
 	createUserCmd.Flags().BoolVar(&AllFlag, "all", false, "Create a user on every cluster.")
@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/pgo/api"
@@ -32,7 +33,8 @@ var failoverCmd = &cobra.Command{
 	Short: "Performs a manual failover",
 	Long: `Performs a manual failover. For example:
 
-	pgo failover mycluster`,
+	pgo failover mycluster --target=mycluster-abcd
+	pgo failover mycluster --target=auto`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if Namespace == "" {
 			Namespace = PGONamespace
@@ -62,7 +64,7 @@ func init() {
 
 	failoverCmd.Flags().BoolVarP(&Query, "query", "", false, "Prints the list of failover candidates.")
 	failoverCmd.Flags().BoolVar(&NoPrompt, "no-prompt", false, "No command line confirmation.")
-	failoverCmd.Flags().StringVarP(&Target, "target", "", "", "The replica target which the failover will occur on.")
+	failoverCmd.Flags().StringVarP(&Target, "target", "", "", "The replica target which the failover will occur on. Use \"auto\" for the recommended target.")
 
 }
 
@@ -129,8 +131,9 @@ func queryFailover(args []string, ns string) {
 		return
 	}
 
-	// output the information about each instance
-	fmt.Printf("%-20s\t%-10s\t%-10s\t%s\n", "REPLICA", "STATUS", "NODE", "REPLICATION LAG")
+	// output the information about each instance, from the best failover target
+	// to the worst
+	fmt.Printf("%-20s\t%-10s\t%-10s\t%s\t%s\n", "REPLICA", "STATUS", "NODE", "REPLICATION LAG", "SCORE")
 
 	for i := 0; i < len(response.Results); i++ {
 		instance := response.Results[i]
@@ -139,7 +142,25 @@ func queryFailover(args []string, ns string) {
 
 		node := instance.Node
 
-		fmt.Printf("%-20s\t%-10s\t%-10s\t%12d MB\n",
-			instance.Name, instance.Status, node, instance.ReplicationLag)
+		score := fmt.Sprintf("%d", instance.Score)
+		if !instance.Eligible {
+			score += " (ineligible)"
+		}
+
+		fmt.Printf("%-20s\t%-10s\t%-10s\t%12d MB\t%s\n",
+			instance.Name, instance.Status, node, instance.ReplicationLag, score)
+	}
+
+	// explain the recommendation, or why there is none
+	fmt.Println("")
+	if response.Recommended != "" {
+		fmt.Printf("Recommended target: %s (%s)\n", response.Recommended,
+			strings.Join(response.Results[0].Reasons, ", "))
+		return
+	}
+
+	fmt.Println("No replica passes the health bar of a failover target:")
+	for _, instance := range response.Results {
+		fmt.Printf("  %s: %s\n", instance.Name, strings.Join(instance.Reasons, ", "))
 	}
 }
@@ -48,4 +48,5 @@ var PGONamespace string
 var APIServerURL string
 var PGO_CA_CERT, PGO_CLIENT_CERT, PGO_CLIENT_KEY string
 var PGO_DISABLE_TLS bool
+var PGO_TOKEN string
 var EXCLUDE_OS_TRUST bool
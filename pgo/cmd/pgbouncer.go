@@ -18,11 +18,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
 	"github.com/crunchydata/postgres-operator/pgo/api"
 	"github.com/crunchydata/postgres-operator/pgo/util"
+	"github.com/spf13/cobra"
 )
 
 // showPgBouncerTextPadding contains the values for what the text padding should be
@@ -46,6 +48,18 @@ type updatePgBouncerTextPadding struct {
 // pgBouncer Deployment
 var PgBouncerReplicas int32
 
+// PgBouncerReadOnlyReplicas is the total number of replica pods to deploy with
+// the read-only pgBouncer Deployment, which is routed to the replicas
+var PgBouncerReadOnlyReplicas int32
+
+// PgBouncerSettings and PgBouncerDatabaseSettings are the pgBouncer settings to
+// set, in the format "name=value" and "database:name=value" respectively
+var PgBouncerSettings, PgBouncerDatabaseSettings []string
+
+// PgBouncerStats is used to include the statistics of the pgBouncer pods when
+// showing pgBouncer
+var PgBouncerStats bool
+
 // PgBouncerUninstall is used to ensure the objects intalled in PostgreSQL on
 // behalf of pgbouncer are either not applied (in the case of a cluster create)
 // or are removed (in the case of a pgo delete pgbouncer)
@@ -59,13 +73,14 @@ func createPgbouncer(args []string, ns string) {
 	}
 
 	request := msgs.CreatePgbouncerRequest{
-		Args:          args,
-		ClientVersion: msgs.PGO_VERSION,
-		CPURequest:    PgBouncerCPURequest,
-		MemoryRequest: PgBouncerMemoryRequest,
-		Namespace:     ns,
-		Replicas:      PgBouncerReplicas,
-		Selector:      Selector,
+		Args:             args,
+		ClientVersion:    msgs.PGO_VERSION,
+		CPURequest:       PgBouncerCPURequest,
+		MemoryRequest:    PgBouncerMemoryRequest,
+		Namespace:        ns,
+		ReadOnlyReplicas: PgBouncerReadOnlyReplicas,
+		Replicas:         PgBouncerReplicas,
+		Selector:         Selector,
 	}
 
 	if err := util.ValidateQuantity(request.CPURequest, "cpu"); err != nil {
@@ -78,6 +93,11 @@ func createPgbouncer(args []string, ns string) {
 		os.Exit(1)
 	}
 
+	if err := setPgBouncerSettings(&request.Config, &request.Databases); err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(1)
+	}
+
 	response, err := api.CreatePgbouncer(httpclient, &SessionCredentials, &request)
 
 	if err != nil {
@@ -160,6 +180,56 @@ func makeUpdatePgBouncerInterface(values []msgs.UpdatePgBouncerDetail) []interfa
 	return updatePgBouncerInterface
 }
 
+// printShowPgBouncerStatsText prints out the statistics of each pgBouncer
+// deployment, if they were requested
+func printShowPgBouncerStatsText(results []msgs.ShowPgBouncerDetail) {
+	for _, result := range results {
+		for _, stats := range result.Stats {
+			fmt.Println("")
+			fmt.Printf("%s: %d pod(s), %d client connection(s), %d server connection(s)\n",
+				stats.ServiceName, stats.Pods, stats.ClientConnections, stats.ServerConnections)
+
+			for _, err := range stats.Errors {
+				fmt.Println("Error: " + err)
+			}
+
+			pools := [][]string{}
+			for _, pool := range stats.Pools {
+				pools = append(pools, []string{
+					pool.Database,
+					pool.User,
+					strconv.FormatInt(pool.ClientsActive, 10),
+					strconv.FormatInt(pool.ClientsWaiting, 10),
+					fmt.Sprintf("%ds", pool.MaxWait),
+					strconv.FormatInt(pool.ServersActive, 10),
+					strconv.FormatInt(pool.ServersIdle, 10),
+					strconv.FormatInt(pool.PoolSize, 10),
+					fmt.Sprintf("%.0f%%", pool.Saturation*100),
+				})
+			}
+
+			printStatsTable([]string{headingDatabase, headingUsername, headingClientsActive,
+				headingClientsWaiting, headingMaxWait, headingServersActive, headingServersIdle,
+				headingPoolSize, headingSaturation}, pools)
+
+			// the average times are reported by pgBouncer in microseconds
+			databases := [][]string{}
+			for _, database := range stats.Databases {
+				databases = append(databases, []string{
+					database.Database,
+					fmt.Sprintf("%.3fms", float64(database.AverageQueryTime)/1000),
+					fmt.Sprintf("%.3fms", float64(database.AverageTransactionTime)/1000),
+					strconv.FormatInt(database.TotalQueryCount, 10),
+					strconv.FormatInt(database.TotalTransactionCount, 10),
+				})
+			}
+
+			printStatsTable([]string{headingDatabase, headingAvgQuery, headingAvgTransaction,
+				headingQueries, headingTransactions}, databases)
+		}
+	}
+}
+
 // printShowPgBouncerText prints out the information around each PostgreSQL
 // cluster's pgBouncer
 // printShowPgBouncerText renders a text response
@@ -176,8 +246,24 @@ func printShowPgBouncerText(response msgs.ShowPgBouncerResponse) {
 		return
 	}
 
+	// the read-only pgBouncer of a cluster, if there is one, is shown on a row of
+	// its own
+	rows := []msgs.ShowPgBouncerDetail{}
+
+	for _, result := range response.Results {
+		rows = append(rows, result)
+
+		if result.ReadOnlyServiceName != "" {
+			readOnly := result
+			readOnly.ServiceClusterIP = result.ReadOnlyServiceClusterIP
+			readOnly.ServiceExternalIP = result.ReadOnlyServiceExternalIP
+			readOnly.ServiceName = result.ReadOnlyServiceName
+			rows = append(rows, readOnly)
+		}
+	}
+
 	// make the interface for the pgbouncer clusters
-	showPgBouncerInterface := makeShowPgBouncerInterface(response.Results)
+	showPgBouncerInterface := makeShowPgBouncerInterface(rows)
 
 	// format the header
 	// start by setting up the different text paddings
@@ -193,9 +279,11 @@ func printShowPgBouncerText(response msgs.ShowPgBouncerResponse) {
 	printShowPgBouncerTextHeader(padding)
 
 	// iterate through the reuslts and print them out
-	for _, result := range response.Results {
+	for _, result := range rows {
 		printShowPgBouncerTextRow(result, padding)
 	}
+
+	printShowPgBouncerStatsText(response.Results)
 }
 
 // printShowPgBouncerTextHeader prints out the header
@@ -233,6 +321,41 @@ func printShowPgBouncerTextRow(result msgs.ShowPgBouncerDetail, padding showPgBo
 	fmt.Println("")
 }
 
+// printStatsTable prints a table of statistics, where each column is padded to
+// the length of its longest value
+func printStatsTable(headings []string, rows [][]string) {
+	padding := make([]int, len(headings))
+
+	for i, heading := range headings {
+		padding[i] = len(heading) + 1
+	}
+
+	for _, row := range rows {
+		for i, value := range row {
+			if len(value)+1 > padding[i] {
+				padding[i] = len(value) + 1
+			}
+		}
+	}
+
+	// print the header, and the layer of "-" below it
+	fmt.Println("")
+	lines := make([]string, len(headings))
+	for i, heading := range headings {
+		fmt.Printf("%s", util.Rpad(heading, " ", padding[i]))
+		lines[i] = strings.Repeat("-", padding[i]-1)
+	}
+	fmt.Println("")
+	fmt.Println(strings.Join(lines, " "))
+
+	for _, row := range rows {
+		for i, value := range row {
+			fmt.Printf("%s", util.Rpad(value, " ", padding[i]))
+		}
+		fmt.Println("")
+	}
+}
+
 // printUpdatePgBouncerText prints out the information about how each pgBouncer
 // updat efared after a request
 // printShowPgBouncerText renders a text response
@@ -315,6 +438,7 @@ func showPgBouncer(namespace string, clusterNames []string) {
 		ClusterNames: clusterNames,
 		Namespace:    namespace,
 		Selector:     Selector,
+		Stats:        PgBouncerStats,
 	}
 
 	// and make the API request!
@@ -341,7 +465,7 @@ func showPgBouncer(namespace string, clusterNames []string) {
 // a pgBouncer deployment in a cluster
 // one or more pgBouncer deployments. "clusterNames" is an array of cluster
 // names to iterate over
-func updatePgBouncer(namespace string, clusterNames []string) {
+func updatePgBouncer(cmd *cobra.Command, namespace string, clusterNames []string) {
 	// first, determine if any arguments have been pass in
 	if len(clusterNames) == 0 && Selector == "" {
 		fmt.Println("Error: You must provide at least one cluster name, or use a selector with the `--selector` flag")
@@ -369,6 +493,16 @@ func updatePgBouncer(namespace string, clusterNames []string) {
 		os.Exit(1)
 	}
 
+	if err := setPgBouncerSettings(&request.Config, &request.Databases); err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(1)
+	}
+
+	// the read-only pgBouncer is only changed if asked to, as 0 removes it
+	if cmd.Flags().Changed("read-only-replicas") {
+		request.ReadOnlyReplicas = &PgBouncerReadOnlyReplicas
+	}
+
 	// and make the API request!
 	response, err := api.UpdatePgBouncer(httpclient, &SessionCredentials, request)
 
@@ -388,3 +522,47 @@ func updatePgBouncer(namespace string, clusterNames []string) {
 		printUpdatePgBouncerText(response)
 	}
 }
+
+// setPgBouncerSettings parses the pgBouncer settings of the command line into
+// the settings and the database settings of a request
+func setPgBouncerSettings(settings *map[string]string, databases *map[string]map[string]string) error {
+	for _, setting := range PgBouncerSettings {
+		kv := strings.SplitN(setting, "=", 2)
+
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("pgBouncer setting %q must be in the format \"name=value\"", setting)
+		}
+
+		if *settings == nil {
+			*settings = map[string]string{}
+		}
+
+		(*settings)[kv[0]] = kv[1]
+	}
+
+	for _, setting := range PgBouncerDatabaseSettings {
+		database := strings.SplitN(setting, ":", 2)
+
+		if len(database) != 2 || database[0] == "" {
+			return fmt.Errorf("pgBouncer database setting %q must be in the format \"database:name=value\"", setting)
+		}
+
+		kv := strings.SplitN(database[1], "=", 2)
+
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("pgBouncer database setting %q must be in the format \"database:name=value\"", setting)
+		}
+
+		if *databases == nil {
+			*databases = map[string]map[string]string{}
+		}
+
+		if (*databases)[database[0]] == nil {
+			(*databases)[database[0]] = map[string]string{}
+		}
+
+		(*databases)[database[0]][kv[0]] = kv[1]
+	}
+
+	return nil
+}
@@ -98,6 +98,10 @@ func showPgouser(args []string, ns string) {
 		fmt.Println("pgouser : " + pgouser.Username)
 		fmt.Printf("roles : %v\n", pgouser.Role)
 		fmt.Printf("namespaces : %v\n", pgouser.Namespace)
+		fmt.Printf("failed logins : %d\n", pgouser.FailedLogins)
+		if pgouser.Locked {
+			fmt.Printf("locked until : %s\n", pgouser.LockedUntil)
+		}
 	}
 
 }
@@ -0,0 +1,87 @@
+package cmd
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"os"
+
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/pgo/api"
+	"github.com/crunchydata/postgres-operator/pgo/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var restartCmd = &cobra.Command{
+	Use:   "restart",
+	Short: "Performs a rolling restart of a cluster",
+	Long: `Performs a rolling restart of the PostgreSQL instances of a cluster. The replicas
+are restarted one at a time, each once the previous one is streaming from the primary
+again. The cluster is then switched over to a replica so that the former primary can be
+restarted. When a target is given, only that instance is restarted. For example:
+
+	pgo restart mycluster
+	pgo restart mycluster --target=mycluster-abcd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if Namespace == "" {
+			Namespace = PGONamespace
+		}
+		log.Debug("restart called")
+		if len(args) == 0 {
+			fmt.Println(`Error: You must specify the cluster to restart.`)
+		} else if util.AskForConfirmation(NoPrompt, "") {
+			restart(args, Namespace)
+		} else {
+			fmt.Println("Aborting...")
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(restartCmd)
+
+	restartCmd.Flags().BoolVar(&NoPrompt, "no-prompt", false, "No command line confirmation.")
+	restartCmd.Flags().StringVarP(&Target, "target", "", "",
+		"The instance to restart. Defaults to all of the instances of the cluster.")
+}
+
+// restart ....
+func restart(args []string, ns string) {
+	log.Debugf("restart called %v", args)
+
+	request := new(msgs.RestartRequest)
+	request.Namespace = ns
+	request.ClusterName = args[0]
+	request.Target = Target
+	request.ClientVersion = msgs.PGO_VERSION
+
+	response, err := api.Restart(httpclient, &SessionCredentials, request)
+
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	if response.Status.Code == msgs.Ok {
+		for k := range response.Results {
+			fmt.Println(response.Results[k])
+		}
+	} else {
+		fmt.Println("Error: " + response.Status.Msg)
+		os.Exit(2)
+	}
+}
@@ -32,12 +32,24 @@ import (
 var PITRTarget string
 var BackupPath, BackupPVC string
 
+// RestoreTargetCluster is the name of a new cluster to restore into, and
+// RestoreBackupLabel the label of the pgBackRest backup to restore
+var RestoreTargetCluster, RestoreBackupLabel string
+
 var restoreCmd = &cobra.Command{
 	Use:   "restore",
 	Short: "Perform a restore from previous backup",
 	Long: `RESTORE performs a restore to a new PostgreSQL cluster. This includes stopping the database and recreating a new primary with the restored data.  Valid backup types to restore from are pgbackrest and pgdump. For example:
 
-	pgo restore mycluster `,
+	pgo restore mycluster
+
+To restore into a new cluster instead, which leaves the original cluster running, for example to recover a dropped table:
+
+	pgo restore mycluster --target-cluster=mycluster-recovered --pitr-target="2020-03-01 08:00:00.000000+00"
+
+To restore from the second pgBackRest repository of the cluster:
+
+	pgo restore mycluster --repo=2`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if Namespace == "" {
 			Namespace = PGONamespace
@@ -46,7 +58,36 @@ var restoreCmd = &cobra.Command{
 		if len(args) == 0 {
 			fmt.Println(`Error: You must specify the cluster name to restore from.`)
 		} else {
-			if BackupType == "" || BackupType == config.LABEL_BACKUP_TYPE_BACKREST {
+			isBackrest := BackupType == "" || BackupType == config.LABEL_BACKUP_TYPE_BACKREST
+
+			if !isBackrest && (RestoreTargetCluster != "" || RestoreBackupLabel != "") {
+				fmt.Println("Error: --target-cluster and --backup-label are only allowed for pgbackrest restores.")
+				os.Exit(2)
+			}
+
+			if !isBackrest && BackrestRepo != 0 {
+				fmt.Println("Error: --repo is only allowed for pgbackrest restores.")
+				os.Exit(2)
+			}
+
+			if BackrestRepo < 0 {
+				fmt.Println("Error: --repo must be the number of a pgBackRest repository, starting at 1.")
+				os.Exit(2)
+			}
+
+			if BackrestRepo != 0 && (BackrestStorageType != "" || RestoreTargetCluster != "") {
+				fmt.Println("Error: --repo cannot be used with --pgbackrest-storage-type or --target-cluster.")
+				os.Exit(2)
+			}
+
+			if RestoreBackupLabel != "" && RestoreTargetCluster == "" {
+				fmt.Println("Error: --backup-label is only allowed with --target-cluster.")
+				os.Exit(2)
+			}
+
+			if isBackrest && RestoreTargetCluster != "" {
+				fmt.Printf("The backup will be restored into the new cluster %s, leaving %s running.\n", RestoreTargetCluster, args[0])
+			} else if isBackrest {
 				fmt.Println("If currently running, the primary database in this cluster will be stopped and recreated as part of this workflow!")
 			}
 			if pgoutil.AskForConfirmation(NoPrompt, "") {
@@ -69,7 +110,10 @@ func init() {
 	restoreCmd.Flags().BoolVar(&NoPrompt, "no-prompt", false, "No command line confirmation.")
 	restoreCmd.Flags().StringVarP(&BackupPVC, "backup-pvc", "", "", "The PVC containing the pgdump to restore from.")
 	restoreCmd.Flags().StringVarP(&BackupType, "backup-type", "", "", "The type of backup to restore from, default is pgbackrest. Valid types are pgbackrest or pgdump.")
-	restoreCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use for a pgBackRest restore. Either \"local\", \"s3\". (default \"local\")")
+	restoreCmd.Flags().StringVar(&RestoreTargetCluster, "target-cluster", "", "The name of a new cluster to restore into, which leaves the cluster that is restored from running.")
+	restoreCmd.Flags().StringVar(&RestoreBackupLabel, "backup-label", "", "The label of the pgBackRest backup to restore into the new cluster of --target-cluster, e.g. 20200301-010000F. Defaults to the latest backup.")
+	restoreCmd.Flags().IntVar(&BackrestRepo, "repo", 0, "The number of the pgBackRest repository of the cluster to restore from. Defaults to the first repository.")
+	restoreCmd.Flags().StringVarP(&BackrestStorageType, "pgbackrest-storage-type", "", "", "The type of storage to use for a pgBackRest restore. Either \"local\", \"s3\", \"gcs\" or \"azure\". (default \"local\")")
 }
 
 // restore ....
@@ -101,6 +145,9 @@ func restore(args []string, ns string) {
 		request.PITRTarget = PITRTarget
 		request.NodeLabel = NodeLabel
 		request.BackrestStorageType = BackrestStorageType
+		request.TargetCluster = RestoreTargetCluster
+		request.BackupLabel = RestoreBackupLabel
+		request.Repo = BackrestRepo
 
 		response, err = api.Restore(httpclient, &SessionCredentials, request)
 	}
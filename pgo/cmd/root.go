@@ -68,6 +68,7 @@ func init() {
 	RootCmd.PersistentFlags().BoolVar(&PGO_DISABLE_TLS, "disable-tls", false, "Disable TLS authentication to the Postgres Operator.")
 	RootCmd.PersistentFlags().BoolVar(&EXCLUDE_OS_TRUST, "exclude-os-trust", defExclOSTrust, "Exclude CA certs from OS default trust store")
 	RootCmd.PersistentFlags().BoolVar(&DebugFlag, "debug", false, "Enable additional output for debugging.")
+	RootCmd.PersistentFlags().StringVar(&PGO_TOKEN, "token", "", "The OIDC bearer token used to authenticate to the PostgreSQL Operator apiserver instead of the pgouser credentials.")
 
 }
 
@@ -108,6 +109,10 @@ func initConfig() {
 		}
 	}
 
+	if SessionCredentials.Token != "" {
+		setBearerTokenTransport(httpclient, SessionCredentials.Token)
+	}
+
 	if os.Getenv("GENERATE_BASH_COMPLETION") != "" {
 		generateBashCompletion()
 	}
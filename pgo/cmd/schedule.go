@@ -17,6 +17,7 @@ package cmd
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -38,6 +39,11 @@ type schedule struct {
 	selector            string
 	policy              string
 	database            string
+	pgdumpFormat        string
+	pgdumpKeepLast      int
+	concurrencyPolicy   string
+	jitter              string
+	repo                int
 }
 
 func createSchedule(args []string, ns string) {
@@ -58,6 +64,11 @@ func createSchedule(args []string, ns string) {
 		scheduleType:        ScheduleType,
 		policy:              SchedulePolicy,
 		database:            ScheduleDatabase,
+		pgdumpFormat:        PGDumpFormat,
+		pgdumpKeepLast:      PGDumpKeepLast,
+		concurrencyPolicy:   ScheduleConcurrencyPolicy,
+		jitter:              ScheduleJitter,
+		repo:                BackrestRepo,
 	}
 
 	err := s.validateSchedule()
@@ -78,6 +89,13 @@ func createSchedule(args []string, ns string) {
 		PolicyName:          SchedulePolicy,
 		Database:            ScheduleDatabase,
 		Secret:              ScheduleSecret,
+		StorageConfig:       StorageConfig,
+		PGDumpFormat:        PGDumpFormat,
+		PGDumpKeepLast:      PGDumpKeepLast,
+		ConcurrencyPolicy:   ScheduleConcurrencyPolicy,
+		Jitter:              ScheduleJitter,
+		VerifyQuery:         BackrestVerifyQuery,
+		Repo:                BackrestRepo,
 		Namespace:           ns,
 	}
 
@@ -200,11 +218,31 @@ func (s *schedule) validateSchedule() error {
 		return err
 	}
 
+	if err := scheduler.ValidateConcurrencyPolicy(s.concurrencyPolicy); err != nil {
+		return err
+	}
+
+	if err := scheduler.ValidateJitter(s.jitter); err != nil {
+		return err
+	}
+
 	if err := scheduler.ValidateBackRestSchedule(s.scheduleType, s.clusterName, s.selector, s.backrestType,
 		s.backrestStorageType); err != nil {
 		return err
 	}
 
+	if err := scheduler.ValidateBackRestVerifySchedule(s.scheduleType, s.backrestStorageType); err != nil {
+		return err
+	}
+
+	if s.repo != 0 && (strings.ToLower(s.scheduleType) != "pgbackrest" || s.backrestStorageType != "") {
+		return errors.New("--repo is only allowed for pgbackrest schedules, without --pgbackrest-storage-type")
+	}
+
+	if err := scheduler.ValidatePGDumpSchedule(s.scheduleType, s.database, s.pgdumpFormat, s.pgdumpKeepLast); err != nil {
+		return err
+	}
+
 	if err := scheduler.ValidatePolicySchedule(s.scheduleType, s.policy, s.database); err != nil {
 		return err
 	}
@@ -108,6 +108,7 @@ func init() {
 	ShowPolicyCmd.Flags().BoolVar(&AllFlag, "all", false, "show all resources.")
 	ShowPgBouncerCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
 	ShowPgBouncerCmd.Flags().StringVarP(&OutputFormat, "output", "o", "", `The output format. Supported types are: "json"`)
+	ShowPgBouncerCmd.Flags().BoolVar(&PgBouncerStats, "stats", false, "Include the statistics of the pgBouncer pods, e.g. the waiting clients, pool saturation and average query times.")
 	ShowPVCCmd.Flags().BoolVar(&AllFlag, "all", false, "show all resources.")
 	ShowScheduleCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
 	ShowScheduleCmd.Flags().StringVarP(&ScheduleName, "schedule-name", "", "", "The name of the schedule to show.")
@@ -141,6 +142,7 @@ var ShowPgBouncerCmd = &cobra.Command{
 	Long: `Show user, password, and service information about a pgbouncer deployment. For example:
 
 	pgo show pgbouncer hacluster
+	pgo show pgbouncer hacluster --stats
 	pgo show pgounbcer --selector=app=payment
 	`,
 
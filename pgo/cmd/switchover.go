@@ -0,0 +1,101 @@
+package cmd
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"os"
+
+	msgs "github.com/crunchydata/postgres-operator/apiservermsgs"
+	"github.com/crunchydata/postgres-operator/config"
+	"github.com/crunchydata/postgres-operator/pgo/api"
+	"github.com/crunchydata/postgres-operator/pgo/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// SwitchoverMaxLagBytes is the replication lag above which the candidate of a
+// switchover is refused
+var SwitchoverMaxLagBytes int64
+
+// SwitchoverScheduledAt is the time at which a switchover is performed
+var SwitchoverScheduledAt string
+
+var switchoverCmd = &cobra.Command{
+	Use:   "switchover",
+	Short: "Performs a switchover",
+	Long: `Performs a switchover, i.e. a graceful failover where the primary is demoted
+before the candidate is promoted once it has caught up. The candidate is refused if it is
+further behind the primary than the maximum lag. For example:
+
+	pgo switchover mycluster
+	pgo switchover mycluster --target=mycluster-abcd --max-lag-bytes=0
+	pgo switchover mycluster --scheduled-at=2020-06-01T01:00:00Z`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if Namespace == "" {
+			Namespace = PGONamespace
+		}
+		log.Debug("switchover called")
+		if len(args) == 0 {
+			fmt.Println(`Error: You must specify the cluster to switchover.`)
+		} else if util.AskForConfirmation(NoPrompt, "") {
+			createSwitchover(args, Namespace)
+		} else {
+			fmt.Println("Aborting...")
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(switchoverCmd)
+
+	switchoverCmd.Flags().Int64Var(&SwitchoverMaxLagBytes, "max-lag-bytes", config.DefaultSwitchoverMaxLagBytes,
+		"The replication lag, in bytes, above which a replica is refused as the switchover candidate.")
+	switchoverCmd.Flags().BoolVar(&NoPrompt, "no-prompt", false, "No command line confirmation.")
+	switchoverCmd.Flags().StringVar(&SwitchoverScheduledAt, "scheduled-at", "",
+		"The time at which to perform the switchover, as an RFC 3339 timestamp such as 2020-06-01T01:00:00Z. Defaults to now.")
+	switchoverCmd.Flags().StringVarP(&Target, "target", "", "",
+		"The replica to switch over to. Defaults to the running replica with the least replication lag.")
+}
+
+// createSwitchover ....
+func createSwitchover(args []string, ns string) {
+	log.Debugf("createSwitchover called %v", args)
+
+	request := new(msgs.CreateSwitchoverRequest)
+	request.Namespace = ns
+	request.ClusterName = args[0]
+	request.Target = Target
+	request.MaxLagBytes = SwitchoverMaxLagBytes
+	request.ScheduledAt = SwitchoverScheduledAt
+	request.ClientVersion = msgs.PGO_VERSION
+
+	response, err := api.CreateSwitchover(httpclient, &SessionCredentials, request)
+
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	if response.Status.Code == msgs.Ok {
+		for k := range response.Results {
+			fmt.Println(response.Results[k])
+		}
+	} else {
+		fmt.Println("Error: " + response.Status.Msg)
+		os.Exit(2)
+	}
+}
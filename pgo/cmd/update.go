@@ -44,6 +44,12 @@ var (
 	DisableStandby bool
 	// EnableStandby can be used to enable standby mode in an existing cluster
 	EnableStandby bool
+	// PostgresParameters are the PostgreSQL parameters to set on a cluster, in
+	// the format "name=value"
+	PostgresParameters []string
+	// RestartPending is used to restart the instances of a cluster that are
+	// pending a restart
+	RestartPending bool
 	// Shutdown is used to indicate that the cluster should be shutdown
 	Shutdown bool
 	// Startup is used to indicate that the cluster should be started (assuming it is shutdown)
@@ -74,12 +80,34 @@ func init() {
 		"for the pgBackRest repository.")
 	UpdateClusterCmd.Flags().StringVar(&BackrestMemoryRequest, "pgbackrest-memory", "", "Set the amount of Memory to request for "+
 		"the pgBackRest repository.")
+	UpdateClusterCmd.Flags().IntVar(&BackrestRetentionArchive, "pgbackrest-retention-archive", 0,
+		"Set the number of backups to retain the WAL archive of. Set to 0 to remove the setting.")
+	UpdateClusterCmd.Flags().StringVar(&BackrestRetentionArchiveType, "pgbackrest-retention-archive-type", "",
+		"Set the type of backup, i.e. \"full\", \"diff\" or \"incr\", that \"--pgbackrest-retention-archive\" refers to.")
+	UpdateClusterCmd.Flags().IntVar(&BackrestRetentionDiff, "pgbackrest-retention-diff", 0,
+		"Set the number of differential backups to retain. Set to 0 to remove the setting.")
+	UpdateClusterCmd.Flags().IntVar(&BackrestRetentionFull, "pgbackrest-retention-full", 0,
+		"Set the number of full backups to retain. Set to 0 to remove the setting.")
 	UpdateClusterCmd.Flags().BoolVarP(&EnableStandby, "enable-standby", "", false,
 		"Enables standby mode in the cluster(s) specified.")
+	UpdateClusterCmd.Flags().BoolVar(&RestartPending, "restart-pending", false, "Restart the instances of the "+
+		"cluster that are pending a restart, replicas first, e.g. after changing a parameter that requires a restart.")
+	UpdateClusterCmd.Flags().StringArrayVar(&PostgresParameters, "set-parameter", []string{},
+		"Set a PostgreSQL parameter, e.g. \"work_mem=8MB\". An empty value, e.g. \"work_mem=\", restores the "+
+			"default. Can be specified multiple times.")
 	UpdateClusterCmd.Flags().BoolVar(&Startup, "startup", false, "Restart the database cluster if it "+
 		"is currently shutdown.")
 	UpdateClusterCmd.Flags().BoolVar(&Shutdown, "shutdown", false, "Shutdown the database "+
 		"cluster if it is currently running.")
+	UpdateClusterCmd.Flags().StringSliceVar(&SyncReplicationExcludedReplicas, "sync-replication-exclude", []string{},
+		"Set the names of the replicas that are never chosen as synchronous standbys. Set to \"\" to remove "+
+			"the exclusions. Requires synchronous replication.")
+	UpdateClusterCmd.Flags().IntVar(&SyncReplicationNodeCount, "sync-replication-node-count", 0,
+		"Set the number of synchronous standbys. Set to 0 to use the default of 1. More than 1 requires "+
+			"Patroni 2.0. Requires synchronous replication.")
+	UpdateClusterCmd.Flags().BoolVar(&SyncReplicationStrict, "sync-replication-strict", false,
+		"Set whether writes on the primary stop when no synchronous standby is available. Requires "+
+			"synchronous replication.")
 	UpdateClusterCmd.Flags().StringSliceVar(&Tablespaces, "tablespace", []string{},
 		"Add a PostgreSQL tablespace on the cluster, e.g. \"name=ts1:storageconfig=nfsstorage\". The format is "+
 			"a key/value map that is delimited by \"=\" and separated by \":\". The following parameters are available:\n\n"+
@@ -92,13 +120,21 @@ func init() {
 			"--tablespace=name=ts1:storageconfig=nfsstorage:pvcsize=10Gi")
 	UpdatePgBouncerCmd.Flags().StringVar(&PgBouncerCPURequest, "cpu", "", "Set the number of millicores to request for CPU "+
 		"for pgBouncer.")
+	UpdatePgBouncerCmd.Flags().StringArrayVar(&PgBouncerDatabaseSettings, "database-setting", []string{},
+		"Set a pgBouncer setting of the connections to a database, e.g. \"mydb:pool_size=50\". An empty value, e.g. "+
+			"\"mydb:pool_size=\", removes the setting. Can be specified multiple times.")
 	UpdatePgBouncerCmd.Flags().StringVar(&PgBouncerMemoryRequest, "memory", "", "Set the amount of Memory to request for "+
 		"pgBouncer.")
 	UpdatePgBouncerCmd.Flags().BoolVar(&NoPrompt, "no-prompt", false, "No command line confirmation.")
 	UpdatePgBouncerCmd.Flags().StringVarP(&OutputFormat, "output", "o", "", `The output format. Supported types are: "json"`)
+	UpdatePgBouncerCmd.Flags().Int32Var(&PgBouncerReadOnlyReplicas, "read-only-replicas", 0, "Set the total number of instances "+
+		"of the read-only pgBouncer, which is routed to the replicas. 0 removes the read-only pgBouncer.")
 	UpdatePgBouncerCmd.Flags().Int32Var(&PgBouncerReplicas, "replicas", 0, "Set the total number of pgBouncer instances to deploy. If not set, defaults to 1.")
 	UpdatePgBouncerCmd.Flags().BoolVar(&RotatePassword, "rotate-password", false, "Used to rotate the pgBouncer service account password. Can cause interruption of service.")
 	UpdatePgBouncerCmd.Flags().StringVarP(&Selector, "selector", "s", "", "The selector to use for cluster filtering.")
+	UpdatePgBouncerCmd.Flags().StringArrayVar(&PgBouncerSettings, "setting", []string{},
+		"Set a pgBouncer setting, e.g. \"pool_mode=transaction\". An empty value, e.g. \"pool_mode=\", restores "+
+			"the default. Can be specified multiple times. Settings are reloaded without a restart.")
 	UpdatePgouserCmd.Flags().StringVarP(&PgouserNamespaces, "pgouser-namespaces", "", "", "The namespaces to use for updating the pgouser roles.")
 	UpdatePgouserCmd.Flags().BoolVar(&AllNamespaces, "all-namespaces", false, "all namespaces.")
 	UpdatePgouserCmd.Flags().StringVarP(&PgouserRoles, "pgouser-roles", "", "", "The roles to use for updating the pgouser roles.")
@@ -178,7 +214,9 @@ var UpdateClusterCmd = &cobra.Command{
     pgo update cluster mycluster --autofail=false
     pgo update cluster mycluster myothercluster --disable-autofail
     pgo update cluster --selector=name=mycluster --disable-autofail
-    pgo update cluster --all --enable-autofail`,
+    pgo update cluster --all --enable-autofail
+    pgo update cluster mycluster --set-parameter=max_connections=200
+    pgo update cluster mycluster --restart-pending`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if Namespace == "" {
 			Namespace = PGONamespace
@@ -224,12 +262,24 @@ var UpdateClusterCmd = &cobra.Command{
 			fmt.Println("Updating pgBackRest resources can cause temporary unavailability of backups and WAL archives.")
 		}
 
+		if cmd.Flags().Changed("pgbackrest-retention-full") || cmd.Flags().Changed("pgbackrest-retention-diff") ||
+			cmd.Flags().Changed("pgbackrest-retention-archive") ||
+			cmd.Flags().Changed("pgbackrest-retention-archive-type") {
+			fmt.Println("Updating the pgBackRest retention policy restarts the pgBackRest repository, and older " +
+				"backups are expired with the next backup.")
+		}
+
+		if RestartPending {
+			fmt.Println("Restarting the instances that are pending a restart briefly interrupts each of them, " +
+				"including the primary.")
+		}
+
 		if !util.AskForConfirmation(NoPrompt, "") {
 			fmt.Println("Aborting...")
 			return
 		}
 
-		updateCluster(args, Namespace)
+		updateCluster(cmd, args, Namespace)
 	},
 }
 
@@ -289,6 +339,8 @@ var UpdatePgBouncerCmd = &cobra.Command{
 	as by rotating a password. For example:
 
 	pgo update pgbouncer hacluster --rotate-password
+	pgo update pgbouncer hacluster --setting=pool_mode=transaction --database-setting=mydb:pool_size=50
+	pgo update pgbouncer hacluster --read-only-replicas=2
 	`,
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -306,7 +358,12 @@ var UpdatePgBouncerCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		updatePgBouncer(Namespace, args)
+		if PgBouncerReadOnlyReplicas < 0 {
+			fmt.Println("Error: The number of read-only replicas cannot be negative.")
+			os.Exit(1)
+		}
+
+		updatePgBouncer(cmd, Namespace, args)
 	},
 }
 
@@ -29,6 +29,7 @@ import (
 	crunchylog "github.com/crunchydata/postgres-operator/logging"
 	"github.com/crunchydata/postgres-operator/ns"
 	"github.com/crunchydata/postgres-operator/operator/operatorupgrade"
+	"github.com/crunchydata/postgres-operator/operator/webhook"
 	log "github.com/sirupsen/logrus"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -100,6 +101,15 @@ func main() {
 		}
 	}
 
+	// if enabled, register and serve the admission webhooks that validate and
+	// default the custom resources of the Operator
+	if operator.Pgo.Pgo.AdmissionWebhook {
+		if err := startAdmissionWebhook(kubeClientset, stopCh); err != nil {
+			log.Error(err)
+			os.Exit(2)
+		}
+	}
+
 	defer controllerManager.RemoveAll()
 
 	operatorupgrade.OperatorUpdateCRPgoVersion(kubeClientset, pgoRESTclient, namespaceList)
@@ -133,3 +143,29 @@ func createAndStartNamespaceController(kubeClientset *kubernetes.Clientset,
 
 	return nil
 }
+
+// startAdmissionWebhook registers the admission webhooks with Kubernetes and then
+// serves them until the stop channel is closed
+func startAdmissionWebhook(kubeClientset *kubernetes.Clientset, stopCh <-chan struct{}) error {
+	port := *operator.Pgo.Pgo.AdmissionWebhookPort
+
+	certPEM, keyPEM, err := webhook.Register(kubeClientset, operator.PgoNamespace,
+		operator.InstallationName, port)
+	if err != nil {
+		return err
+	}
+
+	server := &webhook.Server{
+		Clientset: kubeClientset,
+		PgoConfig: &operator.Pgo,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(port, certPEM, keyPEM, stopCh); err != nil {
+			log.Error(err)
+		}
+	}()
+	log.Debug("admission webhooks are now running")
+
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"io"
@@ -95,6 +96,33 @@ func NewSelfSignedCACertificate(key *rsa.PrivateKey) (*x509.Certificate, error)
 	return x509.ParseCertificate(certDERBytes)
 }
 
+// NewSelfSignedServingCertificate returns a self-signed certificate for serving
+// TLS under the DNS names provided. As it signs itself, the certificate is also
+// the CA that clients have to trust. The certificate has one-year lease.
+func NewSelfSignedServingCertificate(key *rsa.PrivateKey, dnsNames ...string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:              dnsNames,
+		NotBefore:             now.UTC(),
+		NotAfter:              now.Add(duration365d).UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDERBytes, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(certDERBytes)
+}
+
 // ExtendTrust extends the provided certpool with the PEM-encoded certificates
 // presented by certSource. If reading from certSource produces an error
 // the base pool remains unmodified
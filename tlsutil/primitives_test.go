@@ -70,6 +70,28 @@ func TestCertPEMSymmetry(t *testing.T) {
 	}
 }
 
+func TestSelfSignedServingCertificate(t *testing.T) {
+	privKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate new key - %s", err)
+	}
+
+	cert, err := NewSelfSignedServingCertificate(privKey, "postgres-operator.pgo.svc")
+	if err != nil {
+		t.Fatalf("unable to generate cert - %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	if _, err := cert.Verify(x509.VerifyOptions{
+		DNSName: "postgres-operator.pgo.svc",
+		Roots:   roots,
+	}); err != nil {
+		t.Fatalf("expected the cert to verify against itself - %s", err)
+	}
+}
+
 func TestExtendedTrust(t *testing.T) {
 	expected := "You do that very well. It's as if i was looking in a mirror."
 
@@ -18,6 +18,7 @@ package util
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
@@ -29,6 +30,22 @@ const (
 	BackrestRepoPVCName        = "%s-pgbr-repo"
 )
 
+// BackrestVersion is the version of pgBackRest bundled with the PostgreSQL
+// Operator, which must be kept in sync with PGO_BACKREST_VERSION in the Makefile
+const BackrestVersion = "2.33"
+
+// backrestMultiRepoVersion is the first version of pgBackRest that supports
+// more than one repository, i.e. the "--repo" option and the "repoN-" settings
+// for N greater than 1
+const backrestMultiRepoVersion = "2.33"
+
+// The following constants define the first versions of pgBackRest that support
+// the GCS and Azure storage types
+const (
+	backrestGCSVersion   = "2.33"
+	backrestAzureVersion = "2.28"
+)
+
 // defines the default repo1-path for pgBackRest for use when a specic path is not provided
 // in the pgcluster CR.  The '%s' format verb will be replaced with the cluster name when this
 // variable is utilized
@@ -50,13 +67,17 @@ func ValidateBackrestStorageTypeOnBackupRestore(newBackRestStorageType,
 		!strings.Contains(currentBackRestStorageType, "s3") {
 		return errors.New("Storage type 's3' not allowed. S3 storage is not enabled for " +
 			"pgBackRest in this cluster")
+	} else if storageType := gcsOrAzureStorageType(newBackRestStorageType); storageType != "" &&
+		!strings.Contains(currentBackRestStorageType, storageType) {
+		return fmt.Errorf("Storage type '%s' not allowed. This storage is not enabled for "+
+			"pgBackRest in this cluster", storageType)
 	} else if (newBackRestStorageType == "" ||
 		strings.Contains(newBackRestStorageType, "local")) &&
 		(currentBackRestStorageType != "" &&
 			!strings.Contains(currentBackRestStorageType, "local")) {
 		return errors.New("Storage type 'local' not allowed. Local storage is not enabled for " +
-			"pgBackRest in this cluster. If this cluster uses S3 storage only, specify 's3' " +
-			"for the pgBackRest storage type.")
+			"pgBackRest in this cluster. If this cluster uses S3, GCS or Azure storage only, " +
+			"specify 's3', 'gcs' or 'azure' for the pgBackRest storage type.")
 	}
 
 	// storage type validation that is only applicable for restores
@@ -70,6 +91,17 @@ func ValidateBackrestStorageTypeOnBackupRestore(newBackRestStorageType,
 	return nil
 }
 
+// gcsOrAzureStorageType returns "gcs" or "azure" if the storage type string
+// contains either of them, and an empty string otherwise
+func gcsOrAzureStorageType(storageType string) string {
+	for _, t := range strings.Split(storageType, ",") {
+		if t == "gcs" || t == "azure" {
+			return t
+		}
+	}
+	return ""
+}
+
 // IsValidBackrestStorageType determines if the storage source string contains valid pgBackRest
 // storage type values
 func IsValidBackrestStorageType(storageType string) bool {
@@ -83,6 +115,40 @@ func IsValidBackrestStorageType(storageType string) bool {
 	return isValid
 }
 
+// validateBackrestVersion returns an error if the bundled version of pgBackRest
+// is older than the version a feature requires
+func validateBackrestVersion(feature, version string) error {
+	if !isVersionAtLeast(BackrestVersion, version) {
+		return fmt.Errorf("%s requires pgBackRest %s or later, but pgBackRest %s is bundled with "+
+			"the PostgreSQL Operator", feature, version, BackrestVersion)
+	}
+	return nil
+}
+
+// isVersionAtLeast returns true if a version, e.g. "2.33" of pgBackRest, is the
+// same as or later than another one. Versions are compared field by field, and
+// a field that is not a number compares as 0
+func isVersionAtLeast(version, minimum string) bool {
+	fields := strings.Split(version, ".")
+	minimumFields := strings.Split(minimum, ".")
+
+	for i := 0; i < len(fields) || i < len(minimumFields); i++ {
+		var v, m int
+		if i < len(fields) {
+			v, _ = strconv.Atoi(fields[i])
+		}
+		if i < len(minimumFields) {
+			m, _ = strconv.Atoi(minimumFields[i])
+		}
+
+		if v != m {
+			return v > m
+		}
+	}
+
+	return true
+}
+
 // GetPGBackRestRepoPath is responsible for determining the repo path setting (i.e. 'repo1-path'
 // flag) for use by pgBackRest.  If a specific repo path has been defined in the pgcluster CR,
 // then that path will be returned.  Otherwise a default path will be returned, which is generated
@@ -93,3 +159,41 @@ func GetPGBackRestRepoPath(cluster crv1.Pgcluster) string {
 	}
 	return fmt.Sprintf(defaultBackrestRepoPath, cluster.Name)
 }
+
+// pgBackRestRetentionOptions are the pgBackRest options that set the retention
+// policy of a repository
+var pgBackRestRetentionOptions = []string{
+	"--repo1-retention-full",
+	"--repo1-retention-diff",
+	"--repo1-retention-archive",
+	"--repo1-retention-archive-type",
+}
+
+// SplitPgBackRestRetentionOptions splits pgBackRest options into the options
+// that set the retention policy of a repository and the remaining ones. The
+// names of the retention options that were found are returned along with the
+// remaining options
+func SplitPgBackRestRetentionOptions(options string) ([]string, string) {
+	found := []string{}
+	remaining := []string{}
+
+	fields := strings.Fields(options)
+	for i := 0; i < len(fields); i++ {
+		name := strings.SplitN(fields[i], "=", 2)[0]
+
+		if !IsStringOneOf(name, pgBackRestRetentionOptions...) {
+			remaining = append(remaining, fields[i])
+			continue
+		}
+
+		found = append(found, name)
+
+		// if the value is not part of the option, it is the field that follows
+		if !strings.Contains(fields[i], "=") && i+1 < len(fields) &&
+			!strings.HasPrefix(fields[i+1], "-") {
+			i++
+		}
+	}
+
+	return found, strings.Join(remaining, " ")
+}
@@ -29,9 +29,16 @@ const (
 	// CloneParameterBackrestPVCSize is the parameter name for the Backrest PVC
 	// size parameter
 	CloneParameterBackrestPVCSize = "backrestPVCSize"
+	// CloneParameterBackupLabel is the parameter name for the label of the
+	// pgBackRest backup that is restored, which defaults to the latest backup
+	CloneParameterBackupLabel = "backupLabel"
 	// CloneParameterEnableMetrics if set to true, enables metrics collection in
 	// a newly created cluster
 	CloneParameterEnableMetrics = "enableMetrics"
+	// CloneParameterPITRTarget is the parameter name for the point-in-time
+	// recovery target of the restore, which defaults to the end of the WAL
+	// archive
+	CloneParameterPITRTarget = "pitrTarget"
 	// CloneParameterPVCSize is the parameter name for the PVC parameter for
 	// primary and replicas
 	CloneParameterPVCSize = "pvcSize"
@@ -41,8 +48,10 @@ const (
 type CloneTask struct {
 	BackrestPVCSize       string
 	BackrestStorageSource string
+	BackupLabel           string
 	EnableMetrics         bool
 	PGOUser               string
+	PITRTarget            string
 	PVCSize               string
 	SourceClusterName     string
 	TargetClusterName     string
@@ -79,7 +88,9 @@ func (clone CloneTask) Create() *crv1.Pgtask {
 			Parameters: map[string]string{
 				CloneParameterBackrestPVCSize: clone.BackrestPVCSize,
 				"backrestStorageType":         clone.BackrestStorageSource,
+				CloneParameterBackupLabel:     clone.BackupLabel,
 				CloneParameterEnableMetrics:   enableMetrics,
+				CloneParameterPITRTarget:      clone.PITRTarget,
 				CloneParameterPVCSize:         clone.PVCSize,
 				"sourceClusterName":           clone.SourceClusterName,
 				"targetClusterName":           clone.TargetClusterName,
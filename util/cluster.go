@@ -16,6 +16,7 @@ package util
 */
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -43,6 +44,13 @@ type BackrestRepoConfig struct {
 	ClusterName         string
 	ClusterNamespace    string
 	OperatorNamespace   string
+	// BackrestGCSKey is the byte string value of the service account key, or
+	// of the token, used to access a GCS pgBackRest repository
+	BackrestGCSKey []byte
+	// BackrestAzureAccount and BackrestAzureKey are the storage account and the
+	// shared or SAS key used to access an Azure pgBackRest repository
+	BackrestAzureAccount string
+	BackrestAzureKey     string
 }
 
 // AWSS3Secret is a structured representation for providing  an AWS S3 key and
@@ -53,6 +61,14 @@ type AWSS3Secret struct {
 	AWSS3KeySecret string
 }
 
+// GCSAzureSecret is a structured representation for providing the GCS key and
+// the Azure storage account and key of a pgBackRest repository
+type GCSAzureSecret struct {
+	GCSKey       []byte
+	AzureAccount string
+	AzureKey     string
+}
+
 const (
 	// DefaultGeneratedPasswordLength is the length of what a generated password
 	// is if it's not set in the pgo.yaml file, and to create some semblance of
@@ -66,11 +82,14 @@ const (
 
 // values for the keys used to access the pgBackRest repository Secret
 const (
-	// three of these are exported, as they are used to help add the information
-	// into the templates. Say the last one 10 times fast
+	// the first of these are exported, as they are used to help add the information
+	// into the templates. Say the third one 10 times fast
 	BackRestRepoSecretKeyAWSS3KeyAWSS3CACert    = "aws-s3-ca.crt"
 	BackRestRepoSecretKeyAWSS3KeyAWSS3Key       = "aws-s3-key"
 	BackRestRepoSecretKeyAWSS3KeyAWSS3KeySecret = "aws-s3-key-secret"
+	BackRestRepoSecretKeyGCSKey                 = "gcs-key"
+	BackRestRepoSecretKeyAzureAccount           = "azure-account"
+	BackRestRepoSecretKeyAzureKey               = "azure-key"
 	// the rest are private
 	backRestRepoSecretKeyAuthorizedKeys    = "authorized_keys"
 	backRestRepoSecretKeySSHConfig         = "config"
@@ -121,7 +140,7 @@ func CreateBackrestRepoSecrets(clientset *kubernetes.Clientset,
 		return err
 	}
 
-	// Retrieve the S3/SSHD configuration files from secret
+	// Retrieve the S3/GCS/Azure/SSHD configuration files from secret
 	configs, err := kubeapi.GetSecret(clientset, "pgo-backrest-repo-config",
 		backrestRepoConfig.OperatorNamespace)
 
@@ -151,6 +170,22 @@ func CreateBackrestRepoSecrets(clientset *kubernetes.Clientset,
 		caCert = configs.Data[BackRestRepoSecretKeyAWSS3KeyAWSS3CACert]
 	}
 
+	// likewise for the GCS and Azure credentials
+	gcsKey := backrestRepoConfig.BackrestGCSKey
+	if len(gcsKey) == 0 {
+		gcsKey = configs.Data[BackRestRepoSecretKeyGCSKey]
+	}
+
+	azureAccount := []byte(backrestRepoConfig.BackrestAzureAccount)
+	if backrestRepoConfig.BackrestAzureAccount == "" {
+		azureAccount = configs.Data[BackRestRepoSecretKeyAzureAccount]
+	}
+
+	azureKey := []byte(backrestRepoConfig.BackrestAzureKey)
+	if backrestRepoConfig.BackrestAzureKey == "" {
+		azureKey = configs.Data[BackRestRepoSecretKeyAzureKey]
+	}
+
 	// set up the secret for the cluster that contains the pgBackRest information
 	secret := v1.Secret{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -166,6 +201,9 @@ func CreateBackrestRepoSecrets(clientset *kubernetes.Clientset,
 			BackRestRepoSecretKeyAWSS3KeyAWSS3CACert:    caCert,
 			BackRestRepoSecretKeyAWSS3KeyAWSS3Key:       backrestS3Key,
 			BackRestRepoSecretKeyAWSS3KeyAWSS3KeySecret: backrestS3KeySecret,
+			BackRestRepoSecretKeyGCSKey:                 gcsKey,
+			BackRestRepoSecretKeyAzureAccount:           azureAccount,
+			BackRestRepoSecretKeyAzureKey:               azureKey,
 			backRestRepoSecretKeyAuthorizedKeys:         keys.Public,
 			backRestRepoSecretKeySSHConfig:              configs.Data[backRestRepoSecretKeySSHConfig],
 			backRestRepoSecretKeySSHDConfig:             configs.Data[backRestRepoSecretKeySSHDConfig],
@@ -234,6 +272,22 @@ func GetPrimaryPod(clientset *kubernetes.Clientset, cluster *crv1.Pgcluster) (*v
 	return &pod, nil
 }
 
+// IsPendingRestart returns true if Patroni reports that PostgreSQL parameters
+// were changed that only take effect once the instance of the Pod is restarted.
+// Patroni publishes the state of each instance in the "status" annotation of
+// its Pod
+func IsPendingRestart(pod *v1.Pod) bool {
+	status := struct {
+		PendingRestart bool `json:"pending_restart"`
+	}{}
+
+	if err := json.Unmarshal([]byte(pod.ObjectMeta.Annotations["status"]), &status); err != nil {
+		return false
+	}
+
+	return status.PendingRestart
+}
+
 // GetS3CredsFromBackrestRepoSecret retrieves the AWS S3 credentials, i.e. the key and key
 // secret, from a specific cluster's backrest repo secret
 func GetS3CredsFromBackrestRepoSecret(clientset *kubernetes.Clientset, namespace, clusterName string) (AWSS3Secret, error) {
@@ -255,6 +309,26 @@ func GetS3CredsFromBackrestRepoSecret(clientset *kubernetes.Clientset, namespace
 	return s3Secret, nil
 }
 
+// GetGCSAzureCredsFromBackrestRepoSecret retrieves the GCS key and the Azure
+// storage account and key from a specific cluster's backrest repo secret
+func GetGCSAzureCredsFromBackrestRepoSecret(clientset *kubernetes.Clientset, namespace, clusterName string) (GCSAzureSecret, error) {
+	secretName := fmt.Sprintf("%s-%s", clusterName, config.LABEL_BACKREST_REPO_SECRET)
+	gcsAzureSecret := GCSAzureSecret{}
+
+	secret, err := kubeapi.GetSecret(clientset, secretName, namespace)
+
+	if err != nil {
+		log.Error(err)
+		return gcsAzureSecret, err
+	}
+
+	gcsAzureSecret.GCSKey = secret.Data[BackRestRepoSecretKeyGCSKey]
+	gcsAzureSecret.AzureAccount = string(secret.Data[BackRestRepoSecretKeyAzureAccount])
+	gcsAzureSecret.AzureKey = string(secret.Data[BackRestRepoSecretKeyAzureKey])
+
+	return gcsAzureSecret, nil
+}
+
 // SetPostgreSQLPassword updates the password for a PostgreSQL role in the
 // PostgreSQL cluster by executing into the primary Pod and changing it
 //
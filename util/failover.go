@@ -36,8 +36,13 @@ type InstanceReplicationInfo struct {
 	Name           string
 	Node           string
 	ReplicationLag int
-	Status         string
-	Timeline       int
+	// ReplicationLagBytes is the replication lag in bytes, which is only set
+	// when it is requested with LagBytes. It is ReplicationLagUnknown when
+	// Patroni cannot determine the lag of the instance
+	ReplicationLagBytes int64
+	Role                string
+	Status              string
+	Timeline            int
 }
 
 type ReplicationStatusRequest struct {
@@ -45,6 +50,12 @@ type ReplicationStatusRequest struct {
 	Clientset   *kubernetes.Clientset
 	Namespace   string
 	ClusterName string
+	// IncludePrimary also returns the information about the primary, which is
+	// otherwise skipped
+	IncludePrimary bool
+	// LagBytes also retrieves the replication lag of each instance in bytes,
+	// as the lag that is otherwise returned is rounded to whole megabytes
+	LagBytes bool
 }
 
 type ReplicationStatusResponse struct {
@@ -62,6 +73,21 @@ type instanceReplicationInfoJSON struct {
 	Timeline       int `json:"TL"`
 }
 
+// patroniClusterJSON is the information returned from the cluster endpoint of
+// the Patroni REST API, which reports the replication lag of the members in
+// bytes. The lag is "unknown" when Patroni cannot determine it, and is absent
+// for the leader
+type patroniClusterJSON struct {
+	Members []struct {
+		Name string      `json:"name"`
+		Lag  interface{} `json:"lag"`
+	} `json:"members"`
+}
+
+// ReplicationLagUnknown is the ReplicationLagBytes of an instance whose
+// replication lag could not be determined
+const ReplicationLagUnknown int64 = -1
+
 const (
 	// instanceReplicationInfoTypePrimary is the label used by Patroni to indicate that an instance
 	// is indeed a primary PostgreSQL instance
@@ -139,6 +165,12 @@ func ReplicationStatus(request ReplicationStatusRequest) (ReplicationStatusRespo
 	selector := fmt.Sprintf("%s=%s,%s=replica",
 		config.LABEL_PG_CLUSTER, request.ClusterName, config.LABEL_PGHA_ROLE)
 
+	// if the primary is included, get all of the PostgreSQL pods instead
+	if request.IncludePrimary {
+		selector = fmt.Sprintf("%s=%s,%s",
+			config.LABEL_PG_CLUSTER, request.ClusterName, config.LABEL_PG_DATABASE)
+	}
+
 	log.Debugf(`searching for pods with "%s"`, selector)
 	pods, err := kubeapi.GetPods(request.Clientset, selector, request.Namespace)
 
@@ -182,26 +214,48 @@ func ReplicationStatus(request ReplicationStatusRequest) (ReplicationStatusRespo
 
 	log.Debugf("patroni instance info: %v", rawInstances)
 
+	// if requested, get the replication lag in bytes from the same pod
+	lagBytes := map[string]int64{}
+	if request.LagBytes {
+		if lagBytes, err = getReplicationLagBytes(request.RESTConfig, request.Clientset, pod); err != nil {
+			return response, err
+		}
+	}
+
 	// We need to iterate through this list to format the information for the
 	// response
 	for _, rawInstance := range rawInstances {
-		// if this is a primary, skip it
-		if rawInstance.Type == instanceReplicationInfoTypePrimary ||
-			rawInstance.Type == instanceReplicationInfoTypePrimaryStandby {
+		// if this is a primary, skip it unless it was requested
+		isPrimary := rawInstance.Type == instanceReplicationInfoTypePrimary ||
+			rawInstance.Type == instanceReplicationInfoTypePrimaryStandby
+
+		if isPrimary && !request.IncludePrimary {
 			continue
 		}
 
 		// set up the instance that will be returned
 		instance := InstanceReplicationInfo{
 			ReplicationLag: rawInstance.ReplicationLag,
+			Role:           config.LABEL_PGHA_ROLE_REPLICA,
 			Status:         rawInstance.State,
 			Timeline:       rawInstance.Timeline,
 		}
 
+		if isPrimary {
+			instance.Role = config.LABEL_PGHA_ROLE_PRIMARY
+		}
+
+		if request.LagBytes {
+			instance.ReplicationLagBytes = ReplicationLagUnknown
+			if lag, ok := lagBytes[rawInstance.PodName]; ok {
+				instance.ReplicationLagBytes = lag
+			}
+		}
+
 		// get the instance name that is recognized by the Operator, which is the
 		// first part of the name and is kept on a deployment label. We have these
-		// available in our instanceNodeMap, and because we skip over the primary,
-		// this will not lead to false positive
+		// available in our instanceNodeMap, and because the pattern includes the
+		// random suffixes of the pod name, this will not lead to false positive
 		//
 		// This is not the cleanest way of doing it, but it works
 		for name, node := range instanceNodeMap {
@@ -231,6 +285,46 @@ func ReplicationStatus(request ReplicationStatusRequest) (ReplicationStatusRespo
 	return response, nil
 }
 
+// getReplicationLagBytes returns the replication lag in bytes of the members of
+// a cluster, keyed by the name of their pods, which it gets from the cluster
+// endpoint of the Patroni REST API from within a pod of the cluster. The lag of
+// the leader is 0, and members with an unknown lag are left out
+func getReplicationLagBytes(restConfig *rest.Config, clientset *kubernetes.Clientset,
+	pod v1.Pod) (map[string]int64, error) {
+	command := []string{"curl", "-s", fmt.Sprintf("http://127.0.0.1:%s/cluster", config.DEFAULT_PATRONI_PORT)}
+
+	stdout, stderr, err := kubeapi.ExecToPodThroughAPI(restConfig, clientset, command,
+		pod.Spec.Containers[0].Name, pod.Name, pod.ObjectMeta.Namespace, nil)
+	if err != nil {
+		log.Error(stderr)
+		return nil, err
+	}
+
+	return parseReplicationLagBytes([]byte(stdout))
+}
+
+// parseReplicationLagBytes parses the response of the cluster endpoint of the
+// Patroni REST API into the replication lag in bytes of each member
+func parseReplicationLagBytes(body []byte) (map[string]int64, error) {
+	cluster := patroniClusterJSON{}
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return nil, fmt.Errorf("could not parse the cluster information from Patroni: %s", err.Error())
+	}
+
+	lagBytes := map[string]int64{}
+
+	for _, member := range cluster.Members {
+		switch lag := member.Lag.(type) {
+		case nil:
+			lagBytes[member.Name] = 0
+		case float64:
+			lagBytes[member.Name] = int64(lag)
+		}
+	}
+
+	return lagBytes, nil
+}
+
 // ToggleAutoFailover enables or disables autofailover for a cluster.  Disabling autofailover means "pausing"
 // Patroni, which will result in Patroni stepping aside from managing the cluster.  This will effectively cause
 // Patroni to stop responding to failures or other database activities, e.g. it will not attempt to start the
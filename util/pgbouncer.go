@@ -17,6 +17,8 @@ package util
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
 )
@@ -30,6 +32,113 @@ const pgBouncerSecretFormat = "%s-pgbouncer-secret"
 // file looks like, i.e. `"username" "password"``
 const pgBouncerUserFileFormat = `"%s" "%s"`
 
+// pgBouncerSettingKind describes the values a pgBouncer setting accepts
+type pgBouncerSettingKind int
+
+const (
+	// pgBouncerSettingInteger is a whole number that is 0 or greater
+	pgBouncerSettingInteger pgBouncerSettingKind = iota
+	// pgBouncerSettingSeconds is a number of seconds that is 0 or greater, and
+	// may have a fractional part
+	pgBouncerSettingSeconds
+	// pgBouncerSettingBoolean is either "0" or "1"
+	pgBouncerSettingBoolean
+	// pgBouncerSettingPoolMode is one of the pool modes of pgBouncer
+	pgBouncerSettingPoolMode
+	// pgBouncerSettingString is any value that fits on a single line
+	pgBouncerSettingString
+	// pgBouncerSettingWord is any value without whitespace, as it is part of a
+	// connection string
+	pgBouncerSettingWord
+)
+
+// pgBouncerPoolModes are the pool modes of pgBouncer
+var pgBouncerPoolModes = []string{"session", "transaction", "statement"}
+
+// pgBouncerSettings are the settings of the "[pgbouncer]" section of the
+// pgbouncer.ini file that can be set on a cluster. The settings that the
+// Operator relies on, such as those for the port, the authentication or the
+// administrative users, are not among them
+var pgBouncerSettings = map[string]pgBouncerSettingKind{
+	"application_name_add_host": pgBouncerSettingBoolean,
+	"autodb_idle_timeout":       pgBouncerSettingSeconds,
+	"client_idle_timeout":       pgBouncerSettingSeconds,
+	"client_login_timeout":      pgBouncerSettingSeconds,
+	"default_pool_size":         pgBouncerSettingInteger,
+	"disable_pqexec":            pgBouncerSettingBoolean,
+	"idle_transaction_timeout":  pgBouncerSettingSeconds,
+	"ignore_startup_parameters": pgBouncerSettingString,
+	"log_connections":           pgBouncerSettingBoolean,
+	"log_disconnections":        pgBouncerSettingBoolean,
+	"log_pooler_errors":         pgBouncerSettingBoolean,
+	"max_client_conn":           pgBouncerSettingInteger,
+	"max_db_connections":        pgBouncerSettingInteger,
+	"max_user_connections":      pgBouncerSettingInteger,
+	"min_pool_size":             pgBouncerSettingInteger,
+	"pool_mode":                 pgBouncerSettingPoolMode,
+	"query_timeout":             pgBouncerSettingSeconds,
+	"query_wait_timeout":        pgBouncerSettingSeconds,
+	"reserve_pool_size":         pgBouncerSettingInteger,
+	"reserve_pool_timeout":      pgBouncerSettingSeconds,
+	"server_check_delay":        pgBouncerSettingSeconds,
+	"server_check_query":        pgBouncerSettingString,
+	"server_connect_timeout":    pgBouncerSettingSeconds,
+	"server_idle_timeout":       pgBouncerSettingSeconds,
+	"server_lifetime":           pgBouncerSettingSeconds,
+	"server_login_retry":        pgBouncerSettingSeconds,
+	"server_reset_query":        pgBouncerSettingString,
+	"server_reset_query_always": pgBouncerSettingBoolean,
+	"server_round_robin":        pgBouncerSettingBoolean,
+	"stats_period":              pgBouncerSettingSeconds,
+	"tcp_keepalive":             pgBouncerSettingBoolean,
+	"verbose":                   pgBouncerSettingInteger,
+}
+
+// pgBouncerDatabaseSettings are the settings of the connection to a database in
+// the "[databases]" section of the pgbouncer.ini file that can be set on a
+// cluster
+var pgBouncerDatabaseSettings = map[string]pgBouncerSettingKind{
+	"client_encoding":    pgBouncerSettingWord,
+	"max_db_connections": pgBouncerSettingInteger,
+	"pool_mode":          pgBouncerSettingPoolMode,
+	"pool_size":          pgBouncerSettingInteger,
+	"reserve_pool":       pgBouncerSettingInteger,
+	"timezone":           pgBouncerSettingWord,
+}
+
+// validate returns an error if a value is not one that the kind of setting
+// accepts
+func (kind pgBouncerSettingKind) validate(value string) error {
+	switch kind {
+	case pgBouncerSettingInteger:
+		if i, err := strconv.Atoi(value); err != nil || i < 0 {
+			return fmt.Errorf("%q must be a whole number that is 0 or greater", value)
+		}
+	case pgBouncerSettingSeconds:
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f < 0 {
+			return fmt.Errorf("%q must be a number of seconds that is 0 or greater", value)
+		}
+	case pgBouncerSettingBoolean:
+		if value != "0" && value != "1" {
+			return fmt.Errorf("%q must be either \"0\" or \"1\"", value)
+		}
+	case pgBouncerSettingPoolMode:
+		if !IsStringOneOf(value, pgBouncerPoolModes...) {
+			return fmt.Errorf("%q must be one of: %s", value, strings.Join(pgBouncerPoolModes, ", "))
+		}
+	case pgBouncerSettingString:
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%q must not contain line breaks", value)
+		}
+	case pgBouncerSettingWord:
+		if value == "" || strings.ContainsAny(value, " \t\r\n'\\") {
+			return fmt.Errorf("%q must not be empty or contain whitespace, quotes or backslashes", value)
+		}
+	}
+
+	return nil
+}
+
 // GeneratePgBouncerSecretName returns the name of the secret that contains
 // information around a pgBouncer deployment
 func GeneratePgBouncerSecretName(clusterName string) string {
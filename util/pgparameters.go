@@ -0,0 +1,273 @@
+package util
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// postgresParameterKind describes the values a PostgreSQL parameter accepts
+type postgresParameterKind int
+
+const (
+	// postgresParameterBoolean is one of the spellings of a boolean PostgreSQL
+	// accepts, e.g. "on" or "off"
+	postgresParameterBoolean postgresParameterKind = iota
+	// postgresParameterInteger is a whole number
+	postgresParameterInteger
+	// postgresParameterReal is a number that may have a fractional part
+	postgresParameterReal
+	// postgresParameterMemory is an amount of memory, which is in the unit of
+	// the parameter unless it has one of the memory units, e.g. "128MB"
+	postgresParameterMemory
+	// postgresParameterTime is a duration, which is in the unit of the
+	// parameter unless it has one of the time units, e.g. "5min"
+	postgresParameterTime
+	// postgresParameterEnum is one of the values of the parameter
+	postgresParameterEnum
+	// postgresParameterString is any value that fits on a single line
+	postgresParameterString
+)
+
+const (
+	// PostgresParameterContextPostmaster is the context of the PostgreSQL
+	// parameters that only take effect once PostgreSQL is restarted
+	PostgresParameterContextPostmaster = "postmaster"
+	// PostgresParameterContextSighup is the context of the PostgreSQL
+	// parameters that take effect once PostgreSQL reloads its configuration
+	PostgresParameterContextSighup = "sighup"
+	// PostgresParameterContextSuperuserBackend is the context of the PostgreSQL
+	// parameters that take effect for new sessions
+	PostgresParameterContextSuperuserBackend = "superuser-backend"
+	// PostgresParameterContextSuperuser is the context of the PostgreSQL
+	// parameters that superusers can also change in a session
+	PostgresParameterContextSuperuser = "superuser"
+	// PostgresParameterContextUser is the context of the PostgreSQL parameters
+	// that any user can also change in a session
+	PostgresParameterContextUser = "user"
+)
+
+// postgresParameter is what is known about a PostgreSQL parameter, i.e. the
+// values it accepts and when a change to it takes effect
+type postgresParameter struct {
+	kind postgresParameterKind
+	// context is one of the PostgresParameterContext* constants
+	context string
+	// unit is the unit of a memory or time parameter that has none, e.g. "8kB"
+	// for the parameters that are in blocks of 8kB
+	unit string
+	// min and max are the range of a numeric parameter, in its unit
+	min, max float64
+	// values are the values of an enum parameter
+	values []string
+}
+
+// postgresUnit is a unit of a memory or time parameter along with its size in
+// the smallest unit of its kind
+type postgresUnit struct {
+	name string
+	size float64
+}
+
+// postgresMemoryUnits are the memory units PostgreSQL accepts, in kB
+var postgresMemoryUnits = []postgresUnit{
+	{name: "kB", size: 1}, {name: "MB", size: 1024}, {name: "GB", size: 1024 * 1024},
+	{name: "TB", size: 1024 * 1024 * 1024},
+}
+
+// postgresTimeUnits are the time units PostgreSQL accepts, in microseconds
+var postgresTimeUnits = []postgresUnit{
+	{name: "us", size: 1}, {name: "ms", size: 1000}, {name: "s", size: 1000 * 1000},
+	{name: "min", size: 60 * 1000 * 1000}, {name: "h", size: 60 * 60 * 1000 * 1000},
+	{name: "d", size: 24 * 60 * 60 * 1000 * 1000},
+}
+
+// postgresBooleans are the spellings of a boolean PostgreSQL accepts
+var postgresBooleans = []string{"on", "off", "true", "false", "yes", "no", "1", "0"}
+
+// postgresParameters are the PostgreSQL parameters that can be set on a
+// cluster along with the values they accept, as of PostgreSQL 12. The
+// parameters that the Operator or Patroni rely on, such as those for the port,
+// the data directory, TLS, archiving or replication connections, are not among
+// them
+var postgresParameters = map[string]postgresParameter{
+	"autovacuum":                          {kind: postgresParameterBoolean, context: PostgresParameterContextSighup},
+	"autovacuum_analyze_scale_factor":     {kind: postgresParameterReal, context: PostgresParameterContextSighup, min: 0, max: 100},
+	"autovacuum_max_workers":              {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 1, max: 262143},
+	"autovacuum_naptime":                  {kind: postgresParameterTime, context: PostgresParameterContextSighup, unit: "s", min: 1, max: 2147483},
+	"autovacuum_vacuum_cost_delay":        {kind: postgresParameterTime, context: PostgresParameterContextSighup, unit: "ms", min: -1, max: 100},
+	"autovacuum_vacuum_cost_limit":        {kind: postgresParameterInteger, context: PostgresParameterContextSighup, min: -1, max: 10000},
+	"autovacuum_vacuum_scale_factor":      {kind: postgresParameterReal, context: PostgresParameterContextSighup, min: 0, max: 100},
+	"autovacuum_work_mem":                 {kind: postgresParameterMemory, context: PostgresParameterContextSighup, unit: "kB", min: -1, max: 2147483647},
+	"checkpoint_completion_target":        {kind: postgresParameterReal, context: PostgresParameterContextSighup, min: 0, max: 1},
+	"checkpoint_timeout":                  {kind: postgresParameterTime, context: PostgresParameterContextSighup, unit: "s", min: 30, max: 86400},
+	"default_statistics_target":           {kind: postgresParameterInteger, context: PostgresParameterContextUser, min: 1, max: 10000},
+	"default_transaction_isolation":       {kind: postgresParameterEnum, context: PostgresParameterContextUser, values: []string{"serializable", "repeatable read", "read committed", "read uncommitted"}},
+	"effective_cache_size":                {kind: postgresParameterMemory, context: PostgresParameterContextUser, unit: "8kB", min: 1, max: 2147483647},
+	"effective_io_concurrency":            {kind: postgresParameterInteger, context: PostgresParameterContextUser, min: 0, max: 1000},
+	"hot_standby_feedback":                {kind: postgresParameterBoolean, context: PostgresParameterContextSighup},
+	"huge_pages":                          {kind: postgresParameterEnum, context: PostgresParameterContextPostmaster, values: []string{"off", "on", "try"}},
+	"idle_in_transaction_session_timeout": {kind: postgresParameterTime, context: PostgresParameterContextUser, unit: "ms", min: 0, max: 2147483647},
+	"jit":                                 {kind: postgresParameterBoolean, context: PostgresParameterContextUser},
+	"lock_timeout":                        {kind: postgresParameterTime, context: PostgresParameterContextUser, unit: "ms", min: 0, max: 2147483647},
+	"log_autovacuum_min_duration":         {kind: postgresParameterTime, context: PostgresParameterContextSighup, unit: "ms", min: -1, max: 2147483647},
+	"log_checkpoints":                     {kind: postgresParameterBoolean, context: PostgresParameterContextSighup},
+	"log_connections":                     {kind: postgresParameterBoolean, context: PostgresParameterContextSuperuserBackend},
+	"log_disconnections":                  {kind: postgresParameterBoolean, context: PostgresParameterContextSuperuserBackend},
+	"log_line_prefix":                     {kind: postgresParameterString, context: PostgresParameterContextSighup},
+	"log_lock_waits":                      {kind: postgresParameterBoolean, context: PostgresParameterContextSuperuser},
+	"log_min_duration_statement":          {kind: postgresParameterTime, context: PostgresParameterContextSuperuser, unit: "ms", min: -1, max: 2147483647},
+	"log_statement":                       {kind: postgresParameterEnum, context: PostgresParameterContextSuperuser, values: []string{"none", "ddl", "mod", "all"}},
+	"log_temp_files":                      {kind: postgresParameterMemory, context: PostgresParameterContextSuperuser, unit: "kB", min: -1, max: 2147483647},
+	"log_timezone":                        {kind: postgresParameterString, context: PostgresParameterContextSighup},
+	"maintenance_work_mem":                {kind: postgresParameterMemory, context: PostgresParameterContextUser, unit: "kB", min: 1024, max: 2147483647},
+	"max_connections":                     {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 1, max: 262143},
+	"max_parallel_maintenance_workers":    {kind: postgresParameterInteger, context: PostgresParameterContextUser, min: 0, max: 1024},
+	"max_parallel_workers":                {kind: postgresParameterInteger, context: PostgresParameterContextUser, min: 0, max: 1024},
+	"max_parallel_workers_per_gather":     {kind: postgresParameterInteger, context: PostgresParameterContextUser, min: 0, max: 1024},
+	"max_prepared_transactions":           {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 0, max: 262143},
+	"max_replication_slots":               {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 0, max: 262143},
+	"max_wal_senders":                     {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 0, max: 262143},
+	"max_wal_size":                        {kind: postgresParameterMemory, context: PostgresParameterContextSighup, unit: "MB", min: 2, max: 2147483647},
+	"max_worker_processes":                {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 0, max: 262143},
+	"min_wal_size":                        {kind: postgresParameterMemory, context: PostgresParameterContextSighup, unit: "MB", min: 2, max: 2147483647},
+	"password_encryption":                 {kind: postgresParameterEnum, context: PostgresParameterContextUser, values: []string{"md5", "scram-sha-256"}},
+	"random_page_cost":                    {kind: postgresParameterReal, context: PostgresParameterContextUser, min: 0, max: math.MaxFloat64},
+	"seq_page_cost":                       {kind: postgresParameterReal, context: PostgresParameterContextUser, min: 0, max: math.MaxFloat64},
+	"shared_buffers":                      {kind: postgresParameterMemory, context: PostgresParameterContextPostmaster, unit: "8kB", min: 16, max: 1073741823},
+	"statement_timeout":                   {kind: postgresParameterTime, context: PostgresParameterContextUser, unit: "ms", min: 0, max: 2147483647},
+	"synchronous_commit":                  {kind: postgresParameterEnum, context: PostgresParameterContextUser, values: []string{"local", "remote_write", "remote_apply", "on", "off"}},
+	"temp_buffers":                        {kind: postgresParameterMemory, context: PostgresParameterContextUser, unit: "8kB", min: 100, max: 1073741823},
+	"timezone":                            {kind: postgresParameterString, context: PostgresParameterContextUser},
+	"track_activity_query_size":           {kind: postgresParameterInteger, context: PostgresParameterContextPostmaster, min: 100, max: 1048576},
+	"track_io_timing":                     {kind: postgresParameterBoolean, context: PostgresParameterContextSuperuser},
+	"wal_buffers":                         {kind: postgresParameterMemory, context: PostgresParameterContextPostmaster, unit: "8kB", min: -1, max: 262143},
+	"wal_compression":                     {kind: postgresParameterBoolean, context: PostgresParameterContextSuperuser},
+	"wal_keep_segments":                   {kind: postgresParameterInteger, context: PostgresParameterContextSighup, min: 0, max: 2147483647},
+	"work_mem":                            {kind: postgresParameterMemory, context: PostgresParameterContextUser, unit: "kB", min: 64, max: 2147483647},
+}
+
+// PostgresParameterRequiresRestart returns true if a change to a PostgreSQL
+// parameter only takes effect once PostgreSQL is restarted
+func PostgresParameterRequiresRestart(name string) bool {
+	return postgresParameters[name].context == PostgresParameterContextPostmaster
+}
+
+// validate returns an error if a value is not one that the parameter accepts
+func (parameter postgresParameter) validate(value string) error {
+	switch parameter.kind {
+	case postgresParameterBoolean:
+		if !IsStringOneOf(strings.ToLower(value), postgresBooleans...) {
+			return fmt.Errorf("%q must be one of: %s", value, strings.Join(postgresBooleans, ", "))
+		}
+	case postgresParameterInteger:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q must be a whole number", value)
+		}
+		return parameter.validateRange(value, float64(i))
+	case postgresParameterReal:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q must be a number", value)
+		}
+		return parameter.validateRange(value, f)
+	case postgresParameterMemory:
+		return parameter.validateQuantity(value, postgresMemoryUnits)
+	case postgresParameterTime:
+		return parameter.validateQuantity(value, postgresTimeUnits)
+	case postgresParameterEnum:
+		if !IsStringOneOf(strings.ToLower(value), parameter.values...) {
+			return fmt.Errorf("%q must be one of: %s", value, strings.Join(parameter.values, ", "))
+		}
+	case postgresParameterString:
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%q must not contain line breaks", value)
+		}
+	}
+
+	return nil
+}
+
+// validateQuantity returns an error if a memory or time value is not a number
+// that is followed by nothing or by one of the units, or if it is out of the
+// range of the parameter once converted into the unit of the parameter
+func (parameter postgresParameter) validateQuantity(value string, units []postgresUnit) error {
+	number := strings.TrimRightFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	unit := strings.TrimSpace(value[len(number):])
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return fmt.Errorf("%q must be a number optionally followed by a unit", value)
+	}
+
+	if unit != "" {
+		names := make([]string, len(units))
+		size := 0.0
+		for i, u := range units {
+			names[i] = u.name
+			if u.name == unit {
+				size = u.size
+			}
+		}
+		if size == 0 {
+			return fmt.Errorf("%q has an invalid unit. The following units are allowed: %s", value,
+				strings.Join(names, ", "))
+		}
+		f = f * size / parameter.unitSize(units)
+	}
+
+	return parameter.validateRange(value, f)
+}
+
+// unitSize returns the size of the unit of a memory or time parameter in the
+// smallest unit of its kind
+func (parameter postgresParameter) unitSize(units []postgresUnit) float64 {
+	// the parameters that are in blocks are the only ones whose unit is not
+	// one of the units PostgreSQL accepts in a value
+	if parameter.unit == "8kB" {
+		return 8
+	}
+
+	for _, u := range units {
+		if u.name == parameter.unit {
+			return u.size
+		}
+	}
+
+	return 1
+}
+
+// validateRange returns an error if a number is out of the range of the
+// parameter
+func (parameter postgresParameter) validateRange(value string, f float64) error {
+	if f < parameter.min || f > parameter.max {
+		unit := ""
+		if parameter.unit != "" {
+			unit = " " + parameter.unit
+		}
+		return fmt.Errorf("%q must be between %s and %s%s", value,
+			strconv.FormatFloat(parameter.min, 'g', 10, 64), strconv.FormatFloat(parameter.max, 'g', 10, 64),
+			unit)
+	}
+
+	return nil
+}
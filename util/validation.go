@@ -0,0 +1,409 @@
+package util
+
+/*
+ Copyright 2020 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	crv1 "github.com/crunchydata/postgres-operator/apis/crunchydata.com/v1"
+	"github.com/crunchydata/postgres-operator/kubeapi"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The validators in this file are shared by the apiserver, which validates the
+// requests it receives, and by the admission webhook of the Operator, which
+// validates the custom resources that are applied directly
+
+// storageTypes are the valid storage types of a storage specification
+var storageTypes = []string{"", "emptydir", "existing", "create", "dynamic"}
+
+// backrestRetentionArchiveTypes are the valid types of backup the WAL archive
+// retention of a pgBackRest repository can refer to
+var backrestRetentionArchiveTypes = []string{"", "full", "diff", "incr"}
+
+// maxBackrestRetention is the largest retention count pgBackRest accepts
+const maxBackrestRetention = 9999999
+
+// ValidateBackrestStorageType validates the pgBackRest storage type of a cluster.
+// This includes ensuring the type provided is valid, and that the required
+// configuration settings (s3 bucket, region, etc.) are present, either in the
+// cluster or in the pgo.yaml configuration
+func ValidateBackrestStorageType(storageType, s3Bucket, s3Endpoint, s3Region string) error {
+	if storageType != "" && !IsValidBackrestStorageType(storageType) {
+		return fmt.Errorf("Invalid value provided for pgBackRest storage type. The following values are allowed: %s",
+			"\""+strings.Join(crv1.BackrestStorageTypes, "\", \"")+"\"")
+	} else if strings.Contains(storageType, "s3") && (s3Bucket == "" || s3Endpoint == "" || s3Region == "") {
+		return errors.New("A configuration setting for AWS S3 storage is missing. Values must be " +
+			"provided for the S3 bucket, S3 endpoint and S3 region in order to use the 's3' " +
+			"storage type with pgBackRest.")
+	}
+
+	return nil
+}
+
+// ValidateBackrestGCSAzureStorage validates the GCS or Azure storage of the
+// pgBackRest repository of a cluster. Such storage cannot be combined with
+// other storage types, and its bucket or container must be present, either in
+// the cluster or in the pgo.yaml configuration, along with a valid key type
+func ValidateBackrestGCSAzureStorage(storageType, gcsBucket, gcsKeyType, azureContainer,
+	azureKeyType, azureURIStyle string) error {
+	storageTypes := strings.Split(storageType, ",")
+
+	for _, t := range []string{"gcs", "azure"} {
+		if len(storageTypes) > 1 && IsStringOneOf(t, storageTypes...) {
+			return fmt.Errorf("The '%s' storage type cannot be combined with other storage types. "+
+				"Add a pgBackRest repository for each additional storage instead.", t)
+		}
+	}
+
+	switch storageType {
+	case "gcs":
+		if gcsBucket == "" {
+			return errors.New("A configuration setting for GCS storage is missing. A value must be " +
+				"provided for the GCS bucket in order to use the 'gcs' storage type with pgBackRest.")
+		}
+		if !IsStringOneOf(gcsKeyType, crv1.BackrestGCSKeyTypes...) {
+			return fmt.Errorf("invalid GCS key type %q. The following values are allowed: %s",
+				gcsKeyType, "\""+strings.Join(crv1.BackrestGCSKeyTypes[1:], "\", \"")+"\"")
+		}
+		return validateBackrestVersion("The 'gcs' storage type", backrestGCSVersion)
+	case "azure":
+		if azureContainer == "" {
+			return errors.New("A configuration setting for Azure storage is missing. A value must be " +
+				"provided for the Azure container in order to use the 'azure' storage type with pgBackRest.")
+		}
+		if err := validateBackrestAzureKeyTypeAndURIStyle(azureKeyType, azureURIStyle); err != nil {
+			return err
+		}
+		return validateBackrestVersion("The 'azure' storage type", backrestAzureVersion)
+	}
+
+	return nil
+}
+
+// validateBackrestAzureKeyTypeAndURIStyle validates the type of the key and the
+// style of the URIs of an Azure pgBackRest repository
+func validateBackrestAzureKeyTypeAndURIStyle(keyType, uriStyle string) error {
+	if !IsStringOneOf(keyType, crv1.BackrestAzureKeyTypes...) {
+		return fmt.Errorf("invalid Azure key type %q. The following values are allowed: %s",
+			keyType, "\""+strings.Join(crv1.BackrestAzureKeyTypes[1:], "\", \"")+"\"")
+	}
+
+	if !IsStringOneOf(uriStyle, crv1.BackrestAzureURIStyles...) {
+		return fmt.Errorf("invalid Azure URI style %q. The following values are allowed: %s",
+			uriStyle, "\""+strings.Join(crv1.BackrestAzureURIStyles[1:], "\", \"")+"\"")
+	}
+
+	return nil
+}
+
+// ValidateClusterTLS validates the settings that enable TLS connections to a
+// PostgreSQL cluster, including that the Secrets they refer to exist
+func ValidateClusterTLS(clientset *kubernetes.Clientset, namespace string, tlsOnly bool,
+	tlsSecret, caSecret string) error {
+	// if TLSOnly is not set and  neither TLSSecret no CASecret are set, just return
+	if !tlsOnly && tlsSecret == "" && caSecret == "" {
+		return nil
+	}
+
+	// if TLS only is set, but there is no TLSSecret nor CASecret, return
+	if tlsOnly && !(tlsSecret != "" && caSecret != "") {
+		return fmt.Errorf("TLS only clusters requires both a TLS secret and CA secret")
+	}
+	// if TLSSecret or CASecret is set, but not both are set, return
+	if (tlsSecret != "" && caSecret == "") || (tlsSecret == "" && caSecret != "") {
+		return fmt.Errorf("Both TLS secret and CA secret must be set in order to enable TLS for PostgreSQL")
+	}
+
+	// now check for the existence of the two secrets
+	// First the TLS secret
+	if _, err := kubeapi.GetSecret(clientset, tlsSecret, namespace); err != nil {
+		return err
+	}
+
+	// then, the CA secret
+	if _, err := kubeapi.GetSecret(clientset, caSecret, namespace); err != nil {
+		return err
+	}
+
+	// after this, we are validated!
+	return nil
+}
+
+// ValidateBackrestRetention validates the retention policy of a pgBackRest
+// repository, i.e. that its counts are within the range pgBackRest accepts and
+// that its archive retention type is known
+func ValidateBackrestRetention(retention crv1.PgBackRestRetentionSpec) error {
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{name: "full", value: retention.Full},
+		{name: "diff", value: retention.Diff},
+		{name: "archive", value: retention.Archive},
+	}
+
+	for _, c := range counts {
+		if c.value < 0 || c.value > maxBackrestRetention {
+			return fmt.Errorf("pgBackRest %s retention %d must be between 0 and %d",
+				c.name, c.value, maxBackrestRetention)
+		}
+	}
+
+	if !IsStringOneOf(retention.ArchiveType, backrestRetentionArchiveTypes...) {
+		return fmt.Errorf("invalid pgBackRest archive retention type %q. The following values are allowed: %s",
+			retention.ArchiveType, "\""+strings.Join(backrestRetentionArchiveTypes[1:], "\", \"")+"\"")
+	}
+
+	return nil
+}
+
+// ValidateBackrestRepos validates the pgBackRest repositories of a cluster, i.e.
+// that the first is the local repository, that the others are remote
+// repositories that can be located and accessed, and that their retention
+// policies are valid. Clusters with repositories send their backups to the
+// local repository, so their storage type must be "local" if it is set
+func ValidateBackrestRepos(repos []crv1.PgBackRestRepoSpec, storageType string) error {
+	if len(repos) == 0 {
+		return nil
+	}
+
+	if len(repos) > crv1.MaxBackrestRepos {
+		return fmt.Errorf("a cluster can have at most %d pgBackRest repositories", crv1.MaxBackrestRepos)
+	}
+
+	if storageType != "" && storageType != "local" {
+		return fmt.Errorf("pgBackRest storage type %q cannot be combined with pgBackRest repositories, "+
+			"add a repository for each remote storage instead", storageType)
+	}
+
+	// the first repository is the one of the pgBackRest repository Deployment,
+	// which is configured by the settings of the cluster
+	local := repos[0]
+	if local.Type != "local" {
+		return fmt.Errorf("pgBackRest repository 1 must be of type \"local\", not %q", local.Type)
+	}
+	if local != (crv1.PgBackRestRepoSpec{Type: local.Type, Schedule: local.Schedule}) {
+		return errors.New("pgBackRest repository 1 is the local repository, and only its schedule can be set")
+	}
+
+	for i, repo := range repos[1:] {
+		n := i + 2
+
+		if !IsStringOneOf(repo.Type, crv1.BackrestRemoteRepoTypes...) {
+			return fmt.Errorf("invalid type %q of pgBackRest repository %d. The following values are allowed: %s",
+				repo.Type, n, "\""+strings.Join(crv1.BackrestRemoteRepoTypes, "\", \"")+"\"")
+		}
+
+		if repo.Secret == "" {
+			return fmt.Errorf("pgBackRest repository %d needs a Secret with its credentials", n)
+		}
+
+		switch repo.Type {
+		case "s3":
+			if repo.S3Bucket == "" || repo.S3Endpoint == "" || repo.S3Region == "" {
+				return fmt.Errorf("pgBackRest repository %d needs an S3 bucket, endpoint and region", n)
+			}
+		case "azure":
+			if repo.AzureContainer == "" {
+				return fmt.Errorf("pgBackRest repository %d needs an Azure container", n)
+			}
+			if err := validateBackrestAzureKeyTypeAndURIStyle(repo.AzureKeyType, repo.AzureURIStyle); err != nil {
+				return fmt.Errorf("pgBackRest repository %d: %s", n, err.Error())
+			}
+		}
+
+		if err := ValidateBackrestRetention(repo.Retention); err != nil {
+			return fmt.Errorf("pgBackRest repository %d: %s", n, err.Error())
+		}
+	}
+
+	// the repositories besides the local one are only supported by newer
+	// versions of pgBackRest
+	if len(repos) > 1 {
+		return validateBackrestVersion("more than one pgBackRest repository", backrestMultiRepoVersion)
+	}
+
+	return nil
+}
+
+// PatroniVersion is the version of Patroni in the PostgreSQL containers that the
+// PostgreSQL Operator is released with, i.e. those of CCPImageTag
+const PatroniVersion = "1.6.5"
+
+// patroniSyncNodeCountVersion is the first version of Patroni that supports more
+// than one synchronous standby, i.e. "synchronous_node_count"
+const patroniSyncNodeCountVersion = "2.0"
+
+// ValidateSyncReplicationSettings validates the synchronous replication
+// settings of a cluster, i.e. that the number of synchronous standbys is not
+// negative and is supported by Patroni, and that each excluded replica is named
+// once
+func ValidateSyncReplicationSettings(settings *crv1.SyncReplicationSpec) error {
+	if settings == nil {
+		return nil
+	}
+
+	if settings.NodeCount < 0 {
+		return fmt.Errorf("sync replication node count %d must be 0 or greater", settings.NodeCount)
+	}
+
+	// older versions of Patroni ignore "synchronous_node_count" and only keep
+	// a single synchronous standby
+	if settings.NodeCount > 1 && !isVersionAtLeast(PatroniVersion, patroniSyncNodeCountVersion) {
+		return fmt.Errorf("a sync replication node count above 1 requires Patroni %s or later, but the "+
+			"PostgreSQL containers provide Patroni %s", patroniSyncNodeCountVersion, PatroniVersion)
+	}
+
+	for i, replica := range settings.ExcludedReplicas {
+		if replica == "" {
+			return errors.New("the name of a replica excluded from sync replication cannot be empty")
+		}
+		if IsStringOneOf(replica, settings.ExcludedReplicas[:i]...) {
+			return fmt.Errorf("replica %q is excluded from sync replication more than once", replica)
+		}
+	}
+
+	return nil
+}
+
+// ValidatePostgresParameters validates the PostgreSQL parameters of a cluster,
+// i.e. that each parameter can be set on a cluster and that its value is one
+// the parameter accepts
+func ValidatePostgresParameters(parameters map[string]string) error {
+	for _, name := range sortedKeys(parameters) {
+		parameter, ok := postgresParameters[name]
+		if !ok {
+			return fmt.Errorf("PostgreSQL parameter %q is unknown or managed by the Operator", name)
+		}
+
+		if err := parameter.validate(parameters[name]); err != nil {
+			return fmt.Errorf("PostgreSQL parameter %q: %s", name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ValidatePgBouncerConfig validates the pgBouncer settings of a cluster, i.e.
+// that each setting can be set on a cluster and that its value is one the
+// setting accepts, both for the "[pgbouncer]" section and for each database
+func ValidatePgBouncerConfig(settings map[string]string, databases map[string]map[string]string) error {
+	for _, name := range sortedKeys(settings) {
+		kind, ok := pgBouncerSettings[name]
+		if !ok {
+			return fmt.Errorf("pgBouncer setting %q is unknown or managed by the Operator", name)
+		}
+
+		if err := kind.validate(settings[name]); err != nil {
+			return fmt.Errorf("pgBouncer setting %q: %s", name, err.Error())
+		}
+	}
+
+	databaseNames := make([]string, 0, len(databases))
+	for name := range databases {
+		databaseNames = append(databaseNames, name)
+	}
+	sort.Strings(databaseNames)
+
+	for _, database := range databaseNames {
+		// "pgbouncer" is the administrative console, and "*" is the fallback
+		// connection the Operator manages
+		if database == "" || database == "*" || database == "pgbouncer" ||
+			strings.ContainsAny(database, " \t\r\n=[]'\"\\") {
+			return fmt.Errorf("invalid pgBouncer database %q", database)
+		}
+
+		for _, name := range sortedKeys(databases[database]) {
+			kind, ok := pgBouncerDatabaseSettings[name]
+			if !ok {
+				return fmt.Errorf("pgBouncer setting %q cannot be set for database %q", name, database)
+			}
+
+			if err := kind.validate(databases[database][name]); err != nil {
+				return fmt.Errorf("pgBouncer setting %q of database %q: %s", name, database, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateStandbyCluster validates the settings required to create a standby
+// cluster
+func ValidateStandbyCluster(backrestStorageType, backrestRepoPath string) error {
+	switch {
+	case !strings.Contains(backrestStorageType, "s3"):
+		return errors.New("Backrest storage type 's3' must be selected in order to create a " +
+			"standby cluster")
+	case backrestRepoPath == "":
+		return errors.New("A pgBackRest repository path must be specified when creating a " +
+			"standby cluster")
+	}
+	return nil
+}
+
+// ValidateStorageSpec validates a storage specification, i.e. its storage type,
+// size and match labels
+func ValidateStorageSpec(spec crv1.PgStorageSpec) error {
+	if !IsStringOneOf(spec.StorageType, storageTypes...) {
+		return fmt.Errorf("invalid storage type %q. The following values are allowed: %s",
+			spec.StorageType, "\""+strings.Join(storageTypes[1:], "\", \"")+"\"")
+	}
+
+	if err := ValidateQuantity(spec.Size); err != nil {
+		return fmt.Errorf(`could not parse PVC size "%s": %s (hint: try a value like "1Gi")`,
+			spec.Size, err.Error())
+	}
+
+	if spec.MatchLabels != "" && len(strings.Split(spec.MatchLabels, "=")) != 2 {
+		return fmt.Errorf("match labels %q need to be in key=value format", spec.MatchLabels)
+	}
+
+	return nil
+}
+
+// ValidateQuantity runs the Kubernetes "ParseQuantity" function on a string
+// and determine whether or not it is a valid quantity object. Returns an error
+// if it is invalid, along with the error message. An empty string is not an
+// error, as the quantity is then left unset.
+//
+// See: https://github.com/kubernetes/apimachinery/blob/master/pkg/api/resource/quantity.go
+func ValidateQuantity(quantity string) error {
+	if quantity == "" {
+		return nil
+	}
+
+	_, err := resource.ParseQuantity(quantity)
+	return err
+}
+
+// sortedKeys returns the keys of a map in order, so that the settings of a map
+// are always validated in the same order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}